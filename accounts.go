@@ -0,0 +1,203 @@
+package trading212
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// AccountSet holds multiple named Trading212 accounts (e.g. Invest and Stocks ISA),
+// each with its own API key. Read-only queries fan out across every account, while
+// order placement is only available through the client returned by Account.
+type AccountSet struct {
+	mu      sync.RWMutex
+	names   []string
+	clients map[string]*Client
+}
+
+// AccountSummary holds the state fetched for a single account
+type AccountSummary struct {
+	Name      string
+	Info      *AccountInfo
+	Cash      *CashInfo
+	Portfolio []Position
+	Err       error
+}
+
+// AccountHolding represents the part of a holding held in one account
+type AccountHolding struct {
+	Account  string
+	Quantity float64
+	Value    Decimal
+}
+
+// Holding represents a position consolidated across accounts
+type Holding struct {
+	Ticker   string
+	Currency string
+	Quantity float64
+	Value    Decimal
+	Accounts []AccountHolding
+}
+
+// NewAccountSet creates an empty account set
+func NewAccountSet() *AccountSet {
+	return &AccountSet{
+		clients: make(map[string]*Client),
+	}
+}
+
+// Add registers a client under the given account name
+func (s *AccountSet) Add(name string, client *Client) error {
+	if name == "" {
+		return fmt.Errorf("account name cannot be empty")
+	}
+	if client == nil {
+		return fmt.Errorf("client for account %s cannot be nil", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.clients[name]; exists {
+		return fmt.Errorf("account %s already exists", name)
+	}
+
+	s.names = append(s.names, name)
+	s.clients[name] = client
+	return nil
+}
+
+// Names returns the account names in the order they were added
+func (s *AccountSet) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, len(s.names))
+	copy(names, s.names)
+	return names
+}
+
+// Account returns the client for a single named account. Orders must be placed
+// through this client so that every trade is scoped to exactly one account.
+func (s *AccountSet) Account(name string) (*Client, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	client, exists := s.clients[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown account %s", name)
+	}
+	return client, nil
+}
+
+// Summaries fetches account info, cash and portfolio for every account concurrently.
+// A summary is returned for each account; failures are recorded on the summary and
+// also joined into the returned error.
+func (s *AccountSet) Summaries() ([]AccountSummary, error) {
+	names := s.Names()
+	summaries := make([]AccountSummary, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		client, err := s.Account(name)
+		if err != nil {
+			summaries[i] = AccountSummary{Name: name, Err: err}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, name string, client *Client) {
+			defer wg.Done()
+			summaries[i] = fetchAccountSummary(name, client)
+		}(i, name, client)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, summary := range summaries {
+		if summary.Err != nil {
+			errs = append(errs, fmt.Errorf("account %s: %v", summary.Name, summary.Err))
+		}
+	}
+
+	return summaries, errors.Join(errs...)
+}
+
+// fetchAccountSummary fetches the state of a single account
+func fetchAccountSummary(name string, client *Client) AccountSummary {
+	summary := AccountSummary{Name: name}
+
+	info, err := client.AccountInfo()
+	if err != nil {
+		summary.Err = err
+		return summary
+	}
+	summary.Info = info
+
+	cash, err := client.Cash()
+	if err != nil {
+		summary.Err = err
+		return summary
+	}
+	summary.Cash = cash
+
+	portfolio, err := client.Portfolio()
+	if err != nil {
+		summary.Err = err
+		return summary
+	}
+	summary.Portfolio = portfolio
+
+	return summary
+}
+
+// Holdings fetches every account and returns the consolidated holdings. Accounts
+// that failed to load are left out and reported through the returned error.
+func (s *AccountSet) Holdings() ([]Holding, error) {
+	summaries, err := s.Summaries()
+	return ConsolidateHoldings(summaries), err
+}
+
+// ConsolidateHoldings merges positions by ticker and account currency, keeping a
+// per-account breakdown. Holdings are sorted by value, largest first.
+func ConsolidateHoldings(summaries []AccountSummary) []Holding {
+	index := make(map[string]int)
+	var holdings []Holding
+
+	for _, summary := range summaries {
+		if summary.Err != nil {
+			continue
+		}
+
+		currency := ""
+		if summary.Info != nil {
+			currency = summary.Info.CurrencyCode
+		}
+
+		for _, position := range summary.Portfolio {
+			key := position.Ticker + "|" + currency
+			i, exists := index[key]
+			if !exists {
+				i = len(holdings)
+				index[key] = i
+				holdings = append(holdings, Holding{Ticker: position.Ticker, Currency: currency})
+			}
+
+			holdings[i].Quantity += position.Quantity
+			holdings[i].Value = holdings[i].Value.Add(position.Value)
+			holdings[i].Accounts = append(holdings[i].Accounts, AccountHolding{
+				Account:  summary.Name,
+				Quantity: position.Quantity,
+				Value:    position.Value,
+			})
+		}
+	}
+
+	sort.SliceStable(holdings, func(i, j int) bool {
+		return holdings[i].Value.Cmp(holdings[j].Value) > 0
+	})
+
+	return holdings
+}
@@ -0,0 +1,133 @@
+package trading212
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newAccountServer creates a mock server for a single account
+func newAccountServer(t *testing.T, currency string, positions []Position) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/equity/account/info":
+			writeJSONResponse(t, w, AccountInfo{CurrencyCode: currency, ID: 1, Type: "LIVE"})
+		case "/api/v0/equity/account/cash":
+			writeJSONResponse(t, w, CashInfo{Free: NewDecimalFromInt(100), Total: NewDecimalFromInt(1000)})
+		case "/api/v0/equity/portfolio":
+			writeJSONResponse(t, w, positions)
+		default:
+			writeErrorResponse(t, w, http.StatusNotFound, "not found")
+		}
+	}))
+}
+
+// newTestClient creates a client pointed at a mock server
+func newTestClient(serverURL string) *Client {
+	client := NewClient("test-api-key", true)
+	client.host = serverURL
+	return client
+}
+
+// TestAccountSetAdd tests account registration
+func TestAccountSetAdd(t *testing.T) {
+	set := NewAccountSet()
+
+	if err := set.Add("invest", NewClient("key", true)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := set.Add("invest", NewClient("key", true)); err == nil {
+		t.Error("Expected error for duplicate account name")
+	}
+	if err := set.Add("", NewClient("key", true)); err == nil {
+		t.Error("Expected error for empty account name")
+	}
+	if err := set.Add("isa", nil); err == nil {
+		t.Error("Expected error for nil client")
+	}
+
+	if _, err := set.Account("invest"); err != nil {
+		t.Errorf("Account() error = %v", err)
+	}
+	if _, err := set.Account("missing"); err == nil {
+		t.Error("Expected error for unknown account")
+	}
+}
+
+// TestAccountSetHoldings tests consolidated holdings across accounts
+func TestAccountSetHoldings(t *testing.T) {
+	invest := newAccountServer(t, "GBP", []Position{
+		{Ticker: "AAPL", Quantity: 2, Value: NewDecimalFromInt(300)},
+		{Ticker: "MSFT", Quantity: 1, Value: NewDecimalFromInt(400)},
+	})
+	defer invest.Close()
+
+	isa := newAccountServer(t, "GBP", []Position{
+		{Ticker: "AAPL", Quantity: 3, Value: NewDecimalFromInt(450)},
+	})
+	defer isa.Close()
+
+	set := NewAccountSet()
+	if err := set.Add("invest", newTestClient(invest.URL)); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Add("isa", newTestClient(isa.URL)); err != nil {
+		t.Fatal(err)
+	}
+
+	holdings, err := set.Holdings()
+	if err != nil {
+		t.Fatalf("Holdings() error = %v", err)
+	}
+
+	if len(holdings) != 2 {
+		t.Fatalf("Holdings length = %d, want 2", len(holdings))
+	}
+	if holdings[0].Ticker != "AAPL" || holdings[0].Quantity != 5 || holdings[0].Value != NewDecimalFromInt(750) {
+		t.Errorf("Holdings[0] = %+v, want AAPL quantity 5 value 750", holdings[0])
+	}
+	if len(holdings[0].Accounts) != 2 {
+		t.Errorf("Holdings[0] breakdown length = %d, want 2", len(holdings[0].Accounts))
+	}
+	if holdings[0].Currency != "GBP" {
+		t.Errorf("Holdings[0].Currency = %s, want GBP", holdings[0].Currency)
+	}
+}
+
+// TestAccountSetPartialFailure tests that failing accounts are reported but do not hide others
+func TestAccountSetPartialFailure(t *testing.T) {
+	good := newAccountServer(t, "GBP", []Position{{Ticker: "NVDA", Quantity: 1, Value: NewDecimalFromInt(100)}})
+	defer good.Close()
+
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeErrorResponse(t, w, http.StatusUnauthorized, "unauthorised")
+	}))
+	defer bad.Close()
+
+	set := NewAccountSet()
+	if err := set.Add("invest", newTestClient(good.URL)); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Add("isa", newTestClient(bad.URL)); err != nil {
+		t.Fatal(err)
+	}
+
+	summaries, err := set.Summaries()
+	if err == nil {
+		t.Error("Expected error from failing account")
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("Summaries length = %d, want 2", len(summaries))
+	}
+	if summaries[0].Err != nil || summaries[0].Cash == nil {
+		t.Errorf("Expected invest summary to succeed, got %+v", summaries[0])
+	}
+	if summaries[1].Err == nil {
+		t.Error("Expected isa summary to record error")
+	}
+
+	holdings := ConsolidateHoldings(summaries)
+	if len(holdings) != 1 || holdings[0].Ticker != "NVDA" {
+		t.Errorf("ConsolidateHoldings() = %+v, want single NVDA holding", holdings)
+	}
+}
@@ -0,0 +1,220 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// daysPerYear is the day count used to annualise money-weighted returns
+const daysPerYear = 365.0
+
+// Performance is how a portfolio did over a period. TimeWeighted is the
+// cumulative return with the effect of flows removed, so it measures the
+// investments. MoneyWeighted is the annualised internal rate of return of
+// the flows, so it measures the investor's experience including timing.
+type Performance struct {
+	From          time.Time          `json:"from"`
+	To            time.Time          `json:"to"`
+	StartValue    trading212.Decimal `json:"startValue"`
+	EndValue      trading212.Decimal `json:"endValue"`
+	NetFlows      trading212.Decimal `json:"netFlows"`
+	Gain          trading212.Decimal `json:"gain"`
+	TimeWeighted  float64            `json:"timeWeighted"`
+	MoneyWeighted float64            `json:"moneyWeighted"`
+	Values        []Point            `json:"values,omitempty"`
+}
+
+// Measure reports performance over the days from from to to inclusive, from
+// the daily values reconstructed from points and flows. The start value is
+// the value at the end of the day before from. The money-weighted return is
+// zero when it cannot be solved, such as for a period with nothing invested.
+func Measure(points []Point, flows []CashFlow, from, to time.Time) (*Performance, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("period ends %s before it starts %s", to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+
+	start := startOfDay(from)
+	performance := &Performance{
+		From:       start,
+		To:         startOfDay(to),
+		StartValue: ValueAt(points, flows, start.Add(-time.Nanosecond)),
+		Values:     DailyValues(points, flows, from, to),
+	}
+	end := performance.To.AddDate(0, 0, 1)
+	performance.EndValue = performance.Values[len(performance.Values)-1].Value
+
+	var periodFlows []CashFlow
+	for _, flow := range flows {
+		if !flow.Time.Before(start) && flow.Time.Before(end) {
+			periodFlows = append(periodFlows, flow)
+			performance.NetFlows = performance.NetFlows.Add(flow.Amount)
+		}
+	}
+	performance.Gain = performance.EndValue.Sub(performance.StartValue).Sub(performance.NetFlows)
+
+	twr, err := TimeWeightedReturn(performance.StartValue, performance.Values, periodFlows)
+	if err != nil {
+		return nil, err
+	}
+	performance.TimeWeighted = twr
+
+	investor := make([]CashFlow, 0, len(periodFlows)+2)
+	if !performance.StartValue.IsZero() {
+		investor = append(investor, CashFlow{Time: start, Amount: performance.StartValue.Neg()})
+	}
+	for _, flow := range periodFlows {
+		investor = append(investor, CashFlow{Time: flow.Time, Amount: flow.Amount.Neg()})
+	}
+	investor = append(investor, CashFlow{Time: end, Amount: performance.EndValue})
+	if mwr, err := XIRR(investor); err == nil {
+		performance.MoneyWeighted = mwr
+	}
+	return performance, nil
+}
+
+// TimeWeightedReturn chains the return of each period between values, where
+// a period's flows are assumed to arrive at its start:
+//
+//	r = end value / (previous value + flows) - 1
+//
+// Periods with nothing invested are skipped. Values must be oldest first and
+// flows are assigned to the day of the first value at or after them.
+func TimeWeightedReturn(start trading212.Decimal, values []Point, flows []CashFlow) (float64, error) {
+	returns, err := PeriodReturns(start, values, flows)
+	if err != nil {
+		return 0, err
+	}
+	growth := 1.0
+	for _, r := range returns {
+		growth *= 1 + r.Value
+	}
+	return growth - 1, nil
+}
+
+// Return is the return of the period ending at Time, as a fraction
+type Return struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// PeriodReturns returns the return of each period between values with the
+// effect of flows removed, as chained by TimeWeightedReturn. Flows before the
+// first value's day are already in start, and periods with nothing invested
+// are left out.
+func PeriodReturns(start trading212.Decimal, values []Point, flows []CashFlow) ([]Return, error) {
+	returns := make([]Return, 0, len(values))
+	previous := start
+	next := 0
+	if len(values) > 0 {
+		for next < len(flows) && flows[next].Time.Before(startOfDay(values[0].Time)) {
+			next++
+		}
+	}
+	for _, value := range values {
+		invested := previous
+		for next < len(flows) && !flows[next].Time.After(value.Time.AddDate(0, 0, 1).Add(-time.Nanosecond)) {
+			invested = invested.Add(flows[next].Amount)
+			next++
+		}
+		previous = value.Value
+
+		if invested.Sign() <= 0 {
+			if value.Value.Sign() > 0 {
+				return nil, fmt.Errorf("value of %s on %s has nothing invested", value.Value, value.Time.Format("2006-01-02"))
+			}
+			continue
+		}
+		returns = append(returns, Return{Time: value.Time, Value: value.Value.Div(invested).Float64() - 1})
+	}
+	return returns, nil
+}
+
+// XIRR returns the annual rate at which the flows have a net present value
+// of zero. Flows are from the investor's side: money paid in is negative and
+// money received, including the final value, is positive.
+func XIRR(flows []CashFlow) (float64, error) {
+	if len(flows) < 2 {
+		return 0, errors.New("at least two cash flows are needed")
+	}
+
+	first := flows[0].Time
+	var positive, negative bool
+	years := make([]float64, len(flows))
+	amounts := make([]float64, len(flows))
+	for i, flow := range flows {
+		if flow.Time.Before(first) {
+			first = flow.Time
+		}
+		amounts[i] = flow.Amount.Float64()
+		positive = positive || amounts[i] > 0
+		negative = negative || amounts[i] < 0
+	}
+	if !positive || !negative {
+		return 0, errors.New("cash flows must include money paid in and received")
+	}
+	for i, flow := range flows {
+		years[i] = flow.Time.Sub(first).Hours() / 24 / daysPerYear
+	}
+
+	npv := func(rate float64) (value, derivative float64) {
+		for i, amount := range amounts {
+			discount := math.Pow(1+rate, -years[i])
+			value += amount * discount
+			derivative -= years[i] * amount * discount / (1 + rate)
+		}
+		return value, derivative
+	}
+
+	rate := 0.1
+	for i := 0; i < 50; i++ {
+		value, derivative := npv(rate)
+		if math.Abs(value) < 1e-9 {
+			return rate, nil
+		}
+		if derivative == 0 {
+			break
+		}
+		next := rate - value/derivative
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-rate) < 1e-12 {
+			return next, nil
+		}
+		rate = next
+	}
+	return bisect(npv)
+}
+
+// bisect finds a root of npv between -100% and a rate high enough to change
+// its sign, for flows where Newton's method does not converge
+func bisect(npv func(float64) (float64, float64)) (float64, error) {
+	low, high := -0.999999, 1.0
+	lowValue, _ := npv(low)
+	highValue, _ := npv(high)
+	for lowValue*highValue > 0 {
+		if high > 1e9 {
+			return 0, errors.New("no rate of return solves the cash flows")
+		}
+		high *= 10
+		highValue, _ = npv(high)
+	}
+
+	for i := 0; i < 200; i++ {
+		middle := (low + high) / 2
+		value, _ := npv(middle)
+		if math.Abs(value) < 1e-9 || high-low < 1e-12 {
+			return middle, nil
+		}
+		if value*lowValue > 0 {
+			low, lowValue = middle, value
+		} else {
+			high = middle
+		}
+	}
+	return (low + high) / 2, nil
+}
@@ -0,0 +1,123 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// TestMeasure tests that the time-weighted return ignores the timing of flows
+func TestMeasure(t *testing.T) {
+	points := []Point{
+		{Time: date(2024, 1, 1), Value: trading212.NewDecimalFromInt(1000)},
+		{Time: date(2024, 1, 10), Value: trading212.NewDecimalFromInt(1100)},
+		{Time: date(2024, 1, 20), Value: trading212.NewDecimalFromInt(2200)},
+	}
+	flows := []CashFlow{
+		{Time: date(2024, 1, 1), Amount: trading212.NewDecimalFromInt(1000)},
+		{Time: date(2024, 1, 11), Amount: trading212.NewDecimalFromInt(900)},
+	}
+
+	performance, err := Measure(points, flows, date(2024, 1, 1), date(2024, 1, 20))
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+	if performance.StartValue.String() != "0" || performance.EndValue.String() != "2200" || performance.NetFlows.String() != "1900" || performance.Gain.String() != "300" {
+		t.Errorf("performance = %+v", performance)
+	}
+	if len(performance.Values) != 20 {
+		t.Errorf("Measure() returned %d daily values, want 20", len(performance.Values))
+	}
+
+	// 1000 grows 10% to 1100 then, with 900 added the next day, 2000 grows 10%
+	// to 2200
+	if math.Abs(performance.TimeWeighted-0.21) > 1e-9 {
+		t.Errorf("TimeWeighted = %v, want 0.21", performance.TimeWeighted)
+	}
+	if performance.MoneyWeighted <= 0 {
+		t.Errorf("MoneyWeighted = %v, want positive", performance.MoneyWeighted)
+	}
+
+	later, err := Measure(points, flows, date(2024, 1, 11), date(2024, 1, 20))
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+	if later.StartValue.String() != "1100" || math.Abs(later.TimeWeighted-0.1) > 1e-9 {
+		t.Errorf("later = %+v", later)
+	}
+
+	if _, err := Measure(points, flows, date(2024, 1, 2), date(2024, 1, 1)); err == nil {
+		t.Error("Expected error for a period ending before it starts")
+	}
+}
+
+// TestTimeWeightedReturn tests that flows before the first value are not
+// counted again on top of the start value
+func TestTimeWeightedReturn(t *testing.T) {
+	values := []Point{
+		{Time: date(2024, 1, 10), Value: trading212.NewDecimalFromInt(1100)},
+		{Time: date(2024, 1, 20), Value: trading212.NewDecimalFromInt(2200)},
+	}
+	flows := []CashFlow{
+		{Time: date(2024, 1, 1), Amount: trading212.NewDecimalFromInt(1000)},
+		{Time: date(2024, 1, 15), Amount: trading212.NewDecimalFromInt(900)},
+	}
+
+	twr, err := TimeWeightedReturn(trading212.NewDecimalFromInt(1000), values, flows)
+	if err != nil {
+		t.Fatalf("TimeWeightedReturn() error = %v", err)
+	}
+	if math.Abs(twr-0.21) > 1e-9 {
+		t.Errorf("TimeWeightedReturn() = %v, want 0.21", twr)
+	}
+}
+
+// TestXIRR tests the money-weighted return against known rates
+func TestXIRR(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		flows   []CashFlow
+		want    float64
+		wantErr bool
+	}{
+		{
+			name: "one year",
+			flows: []CashFlow{
+				{Time: start, Amount: trading212.NewDecimalFromInt(-1000)},
+				{Time: start.AddDate(0, 0, 365), Amount: trading212.NewDecimalFromInt(1100)},
+			},
+			want: 0.1,
+		},
+		{
+			name: "loss",
+			flows: []CashFlow{
+				{Time: start, Amount: trading212.NewDecimalFromInt(-1000)},
+				{Time: start.AddDate(0, 0, 730), Amount: trading212.NewDecimalFromInt(810)},
+			},
+			want: -0.1,
+		},
+		{
+			name: "no money received",
+			flows: []CashFlow{
+				{Time: start, Amount: trading212.NewDecimalFromInt(-1000)},
+				{Time: start.AddDate(0, 0, 30), Amount: trading212.NewDecimalFromInt(-100)},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := XIRR(tt.flows)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("XIRR() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("XIRR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
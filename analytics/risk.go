@@ -0,0 +1,333 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// Periods per year for annualising, by how often a series is observed
+const (
+	TradingDays  = 252
+	CalendarDays = 365
+	Weeks        = 52
+	Months       = 12
+)
+
+// Drawdown is a fall from a peak. Depth is the fraction lost at the trough,
+// as a negative number, and Recovery is zero while the peak has not been
+// regained.
+type Drawdown struct {
+	Peak     time.Time `json:"peak"`
+	Trough   time.Time `json:"trough"`
+	Recovery time.Time `json:"recovery,omitzero"`
+	Depth    float64   `json:"depth"`
+}
+
+// Risk summarises a return series. Beta and Correlation are only set when a
+// benchmark is given.
+type Risk struct {
+	Observations     int      `json:"observations"`
+	AnnualisedReturn float64  `json:"annualisedReturn"`
+	Volatility       float64  `json:"volatility"`
+	Sharpe           float64  `json:"sharpe"`
+	Sortino          float64  `json:"sortino"`
+	MaxDrawdown      Drawdown `json:"maxDrawdown"`
+	Calmar           float64  `json:"calmar"`
+	Beta             float64  `json:"beta,omitempty"`
+	Correlation      float64  `json:"correlation,omitempty"`
+}
+
+// MeasureRisk computes the risk statistics of returns observed periodsPerYear
+// times a year, such as CalendarDays for the daily values of an account or
+// TradingDays for daily closing prices. riskFree is the annual risk-free
+// rate. benchmark may be nil.
+func MeasureRisk(returns, benchmark []Return, riskFree float64, periodsPerYear int) Risk {
+	risk := Risk{
+		Observations:     len(returns),
+		AnnualisedReturn: AnnualisedReturn(returns, periodsPerYear),
+		Volatility:       Volatility(returns, periodsPerYear),
+		Sharpe:           Sharpe(returns, riskFree, periodsPerYear),
+		Sortino:          Sortino(returns, riskFree, periodsPerYear),
+		MaxDrawdown:      MaxDrawdown(returns),
+		Calmar:           Calmar(returns, periodsPerYear),
+	}
+	if len(benchmark) > 0 {
+		risk.Beta = Beta(returns, benchmark)
+		risk.Correlation = Correlation(returns, benchmark)
+	}
+	return risk
+}
+
+// PriceReturns returns the change between consecutive prices, such as the
+// closing prices of a position or a benchmark. Prices must be oldest first.
+func PriceReturns(prices []Point) []Return {
+	var returns []Return
+	for i := 1; i < len(prices); i++ {
+		if prices[i-1].Value.Sign() <= 0 {
+			continue
+		}
+		returns = append(returns, Return{Time: prices[i].Time, Value: prices[i].Value.Div(prices[i-1].Value).Float64() - 1})
+	}
+	return returns
+}
+
+// AnnualisedReturn compounds the returns to a yearly rate
+func AnnualisedReturn(returns []Return, periodsPerYear int) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+	growth := 1.0
+	for _, r := range returns {
+		growth *= 1 + r.Value
+	}
+	if growth <= 0 {
+		return -1
+	}
+	return math.Pow(growth, float64(periodsPerYear)/float64(len(returns))) - 1
+}
+
+// Volatility is the annualised sample standard deviation of the returns
+func Volatility(returns []Return, periodsPerYear int) float64 {
+	return standardDeviation(values(returns)) * math.Sqrt(float64(periodsPerYear))
+}
+
+// Sharpe is the annualised mean return above the risk-free rate divided by
+// the volatility, or zero when the returns do not vary
+func Sharpe(returns []Return, riskFree float64, periodsPerYear int) float64 {
+	deviation := standardDeviation(values(returns))
+	if deviation == 0 {
+		return 0
+	}
+	excess := mean(values(returns)) - riskFree/float64(periodsPerYear)
+	return excess / deviation * math.Sqrt(float64(periodsPerYear))
+}
+
+// Sortino is like Sharpe but divides by the deviation of returns below the
+// risk-free rate only, so that gains are not penalised
+func Sortino(returns []Return, riskFree float64, periodsPerYear int) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+	target := riskFree / float64(periodsPerYear)
+	var squares float64
+	for _, r := range returns {
+		if shortfall := r.Value - target; shortfall < 0 {
+			squares += shortfall * shortfall
+		}
+	}
+	downside := math.Sqrt(squares / float64(len(returns)))
+	if downside == 0 {
+		return 0
+	}
+	return (mean(values(returns)) - target) / downside * math.Sqrt(float64(periodsPerYear))
+}
+
+// MaxDrawdown returns the largest fall in the growth of the returns from a
+// previous peak. A fall in the first period is dated from the first return.
+func MaxDrawdown(returns []Return) Drawdown {
+	var worst, current Drawdown
+	growth, peak := 1.0, 1.0
+	if len(returns) > 0 {
+		current.Peak = returns[0].Time
+	}
+	for _, r := range returns {
+		growth *= 1 + r.Value
+		if growth >= peak {
+			if current.Depth < 0 && current.Peak.Equal(worst.Peak) {
+				worst.Recovery = r.Time
+			}
+			peak = growth
+			current = Drawdown{Peak: r.Time}
+			continue
+		}
+		if depth := growth/peak - 1; depth < current.Depth {
+			current.Depth = depth
+			current.Trough = r.Time
+			if depth < worst.Depth {
+				worst = current
+			}
+		}
+	}
+	return worst
+}
+
+// Calmar is the annualised return divided by the depth of the maximum
+// drawdown, or zero when there was no drawdown
+func Calmar(returns []Return, periodsPerYear int) float64 {
+	drawdown := MaxDrawdown(returns)
+	if drawdown.Depth == 0 {
+		return 0
+	}
+	return AnnualisedReturn(returns, periodsPerYear) / -drawdown.Depth
+}
+
+// Beta is the sensitivity of the returns to the benchmark's: their covariance
+// divided by the benchmark's variance. The series are aligned as described by
+// Align.
+func Beta(returns, benchmark []Return) float64 {
+	x, y := Align(returns, benchmark)
+	variance := covariance(y, y)
+	if variance == 0 {
+		return 0
+	}
+	return covariance(x, y) / variance
+}
+
+// Correlation is the Pearson correlation of the returns with the benchmark's,
+// aligned as described by Align
+func Correlation(returns, benchmark []Return) float64 {
+	x, y := Align(returns, benchmark)
+	deviations := standardDeviation(x) * standardDeviation(y)
+	if deviations == 0 {
+		return 0
+	}
+	return covariance(x, y) / deviations
+}
+
+// Align pairs each benchmark return with the returns compounded over the
+// same period, from the benchmark's previous observation to its time, so that
+// an account's weekend returns are combined for the benchmark's Monday
+// return. Periods without returns, and returns before the benchmark's first
+// period, are left out.
+func Align(returns, benchmark []Return) (x, y []float64) {
+	next := 0
+	for i, b := range benchmark {
+		if i == 0 {
+			continue
+		}
+		for next < len(returns) && !returns[next].Time.After(benchmark[i-1].Time) {
+			next++
+		}
+		growth, covered := 1.0, false
+		for next < len(returns) && !returns[next].Time.After(b.Time) {
+			growth *= 1 + returns[next].Value
+			covered = true
+			next++
+		}
+		if covered {
+			x = append(x, growth-1)
+			y = append(y, b.Value)
+		}
+	}
+	return x, y
+}
+
+// LoadSeriesCSV reads a value series, such as benchmark closing prices, from
+// a CSV file with a date column and a value, close or price column. The
+// series is returned oldest first.
+func LoadSeriesCSV(r io.Reader) ([]Point, error) {
+	series, err := loadPrices(r, false)
+	if err != nil {
+		return nil, err
+	}
+	return series[""], nil
+}
+
+// LoadPricesCSV reads the prices of several instruments from a CSV file with
+// date, ticker and price (or close or value) columns, returning each ticker's
+// prices oldest first
+func LoadPricesCSV(r io.Reader) (map[string][]Point, error) {
+	return loadPrices(r, true)
+}
+
+// loadPrices reads dated prices, grouped by ticker when withTicker is set
+func loadPrices(r io.Reader, withTicker bool) (map[string][]Point, error) {
+	reader := csv.NewReader(r)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prices: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("prices file is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	date, hasDate := columns["date"]
+	if !hasDate {
+		return nil, fmt.Errorf("prices file has no date column")
+	}
+	value, hasValue := -1, false
+	for _, name := range []string{"price", "close", "value"} {
+		if value, hasValue = columns[name]; hasValue {
+			break
+		}
+	}
+	if !hasValue {
+		return nil, fmt.Errorf("prices file has no price, close or value column")
+	}
+	ticker, hasTicker := columns["ticker"]
+	if withTicker && !hasTicker {
+		return nil, fmt.Errorf("prices file has no ticker column")
+	}
+
+	prices := make(map[string][]Point)
+	for line, record := range records[1:] {
+		at, err := trading212.ParseDate(strings.TrimSpace(record[date]))
+		if err != nil {
+			return nil, fmt.Errorf("prices line %d: %v", line+2, err)
+		}
+		price, err := trading212.ParseDecimal(strings.TrimSpace(record[value]))
+		if err != nil {
+			return nil, fmt.Errorf("prices line %d: invalid price %q", line+2, record[value])
+		}
+		key := ""
+		if withTicker {
+			key = strings.TrimSpace(record[ticker])
+		}
+		prices[key] = append(prices[key], Point{Time: at, Value: price})
+	}
+	for _, series := range prices {
+		sort.SliceStable(series, func(i, j int) bool { return series[i].Time.Before(series[j].Time) })
+	}
+	return prices, nil
+}
+
+// values returns the return values
+func values(returns []Return) []float64 {
+	result := make([]float64, len(returns))
+	for i, r := range returns {
+		result[i] = r.Value
+	}
+	return result
+}
+
+// mean returns the average of xs
+func mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// covariance returns the sample covariance of xs and ys, which have the same
+// length
+func covariance(xs, ys []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	meanX, meanY := mean(xs), mean(ys)
+	var sum float64
+	for i := range xs {
+		sum += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	return sum / float64(len(xs)-1)
+}
+
+// standardDeviation returns the sample standard deviation of xs
+func standardDeviation(xs []float64) float64 {
+	return math.Sqrt(covariance(xs, xs))
+}
@@ -0,0 +1,109 @@
+package analytics
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// series returns daily returns starting on 1 January 2024
+func series(values ...float64) []Return {
+	returns := make([]Return, len(values))
+	for i, value := range values {
+		returns[i] = Return{Time: date(2024, 1, 1).AddDate(0, 0, i), Value: value}
+	}
+	return returns
+}
+
+// TestMaxDrawdown tests the depth and dates of the largest fall
+func TestMaxDrawdown(t *testing.T) {
+	returns := series(0.1, -0.1, -0.1, 0.05, 0.3, -0.05)
+
+	drawdown := MaxDrawdown(returns)
+	if math.Abs(drawdown.Depth+0.19) > 1e-9 {
+		t.Errorf("Depth = %v, want -0.19", drawdown.Depth)
+	}
+	if !drawdown.Peak.Equal(returns[0].Time) || !drawdown.Trough.Equal(returns[2].Time) || !drawdown.Recovery.Equal(returns[4].Time) {
+		t.Errorf("MaxDrawdown() = %+v", drawdown)
+	}
+
+	if drawdown := MaxDrawdown(series(-0.1, -0.1)); !drawdown.Recovery.IsZero() || math.Abs(drawdown.Depth+0.19) > 1e-9 {
+		t.Errorf("unrecovered drawdown = %+v", drawdown)
+	}
+	if drawdown := MaxDrawdown(series(0.1, 0.2)); drawdown.Depth != 0 {
+		t.Errorf("rising drawdown = %+v", drawdown)
+	}
+}
+
+// TestRatios tests volatility, Sharpe, Sortino and Calmar
+func TestRatios(t *testing.T) {
+	returns := series(0.02, -0.01, 0.03, -0.02, 0.01)
+
+	// mean 0.006, sample variance 0.00043
+	wantVolatility := math.Sqrt(0.00043) * math.Sqrt(TradingDays)
+	if got := Volatility(returns, TradingDays); math.Abs(got-wantVolatility) > 1e-9 {
+		t.Errorf("Volatility() = %v, want %v", got, wantVolatility)
+	}
+	wantSharpe := 0.006 / math.Sqrt(0.00043) * math.Sqrt(TradingDays)
+	if got := Sharpe(returns, 0, TradingDays); math.Abs(got-wantSharpe) > 1e-9 {
+		t.Errorf("Sharpe() = %v, want %v", got, wantSharpe)
+	}
+	wantSortino := 0.006 / math.Sqrt(0.0005/5) * math.Sqrt(TradingDays)
+	if got := Sortino(returns, 0, TradingDays); math.Abs(got-wantSortino) > 1e-9 {
+		t.Errorf("Sortino() = %v, want %v", got, wantSortino)
+	}
+	if got := Calmar(returns, TradingDays); got <= 0 {
+		t.Errorf("Calmar() = %v, want positive", got)
+	}
+	if got := Sharpe(series(0.01, 0.01), 0, TradingDays); got != 0 {
+		t.Errorf("Sharpe() of constant returns = %v, want 0", got)
+	}
+}
+
+// TestBeta tests beta and correlation against a benchmark observed less often
+func TestBeta(t *testing.T) {
+	benchmark := []Return{
+		{Time: date(2024, 1, 1), Value: 0},
+		{Time: date(2024, 1, 3), Value: 0.01},
+		{Time: date(2024, 1, 5), Value: -0.02},
+		{Time: date(2024, 1, 7), Value: 0.03},
+	}
+	// each pair of days compounds to twice the benchmark's return
+	returns := series(0.5, 0.01, 0.0099009901, -0.02, -0.0204081633, 0.03, 0.0291262136)
+
+	risk := MeasureRisk(returns, benchmark, 0, CalendarDays)
+	if math.Abs(risk.Beta-2) > 1e-6 || math.Abs(risk.Correlation-1) > 1e-6 {
+		t.Errorf("Beta = %v, Correlation = %v, want 2 and 1", risk.Beta, risk.Correlation)
+	}
+	if risk.Observations != 7 {
+		t.Errorf("Observations = %d, want 7", risk.Observations)
+	}
+}
+
+// TestLoadPricesCSV tests reading benchmark and position prices
+func TestLoadPricesCSV(t *testing.T) {
+	series, err := LoadSeriesCSV(strings.NewReader("Date,Close\n2024-01-03,110\n2024-01-02,100\n"))
+	if err != nil {
+		t.Fatalf("LoadSeriesCSV() error = %v", err)
+	}
+	returns := PriceReturns(series)
+	if len(returns) != 1 || math.Abs(returns[0].Value-0.1) > 1e-9 || !returns[0].Time.Equal(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("PriceReturns() = %+v", returns)
+	}
+
+	prices, err := LoadPricesCSV(strings.NewReader("date,ticker,price\n2024-01-02,AAPL,180\n2024-01-02,MSFT,370\n2024-01-03,AAPL,185\n"))
+	if err != nil {
+		t.Fatalf("LoadPricesCSV() error = %v", err)
+	}
+	if len(prices["AAPL"]) != 2 || len(prices["MSFT"]) != 1 {
+		t.Errorf("LoadPricesCSV() = %+v", prices)
+	}
+
+	if _, err := LoadPricesCSV(strings.NewReader("date,price\n2024-01-02,180\n")); err == nil {
+		t.Error("Expected error without a ticker column")
+	}
+	if _, err := LoadSeriesCSV(strings.NewReader("date,close\nyesterday,180\n")); err == nil {
+		t.Error("Expected error for an invalid date")
+	}
+}
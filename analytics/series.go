@@ -0,0 +1,194 @@
+// Package analytics measures portfolio performance from account history,
+// reconstructing daily values from snapshots and cash flows.
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/fx"
+)
+
+// Point is the value of a portfolio at a moment, such as an account snapshot
+// or a pie result
+type Point struct {
+	Time  time.Time          `json:"time"`
+	Value trading212.Decimal `json:"value"`
+}
+
+// CashFlow is money moved into a portfolio, or out of it when negative.
+// External flows are not part of the return.
+type CashFlow struct {
+	Time   time.Time          `json:"time"`
+	Amount trading212.Decimal `json:"amount"`
+}
+
+// CashFlowsFromTransactions returns the deposits, withdrawals and transfers
+// of the account's transactions, oldest first. Fees are costs borne by the
+// portfolio and so are not flows.
+func CashFlowsFromTransactions(transactions []trading212.Transaction) []CashFlow {
+	var flows []CashFlow
+	for _, transaction := range transactions {
+		var amount trading212.Decimal
+		switch strings.ToUpper(transaction.Type) {
+		case "DEPOSIT":
+			amount = transaction.Amount.Abs()
+		case "WITHDRAW", "WITHDRAWAL":
+			amount = transaction.Amount.Abs().Neg()
+		case "TRANSFER":
+			amount = transaction.Amount
+		default:
+			continue
+		}
+		flows = append(flows, CashFlow{Time: transaction.DateTime, Amount: amount})
+	}
+	SortCashFlows(flows)
+	return flows
+}
+
+// CashFlowsFromExport returns the deposits and withdrawals of an account
+// history export, oldest first
+func CashFlowsFromExport(rows []trading212.ExportRow) []CashFlow {
+	var flows []CashFlow
+	for _, row := range rows {
+		switch strings.ToLower(row.Action) {
+		case "deposit":
+			flows = append(flows, CashFlow{Time: row.Time, Amount: row.Total.Abs()})
+		case "withdrawal":
+			flows = append(flows, CashFlow{Time: row.Time, Amount: row.Total.Abs().Neg()})
+		}
+	}
+	SortCashFlows(flows)
+	return flows
+}
+
+// CashFlowsFromTrades treats trades as the flows of a sub-portfolio, such as
+// the instruments of one pie: a buy pays its value and fees in and a sell
+// takes its proceeds less fees out. Amounts are converted to currency with
+// source. Splits leave values unchanged, but callers selecting trades by
+// ticker should restate them with corporate.Actions.AdjustTrades first so
+// trades before a rename are included.
+func CashFlowsFromTrades(trades []trading212.Trade, currency string, source fx.RateSource) ([]CashFlow, error) {
+	flows := make([]CashFlow, 0, len(trades))
+	for _, trade := range trades {
+		value, fees, err := fx.TradeValue(source, trade, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to value %s trade %s: %v", trade.Ticker, trade.ID, err)
+		}
+		amount := value.Add(fees)
+		if !trade.IsBuy() {
+			amount = value.Sub(fees).Neg()
+		}
+		flows = append(flows, CashFlow{Time: trade.Time, Amount: amount})
+	}
+	SortCashFlows(flows)
+	return flows, nil
+}
+
+// PieSnapshot is a pie's result observed at a moment
+type PieSnapshot struct {
+	Time   time.Time            `json:"time"`
+	PieID  int                  `json:"pieId"`
+	Result trading212.PieResult `json:"result"`
+}
+
+// PieSnapshots records the results of pies fetched at the given time,
+// skipping pies without a result
+func PieSnapshots(pies []trading212.Pie, at time.Time) []PieSnapshot {
+	var snapshots []PieSnapshot
+	for _, pie := range pies {
+		if pie.Result == nil {
+			continue
+		}
+		snapshots = append(snapshots, PieSnapshot{Time: at, PieID: pie.ID, Result: *pie.Result})
+	}
+	return snapshots
+}
+
+// PieSeries returns the values and cash flows of one pie, or of all pies
+// together when pieID is zero. Each snapshot's value is a point and the
+// change in invested value since the pie's previous snapshot is a flow, so a
+// pie's first snapshot counts its whole invested value as paid in.
+func PieSeries(snapshots []PieSnapshot, pieID int) ([]Point, []CashFlow) {
+	sorted := make([]PieSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if pieID == 0 || snapshot.PieID == pieID {
+			sorted = append(sorted, snapshot)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	invested := make(map[int]trading212.Decimal)
+	values := make(map[int]trading212.Decimal)
+	var points []Point
+	var flows []CashFlow
+	for i, snapshot := range sorted {
+		if change := snapshot.Result.InvestedValue.Sub(invested[snapshot.PieID]); !change.IsZero() {
+			flows = append(flows, CashFlow{Time: snapshot.Time, Amount: change})
+		}
+		invested[snapshot.PieID] = snapshot.Result.InvestedValue
+		values[snapshot.PieID] = snapshot.Result.Value
+
+		if i+1 < len(sorted) && sorted[i+1].Time.Equal(snapshot.Time) {
+			continue
+		}
+		var total trading212.Decimal
+		for _, value := range values {
+			total = total.Add(value)
+		}
+		points = append(points, Point{Time: snapshot.Time, Value: total})
+	}
+	return points, flows
+}
+
+// SortCashFlows sorts flows oldest first
+func SortCashFlows(flows []CashFlow) {
+	sort.SliceStable(flows, func(i, j int) bool { return flows[i].Time.Before(flows[j].Time) })
+}
+
+// ValueAt reconstructs the portfolio value at t from the latest point at or
+// before t plus the flows after that point. Before the first point the value
+// is the sum of the flows so far, so money is assumed to be worth what was
+// paid in until it is first valued. Points and flows must be oldest first.
+func ValueAt(points []Point, flows []CashFlow, t time.Time) trading212.Decimal {
+	var value trading212.Decimal
+	var since time.Time
+	hasPoint := false
+	for _, point := range points {
+		if point.Time.After(t) {
+			break
+		}
+		value, since, hasPoint = point.Value, point.Time, true
+	}
+
+	for _, flow := range flows {
+		if flow.Time.After(t) {
+			break
+		}
+		if hasPoint && !flow.Time.After(since) {
+			continue
+		}
+		value = value.Add(flow.Amount)
+	}
+	return value
+}
+
+// DailyValues reconstructs the value at the end of each day from from to to
+// inclusive, in from's time zone. Points and flows must be oldest first.
+func DailyValues(points []Point, flows []CashFlow, from, to time.Time) []Point {
+	var values []Point
+	for day := startOfDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		values = append(values, Point{Time: day, Value: ValueAt(points, flows, end)})
+	}
+	return values
+}
+
+// startOfDay returns midnight at the start of t's day in its time zone
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,84 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/fx"
+)
+
+// date returns midday UTC on the given day
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+// TestCashFlowsFromTransactions tests that only external flows are kept
+func TestCashFlowsFromTransactions(t *testing.T) {
+	transactions := []trading212.Transaction{
+		{Type: "WITHDRAW", Amount: trading212.NewDecimalFromInt(-200), DateTime: date(2024, 2, 1)},
+		{Type: "DEPOSIT", Amount: trading212.NewDecimalFromInt(1000), DateTime: date(2024, 1, 1)},
+		{Type: "FEE", Amount: trading212.NewDecimalFromInt(-1), DateTime: date(2024, 1, 5)},
+	}
+
+	flows := CashFlowsFromTransactions(transactions)
+	if len(flows) != 2 || flows[0].Amount.String() != "1000" || flows[1].Amount.String() != "-200" {
+		t.Errorf("CashFlowsFromTransactions() = %+v", flows)
+	}
+}
+
+// TestCashFlowsFromTrades tests that buys pay in and sells take out
+func TestCashFlowsFromTrades(t *testing.T) {
+	rates := fx.StaticRates{{From: "USD", To: "GBP"}: trading212.MustParseDecimal("0.8")}
+	trades := []trading212.Trade{
+		{Ticker: "AAPL", Time: date(2024, 1, 1), Quantity: trading212.NewDecimalFromInt(2), Price: trading212.NewDecimalFromInt(100), PriceCurrency: "USD", Fees: trading212.NewDecimalFromInt(1), Currency: "GBP"},
+		{Ticker: "AAPL", Time: date(2024, 2, 1), Quantity: trading212.NewDecimalFromInt(-1), Value: trading212.NewDecimalFromInt(90), Fees: trading212.NewDecimalFromInt(1), Currency: "GBP"},
+	}
+
+	flows, err := CashFlowsFromTrades(trades, "GBP", rates)
+	if err != nil {
+		t.Fatalf("CashFlowsFromTrades() error = %v", err)
+	}
+	if flows[0].Amount.String() != "161" || flows[1].Amount.String() != "-89" {
+		t.Errorf("CashFlowsFromTrades() = %+v", flows)
+	}
+}
+
+// TestPieSeries tests that changes in invested value become flows
+func TestPieSeries(t *testing.T) {
+	snapshots := []PieSnapshot{
+		{Time: date(2024, 2, 1), PieID: 1, Result: trading212.PieResult{InvestedValue: trading212.NewDecimalFromInt(150), Value: trading212.NewDecimalFromInt(160)}},
+		{Time: date(2024, 1, 1), PieID: 1, Result: trading212.PieResult{InvestedValue: trading212.NewDecimalFromInt(100), Value: trading212.NewDecimalFromInt(100)}},
+		{Time: date(2024, 1, 1), PieID: 2, Result: trading212.PieResult{InvestedValue: trading212.NewDecimalFromInt(50), Value: trading212.NewDecimalFromInt(55)}},
+	}
+
+	points, flows := PieSeries(snapshots, 1)
+	if len(points) != 2 || len(flows) != 2 || flows[1].Amount.String() != "50" || points[1].Value.String() != "160" {
+		t.Errorf("PieSeries(1) = %+v, %+v", points, flows)
+	}
+
+	points, flows = PieSeries(snapshots, 0)
+	if len(points) != 2 || points[0].Value.String() != "155" || points[1].Value.String() != "215" || len(flows) != 3 {
+		t.Errorf("PieSeries(0) = %+v, %+v", points, flows)
+	}
+}
+
+// TestDailyValues tests carrying values forward and adding later flows
+func TestDailyValues(t *testing.T) {
+	points := []Point{{Time: date(2024, 1, 2), Value: trading212.NewDecimalFromInt(1100)}}
+	flows := []CashFlow{
+		{Time: date(2024, 1, 1), Amount: trading212.NewDecimalFromInt(1000)},
+		{Time: date(2024, 1, 3), Amount: trading212.NewDecimalFromInt(500)},
+	}
+
+	values := DailyValues(points, flows, date(2024, 1, 1), date(2024, 1, 4))
+	want := []string{"1000", "1100", "1600", "1600"}
+	if len(values) != len(want) {
+		t.Fatalf("DailyValues() returned %d values, want %d", len(values), len(want))
+	}
+	for i, value := range values {
+		if value.Value.String() != want[i] {
+			t.Errorf("day %d = %s, want %s", i, value.Value, want[i])
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package trading212
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Authenticator applies credentials to outgoing API requests
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// APIKeyAuth sends the API key as the raw Authorization header
+type APIKeyAuth struct {
+	APIKey string
+}
+
+// BasicAuth sends an API key and secret pair using HTTP Basic authentication
+type BasicAuth struct {
+	APIKey    string
+	APISecret string
+}
+
+// Authenticate sets the Authorization header to the API key
+func (a APIKeyAuth) Authenticate(req *http.Request) error {
+	if a.APIKey == "" {
+		return fmt.Errorf("api key cannot be empty")
+	}
+	req.Header.Set("Authorization", a.APIKey)
+	return nil
+}
+
+// String returns a redacted representation of the credentials
+func (a APIKeyAuth) String() string {
+	return fmt.Sprintf("api_key=%s", redactSuffix(a.APIKey))
+}
+
+// Authenticate sets a Basic Authorization header from the key and secret
+func (a BasicAuth) Authenticate(req *http.Request) error {
+	if a.APIKey == "" || a.APISecret == "" {
+		return fmt.Errorf("api key and secret cannot be empty")
+	}
+	if strings.Contains(a.APIKey, ":") {
+		return fmt.Errorf("api key cannot contain a colon when using basic authentication")
+	}
+	req.SetBasicAuth(a.APIKey, a.APISecret)
+	return nil
+}
+
+// String returns a redacted representation of the credentials
+func (a BasicAuth) String() string {
+	return fmt.Sprintf("api_key=%s, api_secret=****", redactSuffix(a.APIKey))
+}
+
+// redactSuffix masks a secret, revealing only its last four characters
+func redactSuffix(secret string) string {
+	if len(secret) < 4 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
@@ -0,0 +1,87 @@
+package trading212
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestAPIKeyAuth tests the raw API key authenticator
+func TestAPIKeyAuth(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	if err := (APIKeyAuth{APIKey: "abc123"}).Authenticate(req); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "abc123" {
+		t.Errorf("Authorization = %v, want abc123", got)
+	}
+
+	if err := (APIKeyAuth{}).Authenticate(req); err == nil {
+		t.Error("Expected error for empty api key")
+	}
+}
+
+// TestBasicAuth tests the key and secret authenticator
+func TestBasicAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    BasicAuth
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "valid credentials",
+			auth: BasicAuth{APIKey: "key-1234", APISecret: "s3cr:et/+="},
+			want: "Basic " + base64.StdEncoding.EncodeToString([]byte("key-1234:s3cr:et/+=")),
+		},
+		{
+			name:    "missing secret",
+			auth:    BasicAuth{APIKey: "key-1234"},
+			wantErr: true,
+		},
+		{
+			name:    "colon in key",
+			auth:    BasicAuth{APIKey: "key:1234", APISecret: "secret"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			err := tt.auth.Authenticate(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClientWithBasicAuth tests that requests carry Basic credentials and String redacts them
+func TestClientWithBasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, secret, ok := r.BasicAuth()
+		if !ok || key != "my-key-9876" || secret != "my-secret" {
+			writeErrorResponse(t, w, http.StatusUnauthorized, "unauthorised")
+			return
+		}
+		writeJSONResponse(t, w, CashInfo{Free: NewDecimalFromInt(10)})
+	}))
+	defer server.Close()
+
+	client := NewClientWithAuth(BasicAuth{APIKey: "my-key-9876", APISecret: "my-secret"}, true)
+
+	want := "Trading212(api_key=****9876, api_secret=****, demo=true)"
+	if got := client.String(); got != want {
+		t.Errorf("Client.String() = %v, want %v", got, want)
+	}
+
+	client.host = server.URL
+	if _, err := client.Cash(); err != nil {
+		t.Fatalf("Cash() error = %v", err)
+	}
+}
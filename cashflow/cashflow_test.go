@@ -0,0 +1,110 @@
+package cashflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// day returns noon UTC on a day of 2024
+func day(month time.Month, d int) time.Time {
+	return time.Date(2024, month, d, 12, 0, 0, 0, time.UTC)
+}
+
+// TestCategorise tests categorising endpoint types and export actions
+func TestCategorise(t *testing.T) {
+	tests := []struct {
+		kind string
+		want Category
+	}{
+		{kind: "DEPOSIT", want: Deposit},
+		{kind: "WITHDRAW", want: Withdrawal},
+		{kind: "Withdrawal", want: Withdrawal},
+		{kind: "TRANSFER", want: Transfer},
+		{kind: "FEE", want: Fee},
+		{kind: "Deposit fee", want: Fee},
+		{kind: "Interest on cash", want: Interest},
+		{kind: "Lending interest", want: Interest},
+		{kind: "Dividend (Ordinary)", want: Dividend},
+		{kind: "Currency conversion", want: CurrencyConversion},
+		{kind: "Transfer to pie", want: PieTransfer},
+		{kind: "Card debit", want: Other},
+	}
+	for _, tt := range tests {
+		if got := Categorise(tt.kind); got != tt.want {
+			t.Errorf("Categorise(%q) = %s, want %s", tt.kind, got, tt.want)
+		}
+	}
+}
+
+// TestFromExport tests signing export amounts and leaving out trades
+func TestFromExport(t *testing.T) {
+	rows := []trading212.ExportRow{
+		{Action: "Withdrawal", Time: day(1, 3), Total: trading212.MustParseDecimal("50")},
+		{Action: "Market buy", Time: day(1, 2), Total: trading212.MustParseDecimal("100")},
+		{Action: "Dividend (Ordinary)", Ticker: "AAPL", Time: day(1, 4), Total: trading212.MustParseDecimal("1.25")},
+	}
+	entries := FromExport(rows)
+	if len(entries) != 2 {
+		t.Fatalf("FromExport() = %+v, want 2 entries", entries)
+	}
+	if entries[0].Category != Withdrawal || entries[0].Amount.String() != "-50" {
+		t.Errorf("entries[0] = %+v, want a withdrawal of -50", entries[0])
+	}
+	if entries[1].Description != "Dividend (Ordinary) AAPL" || entries[1].Amount.String() != "1.25" {
+		t.Errorf("entries[1] = %+v", entries[1])
+	}
+}
+
+// TestStatement tests the monthly summary and current-period accrual
+func TestStatement(t *testing.T) {
+	entries := FromTransactions([]trading212.Transaction{
+		{Type: "DEPOSIT", Amount: trading212.MustParseDecimal("1000"), DateTime: day(1, 5)},
+		{Type: "FEE", Amount: trading212.MustParseDecimal("-2"), DateTime: day(1, 10)},
+		{Type: "INTEREST", Amount: trading212.MustParseDecimal("3.5"), DateTime: day(1, 31)},
+		{Type: "PIE_TRANSFER", Amount: trading212.MustParseDecimal("-200"), DateTime: day(1, 15)},
+		{Type: "WITHDRAW", Amount: trading212.MustParseDecimal("100"), DateTime: day(3, 2)},
+	})
+	entries = append(entries, FromDividends([]trading212.Dividend{
+		{Ticker: "AAPL_US_EQ", Amount: trading212.MustParseDecimal("4"), PaidOn: day(3, 20)},
+	})...)
+	Sort(entries)
+
+	cash := &trading212.CashInfo{Interest: trading212.MustParseDecimal("1.2")}
+	months := Statement(entries, cash, day(4, 10))
+
+	if len(months) != 4 {
+		t.Fatalf("Statement() returned %d months, want January to April", len(months))
+	}
+	tests := []struct {
+		month                                            string
+		inflows, outflows, contributions, interest, fees string
+		pies, dividends, net, accrued                    string
+	}{
+		{month: "2024-01", inflows: "1003.5", outflows: "2", contributions: "1000", interest: "3.5", fees: "2", pies: "-200", dividends: "0", net: "801.5", accrued: "0"},
+		{month: "2024-02", inflows: "0", outflows: "0", contributions: "0", interest: "0", fees: "0", pies: "0", dividends: "0", net: "0", accrued: "0"},
+		{month: "2024-03", inflows: "4", outflows: "100", contributions: "-100", interest: "0", fees: "0", pies: "0", dividends: "4", net: "-96", accrued: "0"},
+		{month: "2024-04", inflows: "0", outflows: "0", contributions: "0", interest: "0", fees: "0", pies: "0", dividends: "0", net: "0", accrued: "1.2"},
+	}
+	for i, tt := range tests {
+		m := months[i]
+		got := []string{m.Month, m.Inflows.String(), m.Outflows.String(), m.NetContributions.String(), m.Interest.String(), m.Fees.String(), m.PieTransfers.String(), m.Dividends.String(), m.Net.String(), m.Accrued.String()}
+		want := []string{tt.month, tt.inflows, tt.outflows, tt.contributions, tt.interest, tt.fees, tt.pies, tt.dividends, tt.net, tt.accrued}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Errorf("month %s = %v, want %v", tt.month, got, want)
+				break
+			}
+		}
+	}
+
+	total := Total(months)
+	if total.Month != "2024-01 to 2024-04" || total.NetContributions.String() != "900" || total.Accrued.String() != "1.2" {
+		t.Errorf("Total() = %+v", total)
+	}
+
+	if months := Statement(nil, nil, day(4, 10)); len(months) != 0 {
+		t.Errorf("Statement() of nothing = %+v, want no months", months)
+	}
+}
@@ -0,0 +1,144 @@
+// Package cashflow sorts the account's cash movements into categories and
+// summarises them as a monthly cash-flow statement.
+package cashflow
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// Category is the kind of a cash movement
+type Category string
+
+// Categories of cash movement. Deposits, withdrawals and transfers move money
+// into or out of the account; pie transfers and currency conversions move it
+// within the account.
+const (
+	Deposit            Category = "deposit"
+	Withdrawal         Category = "withdrawal"
+	Transfer           Category = "transfer"
+	Interest           Category = "interest"
+	Fee                Category = "fee"
+	Dividend           Category = "dividend"
+	PieTransfer        Category = "pie-transfer"
+	CurrencyConversion Category = "currency-conversion"
+	Other              Category = "other"
+)
+
+// Internal reports whether the category only moves money within the account
+func (c Category) Internal() bool {
+	return c == PieTransfer || c == CurrencyConversion
+}
+
+// Entry is a categorised cash movement. Amount is positive for money into
+// the account's cash and negative for money out of it.
+type Entry struct {
+	Time        time.Time          `json:"time"`
+	Category    Category           `json:"category"`
+	Amount      trading212.Decimal `json:"amount"`
+	Reference   string             `json:"reference,omitempty"`
+	Description string             `json:"description"`
+}
+
+// Categorise returns the category of a transaction type from the
+// transactions endpoint, or of an action in a CSV export
+func Categorise(kind string) Category {
+	kind = strings.ToUpper(strings.TrimSpace(kind))
+	switch {
+	case kind == "DEPOSIT":
+		return Deposit
+	case kind == "WITHDRAW", kind == "WITHDRAWAL":
+		return Withdrawal
+	case strings.Contains(kind, "PIE"):
+		return PieTransfer
+	case strings.Contains(kind, "CONVERSION"), strings.Contains(kind, "CURRENCY"), kind == "FX":
+		return CurrencyConversion
+	case strings.Contains(kind, "INTEREST"):
+		return Interest
+	case strings.HasPrefix(kind, "DIVIDEND"):
+		return Dividend
+	case strings.Contains(kind, "FEE"), strings.Contains(kind, "CHARGE"):
+		return Fee
+	case kind == "TRANSFER":
+		return Transfer
+	}
+	return Other
+}
+
+// signed applies the sign of a category to an amount that may have been
+// reported without one
+func signed(category Category, amount trading212.Decimal) trading212.Decimal {
+	switch category {
+	case Deposit:
+		return amount.Abs()
+	case Withdrawal, Fee:
+		return amount.Abs().Neg()
+	}
+	return amount
+}
+
+// FromTransactions categorises the account's transactions
+func FromTransactions(transactions []trading212.Transaction) []Entry {
+	entries := make([]Entry, 0, len(transactions))
+	for _, transaction := range transactions {
+		category := Categorise(transaction.Type)
+		entries = append(entries, Entry{
+			Time:        transaction.DateTime,
+			Category:    category,
+			Amount:      signed(category, transaction.Amount),
+			Reference:   transaction.Reference,
+			Description: transaction.Type,
+		})
+	}
+	Sort(entries)
+	return entries
+}
+
+// FromDividends returns paid dividends as entries
+func FromDividends(dividends []trading212.Dividend) []Entry {
+	entries := make([]Entry, 0, len(dividends))
+	for _, dividend := range dividends {
+		entries = append(entries, Entry{
+			Time:        dividend.PaidOn,
+			Category:    Dividend,
+			Amount:      dividend.Amount,
+			Reference:   dividend.Reference,
+			Description: "Dividend " + dividend.Ticker,
+		})
+	}
+	Sort(entries)
+	return entries
+}
+
+// FromExport categorises the cash movements of an account history export,
+// leaving out buys and sells
+func FromExport(rows []trading212.ExportRow) []Entry {
+	var entries []Entry
+	for _, row := range rows {
+		if row.IsBuy() || row.IsSell() {
+			continue
+		}
+		category := Categorise(row.Action)
+		description := row.Action
+		if row.Ticker != "" {
+			description += " " + row.Ticker
+		}
+		entries = append(entries, Entry{
+			Time:        row.Time,
+			Category:    category,
+			Amount:      signed(category, row.Total),
+			Reference:   row.ID,
+			Description: description,
+		})
+	}
+	Sort(entries)
+	return entries
+}
+
+// Sort orders entries oldest first
+func Sort(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+}
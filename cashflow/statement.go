@@ -0,0 +1,122 @@
+package cashflow
+
+import (
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// Month is one month of the cash-flow statement. Inflows and Outflows are the
+// money received and paid out, both as positive amounts, leaving out pie
+// transfers and currency conversions, which move money within the account.
+// NetContributions is what the investor paid in less what they took out.
+// Accrued is the interest earned but not yet paid, only known for the
+// current month.
+type Month struct {
+	Month              string             `json:"month"`
+	Inflows            trading212.Decimal `json:"inflows"`
+	Outflows           trading212.Decimal `json:"outflows"`
+	NetContributions   trading212.Decimal `json:"netContributions"`
+	Interest           trading212.Decimal `json:"interest"`
+	Accrued            trading212.Decimal `json:"accrued,omitzero"`
+	Dividends          trading212.Decimal `json:"dividends"`
+	Fees               trading212.Decimal `json:"fees"`
+	PieTransfers       trading212.Decimal `json:"pieTransfers"`
+	CurrencyConversion trading212.Decimal `json:"currencyConversion"`
+	Other              trading212.Decimal `json:"other"`
+	Net                trading212.Decimal `json:"net"`
+}
+
+// Statement summarises entries by calendar month in UTC, from the month of
+// the first entry to the month of now, including months without entries.
+// When cash is not nil its interest is reported as accrued in the month of
+// now.
+func Statement(entries []Entry, cash *trading212.CashInfo, now time.Time) []Month {
+	var months []Month
+	if len(entries) == 0 && cash == nil {
+		return months
+	}
+
+	first := now
+	for _, entry := range entries {
+		if entry.Time.Before(first) {
+			first = entry.Time
+		}
+	}
+	index := make(map[string]int)
+	for start, last := monthStart(first), monthStart(now); !start.After(last); start = start.AddDate(0, 1, 0) {
+		index[start.Format("2006-01")] = len(months)
+		months = append(months, Month{Month: start.Format("2006-01")})
+	}
+
+	for _, entry := range entries {
+		i, found := index[entry.Time.UTC().Format("2006-01")]
+		if !found {
+			continue
+		}
+		months[i].add(entry)
+	}
+	if cash != nil {
+		months[len(months)-1].Accrued = cash.Interest
+	}
+	return months
+}
+
+// Total sums the months of a statement into one, named after the period
+// they cover
+func Total(months []Month) Month {
+	var total Month
+	if len(months) > 0 {
+		total.Month = months[0].Month + " to " + months[len(months)-1].Month
+	}
+	for _, month := range months {
+		total.Inflows = total.Inflows.Add(month.Inflows)
+		total.Outflows = total.Outflows.Add(month.Outflows)
+		total.NetContributions = total.NetContributions.Add(month.NetContributions)
+		total.Interest = total.Interest.Add(month.Interest)
+		total.Accrued = total.Accrued.Add(month.Accrued)
+		total.Dividends = total.Dividends.Add(month.Dividends)
+		total.Fees = total.Fees.Add(month.Fees)
+		total.PieTransfers = total.PieTransfers.Add(month.PieTransfers)
+		total.CurrencyConversion = total.CurrencyConversion.Add(month.CurrencyConversion)
+		total.Other = total.Other.Add(month.Other)
+		total.Net = total.Net.Add(month.Net)
+	}
+	return total
+}
+
+// add adds an entry to the month
+func (m *Month) add(entry Entry) {
+	amount := entry.Amount
+	m.Net = m.Net.Add(amount)
+	if !entry.Category.Internal() {
+		if amount.Sign() > 0 {
+			m.Inflows = m.Inflows.Add(amount)
+		} else {
+			m.Outflows = m.Outflows.Add(amount.Abs())
+		}
+	}
+
+	switch entry.Category {
+	case Deposit, Withdrawal, Transfer:
+		m.NetContributions = m.NetContributions.Add(amount)
+	case Interest:
+		m.Interest = m.Interest.Add(amount)
+	case Dividend:
+		m.Dividends = m.Dividends.Add(amount)
+	case Fee:
+		m.Fees = m.Fees.Add(amount.Neg())
+	case PieTransfer:
+		m.PieTransfers = m.PieTransfers.Add(amount)
+	case CurrencyConversion:
+		m.CurrencyConversion = m.CurrencyConversion.Add(amount)
+	default:
+		m.Other = m.Other.Add(amount)
+	}
+}
+
+// monthStart returns the first moment of t's month in UTC
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,155 @@
+// Package classify assigns instruments a sector, asset class and region from
+// a user-maintained mapping, falling back to defaults derived from the
+// instrument metadata, and reports portfolio exposure along each dimension.
+package classify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/0xnu/trading212"
+)
+
+// Unclassified is used for a dimension that neither the mapping nor the
+// defaults can decide
+const Unclassified = "Unclassified"
+
+// Classification describes an instrument along each dimension of exposure.
+// Currency is always taken from the instrument metadata.
+type Classification struct {
+	Sector     string `json:"sector,omitempty"`
+	AssetClass string `json:"assetClass,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Currency   string `json:"currency,omitempty"`
+}
+
+// merge fills the empty fields of c from other
+func (c Classification) merge(other Classification) Classification {
+	if c.Sector == "" {
+		c.Sector = other.Sector
+	}
+	if c.AssetClass == "" {
+		c.AssetClass = other.AssetClass
+	}
+	if c.Region == "" {
+		c.Region = other.Region
+	}
+	if c.Currency == "" {
+		c.Currency = other.Currency
+	}
+	return c
+}
+
+// Mapping is the user's classification of instruments by ticker and by ISIN.
+// A ticker entry takes precedence over an ISIN entry, and fields left empty
+// fall back to the defaults.
+type Mapping struct {
+	Tickers map[string]Classification `json:"tickers,omitempty"`
+	ISINs   map[string]Classification `json:"isins,omitempty"`
+}
+
+// LoadMapping reads a JSON mapping file
+func LoadMapping(path string) (*Mapping, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseMapping(file)
+}
+
+// ParseMapping decodes a JSON mapping such as
+//
+//	{"tickers": {"AAPL_US_EQ": {"sector": "Technology"}},
+//	 "isins": {"IE00B5BMR087": {"assetClass": "Equity", "region": "North America"}}}
+func ParseMapping(r io.Reader) (*Mapping, error) {
+	var mapping Mapping
+	if err := json.NewDecoder(r).Decode(&mapping); err != nil {
+		return nil, fmt.Errorf("failed to read classification mapping: %v", err)
+	}
+	return &mapping, nil
+}
+
+// assetClasses are the default asset classes of instrument types
+var assetClasses = map[string]string{
+	"STOCK":          "Equity",
+	"ETF":            "Fund",
+	"CRYPTOCURRENCY": "Crypto",
+	"WARRANT":        "Derivative",
+	"FUTURES":        "Derivative",
+	"INDEX":          "Index",
+}
+
+// regions are the default regions of instrument currencies
+var regions = map[string]string{
+	"USD": "North America",
+	"CAD": "North America",
+	"GBP": "United Kingdom",
+	"GBX": "United Kingdom",
+	"EUR": "Europe",
+	"CHF": "Europe",
+	"SEK": "Europe",
+	"DKK": "Europe",
+	"NOK": "Europe",
+	"PLN": "Europe",
+	"CZK": "Europe",
+	"HUF": "Europe",
+	"JPY": "Asia Pacific",
+	"HKD": "Asia Pacific",
+	"AUD": "Asia Pacific",
+	"SGD": "Asia Pacific",
+}
+
+// Default classifies an instrument from its metadata alone: the asset class
+// from its type, the region from its currency and the currency itself. The
+// sector cannot be derived and is left empty.
+func Default(instrument trading212.Instrument) Classification {
+	currency := strings.ToUpper(instrument.CurrencyCode)
+	return Classification{
+		AssetClass: assetClasses[strings.ToUpper(instrument.Type)],
+		Region:     regions[currency],
+		Currency:   currency,
+	}
+}
+
+// Classifier classifies instruments by ticker
+type Classifier struct {
+	mapping     *Mapping
+	instruments map[string]trading212.Instrument
+}
+
+// NewClassifier returns a classifier using mapping, which may be nil, and the
+// instrument metadata for ISINs and defaults
+func NewClassifier(mapping *Mapping, instruments []trading212.Instrument) *Classifier {
+	if mapping == nil {
+		mapping = &Mapping{}
+	}
+	metadata := make(map[string]trading212.Instrument, len(instruments))
+	for _, instrument := range instruments {
+		metadata[instrument.Ticker] = instrument
+	}
+	return &Classifier{mapping: mapping, instruments: metadata}
+}
+
+// Classify returns the instrument's classification from its ticker entry,
+// then its ISIN entry, then the defaults, with any dimension still unknown
+// set to Unclassified
+func (c *Classifier) Classify(ticker string) Classification {
+	instrument := c.instruments[ticker]
+
+	classification := c.mapping.Tickers[ticker]
+	if instrument.ISIN != "" {
+		classification = classification.merge(c.mapping.ISINs[instrument.ISIN])
+	}
+	classification = classification.merge(Default(instrument))
+
+	for _, field := range []*string{&classification.Sector, &classification.AssetClass, &classification.Region, &classification.Currency} {
+		if *field == "" {
+			*field = Unclassified
+		}
+	}
+	return classification
+}
@@ -0,0 +1,84 @@
+package classify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/0xnu/trading212"
+)
+
+// TestClassify tests precedence of ticker, ISIN and default classifications
+func TestClassify(t *testing.T) {
+	mapping, err := ParseMapping(strings.NewReader(`{
+		"tickers": {"AAPL_US_EQ": {"sector": "Technology"}},
+		"isins": {"US0378331005": {"sector": "Hardware", "region": "United States"}, "IE00B5BMR087": {"sector": "Broad Market"}}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseMapping() error = %v", err)
+	}
+	instruments := []trading212.Instrument{
+		{Ticker: "AAPL_US_EQ", Type: "STOCK", ISIN: "US0378331005", CurrencyCode: "USD"},
+		{Ticker: "CSPXl_EQ", Type: "ETF", ISIN: "IE00B5BMR087", CurrencyCode: "USD"},
+		{Ticker: "VODl_EQ", Type: "STOCK", CurrencyCode: "GBX"},
+	}
+	classifier := NewClassifier(mapping, instruments)
+
+	tests := []struct {
+		ticker string
+		want   Classification
+	}{
+		{ticker: "AAPL_US_EQ", want: Classification{Sector: "Technology", AssetClass: "Equity", Region: "United States", Currency: "USD"}},
+		{ticker: "CSPXl_EQ", want: Classification{Sector: "Broad Market", AssetClass: "Fund", Region: "North America", Currency: "USD"}},
+		{ticker: "VODl_EQ", want: Classification{Sector: Unclassified, AssetClass: "Equity", Region: "United Kingdom", Currency: "GBX"}},
+		{ticker: "UNKNOWN", want: Classification{Sector: Unclassified, AssetClass: Unclassified, Region: Unclassified, Currency: Unclassified}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ticker, func(t *testing.T) {
+			if got := classifier.Classify(tt.ticker); got != tt.want {
+				t.Errorf("Classify() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseMapping(strings.NewReader(`{"tickers": []}`)); err == nil {
+		t.Error("Expected error for invalid mapping")
+	}
+}
+
+// TestExposures tests weights, cash and concentration warnings
+func TestExposures(t *testing.T) {
+	classifier := NewClassifier(&Mapping{Tickers: map[string]Classification{
+		"AAPL": {Sector: "Technology"},
+		"MSFT": {Sector: "Technology"},
+		"JNJ":  {Sector: "Healthcare"},
+	}}, []trading212.Instrument{
+		{Ticker: "AAPL", Type: "STOCK", CurrencyCode: "USD"},
+		{Ticker: "MSFT", Type: "STOCK", CurrencyCode: "USD"},
+		{Ticker: "JNJ", Type: "STOCK", CurrencyCode: "USD"},
+	})
+	values := map[string]trading212.Decimal{
+		"AAPL": trading212.NewDecimalFromInt(500),
+		"MSFT": trading212.NewDecimalFromInt(100),
+		"JNJ":  trading212.NewDecimalFromInt(100),
+	}
+
+	report := Exposures(values, trading212.NewDecimalFromInt(300), "GBP", classifier, DefaultLimits)
+	if report.Total.String() != "1000" {
+		t.Errorf("Total = %s, want 1000", report.Total)
+	}
+	if s := report.Sectors; len(s) != 3 || s[0].Name != "Technology" || s[0].Weight != 0.6 || s[0].Positions != 2 || s[1].Name != Cash || s[1].Positions != 0 {
+		t.Errorf("Sectors = %+v", s)
+	}
+	if c := report.Currencies; len(c) != 2 || c[0].Name != "USD" || c[1].Name != "GBP" || c[1].Weight != 0.3 {
+		t.Errorf("Currencies = %+v", c)
+	}
+
+	want := []string{
+		"position AAPL is 50.0% of the portfolio, above the 10.0% limit",
+		"sector Technology is 60.0% of the portfolio, above the 30.0% limit",
+	}
+	if len(report.Warnings) != len(want) || report.Warnings[0] != want[0] || report.Warnings[1] != want[1] {
+		t.Errorf("Warnings = %q, want %q", report.Warnings, want)
+	}
+}
@@ -0,0 +1,136 @@
+package classify
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/0xnu/trading212"
+)
+
+// Cash is the name under which uninvested cash appears in every breakdown
+const Cash = "Cash"
+
+// Limits are the weights above which a position or group is reported as a
+// concentration. Zero disables the check.
+type Limits struct {
+	Position   float64 `json:"position,omitempty"`
+	Sector     float64 `json:"sector,omitempty"`
+	AssetClass float64 `json:"assetClass,omitempty"`
+	Region     float64 `json:"region,omitempty"`
+	Currency   float64 `json:"currency,omitempty"`
+}
+
+// DefaultLimits warn when one position is over 10% or one sector over 30% of
+// the portfolio
+var DefaultLimits = Limits{Position: 0.10, Sector: 0.30}
+
+// Exposure is the share of the portfolio in one position or group
+type Exposure struct {
+	Name      string             `json:"name"`
+	Value     trading212.Decimal `json:"value"`
+	Weight    float64            `json:"weight"`
+	Positions int                `json:"positions"`
+}
+
+// ExposureReport breaks a portfolio's value down along each dimension, with
+// the largest exposures first
+type ExposureReport struct {
+	Total        trading212.Decimal `json:"total"`
+	Positions    []Exposure         `json:"positions"`
+	Sectors      []Exposure         `json:"sectors"`
+	AssetClasses []Exposure         `json:"assetClasses"`
+	Regions      []Exposure         `json:"regions"`
+	Currencies   []Exposure         `json:"currencies"`
+	Warnings     []string           `json:"warnings,omitempty"`
+}
+
+// Exposures reports the exposure of holdings valued in a single currency,
+// such as the account currency values of fx.ValuePortfolio or
+// Position.Value, plus cash in currency. Groups and positions weighing more
+// than the limits are listed in the warnings.
+func Exposures(values map[string]trading212.Decimal, cash trading212.Decimal, currency string, classifier *Classifier, limits Limits) *ExposureReport {
+	report := &ExposureReport{Total: cash}
+	for _, value := range values {
+		report.Total = report.Total.Add(value)
+	}
+
+	positions := newGroups()
+	sectors, assetClasses, regions, currencies := newGroups(), newGroups(), newGroups(), newGroups()
+	for ticker, value := range values {
+		classification := classifier.Classify(ticker)
+		positions.add(ticker, value, 1)
+		sectors.add(classification.Sector, value, 1)
+		assetClasses.add(classification.AssetClass, value, 1)
+		regions.add(classification.Region, value, 1)
+		currencies.add(classification.Currency, value, 1)
+	}
+	if !cash.IsZero() {
+		sectors.add(Cash, cash, 0)
+		assetClasses.add(Cash, cash, 0)
+		regions.add(Cash, cash, 0)
+		currencies.add(currency, cash, 0)
+	}
+
+	report.Positions = positions.exposures(report.Total)
+	report.Sectors = sectors.exposures(report.Total)
+	report.AssetClasses = assetClasses.exposures(report.Total)
+	report.Regions = regions.exposures(report.Total)
+	report.Currencies = currencies.exposures(report.Total)
+
+	report.warn("position", report.Positions, limits.Position)
+	report.warn("sector", report.Sectors, limits.Sector)
+	report.warn("asset class", report.AssetClasses, limits.AssetClass)
+	report.warn("region", report.Regions, limits.Region)
+	report.warn("currency", report.Currencies, limits.Currency)
+	return report
+}
+
+// warn adds a warning for each exposure over the limit, ignoring cash
+func (r *ExposureReport) warn(dimension string, exposures []Exposure, limit float64) {
+	if limit <= 0 {
+		return
+	}
+	for _, exposure := range exposures {
+		if exposure.Name == Cash || exposure.Name == Unclassified || exposure.Weight <= limit {
+			continue
+		}
+		r.Warnings = append(r.Warnings, fmt.Sprintf("%s %s is %.1f%% of the portfolio, above the %.1f%% limit", dimension, exposure.Name, exposure.Weight*100, limit*100))
+	}
+}
+
+// groups accumulates value and position counts by name
+type groups map[string]*Exposure
+
+// newGroups returns an empty set of groups
+func newGroups() groups {
+	return make(groups)
+}
+
+// add adds value held in the given number of positions to the named group
+func (g groups) add(name string, value trading212.Decimal, positions int) {
+	exposure, exists := g[name]
+	if !exists {
+		exposure = &Exposure{Name: name}
+		g[name] = exposure
+	}
+	exposure.Value = exposure.Value.Add(value)
+	exposure.Positions += positions
+}
+
+// exposures returns the groups weighted against total, largest first
+func (g groups) exposures(total trading212.Decimal) []Exposure {
+	exposures := make([]Exposure, 0, len(g))
+	for _, exposure := range g {
+		if total.Sign() > 0 {
+			exposure.Weight = exposure.Value.Div(total).Round(4, trading212.RoundHalfEven).Float64()
+		}
+		exposures = append(exposures, *exposure)
+	}
+	sort.Slice(exposures, func(i, j int) bool {
+		if c := exposures[i].Value.Cmp(exposures[j].Value); c != 0 {
+			return c > 0
+		}
+		return exposures[i].Name < exposures[j].Name
+	})
+	return exposures
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// instrumentCacheTTL is how long the instrument list is reused. The endpoint
+// allows one request every 50 seconds and the list rarely changes.
+const instrumentCacheTTL = 24 * time.Hour
+
+// defaultCacheDir returns the directory for cached data, or "" when none is available
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "t212")
+}
+
+// cachePath returns the path of a cache file, or "" when caching is disabled
+func (a *app) cachePath(name string) string {
+	if a.cacheDir == "" {
+		return ""
+	}
+	return filepath.Join(a.cacheDir, name)
+}
+
+// instruments returns the instrument list from memory, the on-disk cache for
+// the active environment, or the API, refreshing the cache when it is stale
+func (a *app) instruments() ([]trading212.Instrument, error) {
+	if a.instrumentList != nil {
+		return a.instrumentList, nil
+	}
+
+	path := a.cachePath("instruments-" + a.environment() + ".json")
+	if instruments, ok := readInstrumentCache(path); ok {
+		a.instrumentList = instruments
+		return instruments, nil
+	}
+
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	instruments, err := client.InstrumentList()
+	if err != nil {
+		return nil, err
+	}
+
+	a.instrumentList = instruments
+	writeInstrumentCache(path, instruments)
+	return instruments, nil
+}
+
+// readInstrumentCache reads a cached instrument list that is still fresh
+func readInstrumentCache(path string) ([]trading212.Instrument, bool) {
+	if path == "" {
+		return nil, false
+	}
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > instrumentCacheTTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var instruments []trading212.Instrument
+	if err := json.Unmarshal(data, &instruments); err != nil {
+		return nil, false
+	}
+	return instruments, true
+}
+
+// writeInstrumentCache stores the instrument list. Failures only cost a
+// refetch next time, so they are ignored.
+func writeInstrumentCache(path string, instruments []trading212.Instrument) {
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(instruments)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/cashflow"
+	"github.com/0xnu/trading212/journal"
+)
+
+// cashFlags holds the source flags shared by cash-flow commands
+type cashFlags struct {
+	journal *string
+	export  *string
+}
+
+// newCashFlags registers the cash-flow source flags on fs
+func newCashFlags(fs *flag.FlagSet) *cashFlags {
+	return &cashFlags{
+		journal: newJournalFlag(fs),
+		export:  fs.String("export", "", "CSV export to read instead of the journal or API"),
+	}
+}
+
+// cashEntries returns the categorised cash movements from the export, the synced
+// journal when it holds the whole history, or the transaction and dividend
+// endpoints
+func (a *app) cashEntries(flags *cashFlags) ([]cashflow.Entry, error) {
+	if *flags.export != "" {
+		rows, err := loadExport(*flags.export)
+		if err != nil {
+			return nil, err
+		}
+		return cashflow.FromExport(rows), nil
+	}
+
+	var entries []cashflow.Entry
+	j, err := a.historyJournal(*flags.journal, journal.Transactions, journal.Dividends)
+	if err != nil {
+		return nil, err
+	}
+	if j != nil {
+		transactions, err := j.Transactions()
+		if err != nil {
+			return nil, err
+		}
+		dividends, err := j.Dividends()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(cashflow.FromTransactions(transactions), cashflow.FromDividends(dividends)...)
+	} else {
+		client, err := a.api()
+		if err != nil {
+			return nil, err
+		}
+		transactions, err := client.TransactionHistory(0, 50)
+		if err != nil {
+			return nil, err
+		}
+		dividends, err := client.DividendHistory(0, "", 50)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(cashflow.FromTransactions(transactions), cashflow.FromDividends(dividends)...)
+	}
+	cashflow.Sort(entries)
+	return entries, nil
+}
+
+// runCashflowTransactions lists categorised cash movements
+func runCashflowTransactions(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("cashflow transactions")
+	flags := newCashFlags(fs)
+	category := fs.String("category", "", "only include this category, e.g. interest or fee")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	switch cashflow.Category(*category) {
+	case "", cashflow.Deposit, cashflow.Withdrawal, cashflow.Transfer, cashflow.Interest, cashflow.Fee,
+		cashflow.Dividend, cashflow.PieTransfer, cashflow.CurrencyConversion, cashflow.Other:
+	default:
+		return nil, usageError{fmt.Sprintf("unknown category %q", *category)}
+	}
+
+	entries, err := a.cashEntries(flags)
+	if err != nil {
+		return nil, err
+	}
+	if *category == "" {
+		return entries, nil
+	}
+	filtered := []cashflow.Entry{}
+	for _, entry := range entries {
+		if string(entry.Category) == *category {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered, nil
+}
+
+// runCashflowStatement summarises cash movements by month, with the interest
+// accrued this month from the account cash unless the movements come from an
+// export or --no-accrual is given
+func runCashflowStatement(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("cashflow statement")
+	flags := newCashFlags(fs)
+	total := fs.Bool("total", false, "add a row totalling every month")
+	noAccrual := fs.Bool("no-accrual", false, "leave out the interest accrued this month, which needs the API")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	entries, err := a.cashEntries(flags)
+	if err != nil {
+		return nil, err
+	}
+	var cash *trading212.CashInfo
+	if !*noAccrual && *flags.export == "" {
+		client, err := a.api()
+		if err != nil {
+			return nil, err
+		}
+		if cash, err = client.Cash(); err != nil {
+			return nil, err
+		}
+	}
+
+	months := cashflow.Statement(entries, cash, time.Now())
+	if cash != nil && len(months) > 0 {
+		fmt.Fprintf(a.stderr, "interest accrued this month: %s\n", cash.Interest.StringFixed(2))
+	}
+	if *total {
+		months = append(months, cashflow.Total(months))
+	}
+	return months, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// TestRunCashflow tests categorising transactions and the monthly statement
+func TestRunCashflow(t *testing.T) {
+	month := time.Now().UTC().Format("2006-01")
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/history/transactions":
+			writeJSON(w, `{"items":[`+
+				`{"type":"DEPOSIT","reference":"d-1","amount":500,"dateTime":"`+month+`-01T09:00:00Z"},`+
+				`{"type":"FEE","reference":"f-1","amount":-1.5,"dateTime":"`+month+`-01T10:00:00Z"}]}`)
+		case "/api/v0/history/dividends":
+			writeJSON(w, `{"items":[{"ticker":"AAPL_US_EQ","reference":"v-1","amount":2,"paidOn":"`+month+`-01T11:00:00Z"}]}`)
+		case "/api/v0/equity/account/cash":
+			writeJSON(w, `{"free":500,"interest":0.75}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	if code := a.run([]string{"--output", "json", "cashflow", "transactions", "--category", "fee"}); code != 0 {
+		t.Fatalf("run(cashflow transactions) = %d, stderr %s", code, stderr)
+	}
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(entries) != 1 || entries[0]["reference"] != "f-1" || entries[0]["amount"] != -1.5 {
+		t.Errorf("entries = %v, want the fee", entries)
+	}
+
+	stdout.Reset()
+	if code := a.run([]string{"--output", "json", "cashflow", "statement", "--total"}); code != 0 {
+		t.Fatalf("run(cashflow statement) = %d, stderr %s", code, stderr)
+	}
+	var months []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &months); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(months) != 2 || months[0]["month"] != month || months[0]["netContributions"] != 500.0 || months[0]["dividends"] != 2.0 || months[0]["accrued"] != 0.75 {
+		t.Errorf("months = %v", months)
+	}
+	if !strings.Contains(stderr.String(), "interest accrued this month: 0.75") {
+		t.Errorf("stderr = %q, want the accrued interest", stderr)
+	}
+
+	partial := filepath.Join(t.TempDir(), "journal")
+	if code := a.run([]string{"sync", "--only", "transactions", "--journal", partial}); code != 0 {
+		t.Fatalf("run(sync) = %d, stderr %s", code, stderr)
+	}
+	if code := a.run([]string{"cashflow", "transactions", "--journal", partial}); code != 1 {
+		t.Errorf("run(cashflow transactions --journal) = %d, want 1 for a journal without dividends", code)
+	}
+
+	if code := a.run([]string{"cashflow", "transactions", "--category", "salary"}); code != 2 {
+		t.Errorf("run(cashflow transactions --category salary) = %d, want 2", code)
+	}
+}
+
+// TestRunCashflowStatementExport tests that a statement from an export needs
+// no API credentials
+func TestRunCashflowStatementExport(t *testing.T) {
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	})
+	a.newClient = func(trading212.CredentialProvider, bool) (*trading212.Client, error) {
+		return nil, errors.New("no API key")
+	}
+
+	month := time.Now().UTC().Format("2006-01")
+	export := filepath.Join(t.TempDir(), "export.csv")
+	csv := "Action,Time,Total,Currency (Total),ID\n" +
+		"Deposit," + month + "-01 09:00:00,500,GBP,d-1\n" +
+		"Interest on cash," + month + "-02 09:00:00,0.5,GBP,i-1\n"
+	if err := os.WriteFile(export, []byte(csv), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := a.run([]string{"--output", "json", "cashflow", "statement", "--export", export}); code != 0 {
+		t.Fatalf("run(cashflow statement --export) = %d, stderr %s", code, stderr)
+	}
+	var months []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &months); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(months) != 1 || months[0]["netContributions"] != 500.0 || months[0]["interest"] != 0.5 || months[0]["accrued"] != nil {
+		t.Errorf("months = %v", months)
+	}
+	if strings.Contains(stderr.String(), "accrued") {
+		t.Errorf("stderr = %q, want no accrued interest", stderr)
+	}
+
+	if code := a.run([]string{"cashflow", "statement"}); code != 1 {
+		t.Errorf("run(cashflow statement) = %d, want 1 without an API key", code)
+	}
+	if code := a.run([]string{"cashflow", "statement", "--no-accrual"}); code != 1 {
+		t.Errorf("run(cashflow statement --no-accrual) = %d, want 1 without a journal or API key", code)
+	}
+}
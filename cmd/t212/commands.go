@@ -0,0 +1,258 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/format"
+)
+
+// command describes a CLI command. Commands return their result instead of
+// printing it so that the same layer can back other front ends.
+type command struct {
+	path     string
+	args     string
+	summary  string
+	mutating bool
+	run      func(a *app, args []string) (interface{}, error)
+}
+
+// commands lists every command, in the order shown by help
+var commands = []*command{
+	{path: "account", summary: "Show account information", run: runAccount},
+	{path: "cash", summary: "Show account cash", run: runCash},
+	{path: "portfolio", summary: "List open positions", run: runPortfolio},
+	{path: "position", args: "TICKER", summary: "Show an open position", run: runPosition},
+	{path: "exposure", args: "[--by position|sector|asset-class|region|currency] [--mapping FILE] [--rates FILE]", summary: "Break down open positions by sector, asset class, region or currency", run: runExposure},
+	{path: "orders list", summary: "List pending orders", run: runOrdersList},
+	{path: "orders get", args: "ID", summary: "Show a pending order", run: runOrdersGet},
+	{path: "orders cancel", args: "ID", summary: "Cancel a pending order", mutating: true, run: runOrdersCancel},
+	{path: "order buy", args: "TICKER QUANTITY [--type limit|market|stop|stop-limit] [--limit PRICE] [--stop PRICE] [--validity DAY|GTC] [--yes] [--dry-run]", summary: "Place a buy order", mutating: true, run: runOrder("buy")},
+	{path: "order sell", args: "TICKER QUANTITY [--type limit|market|stop|stop-limit] [--limit PRICE] [--stop PRICE] [--validity DAY|GTC] [--yes] [--dry-run]", summary: "Place a sell order", mutating: true, run: runOrder("sell")},
+	{path: "pies list", summary: "List pies", run: runPiesList},
+	{path: "pies get", args: "ID", summary: "Show a pie", run: runPiesGet},
+	{path: "pies create", args: "--name NAME --icon ICON --goal GOAL --end-date YYYY-MM-DD --share TICKER=WEIGHT...", summary: "Create a pie", mutating: true, run: runPiesCreate},
+	{path: "pies update", args: "ID [--name NAME] [--icon ICON] [--goal GOAL] [--end-date YYYY-MM-DD] [--share TICKER=WEIGHT...]", summary: "Update a pie", mutating: true, run: runPiesUpdate},
+	{path: "pies delete", args: "ID", summary: "Delete a pie", mutating: true, run: runPiesDelete},
+	{path: "history orders", args: "[--ticker TICKER] [--limit N] [--cursor N] [--actions FILE]", summary: "List historical orders", run: runHistoryOrders},
+	{path: "history dividends", args: "[--ticker TICKER] [--limit N] [--cursor N] [--actions FILE]", summary: "List paid dividends", run: runHistoryDividends},
+	{path: "history transactions", args: "[--limit N] [--cursor N]", summary: "List account transactions", run: runHistoryTransactions},
+	{path: "history at", args: "YYYY-MM-DD [--prices FILE] [--rates FILE] [--method fifo|lifo|average] [--journal DIR] [--actions FILE]", summary: "Rebuild holdings, cost basis and cash at a past date", run: runHistoryAt},
+	{path: "sync", args: "[--only orders,dividends,transactions] [--journal DIR]", summary: "Download new orders, dividends and transactions to the local journal", run: runSync},
+	{path: "reconcile", args: "[--journal FILE] [--history DIR] [--export FILE] [--no-api] [--time-tolerance DURATION] [--quantity-tolerance N] [--price-tolerance PERCENT]", summary: "Compare orders in a bot's order journal, the order history and a CSV export", run: runReconcile},
+	{path: "export request", args: "--from YYYY-MM-DD --to YYYY-MM-DD", summary: "Request a CSV export", mutating: true, run: runExportRequest},
+	{path: "export list", summary: "List CSV exports", run: runExportList},
+	{path: "export download", args: "ID [--output FILE]", summary: "Download a finished CSV export", run: runExportDownload},
+	{path: "cashflow transactions", args: "[--category CATEGORY] [--export FILE] [--journal DIR]", summary: "List cash movements by category", run: runCashflowTransactions},
+	{path: "cashflow statement", args: "[--total] [--export FILE] [--journal DIR] [--no-accrual]", summary: "Monthly cash-flow statement with contributions and interest", run: runCashflowStatement},
+	{path: "tax dividends", args: "[--year YYYY/YY] [--export FILE] [--rates FILE] [--actions FILE]", summary: "Report dividend income and withholding tax by tax year", run: runTaxDividends},
+	{path: "snapshot record", args: "[--pies] [--store FILE]", summary: "Record the account value, cash and positions in the local snapshot store", run: runSnapshotRecord},
+	{path: "snapshot values", args: "[--from YYYY-MM-DD] [--to YYYY-MM-DD] [--store FILE]", summary: "List the recorded account value over time", run: runSnapshotValues},
+	{path: "snapshot position", args: "TICKER [--from YYYY-MM-DD] [--to YYYY-MM-DD] [--store FILE]", summary: "List the recorded holding of an instrument over time", run: runSnapshotPosition},
+	{path: "snapshot cash", args: "[--from YYYY-MM-DD] [--to YYYY-MM-DD] [--store FILE]", summary: "List the recorded cash over time", run: runSnapshotCash},
+	{path: "snapshot compact", args: "[--keep-all DAYS] [--daily DAYS] [--max-age DAYS] [--store FILE]", summary: "Thin out old snapshots", run: runSnapshotCompact},
+	{path: "instruments search", args: "QUERY [--limit N]", summary: "Search tradeable instruments", run: runInstrumentsSearch},
+	{path: "watch", args: "[--interval DURATION] [--once] [--no-color]", summary: "Live dashboard of positions, orders, cash and pies", run: runWatch},
+	{path: "profiles", summary: "List configured profiles", run: runProfiles},
+}
+
+// findCommand returns the command matching the longest prefix of args and the
+// remaining arguments
+func findCommand(args []string) (*command, []string) {
+	for n := min(len(args), 2); n >= 1; n-- {
+		path := strings.Join(args[:n], " ")
+		for _, cmd := range commands {
+			if cmd.path == path {
+				return cmd, args[n:]
+			}
+		}
+	}
+	return nil, args
+}
+
+// execute runs the command named by args
+func (a *app) execute(args []string) (interface{}, error) {
+	cmd, rest := findCommand(args)
+	if cmd == nil {
+		return nil, usageError{fmt.Sprintf("unknown command %q, run 't212 help' for usage", strings.Join(args, " "))}
+	}
+
+	if cmd.mutating {
+		a.announce()
+	}
+
+	result, err := cmd.run(a, rest)
+	var usage usageError
+	if errors.As(err, &usage) {
+		return nil, usageError{fmt.Sprintf("%s\nusage: t212 %s %s", usage.message, cmd.path, cmd.args)}
+	}
+	return result, err
+}
+
+// print writes a command result to stdout in the selected output format
+func (a *app) print(result interface{}) error {
+	if result == nil {
+		return nil
+	}
+	return format.Write(a.stdout, a.output, result, format.Options{Currency: a.currency})
+}
+
+// printUsage writes the list of commands to stderr
+func (a *app) printUsage() {
+	fmt.Fprintln(a.stderr, "Usage: t212 [--profile NAME] [--config FILE] [--live] [--output table|json|ndjson|csv] [--currency CODE] <command> [arguments]")
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintln(a.stderr, "Commands:")
+
+	w := tabwriter.NewWriter(a.stderr, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.path, cmd.summary)
+	}
+	fmt.Fprintf(w, "  %s\t%s\n", "shell", "Start an interactive shell")
+	w.Flush()
+}
+
+// newFlagSet creates a flag set for a command that reports errors to stderr
+func (a *app) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
+
+// parseArgs parses flags that may appear before, between or after positional
+// arguments and returns the positional arguments
+func parseArgs(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) != want {
+		return nil, usageError{fmt.Sprintf("%s expects %d argument(s), got %d", fs.Name(), want, len(positional))}
+	}
+	return positional, nil
+}
+
+// parseID parses a numeric order or pie identifier
+func parseID(value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, usageError{fmt.Sprintf("invalid id %q", value)}
+	}
+	return id, nil
+}
+
+// runAccount shows account information
+func runAccount(a *app, args []string) (interface{}, error) {
+	if _, err := parseArgs(a.newFlagSet("account"), args, 0); err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.AccountInfo()
+}
+
+// runCash shows account cash
+func runCash(a *app, args []string) (interface{}, error) {
+	if _, err := parseArgs(a.newFlagSet("cash"), args, 0); err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.Cash()
+}
+
+// runPortfolio lists open positions
+func runPortfolio(a *app, args []string) (interface{}, error) {
+	if _, err := parseArgs(a.newFlagSet("portfolio"), args, 0); err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.Portfolio()
+}
+
+// runPosition shows a single open position
+func runPosition(a *app, args []string) (interface{}, error) {
+	positional, err := parseArgs(a.newFlagSet("position"), args, 1)
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.Position(positional[0])
+}
+
+// runInstrumentsSearch searches instrument metadata
+func runInstrumentsSearch(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("instruments search")
+	limit := fs.Int("limit", 20, "maximum number of results")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	instruments, err := a.instruments()
+	if err != nil {
+		return nil, err
+	}
+
+	results := trading212.SearchInstruments(instruments, positional[0])
+	if *limit > 0 && len(results) > *limit {
+		results = results[:*limit]
+	}
+	return results, nil
+}
+
+// profileSummary describes a configured profile
+type profileSummary struct {
+	Name        string `json:"name"`
+	Active      bool   `json:"active"`
+	Environment string `json:"environment"`
+	Credentials string `json:"credentials"`
+	Output      string `json:"output"`
+}
+
+// runProfiles lists configured profiles
+func runProfiles(a *app, args []string) (interface{}, error) {
+	if _, err := parseArgs(a.newFlagSet("profiles"), args, 0); err != nil {
+		return nil, err
+	}
+
+	summaries := []profileSummary{}
+	for _, name := range a.config.names() {
+		p := a.config.Profiles[name]
+		credentials := p.Credentials.Type
+		if credentials == "" {
+			credentials = "env"
+		}
+		summaries = append(summaries, profileSummary{
+			Name:        name,
+			Active:      name == a.profileName,
+			Environment: p.Environment,
+			Credentials: credentials,
+			Output:      p.Output,
+		})
+	}
+	return summaries, nil
+}
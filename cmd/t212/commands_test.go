@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestFindCommand tests command lookup by longest prefix
+func TestFindCommand(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantPath string
+		wantRest int
+	}{
+		{args: []string{"cash"}, wantPath: "cash", wantRest: 0},
+		{args: []string{"position", "AAPL"}, wantPath: "position", wantRest: 1},
+		{args: []string{"orders", "get", "12"}, wantPath: "orders get", wantRest: 1},
+		{args: []string{"order", "buy", "AAPL", "1"}, wantPath: "order buy", wantRest: 2},
+		{args: []string{"orders"}, wantPath: "", wantRest: 1},
+	}
+
+	for _, tt := range tests {
+		cmd, rest := findCommand(tt.args)
+		path := ""
+		if cmd != nil {
+			path = cmd.path
+		}
+		if path != tt.wantPath || len(rest) != tt.wantRest {
+			t.Errorf("findCommand(%v) = %q with %d args, want %q with %d", tt.args, path, len(rest), tt.wantPath, tt.wantRest)
+		}
+	}
+}
+
+// TestParseArgs tests flags interspersed with positional arguments
+func TestParseArgs(t *testing.T) {
+	a := newApp(&bytes.Buffer{}, &bytes.Buffer{})
+	fs := a.newFlagSet("test")
+	limit := fs.Int("limit", 0, "")
+
+	positional, err := parseArgs(fs, []string{"AAPL", "--limit", "5", "10"}, 2)
+	if err != nil {
+		t.Fatalf("parseArgs() error = %v", err)
+	}
+	if positional[0] != "AAPL" || positional[1] != "10" || *limit != 5 {
+		t.Errorf("parseArgs() = %v, limit %d", positional, *limit)
+	}
+
+	_, err = parseArgs(a.newFlagSet("test"), []string{"one"}, 2)
+	var usage usageError
+	if !errors.As(err, &usage) {
+		t.Errorf("Expected usage error for missing argument, got %v", err)
+	}
+}
+
+// TestParseID tests identifier validation
+func TestParseID(t *testing.T) {
+	if id, err := parseID("42"); err != nil || id != 42 {
+		t.Errorf("parseID(42) = %d, %v", id, err)
+	}
+	for _, value := range []string{"abc", "0", "-3"} {
+		if _, err := parseID(value); err == nil {
+			t.Errorf("parseID(%q) expected error", value)
+		}
+	}
+}
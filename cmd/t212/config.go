@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/0xnu/trading212"
+)
+
+const (
+	configEnv         = "T212_CONFIG"
+	profileEnv        = "T212_PROFILE"
+	defaultPassphrase = "T212_PASSPHRASE"
+)
+
+// config is the t212 configuration file, by default ~/.config/t212/config.json
+type config struct {
+	DefaultProfile string              `json:"defaultProfile"`
+	Profiles       map[string]*profile `json:"profiles"`
+}
+
+// profile describes one account and environment
+type profile struct {
+	Environment    string           `json:"environment"`
+	Credentials    credentialSource `json:"credentials"`
+	Output         string           `json:"output,omitempty"`
+	Currency       string           `json:"currency,omitempty"`
+	Actions        string           `json:"actions,omitempty"`
+	Classification string           `json:"classification,omitempty"`
+	Snapshots      string           `json:"snapshots,omitempty"`
+	Journal        string           `json:"journal,omitempty"`
+	Limits         riskLimits       `json:"limits"`
+}
+
+// credentialSource selects where a profile's credentials come from
+type credentialSource struct {
+	Type          string   `json:"type"`
+	KeyEnv        string   `json:"keyEnv,omitempty"`
+	SecretEnv     string   `json:"secretEnv,omitempty"`
+	Path          string   `json:"path,omitempty"`
+	Command       []string `json:"command,omitempty"`
+	PassphraseEnv string   `json:"passphraseEnv,omitempty"`
+}
+
+// riskLimits caps the size of orders placed through a profile. Zero means no limit.
+type riskLimits struct {
+	MaxOrderQuantity int                `json:"maxOrderQuantity,omitempty"`
+	MaxOrderValue    trading212.Decimal `json:"maxOrderValue,omitzero"`
+}
+
+// defaultConfigPath returns the configuration file location
+func defaultConfigPath() string {
+	if path := os.Getenv(configEnv); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "t212", "config.json")
+}
+
+// loadConfig reads the configuration file. A missing file yields an empty
+// configuration unless the path was given explicitly.
+func loadConfig(path string, explicit bool) (*config, error) {
+	cfg := &config{Profiles: map[string]*profile{}}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %v", path, err)
+	}
+	for name, p := range cfg.Profiles {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("profile %s: %v", name, err)
+		}
+	}
+	return cfg, nil
+}
+
+// profile returns the named profile, falling back to T212_PROFILE and then the
+// default profile. It returns an empty name and nil when none is configured.
+func (c *config) profile(name string) (string, *profile, error) {
+	if name == "" {
+		name = os.Getenv(profileEnv)
+	}
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return "", nil, nil
+	}
+
+	p, exists := c.Profiles[name]
+	if !exists {
+		return "", nil, fmt.Errorf("unknown profile %q", name)
+	}
+	return name, p, nil
+}
+
+// names returns the configured profile names in alphabetical order
+func (c *config) names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validate checks the profile's environment and credential source
+func (p *profile) validate() error {
+	if p.Environment != "demo" && p.Environment != "live" {
+		return fmt.Errorf("environment must be demo or live, got %q", p.Environment)
+	}
+	_, err := p.Credentials.provider()
+	return err
+}
+
+// provider creates the credential provider described by the source
+func (s credentialSource) provider() (trading212.CredentialProvider, error) {
+	switch s.Type {
+	case "", "env":
+		return trading212.EnvCredentials{KeyVar: s.KeyEnv, SecretVar: s.SecretEnv}, nil
+	case "file":
+		if s.Path == "" {
+			return nil, fmt.Errorf("file credentials require a path")
+		}
+		return trading212.FileCredentials{Path: expandHome(s.Path)}, nil
+	case "command":
+		if len(s.Command) == 0 {
+			return nil, fmt.Errorf("command credentials require a command")
+		}
+		return trading212.CommandCredentials{Name: s.Command[0], Args: s.Command[1:]}, nil
+	case "encrypted":
+		if s.Path == "" {
+			return nil, fmt.Errorf("encrypted credentials require a path")
+		}
+		return passphraseProvider{path: expandHome(s.Path), passphraseEnv: s.PassphraseEnv}, nil
+	default:
+		return nil, fmt.Errorf("unknown credentials type %q", s.Type)
+	}
+}
+
+// passphraseProvider reads the passphrase for an encrypted credentials file from
+// the environment when credentials are first needed
+type passphraseProvider struct {
+	path          string
+	passphraseEnv string
+}
+
+func (p passphraseProvider) Credentials() (trading212.Credentials, error) {
+	env := p.passphraseEnv
+	if env == "" {
+		env = defaultPassphrase
+	}
+	passphrase := os.Getenv(env)
+	if passphrase == "" {
+		return trading212.Credentials{}, fmt.Errorf("environment variable %s must hold the passphrase for %s", env, p.path)
+	}
+	return trading212.EncryptedFileCredentials{Path: p.path, Passphrase: passphrase}.Credentials()
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if len(path) < 2 || path[:2] != "~/" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// check enforces the active profile's risk limits on an order. The price is
+// the estimated execution price in the account currency, or zero when unknown.
+func (l riskLimits) check(quantity int, price trading212.Decimal) error {
+	if quantity < 0 {
+		quantity = -quantity
+	}
+	if l.MaxOrderQuantity > 0 && quantity > l.MaxOrderQuantity {
+		return fmt.Errorf("order quantity %d exceeds profile limit of %d", quantity, l.MaxOrderQuantity)
+	}
+	if value := price.MulInt(int64(quantity)); l.MaxOrderValue.Sign() > 0 && price.Sign() > 0 && value.Cmp(l.MaxOrderValue) > 0 {
+		return fmt.Errorf("order value %s exceeds profile limit of %s", value.StringFixed(2), l.MaxOrderValue.StringFixed(2))
+	}
+	return nil
+}
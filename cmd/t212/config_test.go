@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xnu/trading212"
+)
+
+const testConfig = `{
+  "defaultProfile": "invest-demo",
+  "profiles": {
+    "invest-demo": {"environment": "demo", "credentials": {"type": "env"}},
+    "isa": {
+      "environment": "live",
+      "credentials": {"type": "env", "keyEnv": "ISA_KEY"},
+      "output": "csv",
+      "currency": "GBP",
+      "limits": {"maxOrderQuantity": 10, "maxOrderValue": 1000}
+    }
+  }
+}`
+
+// writeTestConfig writes a configuration file and points T212_CONFIG at it
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("T212_CONFIG", path)
+	return path
+}
+
+// TestConfigProfileSelection tests flag, environment and default profile precedence
+func TestConfigProfileSelection(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	cfg, err := loadConfig(path, true)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+
+	t.Setenv("T212_PROFILE", "")
+	if name, _, _ := cfg.profile(""); name != "invest-demo" {
+		t.Errorf("default profile = %q, want invest-demo", name)
+	}
+
+	t.Setenv("T212_PROFILE", "isa")
+	if name, _, _ := cfg.profile(""); name != "isa" {
+		t.Errorf("env profile = %q, want isa", name)
+	}
+	if name, _, _ := cfg.profile("invest-demo"); name != "invest-demo" {
+		t.Errorf("flag profile = %q, want invest-demo", name)
+	}
+
+	if _, _, err := cfg.profile("missing"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+}
+
+// TestLoadConfigValidation tests that invalid profiles are rejected
+func TestLoadConfigValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "bad environment", content: `{"profiles":{"x":{"environment":"staging"}}}`},
+		{name: "bad credentials", content: `{"profiles":{"x":{"environment":"demo","credentials":{"type":"vault"}}}}`},
+		{name: "file without path", content: `{"profiles":{"x":{"environment":"demo","credentials":{"type":"file"}}}}`},
+		{name: "invalid json", content: `{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestConfig(t, tt.content)
+			if _, err := loadConfig(path, true); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.json"), false); err != nil {
+		t.Errorf("Missing default config should not be an error, got %v", err)
+	}
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.json"), true); err == nil {
+		t.Error("Missing explicit config should be an error")
+	}
+}
+
+// TestRunWithProfile tests that a profile sets environment, output and limits
+func TestRunWithProfile(t *testing.T) {
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"id":1,"ticker":"AAPL","quantity":20}`)
+	})
+	writeTestConfig(t, testConfig)
+
+	var live bool
+	newClient := a.newClient
+	a.newClient = newTestClientRecorder(newClient, &live)
+
+	if code := a.run([]string{"--profile", "isa", "cash"}); code != 0 {
+		t.Fatalf("run(cash) = %d, want 0: %s", code, stderr.String())
+	}
+	if !live {
+		t.Error("Expected isa profile to use the live environment")
+	}
+	if !strings.HasPrefix(stdout.String(), "free,total") {
+		t.Errorf("Expected csv output from profile, got %q", stdout.String())
+	}
+
+	stderr.Reset()
+	code := a.run([]string{"--profile", "isa", "order", "buy", "AAPL", "20"})
+	if code != 1 || !strings.Contains(stderr.String(), "exceeds profile limit") {
+		t.Errorf("run(order buy 20) = %d, stderr %q, want limit error", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "profile: isa, environment: LIVE") {
+		t.Errorf("Expected profile banner for mutating command, got %q", stderr.String())
+	}
+}
+
+// TestRunFlagsOverrideProfile tests that explicit flags take precedence over the profile
+func TestRunFlagsOverrideProfile(t *testing.T) {
+	a, stdout, _ := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"free":1}`)
+	})
+	writeTestConfig(t, testConfig)
+
+	if code := a.run([]string{"--profile", "isa", "--output", "json", "cash"}); code != 0 {
+		t.Fatalf("run(cash) = %d, want 0", code)
+	}
+	if !strings.Contains(stdout.String(), `"free": 1`) {
+		t.Errorf("Expected json output, got %q", stdout.String())
+	}
+}
+
+// TestRiskLimits tests order size limits
+func TestRiskLimits(t *testing.T) {
+	limits := riskLimits{MaxOrderQuantity: 5, MaxOrderValue: trading212.NewDecimalFromInt(500)}
+
+	if err := limits.check(-5, trading212.NewDecimalFromInt(100)); err != nil {
+		t.Errorf("check(-5, 100) error = %v", err)
+	}
+	if err := limits.check(6, trading212.Decimal{}); err == nil {
+		t.Error("Expected quantity limit error")
+	}
+	// Three at 0.1 is exactly the limit, although float64 puts it just above
+	if err := (riskLimits{MaxOrderValue: trading212.MustParseDecimal("0.3")}).check(3, trading212.MustParseDecimal("0.1")); err != nil {
+		t.Errorf("check(3, 0.1) error = %v, want a value at the limit allowed", err)
+	}
+	if err := limits.check(5, trading212.MustParseDecimal("100.01")); err == nil || !strings.Contains(err.Error(), "500.05 exceeds profile limit of 500.00") {
+		t.Errorf("check(5, 100.01) error = %v, want value limit error", err)
+	}
+	if err := (riskLimits{}).check(1000, trading212.NewDecimalFromInt(1000)); err != nil {
+		t.Errorf("Empty limits should allow any order, got %v", err)
+	}
+
+	var decoded riskLimits
+	if err := json.Unmarshal([]byte(`{"maxOrderValue": 1000.5}`), &decoded); err != nil || decoded.MaxOrderValue.String() != "1000.5" {
+		t.Errorf("decoded limits = %+v, %v", decoded, err)
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// errInterrupted is returned by readLine when the user presses Ctrl-C
+var errInterrupted = errors.New("interrupted")
+
+// Key codes handled by the line editor
+const (
+	keyCtrlA     = 1
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyBackspace = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// maxListedCompletions caps the number of candidates printed for one tab press
+const maxListedCompletions = 60
+
+// lineEditor reads lines with history and tab completion. When the input is a
+// terminal it is switched to unbuffered, no-echo mode while a line is being
+// read; otherwise lines are read as they arrive and nothing is echoed.
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	terminal *terminal
+	history  []string
+	complete func(line string) (word string, candidates []string)
+
+	line []rune
+	pos  int
+}
+
+// newLineEditor creates an editor reading from in and echoing to out
+func newLineEditor(in io.Reader, out io.Writer) *lineEditor {
+	e := &lineEditor{in: bufio.NewReader(in), out: out}
+	if f, ok := in.(*os.File); ok {
+		e.terminal = openTerminal(f)
+	}
+	return e
+}
+
+// Read lets commands that prompt, such as order confirmation, share the
+// editor's buffered input
+func (e *lineEditor) Read(p []byte) (int, error) {
+	return e.in.Read(p)
+}
+
+// ReadString reads up to and including delim from the editor's buffered input
+func (e *lineEditor) ReadString(delim byte) (string, error) {
+	return e.in.ReadString(delim)
+}
+
+// addHistory records a line, skipping blanks and immediate repeats
+func (e *lineEditor) addHistory(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+}
+
+// readLine prints the prompt and returns the next line without its newline.
+// It returns io.EOF at the end of input or on Ctrl-D at an empty line.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	if e.terminal == nil {
+		fmt.Fprint(e.out, prompt)
+		line, err := e.in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	if err := e.terminal.raw(); err != nil {
+		return "", err
+	}
+	defer e.terminal.restore()
+	return e.edit(prompt)
+}
+
+// edit runs the interactive editing loop for one line
+func (e *lineEditor) edit(prompt string) (string, error) {
+	e.line, e.pos = nil, 0
+	historyIndex := len(e.history)
+	draft := ""
+	e.redraw(prompt)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyLineFeed:
+			fmt.Fprint(e.out, "\r\n")
+			return string(e.line), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(e.line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case keyBackspace, keyDelete:
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.line)
+		case keyCtrlU:
+			e.line, e.pos = e.line[e.pos:], 0
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyTab:
+			e.completeWord()
+		case keyEscape:
+			switch e.readEscape() {
+			case 'A':
+				if historyIndex > 0 {
+					if historyIndex == len(e.history) {
+						draft = string(e.line)
+					}
+					historyIndex--
+					e.setLine(e.history[historyIndex])
+				}
+			case 'B':
+				if historyIndex < len(e.history) {
+					historyIndex++
+					if historyIndex == len(e.history) {
+						e.setLine(draft)
+					} else {
+						e.setLine(e.history[historyIndex])
+					}
+				}
+			case 'C':
+				if e.pos < len(e.line) {
+					e.pos++
+				}
+			case 'D':
+				if e.pos > 0 {
+					e.pos--
+				}
+			case 'H':
+				e.pos = 0
+			case 'F':
+				e.pos = len(e.line)
+			}
+		default:
+			if r >= ' ' {
+				e.insert(string(r))
+			}
+		}
+		e.redraw(prompt)
+	}
+}
+
+// readEscape consumes an ANSI escape sequence and returns its final byte
+func (e *lineEditor) readEscape() byte {
+	b, err := e.in.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return 0
+	}
+	for {
+		b, err = e.in.ReadByte()
+		if err != nil {
+			return 0
+		}
+		if b >= '@' && b <= '~' {
+			return b
+		}
+	}
+}
+
+// insert inserts text at the cursor
+func (e *lineEditor) insert(text string) {
+	runes := []rune(text)
+	line := make([]rune, 0, len(e.line)+len(runes))
+	line = append(line, e.line[:e.pos]...)
+	line = append(line, runes...)
+	e.line = append(line, e.line[e.pos:]...)
+	e.pos += len(runes)
+}
+
+// deleteAt removes the rune at index i
+func (e *lineEditor) deleteAt(i int) {
+	if i < len(e.line) {
+		e.line = append(e.line[:i], e.line[i+1:]...)
+	}
+}
+
+// setLine replaces the line and moves the cursor to its end
+func (e *lineEditor) setLine(line string) {
+	e.line = []rune(line)
+	e.pos = len(e.line)
+}
+
+// redraw rewrites the prompt and line and positions the cursor
+func (e *lineEditor) redraw(prompt string) {
+	fmt.Fprintf(e.out, "\r\x1b[K%s%s", prompt, string(e.line))
+	if back := len(e.line) - e.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+// completeWord completes the word before the cursor. A single candidate
+// replaces the word and is followed by a space, several are extended to their
+// common prefix and listed when the prefix cannot grow.
+func (e *lineEditor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+	word, candidates := e.complete(string(e.line[:e.pos]))
+	if len(candidates) == 0 {
+		return
+	}
+
+	if len(candidates) == 1 {
+		e.replaceWord(word, candidates[0]+" ")
+		return
+	}
+
+	if prefix := commonPrefix(candidates); len(prefix) > len(word) {
+		e.replaceWord(word, prefix)
+		return
+	}
+
+	fmt.Fprint(e.out, "\r\n")
+	if len(candidates) > maxListedCompletions {
+		fmt.Fprintf(e.out, "%d candidates, type more characters\r\n", len(candidates))
+		return
+	}
+	fmt.Fprint(e.out, strings.Join(candidates, "  "), "\r\n")
+}
+
+// replaceWord replaces the word ending at the cursor with text
+func (e *lineEditor) replaceWord(word, text string) {
+	start := e.pos - len([]rune(word))
+	e.line = append(e.line[:start:start], e.line[e.pos:]...)
+	e.pos = start
+	e.insert(text)
+}
+
+// commonPrefix returns the longest prefix shared by all values
+func commonPrefix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// completeFrom returns the sorted, de-duplicated values starting with word,
+// ignoring case
+func completeFrom(word string, values []string) []string {
+	word = strings.ToLower(word)
+	seen := make(map[string]bool)
+	var matches []string
+	for _, value := range values {
+		if strings.HasPrefix(strings.ToLower(value), word) && !seen[value] {
+			seen[value] = true
+			matches = append(matches, value)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// terminal switches a terminal between line-buffered and unbuffered input using
+// stty, keeping the tool free of platform-specific system calls
+type terminal struct {
+	file  *os.File
+	saved string
+}
+
+// openTerminal returns a terminal for f, or nil when f is not a terminal
+func openTerminal(f *os.File) *terminal {
+	t := &terminal{file: f}
+	saved, err := t.stty("-g")
+	if err != nil {
+		return nil
+	}
+	t.saved = strings.TrimSpace(saved)
+	return t
+}
+
+// raw disables line buffering, echo and signal keys
+func (t *terminal) raw() error {
+	_, err := t.stty("-icanon", "-echo", "-isig", "min", "1", "time", "0")
+	return err
+}
+
+// restore returns the terminal to the mode it had when opened
+func (t *terminal) restore() {
+	t.stty(t.saved)
+}
+
+// stty runs stty against the terminal
+func (t *terminal) stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = t.file
+	output, err := cmd.Output()
+	return string(output), err
+}
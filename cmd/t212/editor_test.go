@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// newTestEditor creates an editor that reads the given keystrokes
+func newTestEditor(keys string, history ...string) *lineEditor {
+	return &lineEditor{
+		in:      bufio.NewReader(strings.NewReader(keys)),
+		out:     &bytes.Buffer{},
+		history: history,
+	}
+}
+
+// TestLineEditorKeys tests cursor movement, editing and history navigation
+func TestLineEditorKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys string
+		want string
+	}{
+		{name: "typing", keys: "cash\r", want: "cash"},
+		{name: "backspace", keys: "cassh\x7f\x7fh\r", want: "cash"},
+		{name: "insert after cursor left", keys: "csh\x1b[D\x1b[Da\r", want: "cash"},
+		{name: "home and end", keys: "ash\x01c\x05!\r", want: "cash!"},
+		{name: "kill line", keys: "portfolio\x15cash\r", want: "cash"},
+		{name: "history up", keys: "\x1b[A\x1b[A\r", want: "orders list"},
+		{name: "history down restores draft", keys: "ca\x1b[A\x1b[B\r", want: "ca"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEditor(tt.keys, "orders list", "portfolio")
+			got, err := e.edit("> ")
+			if err != nil || got != tt.want {
+				t.Errorf("edit() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := newTestEditor("\x04").edit("> "); !errors.Is(err, io.EOF) {
+		t.Errorf("Ctrl-D on empty line error = %v, want EOF", err)
+	}
+	if _, err := newTestEditor("cash\x03").edit("> "); !errors.Is(err, errInterrupted) {
+		t.Errorf("Ctrl-C error = %v, want interrupted", err)
+	}
+}
+
+// TestLineEditorComplete tests tab completion of single and shared prefixes
+func TestLineEditorComplete(t *testing.T) {
+	complete := func(line string) (string, []string) {
+		words := strings.Fields(line)
+		word := words[len(words)-1]
+		return word, completeFrom(word, []string{"AAPL_US_EQ", "AAPLl_EQ", "MSFT_US_EQ"})
+	}
+
+	e := newTestEditor("position ms\t\r")
+	e.complete = complete
+	if got, _ := e.edit("> "); got != "position MSFT_US_EQ " {
+		t.Errorf("single completion = %q", got)
+	}
+
+	e = newTestEditor("position a\t\t\r")
+	e.complete = complete
+	got, _ := e.edit("> ")
+	if got != "position AAPL" {
+		t.Errorf("prefix completion = %q, want %q", got, "position AAPL")
+	}
+	if out := e.out.(*bytes.Buffer).String(); !strings.Contains(out, "AAPL_US_EQ  AAPLl_EQ") {
+		t.Errorf("Expected candidates to be listed, got %q", out)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/classify"
+	"github.com/0xnu/trading212/fx"
+)
+
+// breakdowns select the exposures named by exposure --by
+var breakdowns = map[string]func(*classify.ExposureReport) []classify.Exposure{
+	"position":    func(r *classify.ExposureReport) []classify.Exposure { return r.Positions },
+	"sector":      func(r *classify.ExposureReport) []classify.Exposure { return r.Sectors },
+	"asset-class": func(r *classify.ExposureReport) []classify.Exposure { return r.AssetClasses },
+	"region":      func(r *classify.ExposureReport) []classify.Exposure { return r.Regions },
+	"currency":    func(r *classify.ExposureReport) []classify.Exposure { return r.Currencies },
+}
+
+// runExposure breaks the open positions down by position, sector, asset
+// class, region or currency, warning about concentrations
+func runExposure(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("exposure")
+	by := fs.String("by", "sector", "breakdown: position, sector, asset-class, region or currency")
+	mappingPath := fs.String("mapping", "", "JSON file classifying tickers and ISINs (default from the profile)")
+	ratesPath := fs.String("rates", "", "CSV of exchange rates to value positions from their prices")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	breakdown, exists := breakdowns[*by]
+	if !exists {
+		return nil, usageError{fmt.Sprintf("unknown breakdown %q, expected position, sector, asset-class, region or currency", *by)}
+	}
+
+	var mapping *classify.Mapping
+	if *mappingPath == "" {
+		*mappingPath = a.classification
+	}
+	if *mappingPath != "" {
+		loaded, err := classify.LoadMapping(*mappingPath)
+		if err != nil {
+			return nil, err
+		}
+		mapping = loaded
+	}
+	rates, err := loadRates(*ratesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	account, err := client.AccountInfo()
+	if err != nil {
+		return nil, err
+	}
+	positions, err := client.Portfolio()
+	if err != nil {
+		return nil, err
+	}
+	cash, err := client.Cash()
+	if err != nil {
+		return nil, err
+	}
+	instruments, err := a.instruments()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]trading212.Decimal, len(positions))
+	if rates != nil {
+		valuation, err := fx.ValuePortfolio(positions, instruments, account.CurrencyCode, nil, rates, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		for _, holding := range valuation.Holdings {
+			values[holding.Ticker] = holding.Value
+		}
+	} else {
+		for _, position := range positions {
+			values[position.Ticker] = position.Value
+		}
+	}
+
+	report := classify.Exposures(values, cash.Free, account.CurrencyCode, classify.NewClassifier(mapping, instruments), classify.DefaultLimits)
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(a.stderr, "warning: %s\n", warning)
+	}
+
+	return breakdown(report), nil
+}
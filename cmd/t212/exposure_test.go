@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunExposure tests the sector breakdown of positions with a mapping
+func TestRunExposure(t *testing.T) {
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/equity/account/info":
+			writeJSON(w, `{"currencyCode":"GBP","id":1}`)
+		case "/api/v0/equity/portfolio":
+			writeJSON(w, `[{"ticker":"AAPL_US_EQ","quantity":2,"value":300},{"ticker":"VODl_EQ","quantity":100,"value":100}]`)
+		case "/api/v0/equity/account/cash":
+			writeJSON(w, `{"free":100,"total":500}`)
+		case "/api/v0/equity/metadata/instruments":
+			writeJSON(w, `[{"ticker":"AAPL_US_EQ","type":"STOCK","currencyCode":"USD"},{"ticker":"VODl_EQ","type":"STOCK","currencyCode":"GBX"}]`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	mapping := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(mapping, []byte(`{"tickers":{"AAPL_US_EQ":{"sector":"Technology"},"VODl_EQ":{"sector":"Telecoms"}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := a.run([]string{"--output", "json", "exposure", "--mapping", mapping}); code != 0 {
+		t.Fatalf("run(exposure) = %d, stderr %s", code, stderr)
+	}
+
+	var sectors []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &sectors); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(sectors) != 3 || sectors[0]["name"] != "Technology" || sectors[0]["weight"] != 0.6 {
+		t.Errorf("sectors = %v", sectors)
+	}
+	if !strings.Contains(stderr.String(), "sector Technology is 60.0%") {
+		t.Errorf("stderr = %q, want concentration warning", stderr)
+	}
+
+}
+
+// TestRunExposureUnknownBreakdown tests that an unknown breakdown is rejected
+// before any API call
+func TestRunExposureUnknownBreakdown(t *testing.T) {
+	a, _, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	})
+
+	if code := a.run([]string{"exposure", "--by", "industry"}); code != 2 {
+		t.Errorf("run(exposure --by industry) = %d, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), `unknown breakdown "industry"`) {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
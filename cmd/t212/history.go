@@ -0,0 +1,235 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/0xnu/trading212/corporate"
+)
+
+// historyFlags holds the pagination flags shared by history commands
+type historyFlags struct {
+	ticker  *string
+	limit   *int
+	cursor  *int
+	actions *string
+}
+
+// newHistoryFlags registers history pagination flags on fs
+func newHistoryFlags(fs *flag.FlagSet, withTicker bool) *historyFlags {
+	h := &historyFlags{
+		limit:  fs.Int("limit", 50, "page size (maximum 50)"),
+		cursor: fs.Int("cursor", 0, "pagination cursor"),
+	}
+	ticker, actions := "", ""
+	h.ticker, h.actions = &ticker, &actions
+	if withTicker {
+		h.ticker = fs.String("ticker", "", "only include this ticker")
+		h.actions = newActionsFlag(fs)
+	}
+	return h
+}
+
+// newActionsFlag registers the corporate actions file flag on fs
+func newActionsFlag(fs *flag.FlagSet) *string {
+	return fs.String("actions", "", "JSON or CSV file of splits and renames to adjust history for (default from the profile)")
+}
+
+// loadActions reads corporate actions from path, or from the profile's file
+// when path is empty, returning nil when there is neither
+func (a *app) loadActions(path string) (corporate.Actions, error) {
+	if path == "" {
+		path = a.actions
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return corporate.Load(path)
+}
+
+// runHistoryOrders lists historical orders
+func runHistoryOrders(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("history orders")
+	flags := newHistoryFlags(fs, true)
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	actions, err := a.loadActions(*flags.actions)
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	orders, err := client.HistoricalOrders(*flags.cursor, *flags.ticker, *flags.limit)
+	if err != nil {
+		return nil, err
+	}
+	return actions.AdjustOrders(orders), nil
+}
+
+// runHistoryDividends lists paid dividends
+func runHistoryDividends(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("history dividends")
+	flags := newHistoryFlags(fs, true)
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	actions, err := a.loadActions(*flags.actions)
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	dividends, err := client.DividendHistory(*flags.cursor, *flags.ticker, *flags.limit)
+	if err != nil {
+		return nil, err
+	}
+	return actions.AdjustDividends(dividends), nil
+}
+
+// runHistoryTransactions lists account transactions
+func runHistoryTransactions(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("history transactions")
+	flags := newHistoryFlags(fs, false)
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.TransactionHistory(*flags.cursor, *flags.limit)
+}
+
+// runExportRequest requests a CSV export of account history
+func runExportRequest(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("export request")
+	from := fs.String("from", "", "start date (YYYY-MM-DD)")
+	to := fs.String("to", time.Now().UTC().Format("2006-01-02"), "end date (YYYY-MM-DD)")
+	dividends := fs.Bool("dividends", true, "include dividends")
+	interest := fs.Bool("interest", true, "include interest")
+	orders := fs.Bool("orders", true, "include orders")
+	transactions := fs.Bool("transactions", true, "include transactions")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	if *from == "" {
+		return nil, usageError{"export request requires --from"}
+	}
+	timeFrom, err := parseDate("from", *from)
+	if err != nil {
+		return nil, err
+	}
+	timeTo, err := parseDate("to", *to)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.ExportCSV(timeFrom, timeTo, *dividends, *interest, *orders, *transactions)
+}
+
+// runExportList lists CSV exports
+func runExportList(a *app, args []string) (interface{}, error) {
+	if _, err := parseArgs(a.newFlagSet("export list"), args, 0); err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.Export()
+}
+
+// runExportDownload downloads a finished CSV export to a file or stdout
+func runExportDownload(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("export download")
+	output := fs.String("output", "", "file to write, defaults to stdout")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	exports, err := client.Export()
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := findDownloadLink(exports, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if *output == "" {
+		_, err := a.download(link, a.stdout)
+		return nil, err
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	written, err := a.download(link, file)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"id": id, "file": *output, "bytes": written}, nil
+}
+
+// findDownloadLink returns the download link of a finished export
+func findDownloadLink(exports []interface{}, id int) (string, error) {
+	for _, item := range exports {
+		export, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		reportID, _ := export["reportId"].(float64)
+		if int(reportID) != id {
+			continue
+		}
+
+		link, _ := export["downloadLink"].(string)
+		if link == "" {
+			return "", fmt.Errorf("export %d is not ready (status %v)", id, export["status"])
+		}
+		return link, nil
+	}
+	return "", fmt.Errorf("export %d not found", id)
+}
+
+// download copies the contents of a pre-signed export link to w
+func (a *app) download(link string, w io.Writer) (int64, error) {
+	resp, err := a.httpClient.Get(link)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+	return io.Copy(w, resp.Body)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFindDownloadLink tests locating finished exports
+func TestFindDownloadLink(t *testing.T) {
+	exports := []interface{}{
+		map[string]interface{}{"reportId": 1.0, "status": "Processing"},
+		map[string]interface{}{"reportId": 2.0, "status": "Finished", "downloadLink": "https://example.com/2.csv"},
+	}
+
+	if link, err := findDownloadLink(exports, 2); err != nil || link != "https://example.com/2.csv" {
+		t.Errorf("findDownloadLink(2) = %q, %v", link, err)
+	}
+	if _, err := findDownloadLink(exports, 1); err == nil {
+		t.Error("Expected error for unfinished export")
+	}
+	if _, err := findDownloadLink(exports, 3); err == nil {
+		t.Error("Expected error for missing export")
+	}
+}
+
+// TestRunExportDownload tests downloading an export to a file
+func TestRunExportDownload(t *testing.T) {
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Action,Time\nMarket buy,2025-01-01\n"))
+	}))
+	defer storage.Close()
+
+	a, _, _ := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `[{"reportId":7,"status":"Finished","downloadLink":"`+storage.URL+`/7.csv"}]`)
+	})
+
+	output := filepath.Join(t.TempDir(), "export.csv")
+	if code := a.run([]string{"export", "download", "7", "--output", output}); code != 0 {
+		t.Fatalf("run(export download) = %d, want 0", code)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Action,Time\nMarket buy,2025-01-01\n" {
+		t.Errorf("downloaded %q", data)
+	}
+}
+
+// TestRunHistoryOrdersActions tests adjusting orders for a split
+func TestRunHistoryOrdersActions(t *testing.T) {
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"items":[{"id":1,"ticker":"NVDA_US_EQ","status":"FILLED","filledQuantity":2,"fillPrice":1200,"dateExecuted":"2024-06-01T14:00:00Z"}]}`)
+	})
+
+	actions := filepath.Join(t.TempDir(), "actions.csv")
+	if err := os.WriteFile(actions, []byte("date,type,ticker,ratio\n2024-06-10,split,NVDA_US_EQ,10:1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := a.run([]string{"--output", "csv", "history", "orders", "--actions", actions}); code != 0 {
+		t.Fatalf("run(history orders) = %d, stderr %s", code, stderr)
+	}
+	if !strings.Contains(stdout.String(), "NVDA_US_EQ") || !strings.Contains(stdout.String(), ",20,") || !strings.Contains(stdout.String(), ",120,") {
+		t.Errorf("stdout = %q", stdout)
+	}
+}
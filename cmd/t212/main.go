@@ -0,0 +1,255 @@
+// Command t212 is a command-line client for the Trading212 API.
+//
+// Usage:
+//
+//	t212 [--profile NAME] [--live] [--output table|json|ndjson|csv] [--currency CODE] <command> [arguments]
+//
+// Profiles are read from ~/.config/t212/config.json (or T212_CONFIG) and
+// selected with --profile, T212_PROFILE or the file's defaultProfile. Each
+// profile names its environment, credential source, default output format and
+// risk limits. Without a profile, credentials are read from TRADING212_API_KEY
+// and TRADING212_API_SECRET and commands run against the demo environment
+// unless --live is given.
+//
+// "t212 shell" starts an interactive session over the same commands, with tab
+// completion, history and variables holding previous results.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/format"
+)
+
+// app holds the state shared by every command
+type app struct {
+	stdin          io.Reader
+	stdout         io.Writer
+	stderr         io.Writer
+	live           bool
+	output         format.Format
+	currency       string
+	actions        string
+	classification string
+	snapshots      string
+	journal        string
+	config         *config
+	profileName    string
+	limits         riskLimits
+	credentials    trading212.CredentialProvider
+	client         *trading212.Client
+	newClient      func(provider trading212.CredentialProvider, live bool) (*trading212.Client, error)
+	httpClient     *http.Client
+	cacheDir       string
+	dataDir        string
+
+	instrumentList []trading212.Instrument
+}
+
+// usageError reports a command invoked with invalid arguments
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string {
+	return e.message
+}
+
+// newApp creates an app writing to the given streams
+func newApp(stdout, stderr io.Writer) *app {
+	return &app{
+		stdin:       os.Stdin,
+		stdout:      stdout,
+		stderr:      stderr,
+		output:      format.Table,
+		config:      &config{Profiles: map[string]*profile{}},
+		credentials: trading212.EnvCredentials{},
+		newClient:   newProviderClient,
+		httpClient:  &http.Client{Timeout: 5 * time.Minute},
+		cacheDir:    defaultCacheDir(),
+		dataDir:     defaultDataDir(),
+	}
+}
+
+// newProviderClient creates a client from a credential provider
+func newProviderClient(provider trading212.CredentialProvider, live bool) (*trading212.Client, error) {
+	return trading212.NewClientFromProvider(provider, !live)
+}
+
+// api returns the API client, creating it on first use
+func (a *app) api() (*trading212.Client, error) {
+	if a.client != nil {
+		return a.client, nil
+	}
+
+	client, err := a.newClient(a.credentials, a.live)
+	if err != nil {
+		return nil, err
+	}
+	a.client = client
+	return client, nil
+}
+
+// run parses global flags and executes a command, returning the exit code
+func (a *app) run(args []string) int {
+	fs := flag.NewFlagSet("t212", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	configPath := fs.String("config", "", "configuration file (default ~/.config/t212/config.json)")
+	profileName := fs.String("profile", "", "configuration profile to use")
+	fs.BoolVar(&a.live, "live", false, "use the live environment instead of demo")
+	output := fs.String("output", string(format.Table), "output format: table, json, ndjson or csv")
+	fs.StringVar(&a.currency, "currency", "", "account currency used to format money in tables, e.g. GBP")
+	fs.Usage = func() { a.printUsage() }
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	if err := a.applyProfile(*configPath, *profileName, explicit, output); err != nil {
+		return a.fail(err)
+	}
+
+	var err error
+	if a.output, err = format.Parse(*output); err != nil {
+		return a.fail(usageError{err.Error()})
+	}
+
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		a.printUsage()
+		return 0
+	}
+	if fs.NArg() == 1 && fs.Arg(0) == "shell" {
+		return a.runShell()
+	}
+
+	result, err := a.execute(fs.Args())
+	if err != nil {
+		return a.fail(err)
+	}
+
+	if err := a.print(result); err != nil {
+		return a.fail(err)
+	}
+	return 0
+}
+
+// applyProfile loads the configuration and applies the selected profile. Flags
+// given on the command line take precedence over profile settings.
+func (a *app) applyProfile(configPath, profileName string, explicit map[string]bool, output *string) error {
+	path := configPath
+	if path == "" {
+		path = defaultConfigPath()
+	}
+
+	cfg, err := loadConfig(path, configPath != "")
+	if err != nil {
+		return err
+	}
+	a.config = cfg
+
+	name, p, err := cfg.profile(profileName)
+	if err != nil || p == nil {
+		return err
+	}
+
+	a.profileName = name
+	a.limits = p.Limits
+	if a.credentials, err = p.Credentials.provider(); err != nil {
+		return err
+	}
+	if !explicit["live"] {
+		a.live = p.Environment == "live"
+	}
+	if !explicit["output"] && p.Output != "" {
+		*output = p.Output
+	}
+	if !explicit["currency"] {
+		a.currency = p.Currency
+	}
+	a.actions = p.Actions
+	a.classification = p.Classification
+	a.snapshots = p.Snapshots
+	a.journal = p.Journal
+	return nil
+}
+
+// environment returns the name of the active environment
+func (a *app) environment() string {
+	if a.live {
+		return "live"
+	}
+	return "demo"
+}
+
+// announce prints the active profile and environment before a mutating command
+func (a *app) announce() {
+	name := a.profileName
+	if name == "" {
+		name = "(none)"
+	}
+	fmt.Fprintf(a.stderr, "profile: %s, environment: %s\n", name, strings.ToUpper(a.environment()))
+}
+
+// lineReader is input that can be read a line at a time without losing what
+// follows, such as the shell's line editor
+type lineReader interface {
+	ReadString(delim byte) (string, error)
+}
+
+// confirm asks a yes/no question on the terminal and returns an error unless
+// the answer is yes. Only the answer's line is consumed, so a script piped to
+// the shell carries on with the next command.
+func (a *app) confirm(question string) error {
+	fmt.Fprintf(a.stderr, "%s [y/N] ", question)
+
+	in, ok := a.stdin.(lineReader)
+	if !ok {
+		buffered := bufio.NewReader(a.stdin)
+		a.stdin, in = buffered, buffered
+	}
+	answer, err := in.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(a.stderr)
+		return errors.New("aborted: no confirmation given, use --yes to skip")
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errors.New("aborted")
+	}
+}
+
+// fail reports an error and returns the matching exit code
+func (a *app) fail(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+
+	fmt.Fprintf(a.stderr, "t212: %v\n", err)
+
+	var usage usageError
+	if errors.As(err, &usage) {
+		return 2
+	}
+	return 1
+}
+
+func main() {
+	os.Exit(newApp(os.Stdout, os.Stderr).run(os.Args[1:]))
+}
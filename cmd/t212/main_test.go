@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xnu/trading212"
+)
+
+// newTestApp creates an app whose client talks to a mock server
+func newTestApp(t *testing.T, handler http.HandlerFunc) (*app, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	t.Setenv("T212_CONFIG", filepath.Join(t.TempDir(), "missing.json"))
+	t.Setenv("T212_PROFILE", "")
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	var stdout, stderr bytes.Buffer
+	a := newApp(&stdout, &stderr)
+	a.cacheDir = t.TempDir()
+	a.dataDir = t.TempDir()
+	a.newClient = func(provider trading212.CredentialProvider, live bool) (*trading212.Client, error) {
+		client := trading212.NewClient("test-api-key", !live)
+		client.SetBaseURL(server.URL)
+		return client, nil
+	}
+	return a, &stdout, &stderr
+}
+
+// writeJSON writes a JSON body for mock handlers
+func writeJSON(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(body))
+}
+
+// TestRunHelp tests the usage output
+func TestRunHelp(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := newApp(&stdout, &stderr).run([]string{"help"})
+
+	if code != 0 {
+		t.Errorf("run(help) = %d, want 0", code)
+	}
+	for _, want := range []string{"Usage: t212", "orders cancel", "instruments search"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("Usage missing %q", want)
+		}
+	}
+}
+
+// TestRunUnknownCommand tests that unknown commands are usage errors
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := newApp(&stdout, &stderr).run([]string{"frobnicate"})
+
+	if code != 2 {
+		t.Errorf("run(frobnicate) = %d, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "unknown command") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+// TestRunCash tests a read-only command end to end
+func TestRunCash(t *testing.T) {
+	a, stdout, _ := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/equity/account/cash" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		writeJSON(w, `{"free":12.5,"total":100}`)
+	})
+
+	if code := a.run([]string{"--output", "json", "cash"}); code != 0 {
+		t.Fatalf("run(cash) = %d, want 0", code)
+	}
+	if !strings.Contains(stdout.String(), `"free": 12.5`) {
+		t.Errorf("stdout = %s", stdout.String())
+	}
+}
+
+// TestRunOutputFormats tests selecting the output format with a flag
+func TestRunOutputFormats(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `[{"ticker":"AAPL","quantity":2,"value":1234.5}]`)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--currency", "GBP", "portfolio"}, want: "£1,234.50"},
+		{args: []string{"--output", "csv", "portfolio"}, want: "AAPL,2,1234.5"},
+		{args: []string{"--output", "ndjson", "portfolio"}, want: `{"ticker":"AAPL","quantity":2,"value":1234.5}`},
+	}
+
+	for _, tt := range tests {
+		a, stdout, _ := newTestApp(t, handler)
+		if code := a.run(tt.args); code != 0 {
+			t.Fatalf("run(%v) = %d, want 0", tt.args, code)
+		}
+		if !strings.Contains(stdout.String(), tt.want) {
+			t.Errorf("run(%v) stdout = %q, want %q", tt.args, stdout.String(), tt.want)
+		}
+	}
+
+	a, _, _ := newTestApp(t, handler)
+	if code := a.run([]string{"--output", "yaml", "portfolio"}); code != 2 {
+		t.Errorf("run(--output yaml) = %d, want 2", code)
+	}
+}
+
+// TestRunAPIError tests that API failures exit with status 1
+func TestRunAPIError(t *testing.T) {
+	a, _, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	if code := a.run([]string{"account"}); code != 1 {
+		t.Errorf("run(account) = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "API error 401") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+// newTestClientRecorder wraps a client factory and records the requested environment
+func newTestClientRecorder(next func(trading212.CredentialProvider, bool) (*trading212.Client, error), live *bool) func(trading212.CredentialProvider, bool) (*trading212.Client, error) {
+	return func(provider trading212.CredentialProvider, isLive bool) (*trading212.Client, error) {
+		*live = isLive
+		return next(provider, isLive)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/format"
+)
+
+// runOrdersList lists pending orders
+func runOrdersList(a *app, args []string) (interface{}, error) {
+	if _, err := parseArgs(a.newFlagSet("orders list"), args, 0); err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.EquityOrders()
+}
+
+// runOrdersGet shows a single pending order
+func runOrdersGet(a *app, args []string) (interface{}, error) {
+	positional, err := parseArgs(a.newFlagSet("orders get"), args, 1)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.EquityOrder(id)
+}
+
+// runOrdersCancel cancels a pending order
+func runOrdersCancel(a *app, args []string) (interface{}, error) {
+	positional, err := parseArgs(a.newFlagSet("orders cancel"), args, 1)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	if err := client.EquityOrderCancel(id); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"id": id, "status": "CANCELLED"}, nil
+}
+
+// orderArgs holds the parsed arguments of an order command
+type orderArgs struct {
+	ticker       string
+	quantity     int
+	orderType    string
+	limitPrice   trading212.Decimal
+	stopPrice    trading212.Decimal
+	timeValidity string
+	yes          bool
+	dryRun       bool
+}
+
+// orderTypes maps CLI order type names to API order types
+var orderTypes = map[string]string{
+	"market":     trading212.OrderTypeMarket,
+	"limit":      trading212.OrderTypeLimit,
+	"stop":       trading212.OrderTypeStop,
+	"stop-limit": trading212.OrderTypeStopLimit,
+}
+
+// runOrder returns the command placing an order on the given side. The order is
+// previewed and confirmed on the terminal before it is submitted.
+func runOrder(side string) func(a *app, args []string) (interface{}, error) {
+	return func(a *app, args []string) (interface{}, error) {
+		order, err := parseOrderArgs(a, side, args)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.limits.check(order.quantity, trading212.Decimal{}); err != nil {
+			return nil, err
+		}
+		client, err := a.api()
+		if err != nil {
+			return nil, err
+		}
+
+		request := order.request()
+		preview, err := client.OrderPreview(request)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.checkPreviewLimits(preview); err != nil {
+			return nil, err
+		}
+		if order.dryRun {
+			return preview, nil
+		}
+
+		writePreview(a.stderr, preview)
+		if !order.yes {
+			if err := a.confirm("Submit order?"); err != nil {
+				return nil, err
+			}
+		}
+		return client.PlaceOrder(request)
+	}
+}
+
+// parseOrderArgs parses and validates an order command. Sell orders are sent
+// with a negative quantity, as the API expects.
+func parseOrderArgs(a *app, side string, args []string) (orderArgs, error) {
+	fs := a.newFlagSet("order " + side)
+	orderType := fs.String("type", "market", "order type: limit, market, stop or stop-limit")
+	var limitPrice, stopPrice trading212.Decimal
+	fs.TextVar(&limitPrice, "limit", trading212.Decimal{}, "limit price")
+	fs.TextVar(&stopPrice, "stop", trading212.Decimal{}, "stop price")
+	timeValidity := fs.String("validity", "DAY", "time validity: DAY or GTC")
+	yes := fs.Bool("yes", false, "submit without asking for confirmation")
+	dryRun := fs.Bool("dry-run", false, "print the order preview without submitting")
+
+	positional, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return orderArgs{}, err
+	}
+
+	quantity, err := strconv.Atoi(positional[1])
+	if err != nil || quantity <= 0 {
+		return orderArgs{}, usageError{fmt.Sprintf("invalid quantity %q", positional[1])}
+	}
+	if side == "sell" {
+		quantity = -quantity
+	}
+
+	order := orderArgs{
+		ticker:       positional[0],
+		quantity:     quantity,
+		orderType:    strings.ToLower(*orderType),
+		limitPrice:   limitPrice,
+		stopPrice:    stopPrice,
+		timeValidity: strings.ToUpper(*timeValidity),
+		yes:          *yes,
+		dryRun:       *dryRun,
+	}
+	return order, order.validate()
+}
+
+// validate checks that the prices required by the order type are present
+func (o orderArgs) validate() error {
+	needsLimit := o.orderType == "limit" || o.orderType == "stop-limit"
+	needsStop := o.orderType == "stop" || o.orderType == "stop-limit"
+
+	switch {
+	case orderTypes[o.orderType] == "":
+		return usageError{fmt.Sprintf("unknown order type %q", o.orderType)}
+	case needsLimit && o.limitPrice.Sign() <= 0:
+		return usageError{fmt.Sprintf("%s orders require --limit", o.orderType)}
+	case needsStop && o.stopPrice.Sign() <= 0:
+		return usageError{fmt.Sprintf("%s orders require --stop", o.orderType)}
+	}
+	return nil
+}
+
+// request converts the arguments into a library order request
+func (o orderArgs) request() trading212.OrderRequest {
+	return trading212.OrderRequest{
+		Ticker:       o.ticker,
+		Quantity:     o.quantity,
+		Type:         orderTypes[o.orderType],
+		LimitPrice:   o.limitPrice,
+		StopPrice:    o.stopPrice,
+		TimeValidity: o.timeValidity,
+	}
+}
+
+// checkPreviewLimits enforces the profile's value limit using the previewed
+// price in the account currency. Orders that cannot be valued are refused when
+// a value limit is configured.
+func (a *app) checkPreviewLimits(preview *trading212.OrderPreview) error {
+	if !preview.Estimated {
+		if a.limits.MaxOrderValue.Sign() > 0 {
+			return fmt.Errorf("cannot check order value against profile limit: %s", strings.Join(preview.Warnings, "; "))
+		}
+		return nil
+	}
+	return a.limits.check(preview.Quantity, preview.EstimatedPrice.Mul(preview.FXRate))
+}
+
+// writePreview prints an order preview for confirmation
+func writePreview(w io.Writer, p *trading212.OrderPreview) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Instrument:\t%s (%s)\n", p.Name, p.Ticker)
+	fmt.Fprintf(tw, "Order:\t%s %d %s\n", p.Side, p.Quantity, p.Type)
+	if p.LimitPrice.Sign() > 0 {
+		fmt.Fprintf(tw, "Limit price:\t%s\n", format.FormatDecimalMoney(p.LimitPrice, p.InstrumentCurrency))
+	}
+	if p.StopPrice.Sign() > 0 {
+		fmt.Fprintf(tw, "Stop price:\t%s\n", format.FormatDecimalMoney(p.StopPrice, p.InstrumentCurrency))
+	}
+	if p.TimeValidity != "" {
+		fmt.Fprintf(tw, "Validity:\t%s\n", p.TimeValidity)
+	}
+	if p.Estimated {
+		fmt.Fprintf(tw, "Estimated value:\t%s\n", format.FormatDecimalMoney(p.EstimatedValue, p.AccountCurrency))
+	} else {
+		fmt.Fprintf(tw, "Estimated value:\tunknown\n")
+	}
+	fmt.Fprintf(tw, "Position:\t%g -> %g\n", p.PositionQuantity, p.PositionAfter)
+	fmt.Fprintf(tw, "Free cash:\t%s -> %s\n", format.FormatDecimalMoney(p.FreeCash, p.AccountCurrency), format.FormatDecimalMoney(p.FreeCashAfter, p.AccountCurrency))
+	for _, warning := range p.Warnings {
+		fmt.Fprintf(tw, "Warning:\t%s\n", warning)
+	}
+	tw.Flush()
+}
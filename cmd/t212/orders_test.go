@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/0xnu/trading212"
+)
+
+// TestParseOrderArgs tests order argument validation
+func TestParseOrderArgs(t *testing.T) {
+	a := newApp(&bytes.Buffer{}, &bytes.Buffer{})
+
+	tests := []struct {
+		name    string
+		side    string
+		args    []string
+		want    int
+		wantErr bool
+	}{
+		{name: "market buy", side: "buy", args: []string{"AAPL", "3"}, want: 3},
+		{name: "limit sell", side: "sell", args: []string{"AAPL", "2", "--type", "limit", "--limit", "150"}, want: -2},
+		{name: "limit without price", side: "buy", args: []string{"AAPL", "1", "--type", "limit"}, wantErr: true},
+		{name: "stop-limit without stop", side: "buy", args: []string{"AAPL", "1", "--type", "stop-limit", "--limit", "10"}, wantErr: true},
+		{name: "unknown type", side: "buy", args: []string{"AAPL", "1", "--type", "trailing"}, wantErr: true},
+		{name: "invalid quantity", side: "buy", args: []string{"AAPL", "zero"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := parseOrderArgs(a, tt.side, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOrderArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && order.quantity != tt.want {
+				t.Errorf("quantity = %d, want %d", order.quantity, tt.want)
+			}
+		})
+	}
+}
+
+// previewHandler serves the read-only endpoints used by order previews and
+// passes other requests to next
+func previewHandler(t *testing.T, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/equity/metadata/instruments":
+			writeJSON(w, `[{"ticker":"AAPL_US_EQ","name":"Apple","currencyCode":"USD"},{"ticker":"VUSAl_EQ","name":"Vanguard S&P 500","currencyCode":"GBP"}]`)
+		case "/api/v0/equity/account/info":
+			writeJSON(w, `{"currencyCode":"GBP"}`)
+		case "/api/v0/equity/account/cash":
+			writeJSON(w, `{"free":1000}`)
+		case "/api/v0/equity/portfolio":
+			writeJSON(w, `[{"ticker":"VUSAl_EQ","quantity":5,"currentPrice":80}]`)
+		default:
+			next(w, r)
+		}
+	}
+}
+
+// TestRunOrderSellLimit tests that a sell limit order is submitted with a negative quantity
+func TestRunOrderSellLimit(t *testing.T) {
+	a, _, stderr := newTestApp(t, previewHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v0/equity/orders/limit" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body["quantity"] != -2.0 || body["limitPrice"] != 150.0 || body["timeValidity"] != "GTC" {
+			t.Errorf("unexpected order body %v", body)
+		}
+		writeJSON(w, `{"id":1,"ticker":"AAPL_US_EQ","quantity":-2}`)
+	}))
+
+	code := a.run([]string{"order", "sell", "AAPL_US_EQ", "2", "--type", "limit", "--limit", "150", "--validity", "gtc", "--yes"})
+	if code != 0 {
+		t.Errorf("run(order sell) = %d, want 0: %s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "SELL 2 LIMIT") {
+		t.Errorf("Expected order preview on stderr, got %q", stderr.String())
+	}
+}
+
+// TestRunOrderConfirm tests that orders are only submitted after confirmation
+func TestRunOrderConfirm(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantCode   int
+		wantSubmit bool
+	}{
+		{name: "confirmed", input: "y\n", wantCode: 0, wantSubmit: true},
+		{name: "declined", input: "n\n", wantCode: 1},
+		{name: "no input", input: "", wantCode: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submitted := false
+			a, stdout, stderr := newTestApp(t, previewHandler(t, func(w http.ResponseWriter, r *http.Request) {
+				submitted = r.Method == "POST"
+				writeJSON(w, `{"id":7,"ticker":"VUSAl_EQ","quantity":3}`)
+			}))
+			a.stdin = strings.NewReader(tt.input)
+
+			code := a.run([]string{"--output", "json", "order", "buy", "VUSAl_EQ", "3"})
+			if code != tt.wantCode || submitted != tt.wantSubmit {
+				t.Errorf("run(order buy) = %d, submitted %v, want %d, %v", code, submitted, tt.wantCode, tt.wantSubmit)
+			}
+			for _, want := range []string{"Vanguard S&P 500", "£240.00", "£1,000.00 -> £760.00", "5 -> 8", "Submit order? [y/N]"} {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("preview missing %q:\n%s", want, stderr.String())
+				}
+			}
+			if tt.wantSubmit && !strings.Contains(stdout.String(), `"id": 7`) {
+				t.Errorf("stdout = %q", stdout.String())
+			}
+		})
+	}
+}
+
+// TestRunOrderDryRun tests that --dry-run prints the preview without submitting
+func TestRunOrderDryRun(t *testing.T) {
+	a, stdout, _ := newTestApp(t, previewHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+
+	if code := a.run([]string{"--output", "json", "order", "sell", "VUSAl_EQ", "1", "--dry-run"}); code != 0 {
+		t.Fatalf("run(order sell --dry-run) = %d, want 0", code)
+	}
+	if !strings.Contains(stdout.String(), `"freeCashAfter": 1080`) {
+		t.Errorf("stdout = %s", stdout.String())
+	}
+}
+
+// TestRunOrderValueLimit tests that profile value limits use the previewed value
+func TestRunOrderValueLimit(t *testing.T) {
+	a, _, stderr := newTestApp(t, previewHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	a.limits = riskLimits{MaxOrderValue: trading212.NewDecimalFromInt(200)}
+
+	if code := a.run([]string{"order", "buy", "VUSAl_EQ", "3", "--yes"}); code != 1 || !strings.Contains(stderr.String(), "exceeds profile limit") {
+		t.Errorf("run(market buy) = %d, stderr %q, want value limit error", code, stderr.String())
+	}
+
+	stderr.Reset()
+	if code := a.run([]string{"order", "buy", "AAPL_US_EQ", "1", "--yes"}); code != 1 || !strings.Contains(stderr.String(), "cannot check order value") {
+		t.Errorf("run(unpriced buy) = %d, stderr %q, want refusal", code, stderr.String())
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// sharesFlag collects repeated --share TICKER=WEIGHT flags
+type sharesFlag map[string]trading212.Decimal
+
+func (s sharesFlag) String() string {
+	parts := make([]string, 0, len(s))
+	for ticker, weight := range s {
+		parts = append(parts, ticker+"="+weight.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s sharesFlag) Set(value string) error {
+	ticker, weight, found := strings.Cut(value, "=")
+	if !found {
+		return fmt.Errorf("expected TICKER=WEIGHT, got %q", value)
+	}
+	share, err := trading212.ParseDecimal(weight)
+	if err != nil {
+		return fmt.Errorf("invalid weight in %q", value)
+	}
+	s[ticker] = share
+	return nil
+}
+
+// pieFlags holds the flags shared by pies create and pies update
+type pieFlags struct {
+	name           *string
+	icon           *string
+	goal           *int
+	endDate        *string
+	dividendAction *string
+	shares         sharesFlag
+}
+
+// newPieFlags registers the pie flags on fs
+func newPieFlags(fs *flag.FlagSet) *pieFlags {
+	p := &pieFlags{
+		name:           fs.String("name", "", "pie name"),
+		icon:           fs.String("icon", "", "pie icon, e.g. Home or Tech"),
+		goal:           fs.Int("goal", 0, "pie goal in account currency"),
+		endDate:        fs.String("end-date", "", "pie end date (YYYY-MM-DD)"),
+		dividendAction: fs.String("dividend-action", "", "REINVEST or TO_ACCOUNT_CASH"),
+		shares:         sharesFlag{},
+	}
+	fs.Var(p.shares, "share", "instrument weight as TICKER=WEIGHT (repeatable)")
+	return p
+}
+
+// parseDate parses a YYYY-MM-DD date flag
+func parseDate(name, value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, usageError{fmt.Sprintf("invalid --%s %q, expected YYYY-MM-DD", name, value)}
+	}
+	return date, nil
+}
+
+// runPiesList lists pies
+func runPiesList(a *app, args []string) (interface{}, error) {
+	if _, err := parseArgs(a.newFlagSet("pies list"), args, 0); err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.Pies()
+}
+
+// runPiesGet shows a single pie
+func runPiesGet(a *app, args []string) (interface{}, error) {
+	positional, err := parseArgs(a.newFlagSet("pies get"), args, 1)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.Pie(id)
+}
+
+// runPiesCreate creates a pie
+func runPiesCreate(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("pies create")
+	flags := newPieFlags(fs)
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	if *flags.name == "" || *flags.icon == "" || *flags.endDate == "" {
+		return nil, usageError{"pies create requires --name, --icon and --end-date"}
+	}
+	endDate, err := parseDate("end-date", *flags.endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	dividendAction := *flags.dividendAction
+	if dividendAction == "" {
+		dividendAction = "REINVEST"
+	}
+
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	return client.PieCreate(dividendAction, endDate, *flags.goal, *flags.icon, *flags.name, flags.shares)
+}
+
+// runPiesUpdate updates a pie, keeping current values for flags that are not given
+func runPiesUpdate(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("pies update")
+	flags := newPieFlags(fs)
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	pie, err := client.Pie(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if *flags.name != "" {
+		pie.Name = *flags.name
+	}
+	if *flags.icon != "" {
+		pie.Icon = *flags.icon
+	}
+	if *flags.goal != 0 {
+		pie.Goal = *flags.goal
+	}
+	if *flags.dividendAction != "" {
+		pie.DividendCashAction = *flags.dividendAction
+	}
+	if len(flags.shares) > 0 {
+		pie.InstrumentShares = flags.shares
+	}
+	if *flags.endDate != "" {
+		endDate, err := parseDate("end-date", *flags.endDate)
+		if err != nil {
+			return nil, err
+		}
+		pie.EndDate = endDate.Format("2006-01-02T15:04:05Z")
+	}
+
+	return client.PieUpdate(id, pie.DividendCashAction, pie.EndDate, pie.Goal, pie.Icon, pie.Name, pie.InstrumentShares)
+}
+
+// runPiesDelete deletes a pie
+func runPiesDelete(a *app, args []string) (interface{}, error) {
+	positional, err := parseArgs(a.newFlagSet("pies delete"), args, 1)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+	if err := client.PieDelete(id); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"id": id, "status": "DELETED"}, nil
+}
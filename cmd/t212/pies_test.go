@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// TestSharesFlag tests parsing of repeated --share flags
+func TestSharesFlag(t *testing.T) {
+	shares := sharesFlag{}
+	if err := shares.Set("AAPL_US_EQ=0.6"); err != nil {
+		t.Fatal(err)
+	}
+	if err := shares.Set("MSFT_US_EQ=0.4"); err != nil {
+		t.Fatal(err)
+	}
+	if shares["AAPL_US_EQ"].String() != "0.6" || shares["MSFT_US_EQ"].String() != "0.4" {
+		t.Errorf("shares = %v", shares)
+	}
+
+	for _, value := range []string{"AAPL", "AAPL=abc"} {
+		if err := shares.Set(value); err == nil {
+			t.Errorf("Set(%q) expected error", value)
+		}
+	}
+}
+
+// TestRunPiesUpdate tests that unspecified fields keep their current values
+func TestRunPiesUpdate(t *testing.T) {
+	a, _, _ := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			writeJSON(w, `{"id":3,"name":"Old","icon":"Home","goal":500,"endDate":"2030-01-01T00:00:00Z","dividendCashAction":"REINVEST","instrumentShares":{"AAPL_US_EQ":1}}`)
+			return
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body["name"] != "New" || body["icon"] != "Home" || body["goal"] != 500.0 {
+			t.Errorf("unexpected update body %v", body)
+		}
+		writeJSON(w, `{"id":3,"name":"New"}`)
+	})
+
+	if code := a.run([]string{"pies", "update", "3", "--name", "New"}); code != 0 {
+		t.Errorf("run(pies update) = %d, want 0", code)
+	}
+}
+
+// TestRunPiesCreateRequiresFlags tests create argument validation
+func TestRunPiesCreateRequiresFlags(t *testing.T) {
+	a, _, _ := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected API call")
+	})
+
+	if code := a.run([]string{"pies", "create", "--name", "Tech"}); code != 2 {
+		t.Errorf("run(pies create) = %d, want 2", code)
+	}
+}
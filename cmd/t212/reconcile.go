@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/reconcile"
+)
+
+// runReconcile compares the orders in a bot's order journal, the order
+// history endpoint, the synced history journal and a CSV export, listing
+// missing, duplicated and mismatched records with a summary per source on
+// stderr
+func runReconcile(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("reconcile")
+	journalPath := fs.String("journal", "", "order journal written by a bot to compare, as JSON Lines or CSV")
+	historyDir := fs.String("history", "", "synced history journal directory to compare")
+	exportPath := fs.String("export", "", "CSV export to compare")
+	noAPI := fs.Bool("no-api", false, "compare the other sources without fetching the order history")
+	timeTolerance := fs.Duration("time-tolerance", reconcile.DefaultTolerance.Time, "largest difference between matching timestamps")
+	quantityTolerance := fs.String("quantity-tolerance", reconcile.DefaultTolerance.Quantity.String(), "largest difference between matching quantities")
+	priceTolerance := fs.Float64("price-tolerance", reconcile.DefaultTolerance.Price*100, "largest difference between matching prices, in percent")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	tolerance := reconcile.Tolerance{Time: *timeTolerance, Price: *priceTolerance / 100}
+	var err error
+	if tolerance.Quantity, err = trading212.ParseDecimal(*quantityTolerance); err != nil {
+		return nil, usageError{fmt.Sprintf("invalid --quantity-tolerance %q", *quantityTolerance)}
+	}
+	if tolerance.Time < 0 || tolerance.Quantity.Sign() < 0 || tolerance.Price < 0 {
+		return nil, usageError{"tolerances must not be negative"}
+	}
+
+	// Instruments give API tickers the ISINs that exports are matched by
+	var instruments []trading212.Instrument
+	if !*noAPI || *exportPath != "" {
+		if instruments, err = a.instruments(); err != nil {
+			return nil, err
+		}
+	}
+
+	var sources []reconcile.Source
+	if !*noAPI {
+		client, err := a.api()
+		if err != nil {
+			return nil, err
+		}
+		orders, err := client.HistoricalOrders(0, "", 50)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, reconcile.Source{Name: "api", Records: reconcile.RecordsFromOrders("api", orders, instruments)})
+	}
+
+	if *historyDir != "" {
+		j, err := a.syncedJournal(*historyDir)
+		if err != nil {
+			return nil, err
+		}
+		orders, err := j.Orders()
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, reconcile.Source{Name: "history", Records: reconcile.RecordsFromOrders("history", orders, instruments)})
+	}
+
+	if *journalPath != "" {
+		records, err := reconcile.LoadRecords(*journalPath, "journal", instruments)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, reconcile.Source{Name: "journal", Records: records})
+	}
+
+	if *exportPath != "" {
+		rows, err := loadExport(*exportPath)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, reconcile.Source{Name: "export", Records: reconcile.RecordsFromExport("export", rows)})
+	}
+
+	if len(sources) < 2 {
+		return nil, usageError{"at least two of the order history, the synced history, an order journal and an export are needed"}
+	}
+
+	report := reconcile.Reconcile(sources[0], sources[1:], tolerance)
+	fmt.Fprintf(a.stderr, "%s: %d records, %d duplicates\n", report.Sources[0].Source, report.Sources[0].Records, report.Sources[0].Duplicates)
+	for _, summary := range report.Sources[1:] {
+		fmt.Fprintf(a.stderr, "%s: %d records, %d matched, %d missing, %d extra, %d duplicates, %d mismatched\n",
+			summary.Source, summary.Records, summary.Matched, summary.Missing, summary.Extra, summary.Duplicates, summary.Mismatched)
+	}
+	return report.Issues, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunReconcile tests comparing the order history with a bot's order
+// journal, the synced history and an export
+func TestRunReconcile(t *testing.T) {
+	orders := `{"items":[` +
+		`{"id":2,"ticker":"MSFT_US_EQ","status":"FILLED","filledQuantity":1,"fillPrice":400,"dateCreated":"2024-01-03T10:00:00Z","dateExecuted":"2024-01-03T10:00:00Z"},` +
+		`{"id":1,"ticker":"AAPL_US_EQ","status":"FILLED","filledQuantity":2,"fillPrice":150,"dateCreated":"2024-01-02T10:00:00Z","dateExecuted":"2024-01-02T10:00:00Z"}]}`
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/equity/history/orders":
+			writeJSON(w, orders)
+		case "/api/v0/equity/metadata/instruments":
+			writeJSON(w, `[{"ticker":"AAPL_US_EQ","isin":"US0378331005"},{"ticker":"MSFT_US_EQ","isin":"US5949181045"}]`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	history := filepath.Join(t.TempDir(), "history")
+	if code := a.run([]string{"sync", "--only", "orders", "--journal", history}); code != 0 {
+		t.Fatalf("run(sync) = %d, stderr %s", code, stderr)
+	}
+
+	// The bot recorded the MSFT order at a different price
+	orderJournal := filepath.Join(t.TempDir(), "orders.jsonl")
+	records := `{"id":"1","ticker":"AAPL_US_EQ","time":"2024-01-02T10:00:00Z","quantity":2,"price":150}` + "\n" +
+		`{"id":"2","ticker":"MSFT_US_EQ","time":"2024-01-03T10:00:00Z","quantity":1,"price":410}` + "\n"
+	if err := os.WriteFile(orderJournal, []byte(records), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	export := filepath.Join(t.TempDir(), "export.csv")
+	csv := "Action,Time,ISIN,Ticker,Name,No. of shares,Price / share,Currency (Price / share),Exchange rate,Total,Currency (Total),ID\n" +
+		"Market buy,2024-01-02 10:00:20,US0378331005,AAPL,Apple,2,150,USD,1,300,USD,EOF1\n"
+	if err := os.WriteFile(export, []byte(csv), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := a.run([]string{"--output", "json", "reconcile", "--journal", orderJournal, "--history", history, "--export", export}); code != 0 {
+		t.Fatalf("run(reconcile) = %d, stderr %s", code, stderr)
+	}
+	var issues []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(issues) != 2 || issues[0]["kind"] != "mismatch" || issues[0]["source"] != "journal" || issues[1]["kind"] != "missing" || issues[1]["source"] != "export" {
+		t.Errorf("issues = %v, want the MSFT_US_EQ price mismatched in the journal and missing from the export", issues)
+	}
+	for _, line := range []string{"history: 2 records, 2 matched", "journal: 2 records, 1 matched, 0 missing, 0 extra, 0 duplicates, 1 mismatched", "export: 1 records, 1 matched, 1 missing"} {
+		if !strings.Contains(stderr.String(), line) {
+			t.Errorf("stderr = %q, want %q", stderr, line)
+		}
+	}
+
+	for _, flag := range []string{"--journal", "--history"} {
+		if code := a.run([]string{"reconcile", "--no-api", "--export", export, flag, filepath.Join(t.TempDir(), "missing")}); code == 0 {
+			t.Errorf("Expected a missing %s to fail", flag)
+		}
+	}
+	if code := a.run([]string{"reconcile", "--no-api", "--journal", orderJournal}); code != 2 {
+		t.Errorf("run(reconcile --no-api) = %d, want 2 with only the order journal", code)
+	}
+
+	// Without the order history, the bot's journal is checked against the export
+	stdout.Reset()
+	if code := a.run([]string{"--output", "json", "reconcile", "--no-api", "--journal", orderJournal, "--export", export}); code != 0 {
+		t.Fatalf("run(reconcile --no-api) = %d, stderr %s", code, stderr)
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil || len(issues) != 1 || issues[0]["source"] != "export" {
+		t.Errorf("issues = %v, %v, want MSFT_US_EQ missing from the export", issues, err)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/0xnu/trading212/analytics"
+	"github.com/0xnu/trading212/costbasis"
+	"github.com/0xnu/trading212/journal"
+	"github.com/0xnu/trading212/replay"
+)
+
+// runHistoryAt rebuilds the holdings and cash at a past date from the
+// journal, or from the history endpoints when no full journal has been synced
+func runHistoryAt(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("history at")
+	dir := newJournalFlag(fs)
+	pricesPath := fs.String("prices", "", "CSV of date, ticker and price to value holdings")
+	ratesPath := fs.String("rates", "", "CSV of exchange rates for trades and prices in other currencies")
+	method := fs.String("method", string(costbasis.FIFO), "cost basis method: fifo, lifo or average")
+	actionsPath := newActionsFlag(fs)
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	at, err := parseMoment(positional[0])
+	if err != nil {
+		return nil, err
+	}
+	options := replay.Options{Currency: a.currency}
+	if options.Method, err = costbasis.ParseMethod(*method); err != nil {
+		return nil, usageError{err.Error()}
+	}
+	if options.Rates, err = loadRates(*ratesPath); err != nil {
+		return nil, err
+	}
+	if options.Prices, err = loadPrices(*pricesPath); err != nil {
+		return nil, err
+	}
+
+	var history replay.History
+	if history.Actions, err = a.loadActions(*actionsPath); err != nil {
+		return nil, err
+	}
+	if err := a.loadHistory(*dir, &history); err != nil {
+		return nil, err
+	}
+	if history.Instruments, err = a.instruments(); err != nil {
+		return nil, err
+	}
+	if options.Currency == "" {
+		client, err := a.api()
+		if err != nil {
+			return nil, err
+		}
+		account, err := client.AccountInfo()
+		if err != nil {
+			return nil, err
+		}
+		options.Currency = account.CurrencyCode
+	}
+
+	portfolio, err := replay.At(history, at, options)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range portfolio.Warnings {
+		fmt.Fprintf(a.stderr, "warning: %s\n", warning)
+	}
+	fmt.Fprintf(a.stderr, "%s: cash %s, holdings %s, total %s %s\n", at.Format(time.RFC3339),
+		portfolio.Cash.StringFixed(2), portfolio.Value.StringFixed(2), portfolio.Total.StringFixed(2), portfolio.Currency)
+	return portfolio.Holdings, nil
+}
+
+// loadHistory reads orders, dividends and transactions from the journal
+// when it holds their whole history, and from the API otherwise
+func (a *app) loadHistory(dir string, history *replay.History) error {
+	j, err := a.historyJournal(dir, journal.Orders, journal.Dividends, journal.Transactions)
+	if err != nil {
+		return err
+	}
+	if j != nil {
+		if history.Orders, err = j.Orders(); err != nil {
+			return err
+		}
+		if history.Dividends, err = j.Dividends(); err != nil {
+			return err
+		}
+		history.Transactions, err = j.Transactions()
+		return err
+	}
+
+	client, err := a.api()
+	if err != nil {
+		return err
+	}
+	if history.Orders, err = client.HistoricalOrders(0, "", 50); err != nil {
+		return err
+	}
+	if history.Dividends, err = client.DividendHistory(0, "", 50); err != nil {
+		return err
+	}
+	history.Transactions, err = client.TransactionHistory(0, 50)
+	return err
+}
+
+// loadPrices reads a CSV of prices by ticker, returning nil when no file is
+// given
+func loadPrices(path string) (map[string][]analytics.Point, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return analytics.LoadPricesCSV(file)
+}
+
+// parseMoment parses a date, meaning the end of that day, or an RFC 3339
+// timestamp
+func parseMoment(value string) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, usageError{fmt.Sprintf("invalid date %q, expected YYYY-MM-DD or an RFC 3339 time", value)}
+	}
+	return date.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
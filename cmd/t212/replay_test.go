@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunHistoryAt tests rebuilding holdings at a past date from the history
+// endpoints
+func TestRunHistoryAt(t *testing.T) {
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/equity/history/orders":
+			writeJSON(w, `{"items":[`+
+				`{"id":2,"ticker":"VOD_EQ","status":"FILLED","filledQuantity":-4,"fillPrice":12,"dateExecuted":"2024-02-01T10:00:00Z"},`+
+				`{"id":1,"ticker":"VOD_EQ","status":"FILLED","filledQuantity":10,"fillPrice":10,"dateExecuted":"2024-01-02T10:00:00Z"}]}`)
+		case "/api/v0/history/dividends":
+			writeJSON(w, `{"items":[]}`)
+		case "/api/v0/history/transactions":
+			writeJSON(w, `{"items":[{"type":"DEPOSIT","amount":500,"dateTime":"2024-01-01T09:00:00Z"}]}`)
+		case "/api/v0/equity/metadata/instruments":
+			writeJSON(w, `[{"ticker":"VOD_EQ","currencyCode":"GBP"}]`)
+		case "/api/v0/equity/account/info":
+			writeJSON(w, `{"currencyCode":"GBP","id":1}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	prices := filepath.Join(t.TempDir(), "prices.csv")
+	if err := os.WriteFile(prices, []byte("date,ticker,price\n2024-01-15,VOD_EQ,11\n2024-02-15,VOD_EQ,13\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := a.run([]string{"--output", "json", "history", "at", "2024-01-31", "--prices", prices}); code != 0 {
+		t.Fatalf("run(history at) = %d, stderr %s", code, stderr)
+	}
+	var holdings []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &holdings); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(holdings) != 1 || holdings[0]["quantity"] != 10.0 || holdings[0]["value"] != 110.0 {
+		t.Errorf("holdings = %v, want 10 VOD_EQ worth 110", holdings)
+	}
+	if !strings.Contains(stderr.String(), "cash 400.00, holdings 110.00, total 510.00 GBP") {
+		t.Errorf("stderr = %q, want the cash and total", stderr)
+	}
+
+	// A journal without the whole history is not used in place of the API
+	if code := a.run([]string{"sync", "--only", "orders"}); code != 0 {
+		t.Fatalf("run(sync) = %d, stderr %s", code, stderr)
+	}
+	stderr.Reset()
+	if code := a.run([]string{"history", "at", "2024-01-31", "--prices", prices}); code != 0 {
+		t.Fatalf("run(history at) = %d, stderr %s", code, stderr)
+	}
+	if !strings.Contains(stderr.String(), "does not hold the whole dividends history, using the API") || !strings.Contains(stderr.String(), "total 510.00 GBP") {
+		t.Errorf("stderr = %q, want the API used with a warning", stderr)
+	}
+	partial := filepath.Join(t.TempDir(), "journal")
+	if code := a.run([]string{"sync", "--only", "orders,dividends", "--journal", partial}); code != 0 {
+		t.Fatalf("run(sync) = %d, stderr %s", code, stderr)
+	}
+	if code := a.run([]string{"history", "at", "2024-01-31", "--journal", partial}); code != 1 {
+		t.Errorf("run(history at --journal) = %d, want 1 for a journal without transactions", code)
+	}
+
+	// Once every endpoint is synced the journal is used
+	if code := a.run([]string{"sync"}); code != 0 {
+		t.Fatalf("run(sync) = %d, stderr %s", code, stderr)
+	}
+	stderr.Reset()
+	if code := a.run([]string{"history", "at", "2024-01-31", "--prices", prices}); code != 0 {
+		t.Fatalf("run(history at) = %d, stderr %s", code, stderr)
+	}
+	if strings.Contains(stderr.String(), "warning") || !strings.Contains(stderr.String(), "total 510.00 GBP") {
+		t.Errorf("stderr = %q, want the journal used", stderr)
+	}
+
+	if code := a.run([]string{"history", "at", "last month"}); code != 2 {
+		t.Errorf("run(history at 'last month') = %d, want 2", code)
+	}
+	if code := a.run([]string{"history", "at", "2024-01-31", "--method", "hifo"}); code != 2 {
+		t.Errorf("run(history at --method hifo) = %d, want 2", code)
+	}
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/0xnu/trading212/format"
+)
+
+// errQuit ends the shell
+var errQuit = errors.New("quit")
+
+// shellBuiltins lists the commands handled by the shell itself
+var shellBuiltins = []struct {
+	name    string
+	summary string
+}{
+	{"vars", "List variables holding previous results"},
+	{"history", "List previous input lines"},
+	{"output FORMAT", "Switch the output format: table, json, ndjson or csv"},
+	{"help", "Show this help"},
+	{"exit", "Leave the shell"},
+}
+
+// maxHistory is the number of lines kept in the history file
+const maxHistory = 1000
+
+// shell is an interactive session over the command layer. Every result is kept
+// in a numbered variable, $1, $2 and so on, with $_ holding the latest one.
+type shell struct {
+	a      *app
+	editor *lineEditor
+	vars   map[string]interface{}
+	count  int
+}
+
+// runShell starts an interactive shell and returns the exit code
+func (a *app) runShell() int {
+	s := &shell{
+		a:      a,
+		editor: newLineEditor(a.stdin, a.stdout),
+		vars:   make(map[string]interface{}),
+	}
+	s.editor.complete = s.complete
+	a.stdin = s.editor
+	s.loadHistory()
+
+	if s.editor.terminal != nil {
+		fmt.Fprintln(a.stdout, "Type 'help' for commands, Tab to complete and 'exit' to leave.")
+	}
+
+	for {
+		line, err := s.editor.readLine(s.prompt())
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return a.fail(err)
+		}
+
+		s.editor.addHistory(line)
+		if err := s.exec(line); err != nil {
+			if errors.Is(err, errQuit) {
+				break
+			}
+			fmt.Fprintf(a.stderr, "error: %v\n", err)
+		}
+	}
+
+	s.saveHistory()
+	return 0
+}
+
+// prompt shows the active profile and environment
+func (s *shell) prompt() string {
+	if s.a.profileName != "" {
+		return fmt.Sprintf("t212 %s (%s)> ", s.a.profileName, s.a.environment())
+	}
+	return fmt.Sprintf("t212 (%s)> ", s.a.environment())
+}
+
+// exec runs one line of input. "NAME = COMMAND" also stores the result in $NAME
+// and a line holding only a variable reference prints it.
+func (s *shell) exec(line string) error {
+	words, err := splitWords(line)
+	if err != nil || len(words) == 0 {
+		return err
+	}
+
+	name := ""
+	if len(words) >= 3 && words[1] == "=" {
+		if !isIdentifier(words[0]) {
+			return fmt.Errorf("invalid variable name %q", words[0])
+		}
+		name, words = words[0], words[2:]
+	}
+
+	if name == "" {
+		switch words[0] {
+		case "exit", "quit":
+			return errQuit
+		case "help":
+			s.help()
+			return nil
+		case "history":
+			for i, entry := range s.editor.history {
+				fmt.Fprintf(s.a.stdout, "%5d  %s\n", i+1, entry)
+			}
+			return nil
+		case "vars":
+			return s.a.print(s.summaries())
+		case "output":
+			if len(words) != 2 {
+				return fmt.Errorf("usage: output table|json|ndjson|csv")
+			}
+			output, err := format.Parse(words[1])
+			if err != nil {
+				return err
+			}
+			s.a.output = output
+			return nil
+		}
+	}
+
+	if words[0] == "shell" {
+		return fmt.Errorf("already in the shell")
+	}
+
+	var result interface{}
+	if len(words) == 1 && strings.HasPrefix(words[0], "$") {
+		result, err = s.lookup(words[0])
+	} else {
+		result, err = s.run(words)
+	}
+	if err != nil || result == nil {
+		return err
+	}
+
+	ref := s.store(name, result)
+	if err := s.a.print(result); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.a.stderr, "(%s)\n", ref)
+	return nil
+}
+
+// run substitutes variables into the arguments and executes a command
+func (s *shell) run(words []string) (interface{}, error) {
+	args := make([]string, len(words))
+	for i, word := range words {
+		if !strings.HasPrefix(word, "$") || i == 0 {
+			args[i] = word
+			continue
+		}
+		value, err := s.scalar(word)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	return s.a.execute(args)
+}
+
+// store saves a result in the next numbered variable, in $_ and optionally in
+// a named variable, and returns the reference to show the user
+func (s *shell) store(name string, result interface{}) string {
+	s.count++
+	ref := "$" + strconv.Itoa(s.count)
+	s.vars[strconv.Itoa(s.count)] = result
+	s.vars["_"] = result
+	if name != "" {
+		s.vars[name] = result
+		ref += ", $" + name
+	}
+	return ref
+}
+
+// lookup resolves a reference such as $2, $_, $orders[0].ticker or $cash.free
+func (s *shell) lookup(ref string) (interface{}, error) {
+	name, path := splitReference(strings.TrimPrefix(ref, "$"))
+	value, exists := s.vars[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown variable $%s", name)
+	}
+	if path == "" {
+		return value, nil
+	}
+
+	current, err := generic(value)
+	if err != nil {
+		return nil, err
+	}
+	for path != "" {
+		var segment string
+		switch path[0] {
+		case '.':
+			end := strings.IndexAny(path[1:], ".[")
+			if end < 0 {
+				end = len(path) - 1
+			}
+			segment, path = path[1:end+1], path[end+1:]
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: cannot select field %q", ref, segment)
+			}
+			if current, ok = object[segment]; !ok {
+				return nil, fmt.Errorf("%s: no field %q", ref, segment)
+			}
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%s: missing ]", ref)
+			}
+			segment, path = path[1:end], path[end+1:]
+			index, err := strconv.Atoi(segment)
+			list, ok := current.([]interface{})
+			if err != nil || !ok || index < 0 || index >= len(list) {
+				return nil, fmt.Errorf("%s: invalid index [%s]", ref, segment)
+			}
+			current = list[index]
+		default:
+			return nil, fmt.Errorf("invalid reference %s", ref)
+		}
+	}
+	return current, nil
+}
+
+// scalar resolves a reference that must hold a single value, for use as a
+// command argument
+func (s *shell) scalar(ref string) (string, error) {
+	value, err := s.lookup(ref)
+	if err != nil {
+		return "", err
+	}
+	if value, err = generic(value); err != nil {
+		return "", err
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("%s is not a single value, select a field such as %s.id or %s[0]", ref, ref, ref)
+	}
+}
+
+// variableSummary describes a variable for the vars builtin
+type variableSummary struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Items int    `json:"items"`
+}
+
+// summaries describes the variables, numbered ones first
+func (s *shell) summaries() []variableSummary {
+	names := make([]string, 0, len(s.vars))
+	for name := range s.vars {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, errA := strconv.Atoi(names[i])
+		b, errB := strconv.Atoi(names[j])
+		switch {
+		case errA == nil && errB == nil:
+			return a < b
+		case errA == nil || errB == nil:
+			return errA == nil
+		default:
+			return names[i] < names[j]
+		}
+	})
+
+	summaries := make([]variableSummary, 0, len(names))
+	for _, name := range names {
+		value := reflect.ValueOf(s.vars[name])
+		items := 1
+		if value.Kind() == reflect.Slice {
+			items = value.Len()
+		}
+		summaries = append(summaries, variableSummary{Name: "$" + name, Type: value.Type().String(), Items: items})
+	}
+	return summaries
+}
+
+// help lists commands and shell builtins
+func (s *shell) help() {
+	s.a.printUsage()
+	fmt.Fprintln(s.a.stderr)
+	fmt.Fprintln(s.a.stderr, "Shell:")
+	for _, builtin := range shellBuiltins {
+		fmt.Fprintf(s.a.stderr, "  %-15s%s\n", builtin.name, builtin.summary)
+	}
+	fmt.Fprintln(s.a.stderr, "  NAME = COMMAND  Run a command and store its result in $NAME")
+	fmt.Fprintln(s.a.stderr)
+	fmt.Fprintln(s.a.stderr, "Results are stored in $1, $2, ... and $_. Select values with $1[0].ticker or $cash.free")
+	fmt.Fprintln(s.a.stderr, "and pass them as arguments, e.g. orders cancel $_[0].id")
+}
+
+// complete returns the word before the cursor and its completions: command
+// names, then variables, then tickers from the instrument cache
+func (s *shell) complete(line string) (string, []string) {
+	words := strings.Fields(line)
+	word := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		word, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	if strings.HasPrefix(word, "$") {
+		var names []string
+		for name := range s.vars {
+			names = append(names, "$"+name)
+		}
+		return word, completeFrom(word, names)
+	}
+
+	var names []string
+	for _, cmd := range commands {
+		parts := strings.Fields(cmd.path)
+		if len(parts) > len(words) && strings.Join(parts[:len(words)], " ") == strings.Join(words, " ") {
+			names = append(names, parts[len(words)])
+		}
+	}
+	if len(words) == 0 {
+		for _, builtin := range shellBuiltins {
+			names = append(names, strings.Fields(builtin.name)[0])
+		}
+	}
+	if candidates := completeFrom(word, names); len(candidates) > 0 || len(words) == 0 {
+		return word, candidates
+	}
+
+	if word == "" || strings.HasPrefix(word, "-") {
+		return word, nil
+	}
+	instruments, err := s.a.instruments()
+	if err != nil {
+		return word, nil
+	}
+	tickers := make([]string, len(instruments))
+	for i, instrument := range instruments {
+		tickers[i] = instrument.Ticker
+	}
+	return word, completeFrom(word, tickers)
+}
+
+// loadHistory reads previous input lines from the history file
+func (s *shell) loadHistory() {
+	path := s.a.cachePath("history")
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		s.editor.addHistory(line)
+	}
+}
+
+// saveHistory writes the most recent input lines to the history file
+func (s *shell) saveHistory() {
+	path := s.a.cachePath("history")
+	if path == "" {
+		return
+	}
+	history := s.editor.history
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	if err := os.MkdirAll(s.a.cacheDir, 0700); err != nil {
+		return
+	}
+	os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0600)
+}
+
+// generic converts a value to its JSON representation of maps, slices, strings,
+// numbers and booleans so that fields can be selected by their JSON names
+func generic(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+// splitReference splits "name.path[0]" into the variable name and the path
+func splitReference(ref string) (string, string) {
+	end := strings.IndexAny(ref, ".[")
+	if end < 0 {
+		return ref, ""
+	}
+	return ref[:end], ref[end:]
+}
+
+// isIdentifier reports whether name can be used as a variable name
+func isIdentifier(name string) bool {
+	if name == "" || name == "_" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// splitWords splits a line into words, honouring single and double quotes and
+// backslash escapes
+func splitWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
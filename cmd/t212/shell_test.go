@@ -0,0 +1,140 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestShellSession tests variables, substitution and builtins over a scripted session
+func TestShellSession(t *testing.T) {
+	var cancelled string
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v0/equity/orders":
+			writeJSON(w, `[{"id":41,"ticker":"AAPL_US_EQ","quantity":1},{"id":42,"ticker":"MSFT_US_EQ","quantity":2}]`)
+		case r.Method == "DELETE":
+			cancelled = r.URL.Path
+		case r.URL.Path == "/api/v0/equity/account/cash":
+			writeJSON(w, `{"free":12.5}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	a.stdin = strings.NewReader(strings.Join([]string{
+		"pending = orders list",
+		"orders cancel $pending[1].id",
+		"output json",
+		"$1[0].ticker",
+		"cash",
+		"$_.free",
+		"orders cancel $1",
+		"vars",
+		"history",
+		"exit",
+	}, "\n"))
+
+	if code := a.run([]string{"shell"}); code != 0 {
+		t.Fatalf("run(shell) = %d, want 0", code)
+	}
+
+	if !strings.HasSuffix(cancelled, "/equity/orders/42") {
+		t.Errorf("cancelled %q, want order 42", cancelled)
+	}
+	for _, want := range []string{`"AAPL_US_EQ"`, "12.5", `"name": "$pending"`, "orders cancel $pending[1].id"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("stdout missing %q:\n%s", want, stdout.String())
+		}
+	}
+	if !strings.Contains(stderr.String(), "$1 is not a single value") {
+		t.Errorf("stderr = %q, want scalar error", stderr.String())
+	}
+
+	history, err := os.ReadFile(filepath.Join(a.cacheDir, "history"))
+	if err != nil || !strings.Contains(string(history), "pending = orders list") {
+		t.Errorf("history file = %q, %v", history, err)
+	}
+}
+
+// TestShellConfirm tests that answering a confirmation leaves the following
+// commands for the shell
+func TestShellConfirm(t *testing.T) {
+	submitted := false
+	a, stdout, stderr := newTestApp(t, previewHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		submitted = r.Method == "POST"
+		writeJSON(w, `{"id":7,"ticker":"VUSAl_EQ","quantity":3}`)
+	}))
+	a.stdin = strings.NewReader("order buy VUSAl_EQ 3\ny\noutput json\n$1.id\nexit\n")
+
+	if code := a.run([]string{"shell"}); code != 0 {
+		t.Fatalf("run(shell) = %d, want 0: %s", code, stderr.String())
+	}
+	if !submitted {
+		t.Error("order was not submitted after confirmation")
+	}
+	if !strings.Contains(stdout.String(), "> 7\n") {
+		t.Errorf("stdout = %q, want the order id after the confirmation", stdout.String())
+	}
+}
+
+// TestShellComplete tests completion of commands, variables and tickers
+func TestShellComplete(t *testing.T) {
+	a, _, _ := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/equity/metadata/instruments" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		writeJSON(w, `[{"ticker":"AAPL_US_EQ"},{"ticker":"AMZN_US_EQ"},{"ticker":"MSFT_US_EQ"}]`)
+	})
+	s := &shell{a: a, vars: map[string]interface{}{"1": nil, "cash": nil}}
+
+	tests := []struct {
+		line string
+		word string
+		want []string
+	}{
+		{line: "or", word: "or", want: []string{"order", "orders"}},
+		{line: "orders ", word: "", want: []string{"cancel", "get", "list"}},
+		{line: "ex", word: "ex", want: []string{"exit", "export", "exposure"}},
+		{line: "position a", word: "a", want: []string{"AAPL_US_EQ", "AMZN_US_EQ"}},
+		{line: "order buy ms", word: "ms", want: []string{"MSFT_US_EQ"}},
+		{line: "orders cancel $c", word: "$c", want: []string{"$cash"}},
+		{line: "order buy AAPL_US_EQ 1 --", word: "--", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			word, candidates := s.complete(tt.line)
+			if word != tt.word || !reflect.DeepEqual(candidates, tt.want) {
+				t.Errorf("complete(%q) = %q, %v, want %q, %v", tt.line, word, candidates, tt.word, tt.want)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(a.cacheDir, "instruments-demo.json")); err != nil {
+		t.Errorf("Expected instrument cache to be written: %v", err)
+	}
+}
+
+// TestSplitWords tests quoting and escaping of shell input
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{line: "pies create --name 'My Pie' --goal 100", want: []string{"pies", "create", "--name", "My Pie", "--goal", "100"}},
+		{line: `a "b c" d\ e ""`, want: []string{"a", "b c", "d e", ""}},
+		{line: "   ", want: nil},
+		{line: "'open", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := splitWords(tt.line)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, %v, want %q", tt.line, got, err, tt.want)
+		}
+	}
+}
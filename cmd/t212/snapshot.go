@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/snapshot"
+)
+
+// defaultDataDir returns the directory for data kept between runs, such as
+// snapshots, or "" when none is available
+func defaultDataDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "t212")
+}
+
+// dataPath returns the path in the data directory of a file or directory
+// kept per profile, or per environment without a profile, or "" when there is
+// no data directory
+func (a *app) dataPath(prefix, suffix string) string {
+	if a.dataDir == "" {
+		return ""
+	}
+	name := a.profileName
+	if name == "" {
+		name = a.environment()
+	}
+	return filepath.Join(a.dataDir, prefix+"-"+name+suffix)
+}
+
+// newStoreFlag registers the snapshot store flag on fs
+func newStoreFlag(fs *flag.FlagSet) *string {
+	return fs.String("store", "", "snapshot file (default from the profile, or one per profile in the data directory)")
+}
+
+// snapshotStore opens the store at path, the profile's store, or a store per
+// profile or environment in the data directory
+func (a *app) snapshotStore(path string) (*snapshot.Store, error) {
+	if path == "" {
+		path = a.snapshots
+	}
+	if path == "" {
+		if path = a.dataPath("snapshots", ".jsonl"); path == "" {
+			return nil, fmt.Errorf("no data directory for snapshots, use --store")
+		}
+	}
+	return snapshot.Open(path), nil
+}
+
+// rangeFlags holds the --from and --to flags of snapshot queries
+type rangeFlags struct {
+	from *string
+	to   *string
+}
+
+// newRangeFlags registers date range flags on fs
+func newRangeFlags(fs *flag.FlagSet) *rangeFlags {
+	return &rangeFlags{
+		from: fs.String("from", "", "first date (YYYY-MM-DD, default the first snapshot)"),
+		to:   fs.String("to", "", "last date (YYYY-MM-DD, default the latest snapshot)"),
+	}
+}
+
+// parse returns the range bounds, with to covering the whole of its day
+func (r *rangeFlags) parse() (from, to time.Time, err error) {
+	if *r.from != "" {
+		if from, err = parseDate("from", *r.from); err != nil {
+			return from, to, err
+		}
+	}
+	if *r.to != "" {
+		if to, err = parseDate("to", *r.to); err != nil {
+			return from, to, err
+		}
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return from, to, nil
+}
+
+// snapshotSummary describes a recorded snapshot
+type snapshotSummary struct {
+	Time      time.Time          `json:"time"`
+	Value     trading212.Decimal `json:"value"`
+	Positions int                `json:"positions"`
+	Pies      int                `json:"pies"`
+}
+
+// runSnapshotRecord fetches the account state and appends it to the store
+func runSnapshotRecord(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("snapshot record")
+	pies := fs.Bool("pies", false, "include pies in the snapshot")
+	path := newStoreFlag(fs)
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	store, err := a.snapshotStore(*path)
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := client.Snapshot(*pies)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Append(*account); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(a.stderr, "recorded snapshot in %s\n", store.Path())
+	return snapshotSummary{Time: account.Time, Value: account.Value(), Positions: len(account.Positions), Pies: len(account.Pies)}, nil
+}
+
+// runSnapshotValues lists the account value at each recorded snapshot
+func runSnapshotValues(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("snapshot values")
+	dates := newRangeFlags(fs)
+	path := newStoreFlag(fs)
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	from, to, err := dates.parse()
+	if err != nil {
+		return nil, err
+	}
+	store, err := a.snapshotStore(*path)
+	if err != nil {
+		return nil, err
+	}
+	return store.Values(from, to)
+}
+
+// runSnapshotPosition lists the holding in one instrument at each snapshot
+func runSnapshotPosition(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("snapshot position")
+	dates := newRangeFlags(fs)
+	path := newStoreFlag(fs)
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := dates.parse()
+	if err != nil {
+		return nil, err
+	}
+	store, err := a.snapshotStore(*path)
+	if err != nil {
+		return nil, err
+	}
+	return store.Positions(positional[0], from, to)
+}
+
+// runSnapshotCash lists the account cash at each snapshot
+func runSnapshotCash(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("snapshot cash")
+	dates := newRangeFlags(fs)
+	path := newStoreFlag(fs)
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	from, to, err := dates.parse()
+	if err != nil {
+		return nil, err
+	}
+	store, err := a.snapshotStore(*path)
+	if err != nil {
+		return nil, err
+	}
+	return store.CashHistory(from, to)
+}
+
+// runSnapshotCompact thins old snapshots according to the retention policy
+func runSnapshotCompact(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("snapshot compact")
+	keepAll := fs.Int("keep-all", int(snapshot.DefaultPolicy.KeepAll.Hours()/24), "days for which every snapshot is kept")
+	daily := fs.Int("daily", int(snapshot.DefaultPolicy.Daily.Hours()/24), "days for which one snapshot a day is kept, after which one a week is kept")
+	maxAge := fs.Int("max-age", 0, "days after which snapshots are deleted (0 keeps them)")
+	path := newStoreFlag(fs)
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	if *keepAll < 0 || *daily < 0 || *maxAge < 0 {
+		return nil, usageError{"retention periods must not be negative"}
+	}
+	store, err := a.snapshotStore(*path)
+	if err != nil {
+		return nil, err
+	}
+
+	day := 24 * time.Hour
+	policy := snapshot.Policy{
+		KeepAll: time.Duration(*keepAll) * day,
+		Daily:   time.Duration(*daily) * day,
+		MaxAge:  time.Duration(*maxAge) * day,
+	}
+	return store.Compact(policy, time.Now())
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunSnapshot tests recording snapshots and querying them
+func TestRunSnapshot(t *testing.T) {
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/equity/account/info":
+			writeJSON(w, `{"currencyCode":"GBP","id":1}`)
+		case "/api/v0/equity/portfolio":
+			writeJSON(w, `[{"ticker":"AAPL_US_EQ","quantity":2,"value":300}]`)
+		case "/api/v0/equity/account/cash":
+			writeJSON(w, `{"free":100,"total":400}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		if code := a.run([]string{"snapshot", "record"}); code != 0 {
+			t.Fatalf("run(snapshot record) = %d, stderr %s", code, stderr)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(a.dataDir, "snapshots-demo.jsonl")); err != nil {
+		t.Fatalf("Expected the snapshot store in the data directory: %v", err)
+	}
+
+	tests := []struct {
+		args  []string
+		field string
+		want  interface{}
+	}{
+		{args: []string{"snapshot", "values"}, field: "value", want: 400.0},
+		{args: []string{"snapshot", "position", "AAPL_US_EQ"}, field: "quantity", want: 2.0},
+		{args: []string{"snapshot", "cash"}, field: "free", want: 100.0},
+	}
+	for _, tt := range tests {
+		stdout.Reset()
+		if code := a.run(append([]string{"--output", "json"}, tt.args...)); code != 0 {
+			t.Fatalf("run(%v) = %d, stderr %s", tt.args, code, stderr)
+		}
+		var rows []map[string]interface{}
+		if err := json.Unmarshal(stdout.Bytes(), &rows); err != nil {
+			t.Fatalf("invalid JSON %q: %v", stdout, err)
+		}
+		if len(rows) != 2 || rows[1][tt.field] != tt.want {
+			t.Errorf("run(%v) = %v, want %s %v", tt.args, rows, tt.field, tt.want)
+		}
+	}
+
+	stdout.Reset()
+	if code := a.run([]string{"--output", "json", "snapshot", "compact", "--keep-all", "0", "--daily", "1"}); code != 0 {
+		t.Fatalf("run(snapshot compact) = %d, stderr %s", code, stderr)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if result["before"] != 2.0 || result["after"] != 1.0 {
+		t.Errorf("compact = %v, want 2 snapshots thinned to 1", result)
+	}
+
+	if code := a.run([]string{"snapshot", "values", "--from", "yesterday"}); code != 2 {
+		t.Errorf("run(snapshot values --from yesterday) = %d, want 2", code)
+	}
+	if code := a.run([]string{"snapshot", "compact", "--daily", "-1"}); code != 2 {
+		t.Errorf("run(snapshot compact --daily -1) = %d, want 2", code)
+	}
+}
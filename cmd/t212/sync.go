@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/0xnu/trading212/journal"
+)
+
+// newJournalFlag registers the journal directory flag on fs
+func newJournalFlag(fs *flag.FlagSet) *string {
+	return fs.String("journal", "", "history journal directory (default from the profile, or one per profile in the data directory)")
+}
+
+// openJournal opens the journal in dir, the profile's journal, or a journal
+// per profile or environment in the data directory
+func (a *app) openJournal(dir string) (*journal.Journal, error) {
+	if dir == "" {
+		dir = a.journal
+	}
+	if dir == "" {
+		if dir = a.dataPath("journal", ""); dir == "" {
+			return nil, fmt.Errorf("no data directory for the journal, use --journal")
+		}
+	}
+	return journal.Open(dir), nil
+}
+
+// syncedJournal returns the journal in dir, or the profile's journal when it
+// has been synced, or nil when neither is available
+func (a *app) syncedJournal(dir string) (*journal.Journal, error) {
+	j, err := a.openJournal(dir)
+	if err != nil {
+		return nil, nil
+	}
+	if _, err := os.Stat(j.Dir()); err != nil {
+		if dir != "" {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return j, nil
+}
+
+// historyJournal returns the synced journal when it holds the whole history
+// of the endpoints, and nil when it does not so that the API is used instead.
+// A journal named by dir that is not full is an error.
+func (a *app) historyJournal(dir string, endpoints ...string) (*journal.Journal, error) {
+	j, err := a.syncedJournal(dir)
+	if err != nil || j == nil {
+		return nil, err
+	}
+	states, err := j.States()
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		if states[endpoint].Full() {
+			continue
+		}
+		if dir != "" {
+			return nil, fmt.Errorf("journal %s does not hold the whole %s history, run sync to complete it", j.Dir(), endpoint)
+		}
+		fmt.Fprintf(a.stderr, "warning: journal %s does not hold the whole %s history, using the API\n", j.Dir(), endpoint)
+		return nil, nil
+	}
+	return j, nil
+}
+
+// runSync brings the local history journal up to date, reporting each page
+// on stderr
+func runSync(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("sync")
+	only := fs.String("only", "", "comma-separated endpoints to sync: orders, dividends or transactions (default all)")
+	dir := newJournalFlag(fs)
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	var endpoints []string
+	if *only != "" {
+		for _, name := range strings.Split(*only, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			switch name {
+			case journal.Orders, journal.Dividends, journal.Transactions:
+				endpoints = append(endpoints, name)
+			default:
+				return nil, usageError{fmt.Sprintf("unknown endpoint %q, expected orders, dividends or transactions", name)}
+			}
+		}
+	}
+
+	j, err := a.openJournal(*dir)
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+
+	return j.Sync(client, journal.Options{
+		Endpoints: endpoints,
+		Progress: func(p journal.Progress) {
+			stage := "new"
+			if p.Backfill {
+				stage = "backfill"
+			}
+			fmt.Fprintf(a.stderr, "%s: page %d (%s), %d fetched, %d added\n", p.Endpoint, p.Pages, stage, p.Fetched, p.Added)
+		},
+	})
+}
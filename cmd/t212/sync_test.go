@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunSync tests syncing history into the journal with progress output
+func TestRunSync(t *testing.T) {
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/equity/history/orders":
+			writeJSON(w, `{"items":[{"id":2,"dateCreated":"2024-01-02T10:00:00Z"},{"id":1,"dateCreated":"2024-01-01T10:00:00Z"}]}`)
+		case "/api/v0/history/transactions":
+			writeJSON(w, `{"items":[{"type":"DEPOSIT","reference":"d-1","amount":100,"dateTime":"2024-01-01T09:00:00Z"}]}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	if code := a.run([]string{"--output", "json", "sync", "--only", "orders,transactions"}); code != 0 {
+		t.Fatalf("run(sync) = %d, stderr %s", code, stderr)
+	}
+	var results []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(results) != 2 || results[0]["added"] != 2.0 || results[1]["records"] != 1.0 {
+		t.Errorf("results = %v", results)
+	}
+	if !strings.Contains(stderr.String(), "orders: page 1 (backfill), 2 fetched, 2 added") {
+		t.Errorf("stderr = %q, want progress", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(a.dataDir, "journal-demo", "orders.jsonl")); err != nil {
+		t.Errorf("Expected the journal in the data directory: %v", err)
+	}
+
+	stdout.Reset()
+	if code := a.run([]string{"--output", "json", "sync", "--only", "orders"}); code != 0 {
+		t.Fatalf("run(sync) = %d, stderr %s", code, stderr)
+	}
+	results = nil
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(results) != 1 || results[0]["added"] != 0.0 || results[0]["records"] != 2.0 {
+		t.Errorf("second sync = %v, want nothing added", results)
+	}
+
+	if code := a.run([]string{"sync", "--only", "pies"}); code != 2 {
+		t.Errorf("run(sync --only pies) = %d, want 2", code)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/fx"
+	"github.com/0xnu/trading212/tax"
+)
+
+// runTaxDividends reports dividend income by tax year from the API or a CSV
+// export
+func runTaxDividends(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("tax dividends")
+	year := fs.String("year", "", "only include this tax year, e.g. 2024/25")
+	exportPath := fs.String("export", "", "read an account history CSV export instead of the API")
+	ratesPath := fs.String("rates", "", "CSV of exchange rates with the header date,from,to,rate")
+	actionsPath := newActionsFlag(fs)
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	var taxYear tax.TaxYear
+	if *year != "" {
+		parsed, err := tax.ParseTaxYear(*year)
+		if err != nil {
+			return nil, usageError{err.Error()}
+		}
+		taxYear = parsed
+	}
+
+	rates, err := loadRates(*ratesPath)
+	if err != nil {
+		return nil, err
+	}
+	actions, err := a.loadActions(*actionsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var payments []tax.DividendPayment
+	var source fx.RateSource = rates
+	var currency string
+	if *exportPath != "" {
+		rows, err := loadExport(*exportPath)
+		if err != nil {
+			return nil, err
+		}
+		payments = tax.DividendsFromExport(rows)
+		source = fx.Sources{rates, fx.ExportRates(rows)}
+		currency = exportCurrency(rows, a.currency)
+	} else {
+		client, err := a.api()
+		if err != nil {
+			return nil, err
+		}
+		account, err := client.AccountInfo()
+		if err != nil {
+			return nil, err
+		}
+		dividends, err := client.DividendHistory(0, "", 50)
+		if err != nil {
+			return nil, err
+		}
+		instruments, err := a.instruments()
+		if err != nil {
+			return nil, err
+		}
+		currency = account.CurrencyCode
+		payments = tax.DividendsFromHistory(actions.AdjustDividends(dividends), instruments, currency)
+		if err := checkRates(payments, currency, source); err != nil {
+			return nil, err
+		}
+	}
+
+	report, err := tax.DividendIncome(payments, currency, source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []tax.DividendLine{}
+	for _, line := range report.Lines {
+		if *year != "" && line.TaxYear != taxYear {
+			continue
+		}
+		lines = append(lines, line)
+		if line.HighWithholding {
+			fmt.Fprintf(a.stderr, "warning: %s %s %s: %s\n", line.TaxYear, line.Ticker, line.Currency, line.Warning)
+		}
+	}
+	return lines, nil
+}
+
+// checkRates fails before any report is built when a dividend is paid in a
+// currency that source cannot convert to the account currency. The API
+// reports only the net amount in the account currency, so the rate cannot be
+// derived without knowing the withholding.
+func checkRates(payments []tax.DividendPayment, currency string, source fx.RateSource) error {
+	for _, payment := range payments {
+		if _, err := fx.Rate(source, payment.Gross.Currency, currency, payment.PaidOn); err != nil {
+			return usageError{fmt.Sprintf("%s pays dividends in %s: %v, use --rates with a CSV of %s/%s rates", payment.Ticker, payment.Gross.Currency, err, payment.Gross.Currency, currency)}
+		}
+	}
+	return nil
+}
+
+// loadExport reads an account history CSV export
+func loadExport(path string) ([]trading212.ExportRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return trading212.ParseExportCSV(file)
+}
+
+// loadRates reads a CSV of exchange rates, returning nil when no file is given
+func loadRates(path string) (fx.RateSource, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return fx.LoadRatesCSV(file)
+}
+
+// exportCurrency returns the account currency of an export, which is the
+// currency of its totals
+func exportCurrency(rows []trading212.ExportRow, fallback string) string {
+	for _, row := range rows {
+		if row.TotalCurrency != "" {
+			return strings.ToUpper(row.TotalCurrency)
+		}
+	}
+	if fallback == "" {
+		return "GBP"
+	}
+	return fallback
+}
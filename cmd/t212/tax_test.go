@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunTaxDividendsExport tests the dividend report from a CSV export
+func TestRunTaxDividendsExport(t *testing.T) {
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected API call")
+	})
+
+	export := filepath.Join(t.TempDir(), "export.csv")
+	data := "Action,Time,Ticker,Name,No. of shares,Price / share,Currency (Price / share),Exchange rate,Total,Currency (Total),Withholding tax,Currency (Withholding tax)\n" +
+		"Market buy,2024-01-02 10:00:00,KO,Coca-Cola,50,60,USD,1.25,2400,GBP,,\n" +
+		"Dividend (Ordinary),2024-04-01 10:00:00,KO,Coca-Cola,50,0.4,USD,,11.20,GBP,6.00,USD\n" +
+		"Dividend (Ordinary),2024-07-01 10:00:00,KO,Coca-Cola,50,0.4,USD,,11.20,GBP,6.00,USD\n"
+	if err := os.WriteFile(export, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := a.run([]string{"--output", "json", "tax", "dividends", "--export", export, "--year", "2024/25"}); code != 0 {
+		t.Fatalf("run(tax dividends) = %d, stderr %s", code, stderr)
+	}
+
+	var lines []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &lines); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(lines) != 1 || lines[0]["taxYear"] != "2024/25" || lines[0]["highWithholding"] != true {
+		t.Errorf("lines = %v", lines)
+	}
+	if !strings.Contains(stderr.String(), "W-8BEN") {
+		t.Errorf("stderr = %q, want withholding warning", stderr)
+	}
+}
+
+// TestRunTaxDividendsAPI tests the dividend report from dividend history
+func TestRunTaxDividendsAPI(t *testing.T) {
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/equity/account/info":
+			writeJSON(w, `{"currencyCode":"GBP","id":1}`)
+		case "/api/v0/history/dividends":
+			writeJSON(w, `{"items":[{"ticker":"VODl_EQ","quantity":100,"amount":5,"grossAmountPerShare":5,"paidOn":"2024-08-02T00:00:00Z"}]}`)
+		case "/api/v0/equity/metadata/instruments":
+			writeJSON(w, `[{"ticker":"VODl_EQ","name":"Vodafone","currencyCode":"GBX"}]`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	if code := a.run([]string{"--output", "csv", "tax", "dividends"}); code != 0 {
+		t.Fatalf("run(tax dividends) = %d, stderr %s", code, stderr)
+	}
+	if !strings.Contains(stdout.String(), "2024/25,VODl_EQ,Vodafone,GBX,1,500.00 GBX,0.00 GBX,5.00 GBP") {
+		t.Errorf("stdout = %q", stdout)
+	}
+}
+
+// TestRunTaxDividendsAPIForeign tests that a dividend in another currency
+// needs exchange rates, which convert it when given
+func TestRunTaxDividendsAPIForeign(t *testing.T) {
+	a, stdout, stderr := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/equity/account/info":
+			writeJSON(w, `{"currencyCode":"GBP","id":1}`)
+		case "/api/v0/history/dividends":
+			writeJSON(w, `{"items":[{"ticker":"AAPL_US_EQ","quantity":10,"amount":3.4,"grossAmountPerShare":0.5,"paidOn":"2024-08-02T00:00:00Z"}]}`)
+		case "/api/v0/equity/metadata/instruments":
+			writeJSON(w, `[{"ticker":"AAPL_US_EQ","name":"Apple","currencyCode":"USD"}]`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	if code := a.run([]string{"tax", "dividends"}); code != 2 || !strings.Contains(stderr.String(), "--rates") {
+		t.Errorf("run(tax dividends) = %d, stderr %q, want a usage error naming --rates", code, stderr)
+	}
+
+	rates := filepath.Join(t.TempDir(), "rates.csv")
+	if err := os.WriteFile(rates, []byte("date,from,to,rate\n2024-08-01,USD,GBP,0.8\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := a.run([]string{"--output", "csv", "tax", "dividends", "--rates", rates}); code != 0 {
+		t.Fatalf("run(tax dividends --rates) = %d, stderr %s", code, stderr)
+	}
+	if !strings.Contains(stdout.String(), "2024/25,AAPL_US_EQ,Apple,USD,1,5.00 USD,0.75 USD,4.00 GBP") {
+		t.Errorf("stdout = %q", stdout)
+	}
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/format"
+)
+
+// ANSI escape sequences used by the dashboard
+const (
+	ansiReset      = "\x1b[0m"
+	ansiBold       = "\x1b[1m"
+	ansiDim        = "\x1b[2m"
+	ansiRed        = "\x1b[31m"
+	ansiGreen      = "\x1b[32m"
+	ansiChanged    = "\x1b[1;33m"
+	ansiClear      = "\x1b[H\x1b[2J"
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+)
+
+// Minimum refresh intervals, matching the API's per-endpoint rate limits
+const (
+	minPortfolioInterval = 5 * time.Second
+	minOrdersInterval    = 5 * time.Second
+	minCashInterval      = 2 * time.Second
+	minPiesInterval      = 30 * time.Second
+	minAccountInterval   = 30 * time.Second
+)
+
+// maxBackoff caps the wait before retrying a section that keeps failing
+const maxBackoff = 5 * time.Minute
+
+// progressBarWidth is the number of cells in a pie progress bar
+const progressBarWidth = 20
+
+// runWatch shows a dashboard of positions, pending orders, cash and pies that
+// refreshes until interrupted
+func runWatch(a *app, args []string) (interface{}, error) {
+	fs := a.newFlagSet("watch")
+	interval := fs.Duration("interval", 5*time.Second, "refresh interval, raised to each endpoint's rate limit")
+	once := fs.Bool("once", false, "render a single frame and exit")
+	noColor := fs.Bool("no-color", false, "disable colours")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	client, err := a.api()
+	if err != nil {
+		return nil, err
+	}
+
+	d := newDashboard(client, *interval)
+	d.title = fmt.Sprintf("Trading212 %s", strings.ToUpper(a.environment()))
+	if a.profileName != "" {
+		d.title += " · " + a.profileName
+	}
+	d.currency = a.currency
+	d.color = !*noColor && os.Getenv("NO_COLOR") == ""
+
+	if *once {
+		d.refresh(time.Now())
+		d.render(a.stdout, time.Now())
+		return nil, nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Fprint(a.stdout, ansiHideCursor)
+	defer fmt.Fprint(a.stdout, ansiShowCursor)
+
+	for {
+		d.refresh(time.Now())
+		fmt.Fprint(a.stdout, ansiClear)
+		d.render(a.stdout, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// watchSection is one independently refreshed part of the dashboard. A
+// section with done stops refreshing once it reports true.
+type watchSection struct {
+	name     string
+	interval time.Duration
+	next     time.Time
+	updated  time.Time
+	failures int
+	err      error
+	fetch    func() error
+	done     func() bool
+}
+
+// dashboard holds the latest and previous state shown by watch
+type dashboard struct {
+	client   *trading212.Client
+	title    string
+	currency string
+	color    bool
+	sections []*watchSection
+
+	positions []trading212.Position
+	previous  map[string]trading212.Position
+	orders    []trading212.Order
+	newOrders map[int]bool
+	cash      *trading212.CashInfo
+	prevCash  *trading212.CashInfo
+	pies      []trading212.Pie
+}
+
+// newDashboard creates a dashboard refreshing each section at the given
+// interval or its endpoint's rate limit, whichever is longer
+func newDashboard(client *trading212.Client, interval time.Duration) *dashboard {
+	d := &dashboard{client: client}
+	d.sections = []*watchSection{
+		{name: "account", interval: max(interval, minAccountInterval), fetch: d.fetchAccount, done: func() bool { return d.currency != "" }},
+		{name: "cash", interval: max(interval, minCashInterval), fetch: d.fetchCash},
+		{name: "portfolio", interval: max(interval, minPortfolioInterval), fetch: d.fetchPortfolio},
+		{name: "orders", interval: max(interval, minOrdersInterval), fetch: d.fetchOrders},
+		{name: "pies", interval: max(interval, minPiesInterval), fetch: d.fetchPies},
+	}
+	return d
+}
+
+// refresh fetches every section that is due. While the last response reported
+// no remaining requests, fetches are postponed until the limit resets, and a
+// section that fails waits twice as long before each retry.
+func (d *dashboard) refresh(now time.Time) {
+	for _, section := range d.sections {
+		if now.Before(section.next) || (section.done != nil && section.done()) {
+			continue
+		}
+		if limit := d.client.RateLimit(); limit.Limit > 0 && limit.Remaining == 0 && limit.Reset.After(now) {
+			section.next = limit.Reset
+			continue
+		}
+
+		section.err = section.fetch()
+		if section.err != nil {
+			section.failures++
+			section.next = now.Add(backoff(section.interval, section.failures))
+			continue
+		}
+		section.updated, section.failures = now, 0
+		section.next = now.Add(section.interval)
+	}
+}
+
+// backoff returns the wait after consecutive failures: the interval doubled
+// for each failure after the first, up to maxBackoff
+func backoff(interval time.Duration, failures int) time.Duration {
+	wait := interval
+	for i := 1; i < failures && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return max(min(wait, maxBackoff), interval)
+}
+
+// fetchAccount looks up the account currency for formatting amounts
+func (d *dashboard) fetchAccount() error {
+	account, err := d.client.AccountInfo()
+	if err != nil {
+		return err
+	}
+	d.currency = account.CurrencyCode
+	return nil
+}
+
+// fetchCash refreshes account cash, keeping the previous values for highlighting
+func (d *dashboard) fetchCash() error {
+	cash, err := d.client.Cash()
+	if err != nil {
+		return err
+	}
+	d.prevCash, d.cash = d.cash, cash
+	return nil
+}
+
+// fetchPortfolio refreshes positions, keeping the previous values for highlighting
+func (d *dashboard) fetchPortfolio() error {
+	positions, err := d.client.Portfolio()
+	if err != nil {
+		return err
+	}
+
+	if d.positions != nil {
+		d.previous = make(map[string]trading212.Position, len(d.positions))
+		for _, position := range d.positions {
+			d.previous[position.Ticker] = position
+		}
+	}
+
+	sort.SliceStable(positions, func(i, j int) bool { return positions[i].PPL.Cmp(positions[j].PPL) > 0 })
+	d.positions = positions
+	return nil
+}
+
+// fetchOrders refreshes pending orders, marking those not seen before
+func (d *dashboard) fetchOrders() error {
+	orders, err := d.client.EquityOrders()
+	if err != nil {
+		return err
+	}
+
+	d.newOrders = make(map[int]bool)
+	if d.orders != nil {
+		seen := make(map[int]bool, len(d.orders))
+		for _, order := range d.orders {
+			seen[order.ID] = true
+		}
+		for _, order := range orders {
+			d.newOrders[order.ID] = !seen[order.ID]
+		}
+	}
+	d.orders = orders
+	return nil
+}
+
+// fetchPies refreshes pie progress
+func (d *dashboard) fetchPies() error {
+	pies, err := d.client.Pies()
+	if err != nil {
+		return err
+	}
+	d.pies = pies
+	return nil
+}
+
+// render writes one frame of the dashboard
+func (d *dashboard) render(w io.Writer, now time.Time) {
+	fmt.Fprintf(w, "%s  %s\n\n", d.style(ansiBold, d.title), d.style(ansiDim, now.Format("2006-01-02 15:04:05")))
+
+	d.renderCash(w)
+	d.renderPositions(w)
+	d.renderOrders(w)
+	d.renderPies(w)
+	d.renderStatus(w, now)
+}
+
+// renderCash writes the cash summary line
+func (d *dashboard) renderCash(w io.Writer) {
+	if d.cash == nil {
+		return
+	}
+
+	var prev trading212.CashInfo
+	if d.prevCash != nil {
+		prev = *d.prevCash
+	}
+	var ppl trading212.Decimal
+	for _, position := range d.positions {
+		ppl = ppl.Add(position.PPL)
+	}
+	fmt.Fprintf(w, "%s  free %s  total %s  open P&L %s\n\n",
+		d.style(ansiBold, "Cash"),
+		d.changed(d.money(d.cash.Free), d.prevCash != nil, d.cash.Free.Cmp(prev.Free)),
+		d.changed(d.money(d.cash.Total), d.prevCash != nil, d.cash.Total.Cmp(prev.Total)),
+		d.profit(ppl, d.money(ppl)))
+}
+
+// renderPositions writes open positions sorted by profit and loss
+func (d *dashboard) renderPositions(w io.Writer) {
+	fmt.Fprintln(w, d.style(ansiBold, "Positions")+d.style(ansiDim, " (by P&L)"))
+	if len(d.positions) == 0 {
+		fmt.Fprintln(w, d.style(ansiDim, "  none"))
+		fmt.Fprintln(w)
+		return
+	}
+
+	rows := make([][]string, 0, len(d.positions))
+	for _, position := range d.positions {
+		prev, seen := d.previous[position.Ticker]
+		known := d.previous != nil
+		rows = append(rows, []string{
+			position.Ticker,
+			d.changed(formatNumber(position.Quantity), known, cmp.Compare(position.Quantity, prev.Quantity)),
+			position.AveragePrice.String(),
+			d.changed(position.CurrentPrice.String(), known && seen, position.CurrentPrice.Cmp(prev.CurrentPrice)),
+			d.profit(position.PPL, d.money(position.PPL)),
+		})
+	}
+	d.table(w, []string{"TICKER", "QUANTITY", "AVG PRICE", "PRICE", "P&L"}, rows)
+}
+
+// renderOrders writes pending orders, highlighting new ones
+func (d *dashboard) renderOrders(w io.Writer) {
+	fmt.Fprintln(w, d.style(ansiBold, "Pending orders"))
+	if len(d.orders) == 0 {
+		fmt.Fprintln(w, d.style(ansiDim, "  none"))
+		fmt.Fprintln(w)
+		return
+	}
+
+	rows := make([][]string, 0, len(d.orders))
+	for _, order := range d.orders {
+		id := strconv.Itoa(order.ID)
+		if d.newOrders[order.ID] {
+			id = d.style(ansiChanged, id+" new")
+		}
+		rows = append(rows, []string{
+			id,
+			order.Ticker,
+			strconv.Itoa(order.Quantity),
+			formatOptional(order.LimitPrice),
+			formatOptional(order.StopPrice),
+			order.TimeValidity,
+		})
+	}
+	d.table(w, []string{"ID", "TICKER", "QUANTITY", "LIMIT", "STOP", "VALIDITY"}, rows)
+}
+
+// renderPies writes pie progress towards each goal
+func (d *dashboard) renderPies(w io.Writer) {
+	if len(d.pies) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, d.style(ansiBold, "Pies"))
+	rows := make([][]string, 0, len(d.pies))
+	for _, pie := range d.pies {
+		value, result := "", ""
+		if pie.Result != nil {
+			value = d.money(pie.Result.Value)
+			result = d.profit(pie.Result.Result, d.money(pie.Result.Result))
+		}
+		rows = append(rows, []string{strconv.Itoa(pie.ID), pie.Status, progressBar(pie.Progress), value, result})
+	}
+	d.table(w, []string{"ID", "STATUS", "PROGRESS", "VALUE", "RESULT"}, rows)
+}
+
+// renderStatus writes the rate-limit budget, section ages and errors
+func (d *dashboard) renderStatus(w io.Writer, now time.Time) {
+	limit := d.client.RateLimit()
+	if limit.Limit > 0 {
+		status := fmt.Sprintf("Rate limit: %d/%d requests remaining", limit.Remaining, limit.Limit)
+		if wait := limit.Reset.Sub(now); wait > 0 {
+			status += fmt.Sprintf(", resets in %s", wait.Round(time.Second))
+		}
+		style := ansiDim
+		if limit.Remaining == 0 {
+			style = ansiRed
+		}
+		fmt.Fprintln(w, d.style(style, status))
+	}
+
+	ages := make([]string, 0, len(d.sections))
+	for _, section := range d.sections {
+		if !section.updated.IsZero() && section.done == nil {
+			ages = append(ages, fmt.Sprintf("%s %s ago", section.name, now.Sub(section.updated).Round(time.Second)))
+		}
+	}
+	if len(ages) > 0 {
+		fmt.Fprintln(w, d.style(ansiDim, "Updated: "+strings.Join(ages, ", ")))
+	}
+
+	for _, section := range d.sections {
+		if section.err != nil {
+			fmt.Fprintln(w, d.style(ansiRed, fmt.Sprintf("%s: %v", section.name, section.err)))
+		}
+	}
+}
+
+// table writes rows aligned by their visible width, ignoring escape codes
+func (d *dashboard) table(w io.Writer, header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for i, title := range header {
+		widths[i] = visibleWidth(title)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], visibleWidth(cell))
+		}
+	}
+
+	writeRow := func(cells []string) {
+		var b strings.Builder
+		b.WriteString("  ")
+		for i, cell := range cells {
+			b.WriteString(cell)
+			if i < len(cells)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-visibleWidth(cell)+2))
+			}
+		}
+		fmt.Fprintln(w, b.String())
+	}
+
+	titles := make([]string, len(header))
+	for i, title := range header {
+		titles[i] = d.style(ansiDim, title)
+	}
+	writeRow(titles)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	fmt.Fprintln(w)
+}
+
+// style wraps text in an escape sequence when colours are enabled
+func (d *dashboard) style(code, text string) string {
+	if !d.color || text == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// changed highlights text with an arrow when a known value has moved, given
+// the comparison of the value with its previous one
+func (d *dashboard) changed(text string, known bool, direction int) string {
+	switch {
+	case !known || direction == 0:
+		return text
+	case direction > 0:
+		return d.style(ansiChanged, text+" ▲")
+	default:
+		return d.style(ansiChanged, text+" ▼")
+	}
+}
+
+// profit colours text green for gains and red for losses
+func (d *dashboard) profit(value trading212.Decimal, text string) string {
+	switch value.Sign() {
+	case 1:
+		return d.style(ansiGreen, text)
+	case -1:
+		return d.style(ansiRed, text)
+	default:
+		return text
+	}
+}
+
+// money formats an amount in the account currency
+func (d *dashboard) money(amount trading212.Decimal) string {
+	return format.FormatDecimalMoney(amount, d.currency)
+}
+
+// progressBar draws a progress fraction as a fixed-width bar
+func progressBar(progress float64) string {
+	progress = min(max(progress, 0), 1)
+	filled := int(progress*progressBarWidth + 0.5)
+	return fmt.Sprintf("[%s%s] %3.0f%%", strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled), progress*100)
+}
+
+// formatNumber formats a quantity or price without trailing zeros
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatOptional formats a price, leaving zero blank
+func formatOptional(value trading212.Decimal) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.String()
+}
+
+// visibleWidth returns the number of characters shown for text, skipping
+// ANSI escape sequences
+func visibleWidth(text string) int {
+	width := 0
+	for i := 0; i < len(text); {
+		if text[i] == '\x1b' {
+			end := strings.IndexByte(text[i:], 'm')
+			if end < 0 {
+				break
+			}
+			i += end + 1
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		width++
+	}
+	return width
+}
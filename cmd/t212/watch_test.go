@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// watchHandler serves dashboard endpoints, using the second set of responses
+// after the first portfolio request
+func watchHandler(t *testing.T, requests *atomic.Int32, reset time.Time, remaining int) http.HandlerFunc {
+	var portfolioCalls atomic.Int32
+	return func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("x-ratelimit-limit", "5")
+		w.Header().Set("x-ratelimit-remaining", strconv.Itoa(remaining))
+		w.Header().Set("x-ratelimit-reset", strconv.FormatInt(reset.Unix(), 10))
+
+		switch r.URL.Path {
+		case "/api/v0/equity/account/info":
+			writeJSON(w, `{"currencyCode":"GBP"}`)
+		case "/api/v0/equity/account/cash":
+			writeJSON(w, `{"free":100,"total":1000}`)
+		case "/api/v0/equity/portfolio":
+			if portfolioCalls.Add(1) == 1 {
+				writeJSON(w, `[{"ticker":"MSFT_US_EQ","quantity":1,"currentPrice":400,"ppl":-5},{"ticker":"AAPL_US_EQ","quantity":2,"currentPrice":200,"ppl":12.5}]`)
+			} else {
+				writeJSON(w, `[{"ticker":"MSFT_US_EQ","quantity":1,"currentPrice":410,"ppl":5},{"ticker":"AAPL_US_EQ","quantity":2,"currentPrice":200,"ppl":12.5}]`)
+			}
+		case "/api/v0/equity/orders":
+			writeJSON(w, `[{"id":7,"ticker":"AAPL_US_EQ","quantity":1,"limitPrice":150,"timeValidity":"GTC"}]`)
+		case "/api/v0/equity/pies":
+			writeJSON(w, `[{"id":3,"status":"AHEAD","progress":0.5,"result":{"value":250,"result":-10}}]`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}
+}
+
+// TestRunWatchOnce tests a single dashboard frame
+func TestRunWatchOnce(t *testing.T) {
+	var requests atomic.Int32
+	a, stdout, stderr := newTestApp(t, watchHandler(t, &requests, time.Now().Add(3*time.Second), 4))
+
+	if code := a.run([]string{"watch", "--once", "--no-color"}); code != 0 {
+		t.Fatalf("run(watch) = %d, want 0: %s", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{"Trading212 DEMO", "free £100.00", "open P&L £7.50", "LIMIT", "GTC", "[##########----------]  50%", "-£10.00", "Rate limit: 4/5 requests remaining, resets in"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("frame missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "AAPL_US_EQ") > strings.Index(out, "MSFT_US_EQ") {
+		t.Errorf("positions not sorted by P&L:\n%s", out)
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("--no-color frame contains escape codes:\n%q", out)
+	}
+}
+
+// TestDashboardHighlightsChanges tests that moved prices are highlighted between refreshes
+func TestDashboardHighlightsChanges(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(watchHandler(t, &requests, time.Now(), 4))
+	defer server.Close()
+
+	client := trading212.NewClient("key", true)
+	client.SetBaseURL(server.URL)
+	d := newDashboard(client, time.Second)
+	d.color = true
+
+	start := time.Now()
+	d.refresh(start)
+	d.refresh(start.Add(time.Minute))
+
+	var buf bytes.Buffer
+	d.render(&buf, start.Add(time.Minute))
+	out := buf.String()
+
+	if !strings.Contains(out, ansiChanged+"410 ▲"+ansiReset) {
+		t.Errorf("Expected MSFT price rise to be highlighted:\n%q", out)
+	}
+	if strings.Contains(out, "200 ▲") || strings.Contains(out, "200 ▼") {
+		t.Errorf("Unchanged price should not be highlighted:\n%q", out)
+	}
+	if strings.Index(out, "AAPL_US_EQ") > strings.Index(out, "MSFT_US_EQ") {
+		t.Errorf("Expected AAPL (higher P&L) before MSFT:\n%s", out)
+	}
+}
+
+// TestDashboardRateLimit tests that fetches wait for the rate limit to reset
+func TestDashboardRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	reset := time.Now().Add(time.Hour)
+	server := httptest.NewServer(watchHandler(t, &requests, reset, 0))
+	defer server.Close()
+
+	client := trading212.NewClient("key", true)
+	client.SetBaseURL(server.URL)
+	d := newDashboard(client, time.Second)
+
+	now := time.Now()
+	d.refresh(now)
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("requests after exhausted limit = %d, want 1", got)
+	}
+	// The account lookup made the request, and every other section waits
+	for _, section := range d.sections[1:] {
+		if !section.next.Equal(reset.Truncate(time.Second)) {
+			t.Errorf("%s next refresh = %v, want %v", section.name, section.next, reset)
+		}
+	}
+
+	d.refresh(now.Add(time.Minute))
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests before reset = %d, want 1", got)
+	}
+}
+
+// TestDashboardAccountBackoff tests that a failed currency lookup is retried
+// at its own interval, backing off, and reported in the status
+func TestDashboardAccountBackoff(t *testing.T) {
+	var accountCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v0/equity/account/info" {
+			accountCalls.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, `[]`)
+	}))
+	defer server.Close()
+
+	client := trading212.NewClient("key", true)
+	client.SetBaseURL(server.URL)
+	d := newDashboard(client, time.Second)
+
+	now := time.Now()
+	for tick := 0; tick < 120; tick++ {
+		d.refresh(now.Add(time.Duration(tick) * time.Second))
+	}
+	// Tried at 0s, 30s and 90s as the wait doubles
+	if got := accountCalls.Load(); got != 3 {
+		t.Errorf("account requests over two minutes = %d, want 3", got)
+	}
+
+	var buf bytes.Buffer
+	d.render(&buf, now.Add(2*time.Minute))
+	if !strings.Contains(buf.String(), "account: ") {
+		t.Errorf("Expected the account error in the status:\n%s", buf.String())
+	}
+}
+
+// TestBackoff tests the retry wait after consecutive failures
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 30 * time.Second},
+		{failures: 2, want: time.Minute},
+		{failures: 4, want: 4 * time.Minute},
+		{failures: 10, want: maxBackoff},
+	}
+	for _, tt := range tests {
+		if got := backoff(30*time.Second, tt.failures); got != tt.want {
+			t.Errorf("backoff(30s, %d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+// TestVisibleWidth tests width calculation ignoring escape codes
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{text: "AAPL", want: 4},
+		{text: ansiGreen + "£12.50" + ansiReset, want: 6},
+		{text: ansiChanged + "410 ▲" + ansiReset, want: 5},
+	}
+
+	for _, tt := range tests {
+		if got := visibleWidth(tt.text); got != tt.want {
+			t.Errorf("visibleWidth(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
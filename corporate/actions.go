@@ -0,0 +1,355 @@
+// Package corporate adjusts account history for corporate actions, so that
+// quantities and prices before a split are comparable with those after it
+// and renamed instruments keep a single ticker.
+package corporate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// Action types
+const (
+	Split        = "split"
+	ReverseSplit = "reverse-split"
+	Rename       = "rename"
+)
+
+// Ratio is the number of new shares issued for a number of old shares,
+// written "10:1" for a ten-for-one split
+type Ratio struct {
+	New trading212.Decimal
+	Old trading212.Decimal
+}
+
+// ParseRatio parses a ratio written "new:old", or a single number of new
+// shares per old share
+func ParseRatio(s string) (Ratio, error) {
+	newPart, oldPart, found := strings.Cut(strings.TrimSpace(s), ":")
+	if !found {
+		oldPart = "1"
+	}
+	newShares, err := trading212.ParseDecimal(strings.TrimSpace(newPart))
+	if err != nil {
+		return Ratio{}, fmt.Errorf("invalid ratio %q", s)
+	}
+	oldShares, err := trading212.ParseDecimal(strings.TrimSpace(oldPart))
+	if err != nil {
+		return Ratio{}, fmt.Errorf("invalid ratio %q", s)
+	}
+	if newShares.Sign() <= 0 || oldShares.Sign() <= 0 {
+		return Ratio{}, fmt.Errorf("invalid ratio %q: both sides must be positive", s)
+	}
+	return Ratio{New: newShares, Old: oldShares}, nil
+}
+
+// String formats the ratio as "new:old"
+func (r Ratio) String() string {
+	return r.New.String() + ":" + r.Old.String()
+}
+
+// IsZero reports whether the ratio is unset
+func (r Ratio) IsZero() bool {
+	return r.New.IsZero() && r.Old.IsZero()
+}
+
+// MarshalText encodes the ratio as "new:old"
+func (r Ratio) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText decodes a ratio written "new:old"
+func (r *Ratio) UnmarshalText(text []byte) error {
+	parsed, err := ParseRatio(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// quantity returns an old quantity in new shares
+func (r Ratio) quantity(old trading212.Decimal) trading212.Decimal {
+	return old.MulDiv(r.New, r.Old)
+}
+
+// price returns an old price per share in new shares
+func (r Ratio) price(old trading212.Decimal) trading212.Decimal {
+	return old.MulDiv(r.Old, r.New)
+}
+
+// Action is a split, reverse split or rename effective from the start of
+// Date. It applies to history in Ticker, or in ISIN when Ticker is empty.
+// Renames set NewTicker, NewISIN or both.
+type Action struct {
+	Type      string    `json:"type"`
+	Date      time.Time `json:"date"`
+	Ticker    string    `json:"ticker,omitempty"`
+	ISIN      string    `json:"isin,omitempty"`
+	Ratio     Ratio     `json:"ratio,omitzero"`
+	NewTicker string    `json:"newTicker,omitempty"`
+	NewISIN   string    `json:"newIsin,omitempty"`
+}
+
+// Validate checks that the action is complete
+func (a Action) Validate() error {
+	if a.Ticker == "" && a.ISIN == "" {
+		return fmt.Errorf("%s on %s has no ticker or ISIN", a.Type, a.Date.Format("2006-01-02"))
+	}
+	if a.Date.IsZero() {
+		return fmt.Errorf("%s of %s has no date", a.Type, a.instrument())
+	}
+
+	switch a.Type {
+	case Split, ReverseSplit:
+		if a.Ratio.New.Sign() <= 0 || a.Ratio.Old.Sign() <= 0 {
+			return fmt.Errorf("%s of %s has no ratio", a.Type, a.instrument())
+		}
+		if a.Type == ReverseSplit && a.Ratio.New.Cmp(a.Ratio.Old) >= 0 {
+			return fmt.Errorf("reverse split of %s must reduce the number of shares, got %s", a.instrument(), a.Ratio)
+		}
+	case Rename:
+		if a.NewTicker == "" && a.NewISIN == "" {
+			return fmt.Errorf("rename of %s has no new ticker or ISIN", a.instrument())
+		}
+	default:
+		return fmt.Errorf("unknown corporate action %q, expected split, reverse-split or rename", a.Type)
+	}
+	return nil
+}
+
+// instrument names the instrument the action applies to
+func (a Action) instrument() string {
+	if a.Ticker != "" {
+		return a.Ticker
+	}
+	return a.ISIN
+}
+
+// matches reports whether history recorded at t for the instrument is before
+// the action and so affected by it
+func (a Action) matches(ticker, isin string, t time.Time) bool {
+	if !t.Before(a.Date) {
+		return false
+	}
+	if a.Ticker != "" {
+		return ticker == a.Ticker
+	}
+	return isin != "" && isin == a.ISIN
+}
+
+// rename returns the instrument's identifiers after a rename
+func (a Action) rename(ticker, isin string) (string, string) {
+	if a.NewTicker != "" {
+		ticker = a.NewTicker
+	}
+	if a.NewISIN != "" {
+		isin = a.NewISIN
+	}
+	return ticker, isin
+}
+
+// Actions is a list of corporate actions, applied oldest first
+type Actions []Action
+
+// Load reads actions from a JSON or CSV file, chosen by its extension
+func Load(path string) (Actions, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return LoadCSV(file)
+	}
+	return LoadJSON(file)
+}
+
+// LoadJSON reads a JSON array of actions. Dates may be written "2024-06-10"
+// or in RFC 3339.
+func LoadJSON(r io.Reader) (Actions, error) {
+	var records []struct {
+		Action
+		Date string `json:"date"`
+	}
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("failed to read corporate actions: %v", err)
+	}
+
+	actions := make(Actions, 0, len(records))
+	for i, record := range records {
+		action := record.Action
+		date, err := trading212.ParseDate(record.Date)
+		if err != nil {
+			return nil, fmt.Errorf("corporate action %d: %v", i+1, err)
+		}
+		action.Date = date
+		action.Type = strings.ToLower(action.Type)
+		if err := action.Validate(); err != nil {
+			return nil, fmt.Errorf("corporate action %d: %v", i+1, err)
+		}
+		actions = append(actions, action)
+	}
+	actions.sort()
+	return actions, nil
+}
+
+// LoadCSV reads actions from a CSV file with the header
+// date,type,ticker,isin,ratio,newTicker,newIsin. Only date and type are
+// required columns.
+func LoadCSV(r io.Reader) (Actions, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corporate actions: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("corporate actions file is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "type"} {
+		if _, exists := columns[name]; !exists {
+			return nil, fmt.Errorf("corporate actions file has no %s column", name)
+		}
+	}
+
+	actions := make(Actions, 0, len(records)-1)
+	for line, record := range records[1:] {
+		field := func(name string) string {
+			i, exists := columns[strings.ToLower(name)]
+			if !exists || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		date, err := trading212.ParseDate(field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("corporate actions line %d: %v", line+2, err)
+		}
+		action := Action{
+			Type:      strings.ToLower(field("type")),
+			Date:      date,
+			Ticker:    field("ticker"),
+			ISIN:      field("isin"),
+			NewTicker: field("newTicker"),
+			NewISIN:   field("newIsin"),
+		}
+		if ratio := field("ratio"); ratio != "" {
+			if action.Ratio, err = ParseRatio(ratio); err != nil {
+				return nil, fmt.Errorf("corporate actions line %d: %v", line+2, err)
+			}
+		}
+		if err := action.Validate(); err != nil {
+			return nil, fmt.Errorf("corporate actions line %d: %v", line+2, err)
+		}
+		actions = append(actions, action)
+	}
+	actions.sort()
+	return actions, nil
+}
+
+// sort orders actions oldest first, keeping the file order on the same date
+func (actions Actions) sort() {
+	sort.SliceStable(actions, func(i, j int) bool { return actions[i].Date.Before(actions[j].Date) })
+}
+
+// Ticker returns the ticker that history in ticker recorded at t is known by
+// after every later rename
+func (actions Actions) Ticker(ticker string, t time.Time) string {
+	isin := ""
+	for _, action := range actions {
+		if action.Type == Rename && action.matches(ticker, isin, t) {
+			ticker, isin = action.rename(ticker, isin)
+		}
+	}
+	return ticker
+}
+
+// AdjustTrades returns a copy of trades with quantities and prices restated
+// in shares after every later split and tickers after every later rename.
+// Values and fees are unchanged.
+func (actions Actions) AdjustTrades(trades []trading212.Trade) []trading212.Trade {
+	adjusted := append([]trading212.Trade(nil), trades...)
+	for _, action := range actions {
+		for i := range adjusted {
+			trade := &adjusted[i]
+			if !action.matches(trade.Ticker, trade.ISIN, trade.Time) {
+				continue
+			}
+			if action.Type == Rename {
+				trade.Ticker, trade.ISIN = action.rename(trade.Ticker, trade.ISIN)
+				continue
+			}
+			trade.Quantity = action.Ratio.quantity(trade.Quantity)
+			trade.Price = action.Ratio.price(trade.Price)
+		}
+	}
+	return adjusted
+}
+
+// AdjustOrders returns a copy of historical orders restated in the same way
+// as AdjustTrades, using the execution time or, failing that, the creation
+// time of each order
+func (actions Actions) AdjustOrders(orders []trading212.HistoricalOrder) []trading212.HistoricalOrder {
+	adjusted := append([]trading212.HistoricalOrder(nil), orders...)
+	for _, action := range actions {
+		for i := range adjusted {
+			order := &adjusted[i]
+			at := order.DateExecuted
+			if at.IsZero() {
+				at = order.DateCreated
+			}
+			if !action.matches(order.Ticker, "", at) {
+				continue
+			}
+			if action.Type == Rename {
+				order.Ticker, _ = action.rename(order.Ticker, "")
+				continue
+			}
+			order.OrderedQuantity = action.Ratio.quantity(trading212.NewDecimalFromFloat(order.OrderedQuantity)).Float64()
+			order.FilledQuantity = action.Ratio.quantity(trading212.NewDecimalFromFloat(order.FilledQuantity)).Float64()
+			order.FillPrice = action.Ratio.price(order.FillPrice)
+			order.LimitPrice = action.Ratio.price(order.LimitPrice)
+			order.StopPrice = action.Ratio.price(order.StopPrice)
+		}
+	}
+	return adjusted
+}
+
+// AdjustDividends returns a copy of dividends with the shares held and the
+// gross amount per share restated after every later split, and tickers after
+// every later rename
+func (actions Actions) AdjustDividends(dividends []trading212.Dividend) []trading212.Dividend {
+	adjusted := append([]trading212.Dividend(nil), dividends...)
+	for _, action := range actions {
+		for i := range adjusted {
+			dividend := &adjusted[i]
+			if !action.matches(dividend.Ticker, "", dividend.PaidOn) {
+				continue
+			}
+			if action.Type == Rename {
+				dividend.Ticker, _ = action.rename(dividend.Ticker, "")
+				continue
+			}
+			dividend.Quantity = action.Ratio.quantity(trading212.NewDecimalFromFloat(dividend.Quantity)).Float64()
+			dividend.GrossAmountPerShare = action.Ratio.price(dividend.GrossAmountPerShare)
+		}
+	}
+	return adjusted
+}
@@ -0,0 +1,112 @@
+package corporate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// TestParseRatio tests ratio formats
+func TestParseRatio(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "10:1", want: "10:1"},
+		{input: " 1 : 20 ", want: "1:20"},
+		{input: "3", want: "3:1"},
+		{input: "0:1", wantErr: true},
+		{input: "ten", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRatio(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRatio() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("ParseRatio() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoad tests reading and validating JSON and CSV files
+func TestLoad(t *testing.T) {
+	actions, err := LoadJSON(strings.NewReader(`[
+		{"type": "rename", "date": "2022-06-09", "ticker": "FB", "newTicker": "META"},
+		{"type": "Split", "date": "2021-07-20", "ticker": "NVDA", "ratio": "4:1"}
+	]`))
+	if err != nil {
+		t.Fatalf("LoadJSON() error = %v", err)
+	}
+	if len(actions) != 2 || actions[0].Type != Split || actions[0].Ratio.String() != "4:1" || actions[1].NewTicker != "META" {
+		t.Errorf("LoadJSON() = %+v", actions)
+	}
+
+	actions, err = LoadCSV(strings.NewReader("date,type,isin,ratio\n2023-01-05,reverse-split,GB00X,1:10\n"))
+	if err != nil {
+		t.Fatalf("LoadCSV() error = %v", err)
+	}
+	if len(actions) != 1 || actions[0].ISIN != "GB00X" || actions[0].Ratio.String() != "1:10" {
+		t.Errorf("LoadCSV() = %+v", actions)
+	}
+
+	invalid := []string{
+		"date,type,ticker,ratio\n2023-01-05,reverse-split,ABC,10:1\n",
+		"date,type,ticker\n2023-01-05,merger,ABC\n",
+		"date,type,ticker\n2023-01-05,rename,ABC\n",
+		"date,type,ratio\n2023-01-05,split,2:1\n",
+		"type,ticker\nsplit,ABC\n",
+	}
+	for _, data := range invalid {
+		if _, err := LoadCSV(strings.NewReader(data)); err == nil {
+			t.Errorf("Expected error for %q", data)
+		}
+	}
+}
+
+// TestAdjustTrades tests restating trades before splits and renames
+func TestAdjustTrades(t *testing.T) {
+	actions := Actions{
+		{Type: Rename, Date: time.Date(2022, 6, 9, 0, 0, 0, 0, time.UTC), Ticker: "FB", NewTicker: "META"},
+		{Type: Split, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Ticker: "META", Ratio: Ratio{New: trading212.NewDecimalFromInt(2), Old: trading212.NewDecimalFromInt(1)}},
+	}
+	trades := []trading212.Trade{
+		{Ticker: "FB", Time: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Quantity: trading212.MustParseDecimal("1.5"), Price: trading212.NewDecimalFromInt(300)},
+		{Ticker: "META", Time: time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC), Quantity: trading212.NewDecimalFromInt(-1), Price: trading212.NewDecimalFromInt(160)},
+		{Ticker: "META", Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Quantity: trading212.NewDecimalFromInt(1), Price: trading212.NewDecimalFromInt(120)},
+	}
+
+	adjusted := actions.AdjustTrades(trades)
+	if adjusted[0].Ticker != "META" || adjusted[0].Quantity.String() != "3" || adjusted[0].Price.String() != "150" {
+		t.Errorf("first trade = %+v", adjusted[0])
+	}
+	if adjusted[1].Quantity.String() != "-2" || adjusted[1].Price.String() != "80" {
+		t.Errorf("second trade = %+v", adjusted[1])
+	}
+	if adjusted[2].Quantity.String() != "1" || adjusted[2].Price.String() != "120" {
+		t.Errorf("trade on the split date = %+v", adjusted[2])
+	}
+	if trades[0].Ticker != "FB" {
+		t.Error("AdjustTrades() modified its input")
+	}
+	if ticker := actions.Ticker("FB", trades[0].Time); ticker != "META" {
+		t.Errorf("Ticker(FB) = %s, want META", ticker)
+	}
+}
+
+// TestAdjustDividends tests that the gross amount paid is unchanged by a split
+func TestAdjustDividends(t *testing.T) {
+	actions := Actions{{Type: Split, Date: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), Ticker: "NVDA", Ratio: Ratio{New: trading212.NewDecimalFromInt(10), Old: trading212.NewDecimalFromInt(1)}}}
+	dividends := actions.AdjustDividends([]trading212.Dividend{
+		{Ticker: "NVDA", Quantity: 5, GrossAmountPerShare: trading212.MustParseDecimal("0.04"), PaidOn: time.Date(2024, 3, 27, 0, 0, 0, 0, time.UTC)},
+	})
+	if dividends[0].Quantity != 50 || dividends[0].GrossAmountPerShare.String() != "0.004" {
+		t.Errorf("AdjustDividends() = %+v", dividends)
+	}
+}
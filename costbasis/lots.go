@@ -0,0 +1,300 @@
+// Package costbasis tracks the lots bought by each trade to report realised
+// gains per sale and unrealised gains on what is still held.
+package costbasis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/corporate"
+	"github.com/0xnu/trading212/fx"
+)
+
+// Method decides which lots a sale closes and at what cost
+type Method string
+
+// Lot matching methods
+const (
+	FIFO        Method = "fifo"
+	LIFO        Method = "lifo"
+	AverageCost Method = "average"
+)
+
+// ParseMethod parses a method name, case insensitively
+func ParseMethod(name string) (Method, error) {
+	switch method := Method(strings.ToLower(name)); method {
+	case FIFO, LIFO, AverageCost:
+		return method, nil
+	}
+	return "", fmt.Errorf("unknown cost basis method %q, expected fifo, lifo or average", name)
+}
+
+// Lot is shares bought by one trade that are still held. Cost includes the
+// purchase fees, and under average cost it is the lot's share of the pooled
+// cost.
+type Lot struct {
+	Ticker   string             `json:"ticker"`
+	TradeID  string             `json:"tradeId"`
+	Acquired time.Time          `json:"acquired"`
+	Quantity trading212.Decimal `json:"quantity"`
+	Cost     trading212.Decimal `json:"cost"`
+}
+
+// UnitCost returns the cost of one share in the lot
+func (l Lot) UnitCost() trading212.Decimal {
+	if l.Quantity.IsZero() {
+		return trading212.Decimal{}
+	}
+	return l.Cost.Div(l.Quantity)
+}
+
+// ClosedTrade is the part of a sale matched with one lot. Proceeds are net of
+// the sale's fees, shared in proportion to quantity.
+type ClosedTrade struct {
+	Ticker      string             `json:"ticker"`
+	BuyTradeID  string             `json:"buyTradeId"`
+	SellTradeID string             `json:"sellTradeId"`
+	Acquired    time.Time          `json:"acquired"`
+	Disposed    time.Time          `json:"disposed"`
+	HoldingDays int                `json:"holdingDays"`
+	Quantity    trading212.Decimal `json:"quantity"`
+	Cost        trading212.Decimal `json:"cost"`
+	Proceeds    trading212.Decimal `json:"proceeds"`
+	PnL         trading212.Decimal `json:"pnl"`
+}
+
+// TickerPnL is the profit and loss of one instrument. Unrealised is only set
+// when the market value of the holding is known.
+type TickerPnL struct {
+	Ticker      string             `json:"ticker"`
+	Quantity    trading212.Decimal `json:"quantity"`
+	Cost        trading212.Decimal `json:"cost"`
+	AverageCost trading212.Decimal `json:"averageCost"`
+	Value       trading212.Decimal `json:"value,omitzero"`
+	Fees        trading212.Decimal `json:"fees"`
+	Realised    trading212.Decimal `json:"realised"`
+	Unrealised  trading212.Decimal `json:"unrealised"`
+	Priced      bool               `json:"priced"`
+}
+
+// Book tracks lots per ticker in a single currency. Trades must be added
+// oldest first.
+type Book struct {
+	method   Method
+	currency string
+	source   fx.RateSource
+	actions  corporate.Actions
+
+	lots     map[string][]Lot
+	realised map[string]trading212.Decimal
+	fees     map[string]trading212.Decimal
+	closed   []ClosedTrade
+}
+
+// NewBook returns an empty book that values trades in currency, converting
+// them with source, which may be nil when every trade is in that currency
+func NewBook(method Method, currency string, source fx.RateSource) *Book {
+	return &Book{
+		method:   method,
+		currency: strings.ToUpper(currency),
+		source:   source,
+		lots:     make(map[string][]Lot),
+		realised: make(map[string]trading212.Decimal),
+		fees:     make(map[string]trading212.Decimal),
+	}
+}
+
+// Build sorts the trades and adds them to a new book
+func Build(trades []trading212.Trade, method Method, currency string, source fx.RateSource) (*Book, error) {
+	book := NewBook(method, currency, source)
+	if err := book.AddAll(trades); err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// SetActions sets the corporate actions that trades are adjusted for as they
+// are added, so that lots bought before a split are held in post-split
+// shares under the instrument's latest ticker
+func (b *Book) SetActions(actions corporate.Actions) {
+	b.actions = actions
+}
+
+// AddAll sorts the trades and adds them to the book
+func (b *Book) AddAll(trades []trading212.Trade) error {
+	sorted := append([]trading212.Trade(nil), trades...)
+	trading212.SortTrades(sorted)
+	for _, trade := range sorted {
+		if err := b.Add(trade); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add records a trade. A buy opens a lot and a sale closes lots according to
+// the book's method; selling more than is held is an error.
+func (b *Book) Add(trade trading212.Trade) error {
+	if trade.Quantity.IsZero() {
+		return nil
+	}
+	if len(b.actions) > 0 {
+		trade = b.actions.AdjustTrades([]trading212.Trade{trade})[0]
+	}
+	value, fees, err := fx.TradeValue(b.source, trade, b.currency)
+	if err != nil {
+		return fmt.Errorf("failed to convert trade %s in %s: %v", trade.ID, trade.Ticker, err)
+	}
+	b.fees[trade.Ticker] = b.fees[trade.Ticker].Add(fees)
+
+	if trade.IsBuy() {
+		b.lots[trade.Ticker] = append(b.lots[trade.Ticker], Lot{
+			Ticker:   trade.Ticker,
+			TradeID:  trade.ID,
+			Acquired: trade.Time,
+			Quantity: trade.Shares(),
+			Cost:     value.Add(fees),
+		})
+		if b.method == AverageCost {
+			b.lots[trade.Ticker] = averageLots(b.lots[trade.Ticker])
+		}
+		return nil
+	}
+	return b.sell(trade, value.Sub(fees))
+}
+
+// sell closes lots for a sale with the given net proceeds
+func (b *Book) sell(trade trading212.Trade, proceeds trading212.Decimal) error {
+	lots := b.lots[trade.Ticker]
+	held := trading212.Decimal{}
+	for _, lot := range lots {
+		held = held.Add(lot.Quantity)
+	}
+	if trade.Shares().Cmp(held) > 0 {
+		return fmt.Errorf("sale of %s %s on %s exceeds the %s shares held", trade.Shares(), trade.Ticker, trade.Time.Format("2006-01-02"), held)
+	}
+
+	remaining := trade.Shares()
+	remainingProceeds := proceeds
+	for remaining.Sign() > 0 {
+		i := 0
+		if b.method == LIFO {
+			i = len(lots) - 1
+		}
+		lot := &lots[i]
+
+		quantity := remaining
+		if lot.Quantity.Cmp(quantity) < 0 {
+			quantity = lot.Quantity
+		}
+		cost := lot.Cost.MulDiv(quantity, lot.Quantity)
+		share := remainingProceeds.MulDiv(quantity, remaining)
+
+		b.closed = append(b.closed, ClosedTrade{
+			Ticker:      trade.Ticker,
+			BuyTradeID:  lot.TradeID,
+			SellTradeID: trade.ID,
+			Acquired:    lot.Acquired,
+			Disposed:    trade.Time,
+			HoldingDays: holdingDays(lot.Acquired, trade.Time),
+			Quantity:    quantity,
+			Cost:        cost,
+			Proceeds:    share,
+			PnL:         share.Sub(cost),
+		})
+		b.realised[trade.Ticker] = b.realised[trade.Ticker].Add(share.Sub(cost))
+
+		lot.Quantity = lot.Quantity.Sub(quantity)
+		lot.Cost = lot.Cost.Sub(cost)
+		remaining = remaining.Sub(quantity)
+		remainingProceeds = remainingProceeds.Sub(share)
+		if lot.Quantity.IsZero() {
+			lots = append(lots[:i], lots[i+1:]...)
+		}
+	}
+	b.lots[trade.Ticker] = lots
+	return nil
+}
+
+// OpenLots returns the lots still held for a ticker, oldest first
+func (b *Book) OpenLots(ticker string) []Lot {
+	return append([]Lot(nil), b.lots[ticker]...)
+}
+
+// Closed returns every closed trade in the order the sales were added
+func (b *Book) Closed() []ClosedTrade {
+	return append([]ClosedTrade(nil), b.closed...)
+}
+
+// PnL returns the profit and loss of every ticker traded, sorted by ticker.
+// values are the current market values of the holdings in the book's
+// currency, such as the Value of each fx.Holding; tickers without a value
+// have no unrealised P&L.
+func (b *Book) PnL(values map[string]trading212.Decimal) []TickerPnL {
+	tickers := make([]string, 0, len(b.fees))
+	for ticker := range b.fees {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	results := make([]TickerPnL, 0, len(tickers))
+	for _, ticker := range tickers {
+		result := TickerPnL{Ticker: ticker, Fees: b.fees[ticker], Realised: b.realised[ticker]}
+		for _, lot := range b.lots[ticker] {
+			result.Quantity = result.Quantity.Add(lot.Quantity)
+			result.Cost = result.Cost.Add(lot.Cost)
+		}
+		if !result.Quantity.IsZero() {
+			result.AverageCost = result.Cost.Div(result.Quantity)
+		}
+
+		if value, priced := values[ticker]; priced {
+			result.Value = value
+			result.Unrealised = value.Sub(result.Cost)
+			result.Priced = true
+		} else if result.Quantity.IsZero() {
+			result.Priced = true
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// ValuesFromValuation returns the market value of each holding in a valuation
+func ValuesFromValuation(valuation *fx.Valuation) map[string]trading212.Decimal {
+	values := make(map[string]trading212.Decimal, len(valuation.Holdings))
+	for _, holding := range valuation.Holdings {
+		values[holding.Ticker] = holding.Value
+	}
+	return values
+}
+
+// averageLots spreads the pooled cost of a ticker's lots evenly across their
+// shares, keeping each lot's acquisition date for holding periods
+func averageLots(lots []Lot) []Lot {
+	var quantity, cost trading212.Decimal
+	for _, lot := range lots {
+		quantity = quantity.Add(lot.Quantity)
+		cost = cost.Add(lot.Cost)
+	}
+
+	remainingQuantity, remainingCost := quantity, cost
+	for i := range lots {
+		share := remainingCost.MulDiv(lots[i].Quantity, remainingQuantity)
+		remainingQuantity = remainingQuantity.Sub(lots[i].Quantity)
+		remainingCost = remainingCost.Sub(share)
+		lots[i].Cost = share
+	}
+	return lots
+}
+
+// holdingDays returns the number of calendar days between acquisition and
+// disposal
+func holdingDays(acquired, disposed time.Time) int {
+	from := time.Date(acquired.Year(), acquired.Month(), acquired.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(disposed.Year(), disposed.Month(), disposed.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
@@ -0,0 +1,154 @@
+package costbasis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/corporate"
+	"github.com/0xnu/trading212/fx"
+)
+
+// trade returns a trade in pounds with a known value
+func trade(id string, day int, quantity, value, fees string) trading212.Trade {
+	return trading212.Trade{
+		ID:       id,
+		Ticker:   "VOD",
+		Time:     time.Date(2024, 1, day, 10, 0, 0, 0, time.UTC),
+		Quantity: trading212.MustParseDecimal(quantity),
+		Value:    trading212.MustParseDecimal(value),
+		Fees:     trading212.MustParseDecimal(fees),
+		Currency: "GBP",
+	}
+}
+
+// TestBook tests closing lots under each method
+func TestBook(t *testing.T) {
+	trades := []trading212.Trade{
+		trade("3", 20, "-15", "300", "1"),
+		trade("1", 1, "10", "100", "1"),
+		trade("2", 10, "10.5", "210", "0"),
+	}
+
+	tests := []struct {
+		method       Method
+		wantClosed   int
+		wantRealised string
+		wantCost     string
+	}{
+		{method: FIFO, wantClosed: 2, wantRealised: "98", wantCost: "110"},
+		{method: LIFO, wantClosed: 2, wantRealised: "43.55", wantCost: "55.55"},
+		{method: AverageCost, wantClosed: 2, wantRealised: "71.43902439", wantCost: "83.43902439"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			book, err := Build(trades, tt.method, "GBP", nil)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			closed := book.Closed()
+			if len(closed) != tt.wantClosed {
+				t.Fatalf("Closed() returned %d trades, want %d", len(closed), tt.wantClosed)
+			}
+			var proceeds trading212.Decimal
+			for _, c := range closed {
+				proceeds = proceeds.Add(c.Proceeds)
+			}
+			if proceeds.String() != "299" {
+				t.Errorf("proceeds = %s, want 299", proceeds)
+			}
+
+			lots := book.OpenLots("VOD")
+			if len(lots) != 1 || lots[0].Quantity.String() != "5.5" || lots[0].Cost.String() != tt.wantCost {
+				t.Errorf("OpenLots() = %+v", lots)
+			}
+
+			pnl := book.PnL(map[string]trading212.Decimal{"VOD": trading212.NewDecimalFromInt(110)})
+			if len(pnl) != 1 || pnl[0].Realised.String() != tt.wantRealised || pnl[0].Cost.String() != tt.wantCost || pnl[0].Fees.String() != "2" {
+				t.Errorf("PnL() = %+v", pnl)
+			}
+			if !pnl[0].Priced || pnl[0].Unrealised != trading212.NewDecimalFromInt(110).Sub(pnl[0].Cost) {
+				t.Errorf("unrealised = %+v", pnl[0])
+			}
+		})
+	}
+}
+
+// TestBookLargeQuantity tests that the cost of part of a lot does not
+// overflow when cost × quantity is beyond the range of a Decimal
+func TestBookLargeQuantity(t *testing.T) {
+	trades := []trading212.Trade{
+		trade("1", 1, "2000000", "200000", "0"),
+		trade("2", 2, "-1000000", "150000", "0"),
+	}
+
+	for _, method := range []Method{FIFO, AverageCost} {
+		book, err := Build(trades, method, "GBP", nil)
+		if err != nil {
+			t.Fatalf("Build(%s) error = %v", method, err)
+		}
+		closed := book.Closed()
+		if len(closed) != 1 || closed[0].Cost.String() != "100000" || closed[0].PnL.String() != "50000" {
+			t.Errorf("Build(%s) closed = %+v", method, closed)
+		}
+	}
+}
+
+// TestBookHoldingPeriod tests that closed trades record their lot's dates
+func TestBookHoldingPeriod(t *testing.T) {
+	book, err := Build([]trading212.Trade{
+		trade("1", 1, "2", "20", "0"),
+		trade("2", 31, "-1", "15", "0"),
+	}, FIFO, "GBP", nil)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	closed := book.Closed()
+	if len(closed) != 1 || closed[0].HoldingDays != 30 || closed[0].BuyTradeID != "1" || closed[0].PnL.String() != "5" {
+		t.Errorf("Closed() = %+v", closed)
+	}
+}
+
+// TestBookErrors tests overselling and unconvertible trades
+func TestBookErrors(t *testing.T) {
+	if _, err := Build([]trading212.Trade{trade("1", 1, "1", "10", "0"), trade("2", 2, "-2", "20", "0")}, FIFO, "GBP", nil); err == nil {
+		t.Error("Expected error selling more than is held")
+	}
+
+	usd := trade("1", 1, "1", "10", "0")
+	usd.Currency = "USD"
+	if _, err := Build([]trading212.Trade{usd}, FIFO, "GBP", fx.StaticRates{}); err == nil {
+		t.Error("Expected error without a USD rate")
+	}
+}
+
+// TestParseMethod tests method names
+func TestParseMethod(t *testing.T) {
+	if method, err := ParseMethod("LIFO"); err != nil || method != LIFO {
+		t.Errorf("ParseMethod(LIFO) = %v, %v", method, err)
+	}
+	if _, err := ParseMethod("hifo"); err == nil {
+		t.Error("Expected error for unknown method")
+	}
+}
+
+// TestBookActions tests that lots bought before a split are restated
+func TestBookActions(t *testing.T) {
+	split := corporate.Action{Type: corporate.Split, Date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Ticker: "VOD", Ratio: corporate.Ratio{New: trading212.NewDecimalFromInt(10), Old: trading212.NewDecimalFromInt(1)}}
+	book := NewBook(FIFO, "GBP", nil)
+	book.SetActions(corporate.Actions{split})
+	if err := book.AddAll([]trading212.Trade{trade("1", 1, "2", "100", "0"), trade("2", 20, "-15", "90", "0")}); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	lots := book.OpenLots("VOD")
+	if len(lots) != 1 || lots[0].Quantity.String() != "5" || lots[0].Cost.String() != "25" {
+		t.Errorf("OpenLots() = %+v", lots)
+	}
+	if closed := book.Closed(); len(closed) != 1 || closed[0].PnL.String() != "15" {
+		t.Errorf("Closed() = %+v", closed)
+	}
+}
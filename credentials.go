@@ -0,0 +1,341 @@
+package trading212
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAPIKeyEnv    = "TRADING212_API_KEY"
+	defaultAPISecretEnv = "TRADING212_API_SECRET"
+	encryptedFileFormat = 1
+	pbkdf2Iterations    = 600000
+	commandTimeout      = 30 * time.Second
+)
+
+// Credentials holds an API key and an optional API secret
+type Credentials struct {
+	APIKey    string `json:"apiKey"`
+	APISecret string `json:"apiSecret,omitempty"`
+}
+
+// CredentialProvider loads credentials from a source
+type CredentialProvider interface {
+	Credentials() (Credentials, error)
+}
+
+// EnvCredentials reads credentials from environment variables. Empty variable
+// names default to TRADING212_API_KEY and TRADING212_API_SECRET.
+type EnvCredentials struct {
+	KeyVar    string
+	SecretVar string
+}
+
+// FileCredentials reads JSON credentials from a file that must not be readable by
+// group or others
+type FileCredentials struct {
+	Path string
+}
+
+// CommandCredentials runs an external command such as `pass show trading212` and
+// reads the API key from the first line of output and the secret from the second
+type CommandCredentials struct {
+	Name string
+	Args []string
+}
+
+// EncryptedFileCredentials reads credentials from an AES-GCM encrypted file
+// unlocked with a passphrase
+type EncryptedFileCredentials struct {
+	Path       string
+	Passphrase string
+}
+
+// encryptedFile is the on-disk layout of an encrypted credentials file
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Authenticator returns the authenticator matching the credentials: Basic
+// authentication when a secret is present, the raw API key otherwise
+func (c Credentials) Authenticator() Authenticator {
+	if c.APISecret != "" {
+		return BasicAuth{APIKey: c.APIKey, APISecret: c.APISecret}
+	}
+	return APIKeyAuth{APIKey: c.APIKey}
+}
+
+// validate checks that the credentials contain an API key
+func (c Credentials) validate() error {
+	if c.APIKey == "" {
+		return fmt.Errorf("credentials do not contain an api key")
+	}
+	return nil
+}
+
+// Credentials reads credentials from the environment
+func (e EnvCredentials) Credentials() (Credentials, error) {
+	keyVar := e.KeyVar
+	if keyVar == "" {
+		keyVar = defaultAPIKeyEnv
+	}
+	secretVar := e.SecretVar
+	if secretVar == "" {
+		secretVar = defaultAPISecretEnv
+	}
+
+	creds := Credentials{
+		APIKey:    os.Getenv(keyVar),
+		APISecret: os.Getenv(secretVar),
+	}
+	if creds.APIKey == "" {
+		return Credentials{}, fmt.Errorf("environment variable %s is not set", keyVar)
+	}
+	return creds, nil
+}
+
+// Credentials reads credentials from the file after checking its permissions
+func (f FileCredentials) Credentials() (Credentials, error) {
+	if err := checkPrivateFile(f.Path); err != nil {
+		return Credentials{}, err
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse credentials file %s: %v", f.Path, err)
+	}
+	return creds, creds.validate()
+}
+
+// Credentials runs the command and parses its output
+func (c CommandCredentials) Credentials() (Credentials, error) {
+	if c.Name == "" {
+		return Credentials{}, fmt.Errorf("credential command cannot be empty")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("credential command %s failed: %v: %s", c.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() && len(lines) < 2 {
+		lines = append(lines, strings.TrimSpace(scanner.Text()))
+	}
+
+	var creds Credentials
+	if len(lines) > 0 {
+		creds.APIKey = lines[0]
+	}
+	if len(lines) > 1 {
+		creds.APISecret = lines[1]
+	}
+	return creds, creds.validate()
+}
+
+// Credentials decrypts the file with the passphrase
+func (e EncryptedFileCredentials) Credentials() (Credentials, error) {
+	if err := checkPrivateFile(e.Path); err != nil {
+		return Credentials{}, err
+	}
+
+	data, err := os.ReadFile(e.Path)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse encrypted credentials file %s: %v", e.Path, err)
+	}
+	if file.Version != encryptedFileFormat {
+		return Credentials{}, fmt.Errorf("unsupported encrypted credentials version %d", file.Version)
+	}
+
+	gcm, err := newCredentialCipher(e.Passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to decrypt credentials: wrong passphrase or corrupted file")
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse decrypted credentials: %v", err)
+	}
+	return creds, creds.validate()
+}
+
+// WriteEncryptedCredentials encrypts credentials with the passphrase and writes them
+// to path with owner-only permissions
+func WriteEncryptedCredentials(path, passphrase string, creds Credentials) error {
+	if passphrase == "" {
+		return fmt.Errorf("passphrase cannot be empty")
+	}
+	if err := creds.validate(); err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	gcm, err := newCredentialCipher(passphrase, salt, pbkdf2Iterations)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(encryptedFile{
+		Version:    encryptedFileFormat,
+		Iterations: pbkdf2Iterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// newCredentialCipher derives an AES-256-GCM cipher from the passphrase
+func newCredentialCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 {
+		return nil, fmt.Errorf("invalid key derivation iterations %d", iterations)
+	}
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// checkPrivateFile ensures a secrets file is not accessible by group or others
+func checkPrivateFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("credentials file %s has permissions %v, should be 0600", path, info.Mode().Perm())
+	}
+	return nil
+}
+
+// CredentialAuth is an authenticator backed by a CredentialProvider. Credentials
+// are loaded on first use and can be rotated with Refresh without recreating the
+// client.
+type CredentialAuth struct {
+	provider CredentialProvider
+	mu       sync.RWMutex
+	auth     Authenticator
+}
+
+// NewCredentialAuth creates an authenticator that loads credentials from provider
+func NewCredentialAuth(provider CredentialProvider) *CredentialAuth {
+	return &CredentialAuth{provider: provider}
+}
+
+// Refresh reloads credentials from the provider
+func (a *CredentialAuth) Refresh() error {
+	creds, err := a.provider.Credentials()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.auth = creds.Authenticator()
+	a.mu.Unlock()
+	return nil
+}
+
+// Authenticate applies the current credentials, loading them if necessary
+func (a *CredentialAuth) Authenticate(req *http.Request) error {
+	a.mu.RLock()
+	auth := a.auth
+	a.mu.RUnlock()
+
+	if auth == nil {
+		if err := a.Refresh(); err != nil {
+			return err
+		}
+		a.mu.RLock()
+		auth = a.auth
+		a.mu.RUnlock()
+	}
+
+	return auth.Authenticate(req)
+}
+
+// String returns a redacted representation of the current credentials
+func (a *CredentialAuth) String() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if stringer, ok := a.auth.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return "api_key=****"
+}
+
+// NewClientFromProvider creates a client whose credentials are loaded from provider.
+// The credentials are loaded eagerly so that configuration errors surface early.
+func NewClientFromProvider(provider CredentialProvider, demo bool) (*Client, error) {
+	auth := NewCredentialAuth(provider)
+	if err := auth.Refresh(); err != nil {
+		return nil, err
+	}
+	return NewClientWithAuth(auth, demo), nil
+}
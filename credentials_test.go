@@ -0,0 +1,142 @@
+package trading212
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// staticProvider is a credential provider returning fixed credentials
+type staticProvider struct {
+	creds Credentials
+}
+
+func (p *staticProvider) Credentials() (Credentials, error) {
+	return p.creds, nil
+}
+
+// TestEnvCredentials tests loading credentials from environment variables
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("TRADING212_API_KEY", "env-key")
+	t.Setenv("TRADING212_API_SECRET", "env-secret")
+
+	creds, err := EnvCredentials{}.Credentials()
+	if err != nil {
+		t.Fatalf("Credentials() error = %v", err)
+	}
+	if creds.APIKey != "env-key" || creds.APISecret != "env-secret" {
+		t.Errorf("Credentials() = %+v, want env-key/env-secret", creds)
+	}
+
+	if _, err := (EnvCredentials{KeyVar: "T212_UNSET_KEY"}).Credentials(); err == nil {
+		t.Error("Expected error for unset key variable")
+	}
+}
+
+// TestFileCredentials tests loading credentials from a file with permission checks
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, []byte(`{"apiKey":"file-key"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := FileCredentials{Path: path}.Credentials()
+	if err != nil {
+		t.Fatalf("Credentials() error = %v", err)
+	}
+	if creds.APIKey != "file-key" {
+		t.Errorf("APIKey = %v, want file-key", creds.APIKey)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (FileCredentials{Path: path}).Credentials(); err == nil {
+		t.Error("Expected error for world-readable credentials file")
+	}
+}
+
+// TestCommandCredentials tests loading credentials from an external command
+func TestCommandCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	provider := CommandCredentials{Name: "sh", Args: []string{"-c", "printf 'cmd-key\\ncmd-secret\\nignored\\n'"}}
+	creds, err := provider.Credentials()
+	if err != nil {
+		t.Fatalf("Credentials() error = %v", err)
+	}
+	if creds.APIKey != "cmd-key" || creds.APISecret != "cmd-secret" {
+		t.Errorf("Credentials() = %+v, want cmd-key/cmd-secret", creds)
+	}
+
+	failing := CommandCredentials{Name: "sh", Args: []string{"-c", "exit 1"}}
+	if _, err := failing.Credentials(); err == nil {
+		t.Error("Expected error for failing command")
+	}
+}
+
+// TestEncryptedFileCredentials tests the encrypted credentials round trip
+func TestEncryptedFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	want := Credentials{APIKey: "enc-key", APISecret: "enc-secret"}
+
+	if err := WriteEncryptedCredentials(path, "correct horse", want); err != nil {
+		t.Fatalf("WriteEncryptedCredentials() error = %v", err)
+	}
+
+	creds, err := EncryptedFileCredentials{Path: path, Passphrase: "correct horse"}.Credentials()
+	if err != nil {
+		t.Fatalf("Credentials() error = %v", err)
+	}
+	if creds != want {
+		t.Errorf("Credentials() = %+v, want %+v", creds, want)
+	}
+
+	if _, err := (EncryptedFileCredentials{Path: path, Passphrase: "wrong"}).Credentials(); err == nil {
+		t.Error("Expected error for wrong passphrase")
+	}
+}
+
+// TestCredentialAuthRotation tests rotating credentials without recreating the client
+func TestCredentialAuthRotation(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		writeJSONResponse(t, w, CashInfo{})
+	}))
+	defer server.Close()
+
+	provider := &staticProvider{creds: Credentials{APIKey: "old-key"}}
+	client, err := NewClientFromProvider(provider, true)
+	if err != nil {
+		t.Fatalf("NewClientFromProvider() error = %v", err)
+	}
+	client.host = server.URL
+
+	if _, err := client.Cash(); err != nil {
+		t.Fatal(err)
+	}
+
+	provider.creds = Credentials{APIKey: "new-key"}
+	if err := client.auth.(*CredentialAuth).Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if _, err := client.Cash(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(seen) != 2 || seen[0] != "old-key" || seen[1] != "new-key" {
+		t.Errorf("Authorization headers = %v, want [old-key new-key]", seen)
+	}
+	if got := client.String(); got != "Trading212(api_key=****-key, demo=false)" {
+		t.Errorf("Client.String() = %v", got)
+	}
+}
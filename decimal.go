@@ -0,0 +1,352 @@
+package trading212
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DecimalPlaces is the number of fractional digits a Decimal holds
+const DecimalPlaces = 8
+
+// decimalScale is the number of units in one
+const decimalScale = 100000000
+
+var bigScale = big.NewInt(decimalScale)
+
+// RoundingMode selects how values are rounded to fewer decimal places
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest value, ties to the even neighbour
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to the nearest value, ties away from zero
+	RoundHalfUp
+	// RoundDown rounds towards zero
+	RoundDown
+	// RoundUp rounds away from zero
+	RoundUp
+	// RoundFloor rounds towards negative infinity
+	RoundFloor
+	// RoundCeiling rounds towards positive infinity
+	RoundCeiling
+)
+
+// Decimal is a fixed-point number with eight decimal places, used for prices,
+// cash and other amounts so that sums and differences do not drift. The zero
+// value is zero. Decimals are comparable with ==.
+type Decimal struct {
+	units int64
+}
+
+// NewDecimal returns value × 10^exp, e.g. NewDecimal(12345, -2) is 123.45
+func NewDecimal(value int64, exp int32) Decimal {
+	r := new(big.Rat).SetInt64(value)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(exp))), nil))
+	if exp < 0 {
+		r.Quo(r, scale)
+	} else {
+		r.Mul(r, scale)
+	}
+	d, err := decimalFromRat(r, RoundHalfEven)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewDecimalFromInt returns the integer as a Decimal
+func NewDecimalFromInt(value int64) Decimal {
+	return NewDecimal(value, 0)
+}
+
+// NewDecimalFromFloat converts a float using its shortest decimal
+// representation, so NewDecimalFromFloat(0.1) is exactly 0.1. It panics on
+// NaN, infinities and values too large to represent.
+func NewDecimalFromFloat(value float64) Decimal {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		panic(fmt.Sprintf("trading212: cannot convert %v to Decimal", value))
+	}
+	d, err := ParseDecimal(strconv.FormatFloat(value, 'g', -1, 64))
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// ParseDecimal parses a decimal number such as "-12.5" or "1.2e-3", rounding
+// half to even beyond eight decimal places
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/") {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return decimalFromRat(r, RoundHalfEven)
+}
+
+// MustParseDecimal parses a decimal number and panics if it is invalid
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// decimalFromRat rounds a rational number to eight decimal places
+func decimalFromRat(r *big.Rat, mode RoundingMode) (Decimal, error) {
+	num := new(big.Int).Mul(r.Num(), bigScale)
+	return decimalFromBig(divRound(num, r.Denom(), mode))
+}
+
+// decimalFromBig converts a number of units, failing when it overflows
+func decimalFromBig(units *big.Int) (Decimal, error) {
+	if !units.IsInt64() {
+		return Decimal{}, fmt.Errorf("decimal value out of range")
+	}
+	return Decimal{units: units.Int64()}, nil
+}
+
+// mustDecimal panics on overflow, matching the behaviour of integer arithmetic
+// that cannot return an error
+func mustDecimal(d Decimal, err error) Decimal {
+	if err != nil {
+		panic("trading212: " + err.Error())
+	}
+	return d
+}
+
+// divRound divides num by den, rounding the quotient with the given mode
+func divRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	sign := num.Sign() * den.Sign()
+	half := new(big.Int).Abs(remainder)
+	half.Lsh(half, 1)
+	cmp := half.Cmp(new(big.Int).Abs(den))
+
+	var increment bool
+	switch mode {
+	case RoundDown:
+		increment = false
+	case RoundUp:
+		increment = true
+	case RoundFloor:
+		increment = sign < 0
+	case RoundCeiling:
+		increment = sign > 0
+	case RoundHalfUp:
+		increment = cmp >= 0
+	default:
+		increment = cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1)
+	}
+
+	if increment {
+		quotient.Add(quotient, big.NewInt(int64(sign)))
+	}
+	return quotient
+}
+
+// Add returns d + other
+func (d Decimal) Add(other Decimal) Decimal {
+	sum := d.units + other.units
+	if (d.units > 0 && other.units > 0 && sum < 0) || (d.units < 0 && other.units < 0 && sum >= 0) {
+		panic("trading212: decimal overflow")
+	}
+	return Decimal{units: sum}
+}
+
+// Sub returns d - other
+func (d Decimal) Sub(other Decimal) Decimal {
+	return d.Add(other.Neg())
+}
+
+// Mul returns d × other, rounded half to even to eight decimal places
+func (d Decimal) Mul(other Decimal) Decimal {
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(other.units))
+	return mustDecimal(decimalFromBig(divRound(product, bigScale, RoundHalfEven)))
+}
+
+// MulInt returns d × n
+func (d Decimal) MulInt(n int64) Decimal {
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(n))
+	return mustDecimal(decimalFromBig(product))
+}
+
+// Div returns d ÷ other, rounded half to even to eight decimal places. It
+// panics when other is zero.
+func (d Decimal) Div(other Decimal) Decimal {
+	if other.units == 0 {
+		panic("trading212: decimal division by zero")
+	}
+	num := new(big.Int).Mul(big.NewInt(d.units), bigScale)
+	return mustDecimal(decimalFromBig(divRound(num, big.NewInt(other.units), RoundHalfEven)))
+}
+
+// MulDiv returns d × num ÷ den, rounded half to even to eight decimal places.
+// Unlike Mul followed by Div, the product is kept exact and only the result
+// is rounded, so it neither overflows nor loses precision part way. It
+// panics when den is zero.
+func (d Decimal) MulDiv(num, den Decimal) Decimal {
+	if den.units == 0 {
+		panic("trading212: decimal division by zero")
+	}
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(num.units))
+	return mustDecimal(decimalFromBig(divRound(product, big.NewInt(den.units), RoundHalfEven)))
+}
+
+// Round rounds d to the given number of decimal places. Negative places round
+// to tens, hundreds and so on.
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	if places >= DecimalPlaces {
+		return d
+	}
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(DecimalPlaces-places)), nil)
+	rounded := divRound(big.NewInt(d.units), factor, mode)
+	return mustDecimal(decimalFromBig(rounded.Mul(rounded, factor)))
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	if d.units == math.MinInt64 {
+		panic("trading212: decimal overflow")
+	}
+	return Decimal{units: -d.units}
+}
+
+// Abs returns the absolute value of d
+func (d Decimal) Abs() Decimal {
+	if d.units < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Sign returns -1, 0 or 1 according to the sign of d
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Cmp returns -1, 0 or 1 as d is less than, equal to or greater than other
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.units < other.units:
+		return -1
+	case d.units > other.units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether d is zero
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// Float64 returns the nearest float64
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String formats d without trailing zeros, e.g. "12.5" or "-3"
+func (d Decimal) String() string {
+	s := d.StringFixed(DecimalPlaces)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// StringFixed rounds d half to even and formats it with exactly the given
+// number of decimal places, e.g. StringFixed(2) gives "12.50"
+func (d Decimal) StringFixed(places int32) string {
+	places = min(max(places, 0), DecimalPlaces)
+	rounded := d.Round(places, RoundHalfEven)
+
+	sign := ""
+	units := uint64(rounded.units)
+	if rounded.units < 0 {
+		sign = "-"
+		units = uint64(-(rounded.units + 1)) + 1
+	}
+
+	integer := strconv.FormatUint(units/decimalScale, 10)
+	if places == 0 {
+		return sign + integer
+	}
+	fraction := fmt.Sprintf("%08d", units%decimalScale)
+	return sign + integer + "." + fraction[:places]
+}
+
+// MarshalJSON encodes d as a JSON number
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number, a quoted number or null
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText encodes d as text, for use in CSV, flags and map keys
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes d from text
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// SumDecimals returns the sum of values
+func SumDecimals(values ...Decimal) Decimal {
+	var total Decimal
+	for _, value := range values {
+		total = total.Add(value)
+	}
+	return total
+}
+
+// abs32 returns the absolute value of n
+func abs32(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,143 @@
+package trading212
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestParseDecimal tests parsing and canonical formatting
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "12.50", want: "12.5"},
+		{input: "-3", want: "-3"},
+		{input: "0.000000001", want: "0"},
+		{input: "0.000000015", want: "0.00000002"},
+		{input: "1.2e-3", want: "0.0012"},
+		{input: " 7 ", want: "7"},
+		{input: "", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "1/3", wantErr: true},
+		{input: "1e20", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDecimal(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDecimal(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestDecimalArithmetic tests that sums do not drift like float64
+func TestDecimalArithmetic(t *testing.T) {
+	var total Decimal
+	for i := 0; i < 10; i++ {
+		total = total.Add(MustParseDecimal("0.1"))
+	}
+	if total != NewDecimalFromInt(1) {
+		t.Errorf("sum of ten 0.1 = %s, want 1", total)
+	}
+
+	a, b := MustParseDecimal("19.99"), MustParseDecimal("3")
+	if got := a.Mul(b).String(); got != "59.97" {
+		t.Errorf("Mul() = %s", got)
+	}
+	if got := a.MulInt(-2).String(); got != "-39.98" {
+		t.Errorf("MulInt() = %s", got)
+	}
+	if got := a.Sub(b).String(); got != "16.99" {
+		t.Errorf("Sub() = %s", got)
+	}
+	if got := NewDecimalFromInt(1).Div(b).String(); got != "0.33333333" {
+		t.Errorf("Div() = %s", got)
+	}
+	// 200,000 × 1,000,000 overflows as an intermediate product of Mul
+	if got := NewDecimalFromInt(200000).MulDiv(NewDecimalFromInt(1000000), NewDecimalFromInt(2000000)).String(); got != "100000" {
+		t.Errorf("MulDiv() = %s", got)
+	}
+	if got := NewDecimalFromInt(10).MulDiv(NewDecimalFromInt(2), b).String(); got != "6.66666667" {
+		t.Errorf("MulDiv() = %s", got)
+	}
+	if got := NewDecimal(12345, -2).String(); got != "123.45" {
+		t.Errorf("NewDecimal() = %s", got)
+	}
+	if got := NewDecimalFromFloat(0.1).Add(NewDecimalFromFloat(0.2)).String(); got != "0.3" {
+		t.Errorf("NewDecimalFromFloat() sum = %s", got)
+	}
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 || a.Cmp(a) != 0 {
+		t.Error("Cmp() ordering is wrong")
+	}
+	if SumDecimals(a, b, a.Neg()) != b {
+		t.Error("SumDecimals() is wrong")
+	}
+}
+
+// TestDecimalRound tests each rounding mode on ties and negative values
+func TestDecimalRound(t *testing.T) {
+	tests := []struct {
+		value string
+		mode  RoundingMode
+		want  string
+	}{
+		{value: "2.345", mode: RoundHalfEven, want: "2.34"},
+		{value: "2.355", mode: RoundHalfEven, want: "2.36"},
+		{value: "2.345", mode: RoundHalfUp, want: "2.35"},
+		{value: "-2.345", mode: RoundHalfUp, want: "-2.35"},
+		{value: "2.349", mode: RoundDown, want: "2.34"},
+		{value: "-2.349", mode: RoundDown, want: "-2.34"},
+		{value: "2.341", mode: RoundUp, want: "2.35"},
+		{value: "-2.341", mode: RoundUp, want: "-2.35"},
+		{value: "-2.341", mode: RoundFloor, want: "-2.35"},
+		{value: "2.349", mode: RoundFloor, want: "2.34"},
+		{value: "-2.349", mode: RoundCeiling, want: "-2.34"},
+		{value: "2.341", mode: RoundCeiling, want: "2.35"},
+	}
+
+	for _, tt := range tests {
+		if got := MustParseDecimal(tt.value).Round(2, tt.mode).String(); got != tt.want {
+			t.Errorf("Round(%s, %d) = %s, want %s", tt.value, tt.mode, got, tt.want)
+		}
+	}
+
+	if got := MustParseDecimal("1250").Round(-2, RoundHalfEven).String(); got != "1200" {
+		t.Errorf("Round(-2) = %s, want 1200", got)
+	}
+	if got := MustParseDecimal("-0.5").StringFixed(2); got != "-0.50" {
+		t.Errorf("StringFixed(2) = %s, want -0.50", got)
+	}
+}
+
+// TestDecimalJSON tests decoding API numbers and encoding them back as numbers
+func TestDecimalJSON(t *testing.T) {
+	var decoded struct {
+		Number Decimal `json:"number"`
+		Quoted Decimal `json:"quoted"`
+		Null   Decimal `json:"null"`
+	}
+	if err := json.Unmarshal([]byte(`{"number": 1234.56, "quoted": "0.1", "null": null}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.Number != MustParseDecimal("1234.56") || decoded.Quoted != MustParseDecimal("0.1") || !decoded.Null.IsZero() {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"number":1234.56,"quoted":0.1,"null":0}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"number": true}`), &decoded); err == nil {
+		t.Error("Expected error for a boolean")
+	}
+}
@@ -3,13 +3,16 @@ package main
 import (
 	"fmt"
 	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/format"
+	"github.com/0xnu/trading212/fx"
 	"log"
 	"time"
 )
 
 // TradingDemoRunner handles all trading operations
 type TradingDemoRunner struct {
-	client *trading212.Client
+	client   *trading212.Client
+	currency string
 }
 
 // NewTradingDemoRunner creates a new demo runner instance
@@ -23,10 +26,10 @@ func NewTradingDemoRunner(apiKey string, isDemo bool) *TradingDemoRunner {
 func (t *TradingDemoRunner) Run() {
 	fmt.Println("Starting Trading212 Demo...")
 
+	t.fetchAndDisplayAccountInfo()
 	t.fetchAndDisplayOrders()
 	t.fetchAndDisplayCash()
 	t.fetchAndDisplayPortfolio()
-	t.fetchAndDisplayAccountInfo()
 	t.fetchAndDisplayInstruments()
 	t.fetchAndDisplayPies()
 	t.fetchAndDisplayDividends()
@@ -56,8 +59,8 @@ func (t *TradingDemoRunner) fetchAndDisplayCash() {
 		log.Printf("Error fetching cash: %v", err)
 		return
 	}
-	fmt.Printf("Free cash: £%.2f\n", cash.Free)
-	fmt.Printf("Total cash: £%.2f\n", cash.Total)
+	fmt.Printf("Free cash: %s\n", format.FormatDecimalMoney(cash.Free, t.accountCurrency()))
+	fmt.Printf("Total cash: %s\n", format.FormatDecimalMoney(cash.Total, t.accountCurrency()))
 }
 
 // fetchAndDisplayPortfolio retrieves and displays portfolio positions
@@ -70,9 +73,25 @@ func (t *TradingDemoRunner) fetchAndDisplayPortfolio() {
 	}
 
 	fmt.Printf("Portfolio has %d positions\n", len(portfolio))
+	currency := t.accountCurrency()
 	for _, position := range portfolio {
-		fmt.Printf("- %s: %.2f shares (£%.2f)\n", position.Ticker, position.Quantity, position.Value)
+		fmt.Printf("- %s: %.2f shares (%s)\n", position.Ticker, position.Quantity, format.FormatDecimalMoney(position.Value, currency))
+	}
+
+	instruments, err := t.client.InstrumentList()
+	if err != nil {
+		log.Printf("Error fetching instrument currencies: %v", err)
+		return
 	}
+	valuation, err := fx.ValuePortfolio(portfolio, instruments, currency, nil, nil, time.Now())
+	if err != nil {
+		fmt.Printf("Portfolio valuation needs exchange rates: %v\n", err)
+		return
+	}
+	fmt.Printf("Portfolio value: %s (P&L %s, of which FX %s)\n",
+		format.FormatDecimalMoney(valuation.Value, currency),
+		format.FormatDecimalMoney(valuation.PnL, currency),
+		format.FormatDecimalMoney(valuation.FXPnL, currency))
 }
 
 // fetchAndDisplayAccountInfo retrieves and displays account information
@@ -84,11 +103,21 @@ func (t *TradingDemoRunner) fetchAndDisplayAccountInfo() {
 		return
 	}
 
+	t.currency = accountInfo.CurrencyCode
 	fmt.Printf("Account ID: %d\n", accountInfo.ID)
 	fmt.Printf("Currency: %s\n", accountInfo.CurrencyCode)
 	fmt.Printf("Account Type: %s\n", accountInfo.Type)
 }
 
+// accountCurrency returns the account currency, or GBP when account info
+// could not be fetched
+func (t *TradingDemoRunner) accountCurrency() string {
+	if t.currency == "" {
+		return "GBP"
+	}
+	return t.currency
+}
+
 // fetchAndDisplayInstruments retrieves and displays available instruments
 func (t *TradingDemoRunner) fetchAndDisplayInstruments() {
 	fmt.Println("\nFetching instruments...")
@@ -129,7 +158,7 @@ func (t *TradingDemoRunner) fetchAndDisplayPies() {
 
 	fmt.Printf("Found %d pies\n", len(pies))
 	for _, pie := range pies {
-		fmt.Printf("- %s (ID: %d, Goal: £%d)\n", pie.Name, pie.ID, pie.Goal)
+		fmt.Printf("- %s (ID: %d, Goal: %s)\n", pie.Name, pie.ID, format.FormatDecimalMoney(trading212.NewDecimalFromInt(int64(pie.Goal)), t.accountCurrency()))
 	}
 }
 
@@ -172,7 +201,7 @@ func (t *TradingDemoRunner) requestCSVExport() {
 // placeTestOrder demonstrates placing a limit order
 func (t *TradingDemoRunner) placeTestOrder() {
 	fmt.Println("\nPlacing limit order...")
-	order, err := t.client.EquityOrderPlaceLimit("AAPL", 1, 150.00, "GTC")
+	order, err := t.client.EquityOrderPlaceLimit("AAPL", 1, trading212.MustParseDecimal("150.00"), "GTC")
 	if err != nil {
 		log.Printf("Error placing order: %v", err)
 		return
@@ -183,10 +212,10 @@ func (t *TradingDemoRunner) placeTestOrder() {
 // createTestPie demonstrates creating a new pie
 func (t *TradingDemoRunner) createTestPie() {
 	fmt.Println("\nCreating a demo pie...")
-	instrumentShares := map[string]float64{
-		"PLTR_US_EQ": 0.40, // Palantir Technologies - 40%
-		"AAPL_US_EQ": 0.30, // Apple Inc - 30%
-		"MSFT_US_EQ": 0.30, // Microsoft Corporation - 30%
+	instrumentShares := map[string]trading212.Decimal{
+		"PLTR_US_EQ": trading212.MustParseDecimal("0.40"), // Palantir Technologies - 40%
+		"AAPL_US_EQ": trading212.MustParseDecimal("0.30"), // Apple Inc - 30%
+		"MSFT_US_EQ": trading212.MustParseDecimal("0.30"), // Microsoft Corporation - 30%
 	}
 
 	endDate := time.Now().AddDate(1, 0, 0) // 1 year from now
@@ -199,6 +228,11 @@ func (t *TradingDemoRunner) createTestPie() {
 }
 
 func main() {
-	demoRunner := NewTradingDemoRunner("your_api_key", true)
+	client, err := trading212.NewClientFromProvider(trading212.EnvCredentials{}, true)
+	if err != nil {
+		log.Fatalf("Error loading credentials: %v", err)
+	}
+
+	demoRunner := &TradingDemoRunner{client: client}
 	demoRunner.Run()
 }
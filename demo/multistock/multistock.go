@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"math"
+	"math/big"
 	"strings"
 	"sync"
 	"time"
@@ -11,32 +12,32 @@ import (
 )
 
 type PriceData struct {
-	Price float64
-	High  float64
-	Low   float64
-	Close float64
+	Price trading212.Decimal
+	High  trading212.Decimal
+	Low   trading212.Decimal
+	Close trading212.Decimal
 }
 
 type TradingIndicators struct {
-	ATR        float64
-	UpperBand  float64
-	MiddleBand float64
-	LowerBand  float64
-	StdDev     float64
+	ATR        trading212.Decimal
+	UpperBand  trading212.Decimal
+	MiddleBand trading212.Decimal
+	LowerBand  trading212.Decimal
+	StdDev     trading212.Decimal
 }
 
 type StockData struct {
 	Ticker       string
 	PriceHistory []PriceData
 	Indicators   TradingIndicators
-	CurrentPrice float64
+	CurrentPrice trading212.Decimal
 	LastUpdate   time.Time
 }
 
 type TradingBot struct {
 	client          *trading212.Client
 	stocks          map[string]*StockData
-	riskPercent     float64
+	riskPercent     trading212.Decimal
 	atrPeriod       int
 	bollingerPeriod int
 	mutex           sync.RWMutex
@@ -45,7 +46,10 @@ type TradingBot struct {
 func main() {
 	log.Println("Starting Multi-Stock ATR + Bollinger Bands Trading Bot...")
 
-	client := trading212.NewClient("your_api_key", true) // true for demo; false for live
+	client, err := trading212.NewClientFromProvider(trading212.EnvCredentials{}, true) // true for demo; false for live
+	if err != nil {
+		log.Fatalf("Error loading credentials: %v", err)
+	}
 
 	// Define stock universe
 	tickers := []string{"NVDA", "PLTR", "TSLA", "AAPL", "GOOGL"}
@@ -53,7 +57,7 @@ func main() {
 	bot := &TradingBot{
 		client:          client,
 		stocks:          make(map[string]*StockData),
-		riskPercent:     0.4, // 0.4% per stock (2% total across 5 stocks)
+		riskPercent:     decimal("0.4"), // 0.4% per stock (2% total across 5 stocks)
 		atrPeriod:       14,
 		bollingerPeriod: 20,
 	}
@@ -78,7 +82,7 @@ func main() {
 			continue
 		}
 
-		log.Printf("Portfolio Value: £%.2f", portfolioValue)
+		log.Printf("Portfolio Value: £%s", portfolioValue.StringFixed(2))
 
 		// Update price data for all stocks
 		bot.updateAllPrices()
@@ -98,7 +102,7 @@ func main() {
 	}
 }
 
-func (bot *TradingBot) getPortfolioData() ([]trading212.Position, float64, error) {
+func (bot *TradingBot) getPortfolioData() ([]trading212.Position, trading212.Decimal, error) {
 	var positions []trading212.Position
 	var err error
 	maxRetries := 5
@@ -120,13 +124,13 @@ func (bot *TradingBot) getPortfolioData() ([]trading212.Position, float64, error
 	}
 
 	if err != nil {
-		return nil, 0, err
+		return nil, trading212.Decimal{}, err
 	}
 
 	// Calculate total portfolio value
-	portfolioValue := 0.0
+	var portfolioValue trading212.Decimal
 	for _, pos := range positions {
-		portfolioValue += pos.Value
+		portfolioValue = portfolioValue.Add(pos.Value)
 	}
 
 	return positions, portfolioValue, nil
@@ -138,7 +142,7 @@ func (bot *TradingBot) updateAllPrices() {
 
 	for ticker, stockData := range bot.stocks {
 		currentPrice := bot.getCurrentPrice(ticker)
-		if currentPrice == 0 {
+		if currentPrice.Sign() <= 0 {
 			log.Printf("Could not get price for %s", ticker)
 			continue
 		}
@@ -149,15 +153,15 @@ func (bot *TradingBot) updateAllPrices() {
 		// Add to price history (simplified OHLC approximation)
 		priceData := PriceData{
 			Price: currentPrice,
-			High:  currentPrice * 1.005,
-			Low:   currentPrice * 0.995,
+			High:  currentPrice.Mul(decimal("1.005")),
+			Low:   currentPrice.Mul(decimal("0.995")),
 			Close: currentPrice,
 		}
 
 		stockData.PriceHistory = append(stockData.PriceHistory, priceData)
 
 		// Keep only required history
-		maxHistory := int(math.Max(float64(bot.atrPeriod), float64(bot.bollingerPeriod)))
+		maxHistory := max(bot.atrPeriod, bot.bollingerPeriod)
 		if len(stockData.PriceHistory) > maxHistory {
 			stockData.PriceHistory = stockData.PriceHistory[len(stockData.PriceHistory)-maxHistory:]
 		}
@@ -169,26 +173,27 @@ func (bot *TradingBot) calculateAllIndicators() {
 	defer bot.mutex.Unlock()
 
 	for _, stockData := range bot.stocks {
-		maxHistory := int(math.Max(float64(bot.atrPeriod), float64(bot.bollingerPeriod)))
+		maxHistory := max(bot.atrPeriod, bot.bollingerPeriod)
 		if len(stockData.PriceHistory) >= maxHistory {
 			stockData.Indicators = bot.calculateIndicators(stockData.PriceHistory)
 		}
 	}
 }
 
-func (bot *TradingBot) executeTrading(ticker string, stockData *StockData, positions []trading212.Position, portfolioValue float64) {
+func (bot *TradingBot) executeTrading(ticker string, stockData *StockData, positions []trading212.Position, portfolioValue trading212.Decimal) {
 	bot.mutex.RLock()
 	indicators := stockData.Indicators
 	currentPrice := stockData.CurrentPrice
-	hasData := len(stockData.PriceHistory) >= int(math.Max(float64(bot.atrPeriod), float64(bot.bollingerPeriod)))
+	hasData := len(stockData.PriceHistory) >= max(bot.atrPeriod, bot.bollingerPeriod)
 	bot.mutex.RUnlock()
 
-	if !hasData || currentPrice == 0 {
+	if !hasData || currentPrice.Sign() <= 0 {
 		return
 	}
 
 	// Calculate position size for this stock
-	positionSize := int((portfolioValue * bot.riskPercent / 100) / currentPrice)
+	budget := portfolioValue.Mul(bot.riskPercent).Div(decimal("100"))
+	positionSize := int(budget.Div(currentPrice).Round(0, trading212.RoundDown).Float64())
 	if positionSize < 1 {
 		positionSize = 1
 	}
@@ -212,67 +217,74 @@ func (bot *TradingBot) executeTrading(ticker string, stockData *StockData, posit
 	}
 }
 
-func (bot *TradingBot) evaluateEntry(ticker string, currentPrice float64, indicators TradingIndicators, positionSize int) {
+func (bot *TradingBot) evaluateEntry(ticker string, currentPrice trading212.Decimal, indicators TradingIndicators, positionSize int) {
+	if indicators.MiddleBand.Sign() <= 0 {
+		return
+	}
+
 	// Entry conditions
-	touchesLowerBand := currentPrice <= indicators.LowerBand
-	lowVolatility := indicators.ATR < (currentPrice * 0.025) // ATR less than 2.5% of price
-	belowMiddleBand := currentPrice < indicators.MiddleBand
+	touchesLowerBand := currentPrice.Cmp(indicators.LowerBand) <= 0
+	lowVolatility := indicators.ATR.Cmp(currentPrice.Mul(decimal("0.025"))) < 0 // ATR less than 2.5% of price
+	belowMiddleBand := currentPrice.Cmp(indicators.MiddleBand) < 0
 
 	// Additional filters
-	bollingerWidth := (indicators.UpperBand - indicators.LowerBand) / indicators.MiddleBand
-	normalVolatility := bollingerWidth > 0.02 && bollingerWidth < 0.15 // 2% to 15% width
+	bollingerWidth := indicators.UpperBand.Sub(indicators.LowerBand).Div(indicators.MiddleBand)
+	normalVolatility := bollingerWidth.Cmp(decimal("0.02")) > 0 && bollingerWidth.Cmp(decimal("0.15")) < 0 // 2% to 15% width
 
 	if touchesLowerBand && lowVolatility && belowMiddleBand && normalVolatility {
-		stopLoss := currentPrice - (indicators.ATR * 1.5)
+		stopLoss := currentPrice.Sub(indicators.ATR.Mul(decimal("1.5")))
 
 		_, err := bot.client.EquityOrderPlaceMarket(ticker, positionSize)
 		if err != nil {
 			log.Printf("❌ BUY ERROR %s: %v", ticker, err)
 		} else {
-			log.Printf("🟢 BOUGHT %s: %d shares @ £%.2f | Stop: £%.2f | ATR: %.4f",
-				ticker, positionSize, currentPrice, stopLoss, indicators.ATR)
+			log.Printf("🟢 BOUGHT %s: %d shares @ £%s | Stop: £%s | ATR: %s",
+				ticker, positionSize, currentPrice.StringFixed(2), stopLoss.StringFixed(2), indicators.ATR.StringFixed(4))
 		}
 	} else {
-		log.Printf("📊 %s: £%.2f | BB(%.2f-%.2f) | ATR: %.4f | No Entry",
-			ticker, currentPrice, indicators.LowerBand, indicators.UpperBand, indicators.ATR)
+		log.Printf("📊 %s: £%s | BB(%s-%s) | ATR: %s | No Entry",
+			ticker, currentPrice.StringFixed(2), indicators.LowerBand.StringFixed(2), indicators.UpperBand.StringFixed(2), indicators.ATR.StringFixed(4))
 	}
 }
 
-func (bot *TradingBot) evaluateExit(ticker string, currentPrice float64, indicators TradingIndicators, position trading212.Position) {
-	entryPrice := position.Value / position.Quantity
-	stopLoss := entryPrice - (indicators.ATR * 1.5)
+func (bot *TradingBot) evaluateExit(ticker string, currentPrice trading212.Decimal, indicators TradingIndicators, position trading212.Position) {
+	entryPrice := averagePrice(position)
+	if entryPrice.Sign() <= 0 {
+		return
+	}
+	stopLoss := entryPrice.Sub(indicators.ATR.Mul(decimal("1.5")))
 
 	// Exit conditions
 	var shouldSell bool
 	var sellReason string
 
 	// Take profit at upper band
-	if currentPrice >= indicators.UpperBand {
+	if currentPrice.Cmp(indicators.UpperBand) >= 0 {
 		shouldSell = true
 		sellReason = "Take Profit (Upper Band)"
 	}
 
 	// ATR-based stop loss
-	if currentPrice <= stopLoss {
+	if currentPrice.Cmp(stopLoss) <= 0 {
 		shouldSell = true
 		sellReason = "Stop Loss (ATR)"
 	}
 
 	// Profit protection: if 5%+ profit and price drops below middle band
-	profitPercent := ((currentPrice - entryPrice) / entryPrice) * 100
-	if profitPercent >= 5 && currentPrice < indicators.MiddleBand {
+	profitPercent := currentPrice.Sub(entryPrice).Div(entryPrice).MulInt(100)
+	if profitPercent.Cmp(decimal("5")) >= 0 && currentPrice.Cmp(indicators.MiddleBand) < 0 {
 		shouldSell = true
 		sellReason = "Profit Protection"
 	}
 
 	// High volatility exit
-	if indicators.ATR > (entryPrice*0.04) && profitPercent > 0 {
+	if indicators.ATR.Cmp(entryPrice.Mul(decimal("0.04"))) > 0 && profitPercent.Sign() > 0 {
 		shouldSell = true
 		sellReason = "High Volatility Exit"
 	}
 
 	// Time-based exit (if holding for too long without profit)
-	if profitPercent < 0 && indicators.ATR < (entryPrice*0.01) {
+	if profitPercent.Sign() < 0 && indicators.ATR.Cmp(entryPrice.Mul(decimal("0.01"))) < 0 {
 		shouldSell = true
 		sellReason = "Low Volatility Cut"
 	}
@@ -282,64 +294,72 @@ func (bot *TradingBot) evaluateExit(ticker string, currentPrice float64, indicat
 		if err != nil {
 			log.Printf("❌ SELL ERROR %s: %v", ticker, err)
 		} else {
-			pnl := (currentPrice - entryPrice) * position.Quantity
-			log.Printf("🔴 SOLD %s: %d shares @ £%.2f | %s | P&L: £%.2f (%.1f%%)",
-				ticker, int(position.Quantity), currentPrice, sellReason, pnl, profitPercent)
+			pnl := currentPrice.Sub(entryPrice).Mul(trading212.NewDecimalFromFloat(position.Quantity))
+			log.Printf("🔴 SOLD %s: %d shares @ £%s | %s | P&L: £%s (%s%%)",
+				ticker, int(position.Quantity), currentPrice.StringFixed(2), sellReason, pnl.StringFixed(2), profitPercent.StringFixed(1))
 		}
 	} else {
-		log.Printf("🔵 HOLDING %s: Entry £%.2f | Current £%.2f | P&L: %.1f%% | Stop: £%.2f",
-			ticker, entryPrice, currentPrice, profitPercent, stopLoss)
+		log.Printf("🔵 HOLDING %s: Entry £%s | Current £%s | P&L: %s%% | Stop: £%s",
+			ticker, entryPrice.StringFixed(2), currentPrice.StringFixed(2), profitPercent.StringFixed(1), stopLoss.StringFixed(2))
 	}
 }
 
 func (bot *TradingBot) displayPortfolioSummary(positions []trading212.Position) {
 	log.Println("=== Portfolio Summary ===")
-	totalValue := 0.0
-	totalPnL := 0.0
+	var totalValue, totalPnL trading212.Decimal
 
 	for _, pos := range positions {
 		if _, exists := bot.stocks[pos.Ticker]; exists {
-			entryPrice := pos.Value / pos.Quantity
+			entryPrice := averagePrice(pos)
 			bot.mutex.RLock()
 			currentPrice := bot.stocks[pos.Ticker].CurrentPrice
 			bot.mutex.RUnlock()
 
-			if currentPrice > 0 {
-				pnl := (currentPrice - entryPrice) * pos.Quantity
-				pnlPercent := ((currentPrice - entryPrice) / entryPrice) * 100
-				totalValue += pos.Value
-				totalPnL += pnl
+			if currentPrice.Sign() > 0 && entryPrice.Sign() > 0 {
+				pnl := currentPrice.Sub(entryPrice).Mul(trading212.NewDecimalFromFloat(pos.Quantity))
+				pnlPercent := currentPrice.Sub(entryPrice).Div(entryPrice).MulInt(100)
+				totalValue = totalValue.Add(pos.Value)
+				totalPnL = totalPnL.Add(pnl)
 
-				log.Printf("%s: %d shares | Entry: £%.2f | Current: £%.2f | P&L: £%.2f (%.1f%%)",
-					pos.Ticker, int(pos.Quantity), entryPrice, currentPrice, pnl, pnlPercent)
+				log.Printf("%s: %d shares | Entry: £%s | Current: £%s | P&L: £%s (%s%%)",
+					pos.Ticker, int(pos.Quantity), entryPrice.StringFixed(2), currentPrice.StringFixed(2), pnl.StringFixed(2), pnlPercent.StringFixed(1))
 			}
 		}
 	}
 
-	log.Printf("Total Portfolio Value: £%.2f | Total P&L: £%.2f", totalValue, totalPnL)
+	log.Printf("Total Portfolio Value: £%s | Total P&L: £%s", totalValue.StringFixed(2), totalPnL.StringFixed(2))
 	log.Println("========================")
 }
 
-func (bot *TradingBot) getCurrentPrice(ticker string) float64 {
+func (bot *TradingBot) getCurrentPrice(ticker string) trading212.Decimal {
 	positions, err := bot.client.Portfolio()
 	if err != nil {
 		log.Printf("Portfolio error for %s: %v", ticker, err)
-		return 0
+		return trading212.Decimal{}
 	}
 
 	for _, pos := range positions {
 		if pos.Ticker == ticker {
-			return pos.Value / pos.Quantity
+			return averagePrice(pos)
 		}
 	}
 
 	// If no position exists, return simulated price
 	// In production, you'd use market data API
-	return 0
+	return trading212.Decimal{}
+}
+
+// averagePrice returns the value of a position per share, or zero when it
+// holds no shares
+func averagePrice(position trading212.Position) trading212.Decimal {
+	if position.Quantity == 0 {
+		return trading212.Decimal{}
+	}
+	return position.Value.Div(trading212.NewDecimalFromFloat(position.Quantity))
 }
 
 func (bot *TradingBot) calculateIndicators(priceHistory []PriceData) TradingIndicators {
-	if len(priceHistory) < int(math.Max(float64(bot.atrPeriod), float64(bot.bollingerPeriod))) {
+	if len(priceHistory) < max(bot.atrPeriod, bot.bollingerPeriod) {
 		return TradingIndicators{}
 	}
 
@@ -355,60 +375,80 @@ func (bot *TradingBot) calculateIndicators(priceHistory []PriceData) TradingIndi
 	}
 }
 
-func (bot *TradingBot) calculateATR(priceHistory []PriceData) float64 {
+func (bot *TradingBot) calculateATR(priceHistory []PriceData) trading212.Decimal {
 	if len(priceHistory) < bot.atrPeriod+1 {
-		return 0
+		return trading212.Decimal{}
 	}
 
-	var trueRanges []float64
+	var trueRanges []trading212.Decimal
 
 	for i := 1; i < len(priceHistory); i++ {
 		current := priceHistory[i]
 		previous := priceHistory[i-1]
 
-		tr1 := current.High - current.Low
-		tr2 := math.Abs(current.High - previous.Close)
-		tr3 := math.Abs(current.Low - previous.Close)
+		tr1 := current.High.Sub(current.Low)
+		tr2 := current.High.Sub(previous.Close).Abs()
+		tr3 := current.Low.Sub(previous.Close).Abs()
 
-		trueRange := math.Max(tr1, math.Max(tr2, tr3))
+		trueRange := maxDecimal(tr1, maxDecimal(tr2, tr3))
 		trueRanges = append(trueRanges, trueRange)
 	}
 
 	if len(trueRanges) < bot.atrPeriod {
-		return 0
+		return trading212.Decimal{}
 	}
 
-	sum := 0.0
+	var sum trading212.Decimal
 	start := len(trueRanges) - bot.atrPeriod
 	for i := start; i < len(trueRanges); i++ {
-		sum += trueRanges[i]
+		sum = sum.Add(trueRanges[i])
 	}
 
-	return sum / float64(bot.atrPeriod)
+	return sum.Div(trading212.NewDecimalFromInt(int64(bot.atrPeriod)))
 }
 
-func (bot *TradingBot) calculateBollingerBands(priceHistory []PriceData) (float64, float64, float64, float64) {
+func (bot *TradingBot) calculateBollingerBands(priceHistory []PriceData) (trading212.Decimal, trading212.Decimal, trading212.Decimal, trading212.Decimal) {
 	if len(priceHistory) < bot.bollingerPeriod {
-		return 0, 0, 0, 0
+		return trading212.Decimal{}, trading212.Decimal{}, trading212.Decimal{}, trading212.Decimal{}
 	}
 
-	sum := 0.0
+	period := trading212.NewDecimalFromInt(int64(bot.bollingerPeriod))
+	var sum trading212.Decimal
 	start := len(priceHistory) - bot.bollingerPeriod
 	for i := start; i < len(priceHistory); i++ {
-		sum += priceHistory[i].Close
+		sum = sum.Add(priceHistory[i].Close)
 	}
-	sma := sum / float64(bot.bollingerPeriod)
+	sma := sum.Div(period)
 
-	variance := 0.0
+	var variance trading212.Decimal
 	for i := start; i < len(priceHistory); i++ {
-		diff := priceHistory[i].Close - sma
-		variance += diff * diff
+		diff := priceHistory[i].Close.Sub(sma)
+		variance = variance.Add(diff.Mul(diff))
 	}
-	variance /= float64(bot.bollingerPeriod)
-	stdDev := math.Sqrt(variance)
+	stdDev := sqrt(variance.Div(period))
 
-	upperBand := sma + (2 * stdDev)
-	lowerBand := sma - (2 * stdDev)
+	upperBand := sma.Add(stdDev.MulInt(2))
+	lowerBand := sma.Sub(stdDev.MulInt(2))
 
 	return upperBand, sma, lowerBand, stdDev
 }
+
+// maxDecimal returns the larger of a and b
+func maxDecimal(a, b trading212.Decimal) trading212.Decimal {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// sqrt returns the square root of a non-negative value to the eight decimal
+// places a Decimal holds
+func sqrt(value trading212.Decimal) trading212.Decimal {
+	f, _ := new(big.Float).SetPrec(128).SetString(value.String())
+	return decimal(new(big.Float).Sqrt(f).Text('f', 8))
+}
+
+// decimal parses a constant price, ratio or amount
+func decimal(value string) trading212.Decimal {
+	return trading212.MustParseDecimal(value)
+}
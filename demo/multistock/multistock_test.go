@@ -4,49 +4,65 @@ import (
 	"math"
 	"testing"
 	"time"
+
+	"github.com/0xnu/trading212"
 )
 
+// bar returns price data with the given high, low and close
+func bar(high, low, close int64) PriceData {
+	return PriceData{
+		High:  trading212.NewDecimalFromInt(high),
+		Low:   trading212.NewDecimalFromInt(low),
+		Close: trading212.NewDecimalFromInt(close),
+	}
+}
+
+// closing returns price data with only a close
+func closing(close int64) PriceData {
+	return PriceData{Close: trading212.NewDecimalFromInt(close)}
+}
+
 func TestPriceDataStruct(t *testing.T) {
 	price := PriceData{
-		Price: 100.0,
-		High:  102.0,
-		Low:   98.0,
-		Close: 100.5,
+		Price: decimal("100.0"),
+		High:  decimal("102.0"),
+		Low:   decimal("98.0"),
+		Close: decimal("100.5"),
 	}
 
-	if price.Price != 100.0 {
-		t.Errorf("Expected price 100.0, got %f", price.Price)
+	if price.Price.String() != "100" {
+		t.Errorf("Expected price 100, got %s", price.Price)
 	}
-	if price.High != 102.0 {
-		t.Errorf("Expected high 102.0, got %f", price.High)
+	if price.High.String() != "102" {
+		t.Errorf("Expected high 102, got %s", price.High)
 	}
-	if price.Low != 98.0 {
-		t.Errorf("Expected low 98.0, got %f", price.Low)
+	if price.Low.String() != "98" {
+		t.Errorf("Expected low 98, got %s", price.Low)
 	}
-	if price.Close != 100.5 {
-		t.Errorf("Expected close 100.5, got %f", price.Close)
+	if price.Close.String() != "100.5" {
+		t.Errorf("Expected close 100.5, got %s", price.Close)
 	}
 }
 
 func TestTradingIndicatorsStruct(t *testing.T) {
 	indicators := TradingIndicators{
-		ATR:        2.5,
-		UpperBand:  105.0,
-		MiddleBand: 100.0,
-		LowerBand:  95.0,
-		StdDev:     2.5,
+		ATR:        decimal("2.5"),
+		UpperBand:  decimal("105.0"),
+		MiddleBand: decimal("100.0"),
+		LowerBand:  decimal("95.0"),
+		StdDev:     decimal("2.5"),
 	}
 
-	if indicators.UpperBand <= indicators.MiddleBand {
+	if indicators.UpperBand.Cmp(indicators.MiddleBand) <= 0 {
 		t.Error("Upper band should be greater than middle band")
 	}
-	if indicators.LowerBand >= indicators.MiddleBand {
+	if indicators.LowerBand.Cmp(indicators.MiddleBand) >= 0 {
 		t.Error("Lower band should be less than middle band")
 	}
-	if indicators.ATR <= 0 {
+	if indicators.ATR.Sign() <= 0 {
 		t.Error("ATR should be positive")
 	}
-	if indicators.StdDev <= 0 {
+	if indicators.StdDev.Sign() <= 0 {
 		t.Error("Standard deviation should be positive")
 	}
 }
@@ -55,7 +71,7 @@ func TestStockDataStruct(t *testing.T) {
 	stockData := &StockData{
 		Ticker:       "TEST",
 		PriceHistory: make([]PriceData, 0),
-		CurrentPrice: 100.0,
+		CurrentPrice: decimal("100.0"),
 		LastUpdate:   time.Now(),
 	}
 
@@ -65,15 +81,15 @@ func TestStockDataStruct(t *testing.T) {
 	if stockData.PriceHistory == nil {
 		t.Error("Price history should not be nil")
 	}
-	if stockData.CurrentPrice != 100.0 {
-		t.Errorf("Expected current price 100.0, got %f", stockData.CurrentPrice)
+	if stockData.CurrentPrice.String() != "100" {
+		t.Errorf("Expected current price 100, got %s", stockData.CurrentPrice)
 	}
 }
 
 func TestTradingBotStruct(t *testing.T) {
 	bot := &TradingBot{
 		stocks:          make(map[string]*StockData),
-		riskPercent:     1.0,
+		riskPercent:     decimal("1.0"),
 		atrPeriod:       14,
 		bollingerPeriod: 20,
 	}
@@ -81,8 +97,8 @@ func TestTradingBotStruct(t *testing.T) {
 	if bot.stocks == nil {
 		t.Error("Stocks map should not be nil")
 	}
-	if bot.riskPercent != 1.0 {
-		t.Errorf("Expected risk percent 1.0, got %f", bot.riskPercent)
+	if bot.riskPercent.String() != "1" {
+		t.Errorf("Expected risk percent 1, got %s", bot.riskPercent)
 	}
 	if bot.atrPeriod != 14 {
 		t.Errorf("Expected ATR period 14, got %d", bot.atrPeriod)
@@ -96,65 +112,51 @@ func TestCalculateATR(t *testing.T) {
 	bot := &TradingBot{atrPeriod: 3}
 
 	priceHistory := []PriceData{
-		{High: 102, Low: 98, Close: 100},
-		{High: 104, Low: 99, Close: 103},
-		{High: 105, Low: 101, Close: 102},
-		{High: 103, Low: 99, Close: 101},
+		bar(102, 98, 100),
+		bar(104, 99, 103),
+		bar(105, 101, 102),
+		bar(103, 99, 101),
 	}
 
+	// True ranges are 5, 4 and 4
 	atr := bot.calculateATR(priceHistory)
-	if atr <= 0 {
-		t.Error("ATR should be positive")
+	if atr.String() != "4.33333333" {
+		t.Errorf("Expected ATR 4.33333333, got %s", atr)
 	}
 
 	// Test with insufficient data
-	shortHistory := []PriceData{{High: 100, Low: 98, Close: 99}}
+	shortHistory := []PriceData{bar(100, 98, 99)}
 	atr = bot.calculateATR(shortHistory)
-	if atr != 0 {
+	if !atr.IsZero() {
 		t.Error("ATR should be 0 with insufficient data")
 	}
-
-	// Test edge case - exactly minimum data
-	minHistory := []PriceData{
-		{High: 102, Low: 98, Close: 100},
-		{High: 104, Low: 99, Close: 103},
-		{High: 105, Low: 101, Close: 102},
-		{High: 103, Low: 99, Close: 101},
-	}
-	atr = bot.calculateATR(minHistory)
-	if atr <= 0 {
-		t.Error("ATR should be positive with minimum required data")
-	}
 }
 
 func TestCalculateBollingerBands(t *testing.T) {
 	bot := &TradingBot{bollingerPeriod: 3}
 
-	priceHistory := []PriceData{
-		{Close: 100},
-		{Close: 102},
-		{Close: 98},
-	}
+	priceHistory := []PriceData{closing(100), closing(102), closing(98)}
 
 	upper, middle, lower, stdDev := bot.calculateBollingerBands(priceHistory)
 
-	expectedMiddle := 100.0 // (100 + 102 + 98) / 3
-	if middle != expectedMiddle {
-		t.Errorf("Expected middle band %f, got %f", expectedMiddle, middle)
+	expectedMiddle := "100" // (100 + 102 + 98) / 3
+	if middle.String() != expectedMiddle {
+		t.Errorf("Expected middle band %s, got %s", expectedMiddle, middle)
 	}
 
-	if stdDev <= 0 {
-		t.Error("Standard deviation should be positive")
+	// The variance is 8/3, so the standard deviation is its square root
+	if stdDev.String() != "1.63299316" {
+		t.Errorf("Expected standard deviation 1.63299316, got %s", stdDev)
 	}
 
-	if upper <= middle || lower >= middle {
+	if upper.Cmp(middle) <= 0 || lower.Cmp(middle) >= 0 {
 		t.Error("Upper band should be above middle, lower band below middle")
 	}
 
 	// Test with insufficient data
-	shortHistory := []PriceData{{Close: 100}}
+	shortHistory := []PriceData{closing(100)}
 	upper, middle, lower, stdDev = bot.calculateBollingerBands(shortHistory)
-	if upper != 0 || middle != 0 || lower != 0 || stdDev != 0 {
+	if !upper.IsZero() || !middle.IsZero() || !lower.IsZero() || !stdDev.IsZero() {
 		t.Error("All values should be 0 with insufficient data")
 	}
 }
@@ -167,35 +169,30 @@ func TestCalculateIndicators(t *testing.T) {
 
 	// Create sufficient price history
 	priceHistory := make([]PriceData, 25)
-	basePrice := 100.0
 	for i := 0; i < 25; i++ {
-		price := basePrice + float64(i%5-2) // Creates some variation
-		priceHistory[i] = PriceData{
-			High:  price + 1,
-			Low:   price - 1,
-			Close: price,
-		}
+		price := int64(100 + i%5 - 2) // Creates some variation
+		priceHistory[i] = bar(price+1, price-1, price)
 	}
 
 	indicators := bot.calculateIndicators(priceHistory)
 
-	if indicators.ATR <= 0 {
+	if indicators.ATR.Sign() <= 0 {
 		t.Error("ATR should be positive")
 	}
-	if indicators.MiddleBand <= 0 {
+	if indicators.MiddleBand.Sign() <= 0 {
 		t.Error("Middle band should be positive")
 	}
-	if indicators.UpperBand <= indicators.MiddleBand {
+	if indicators.UpperBand.Cmp(indicators.MiddleBand) <= 0 {
 		t.Error("Upper band should be above middle band")
 	}
-	if indicators.LowerBand >= indicators.MiddleBand {
+	if indicators.LowerBand.Cmp(indicators.MiddleBand) >= 0 {
 		t.Error("Lower band should be below middle band")
 	}
 
 	// Test with insufficient data
-	shortHistory := []PriceData{{High: 100, Low: 98, Close: 99}}
+	shortHistory := []PriceData{bar(100, 98, 99)}
 	indicators = bot.calculateIndicators(shortHistory)
-	if indicators.ATR != 0 || indicators.MiddleBand != 0 {
+	if !indicators.ATR.IsZero() || !indicators.MiddleBand.IsZero() {
 		t.Error("Indicators should be zero with insufficient data")
 	}
 }
@@ -236,9 +233,10 @@ func TestPriceHistoryManagement(t *testing.T) {
 
 	// Add more than max history
 	for i := 0; i < 25; i++ {
+		price := trading212.NewDecimalFromInt(int64(100 + i))
 		priceData := PriceData{
-			Price: float64(100 + i),
-			Close: float64(100 + i),
+			Price: price,
+			Close: price,
 		}
 		stockData.PriceHistory = append(stockData.PriceHistory, priceData)
 
@@ -254,9 +252,9 @@ func TestPriceHistoryManagement(t *testing.T) {
 
 	// Check that latest prices are kept
 	latestPrice := stockData.PriceHistory[len(stockData.PriceHistory)-1].Price
-	expectedLatest := 124.0 // 100 + 24
-	if latestPrice != expectedLatest {
-		t.Errorf("Expected latest price %f, got %f", expectedLatest, latestPrice)
+	expectedLatest := "124" // 100 + 24
+	if latestPrice.String() != expectedLatest {
+		t.Errorf("Expected latest price %s, got %s", expectedLatest, latestPrice)
 	}
 }
 
@@ -265,25 +263,25 @@ func TestATRCalculationEdgeCases(t *testing.T) {
 
 	// Test with identical prices (no volatility)
 	stablePrices := []PriceData{
-		{High: 100, Low: 100, Close: 100},
-		{High: 100, Low: 100, Close: 100},
-		{High: 100, Low: 100, Close: 100},
+		bar(100, 100, 100),
+		bar(100, 100, 100),
+		bar(100, 100, 100),
 	}
 
 	atr := bot.calculateATR(stablePrices)
-	if atr != 0 {
-		t.Errorf("Expected ATR 0 for stable prices, got %f", atr)
+	if !atr.IsZero() {
+		t.Errorf("Expected ATR 0 for stable prices, got %s", atr)
 	}
 
 	// Test with extreme volatility
 	volatilePrices := []PriceData{
-		{High: 100, Low: 90, Close: 95},
-		{High: 110, Low: 95, Close: 105},
-		{High: 120, Low: 100, Close: 110},
+		bar(100, 90, 95),
+		bar(110, 95, 105),
+		bar(120, 100, 110),
 	}
 
 	atr = bot.calculateATR(volatilePrices)
-	if atr <= 0 {
+	if atr.Sign() <= 0 {
 		t.Error("ATR should be positive for volatile prices")
 	}
 }
@@ -292,37 +290,40 @@ func TestBollingerBandsEdgeCases(t *testing.T) {
 	bot := &TradingBot{bollingerPeriod: 3}
 
 	// Test with identical prices
-	stablePrices := []PriceData{
-		{Close: 100},
-		{Close: 100},
-		{Close: 100},
-	}
+	stablePrices := []PriceData{closing(100), closing(100), closing(100)}
 
 	upper, middle, lower, stdDev := bot.calculateBollingerBands(stablePrices)
 
-	if middle != 100.0 {
-		t.Errorf("Expected middle band 100.0, got %f", middle)
+	if middle.String() != "100" {
+		t.Errorf("Expected middle band 100, got %s", middle)
 	}
-	if stdDev != 0 {
-		t.Errorf("Expected stdDev 0 for identical prices, got %f", stdDev)
+	if !stdDev.IsZero() {
+		t.Errorf("Expected stdDev 0 for identical prices, got %s", stdDev)
 	}
 	if upper != middle || lower != middle {
 		t.Error("Upper and lower bands should equal middle band when stdDev is 0")
 	}
 }
 
+func TestAveragePrice(t *testing.T) {
+	position := trading212.Position{Quantity: 4, Value: decimal("410.20")}
+	if price := averagePrice(position); price.String() != "102.55" {
+		t.Errorf("Expected average price 102.55, got %s", price)
+	}
+
+	if price := averagePrice(trading212.Position{Value: decimal("10")}); !price.IsZero() {
+		t.Errorf("Expected 0 for a position with no shares, got %s", price)
+	}
+}
+
 // Benchmark tests
 func BenchmarkCalculateATR(b *testing.B) {
 	bot := &TradingBot{atrPeriod: 14}
 
 	priceHistory := make([]PriceData, 100)
 	for i := 0; i < 100; i++ {
-		price := 100.0 + float64(i%10)
-		priceHistory[i] = PriceData{
-			High:  price + 1,
-			Low:   price - 1,
-			Close: price,
-		}
+		price := int64(100 + i%10)
+		priceHistory[i] = bar(price+1, price-1, price)
 	}
 
 	b.ResetTimer()
@@ -336,8 +337,7 @@ func BenchmarkCalculateBollingerBands(b *testing.B) {
 
 	priceHistory := make([]PriceData, 100)
 	for i := 0; i < 100; i++ {
-		price := 100.0 + float64(i%10)
-		priceHistory[i] = PriceData{Close: price}
+		priceHistory[i] = closing(int64(100 + i%10))
 	}
 
 	b.ResetTimer()
@@ -354,10 +354,10 @@ func BenchmarkCalculateIndicators(b *testing.B) {
 
 	priceHistory := make([]PriceData, 50)
 	for i := 0; i < 50; i++ {
-		price := 100.0 + math.Sin(float64(i)*0.1)*10 // Sine wave price movement
+		price := trading212.NewDecimalFromFloat(100 + math.Sin(float64(i)*0.1)*10) // Sine wave price movement
 		priceHistory[i] = PriceData{
-			High:  price + 1,
-			Low:   price - 1,
+			High:  price.Add(decimal("1")),
+			Low:   price.Sub(decimal("1")),
 			Close: price,
 		}
 	}
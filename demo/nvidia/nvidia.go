@@ -11,7 +11,7 @@ import (
 type TradingBot struct {
 	client      *trading212.Client
 	ticker      string
-	riskPercent float64
+	riskPercent trading212.Decimal
 }
 
 type RetryConfig struct {
@@ -19,7 +19,7 @@ type RetryConfig struct {
 	baseDelay  time.Duration
 }
 
-func NewTradingBot(apiKey string, isDemo bool, ticker string, riskPercent float64) *TradingBot {
+func NewTradingBot(apiKey string, isDemo bool, ticker string, riskPercent trading212.Decimal) *TradingBot {
 	return &TradingBot{
 		client:      trading212.NewClient(apiKey, isDemo),
 		ticker:      ticker,
@@ -30,7 +30,12 @@ func NewTradingBot(apiKey string, isDemo bool, ticker string, riskPercent float6
 func main() {
 	log.Println("Starting trading bot...")
 
-	bot := NewTradingBot("your_api_key", true, "NVDA", 1.0)
+	client, err := trading212.NewClientFromProvider(trading212.EnvCredentials{}, true)
+	if err != nil {
+		log.Fatalf("Error loading credentials: %v", err)
+	}
+
+	bot := &TradingBot{client: client, ticker: "NVDA", riskPercent: trading212.NewDecimalFromInt(1)}
 	bot.Run()
 }
 
@@ -57,7 +62,7 @@ func (bot *TradingBot) executeTradeLogic() bool {
 	}
 
 	currentPrice := bot.getCurrentPrice()
-	if currentPrice == 0 {
+	if currentPrice.Sign() <= 0 {
 		log.Println("Failed to get current price")
 		return false
 	}
@@ -104,41 +109,42 @@ func (bot *TradingBot) shouldRetry(err error, attempt int, config RetryConfig) b
 	return true
 }
 
-func (bot *TradingBot) getCurrentPrice() float64 {
+func (bot *TradingBot) getCurrentPrice() trading212.Decimal {
 	positions, err := bot.client.Portfolio()
 	if err != nil {
 		log.Printf("Portfolio error: %v", err)
-		return 0
+		return trading212.Decimal{}
 	}
 
 	log.Printf("Got %d positions", len(positions))
 
 	for _, pos := range positions {
-		if pos.Ticker == bot.ticker {
-			return pos.Value / pos.Quantity
+		if pos.Ticker == bot.ticker && pos.Quantity != 0 {
+			return pos.Value.Div(trading212.NewDecimalFromFloat(pos.Quantity))
 		}
 	}
 
-	return 0
+	return trading212.Decimal{}
 }
 
-func (bot *TradingBot) calculateBollingerBands(currentPrice float64) (float64, float64) {
-	stdDev := currentPrice * 0.02 // 2% of price as approximation
-	upperBand := currentPrice + (2 * stdDev)
-	lowerBand := currentPrice - (2 * stdDev)
+func (bot *TradingBot) calculateBollingerBands(currentPrice trading212.Decimal) (trading212.Decimal, trading212.Decimal) {
+	stdDev := currentPrice.Mul(trading212.MustParseDecimal("0.02")) // 2% of price as approximation
+	upperBand := currentPrice.Add(stdDev.MulInt(2))
+	lowerBand := currentPrice.Sub(stdDev.MulInt(2))
 	return upperBand, lowerBand
 }
 
-func (bot *TradingBot) calculatePortfolioValue(positions []trading212.Position) float64 {
-	portfolioValue := 0.0
+func (bot *TradingBot) calculatePortfolioValue(positions []trading212.Position) trading212.Decimal {
+	var portfolioValue trading212.Decimal
 	for _, pos := range positions {
-		portfolioValue += pos.Value
+		portfolioValue = portfolioValue.Add(pos.Value)
 	}
 	return portfolioValue
 }
 
-func (bot *TradingBot) calculatePositionSize(portfolioValue, currentPrice float64) int {
-	positionSize := int((portfolioValue * bot.riskPercent / 100) / currentPrice)
+func (bot *TradingBot) calculatePositionSize(portfolioValue, currentPrice trading212.Decimal) int {
+	budget := portfolioValue.Mul(bot.riskPercent).Div(trading212.NewDecimalFromInt(100))
+	positionSize := int(budget.Div(currentPrice).Round(0, trading212.RoundDown).Float64())
 	if positionSize < 1 {
 		positionSize = 1
 	}
@@ -154,7 +160,7 @@ func (bot *TradingBot) hasExistingPosition(positions []trading212.Position) bool
 	return false
 }
 
-func (bot *TradingBot) executeTrade(hasPosition bool, currentPrice, upperBand, lowerBand float64, positionSize int) bool {
+func (bot *TradingBot) executeTrade(hasPosition bool, currentPrice, upperBand, lowerBand trading212.Decimal, positionSize int) bool {
 	if bot.shouldBuy(hasPosition, currentPrice, lowerBand) {
 		return bot.placeBuyOrder(positionSize, currentPrice)
 	}
@@ -166,32 +172,32 @@ func (bot *TradingBot) executeTrade(hasPosition bool, currentPrice, upperBand, l
 	return true
 }
 
-func (bot *TradingBot) shouldBuy(hasPosition bool, currentPrice, lowerBand float64) bool {
-	return !hasPosition && currentPrice < lowerBand
+func (bot *TradingBot) shouldBuy(hasPosition bool, currentPrice, lowerBand trading212.Decimal) bool {
+	return !hasPosition && currentPrice.Cmp(lowerBand) < 0
 }
 
-func (bot *TradingBot) shouldSell(hasPosition bool, currentPrice, upperBand float64) bool {
-	return hasPosition && currentPrice > upperBand
+func (bot *TradingBot) shouldSell(hasPosition bool, currentPrice, upperBand trading212.Decimal) bool {
+	return hasPosition && currentPrice.Cmp(upperBand) > 0
 }
 
-func (bot *TradingBot) placeBuyOrder(positionSize int, currentPrice float64) bool {
+func (bot *TradingBot) placeBuyOrder(positionSize int, currentPrice trading212.Decimal) bool {
 	_, err := bot.client.EquityOrderPlaceMarket(bot.ticker, positionSize)
 	if err != nil {
 		log.Printf("Buy error: %v", err)
 		return false
 	}
 
-	log.Printf("Bought %d %s @ %.2f (Bollinger Band strategy)", positionSize, bot.ticker, currentPrice)
+	log.Printf("Bought %d %s @ %s (Bollinger Band strategy)", positionSize, bot.ticker, currentPrice.StringFixed(2))
 	return true
 }
 
-func (bot *TradingBot) placeSellOrder(positionSize int, currentPrice float64) bool {
+func (bot *TradingBot) placeSellOrder(positionSize int, currentPrice trading212.Decimal) bool {
 	_, err := bot.client.EquityOrderPlaceMarket(bot.ticker, -positionSize)
 	if err != nil {
 		log.Printf("Sell error: %v", err)
 		return false
 	}
 
-	log.Printf("Sold %d %s @ %.2f (Bollinger Band strategy)", positionSize, bot.ticker, currentPrice)
+	log.Printf("Sold %d %s @ %s (Bollinger Band strategy)", positionSize, bot.ticker, currentPrice.StringFixed(2))
 	return true
 }
@@ -8,13 +8,13 @@ import (
 )
 
 func TestNewTradingBot(t *testing.T) {
-	bot := NewTradingBot("test_key", true, "NVDA", 1.0)
+	bot := NewTradingBot("test_key", true, "NVDA", trading212.NewDecimalFromInt(1))
 
 	if bot.ticker != "NVDA" {
 		t.Errorf("Expected ticker NVDA, got %s", bot.ticker)
 	}
-	if bot.riskPercent != 1.0 {
-		t.Errorf("Expected risk percent 1.0, got %f", bot.riskPercent)
+	if bot.riskPercent.String() != "1" {
+		t.Errorf("Expected risk percent 1, got %s", bot.riskPercent)
 	}
 	if bot.client == nil {
 		t.Error("Client should not be nil")
@@ -23,25 +23,25 @@ func TestNewTradingBot(t *testing.T) {
 
 func TestCalculateBollingerBands(t *testing.T) {
 	bot := &TradingBot{}
-	currentPrice := 100.0
+	currentPrice := trading212.NewDecimalFromInt(100)
 
 	upper, lower := bot.calculateBollingerBands(currentPrice)
 
-	expectedUpper := 104.0 // 100 + (2 * 2.0)
-	expectedLower := 96.0  // 100 - (2 * 2.0)
+	expectedUpper := "104" // 100 + (2 * 2.0)
+	expectedLower := "96"  // 100 - (2 * 2.0)
 
-	if upper != expectedUpper {
-		t.Errorf("Expected upper band %f, got %f", expectedUpper, upper)
+	if upper.String() != expectedUpper {
+		t.Errorf("Expected upper band %s, got %s", expectedUpper, upper)
 	}
-	if lower != expectedLower {
-		t.Errorf("Expected lower band %f, got %f", expectedLower, lower)
+	if lower.String() != expectedLower {
+		t.Errorf("Expected lower band %s, got %s", expectedLower, lower)
 	}
 }
 
 func TestCalculatePositionSize(t *testing.T) {
-	bot := &TradingBot{riskPercent: 2.0}
-	portfolioValue := 10000.0
-	currentPrice := 100.0
+	bot := &TradingBot{riskPercent: trading212.NewDecimalFromInt(2)}
+	portfolioValue := trading212.NewDecimalFromInt(10000)
+	currentPrice := trading212.NewDecimalFromInt(100)
 
 	size := bot.calculatePositionSize(portfolioValue, currentPrice)
 	expected := 2 // (10000 * 2 / 100) / 100 = 2
@@ -51,7 +51,7 @@ func TestCalculatePositionSize(t *testing.T) {
 	}
 
 	// Test minimum position size
-	smallPortfolio := 50.0
+	smallPortfolio := trading212.NewDecimalFromInt(50)
 	size = bot.calculatePositionSize(smallPortfolio, currentPrice)
 	if size != 1 {
 		t.Errorf("Expected minimum position size 1, got %d", size)
@@ -61,23 +61,23 @@ func TestCalculatePositionSize(t *testing.T) {
 func TestCalculatePortfolioValue(t *testing.T) {
 	bot := &TradingBot{}
 	positions := []trading212.Position{
-		{Value: 1000.0},
-		{Value: 1500.0},
-		{Value: 2500.0},
+		{Value: trading212.MustParseDecimal("1000.0")},
+		{Value: trading212.MustParseDecimal("1500.0")},
+		{Value: trading212.MustParseDecimal("2500.0")},
 	}
 
 	value := bot.calculatePortfolioValue(positions)
-	expected := 5000.0
+	expected := "5000"
 
-	if value != expected {
-		t.Errorf("Expected portfolio value %f, got %f", expected, value)
+	if value.String() != expected {
+		t.Errorf("Expected portfolio value %s, got %s", expected, value)
 	}
 
 	// Test empty positions
 	emptyPositions := []trading212.Position{}
 	value = bot.calculatePortfolioValue(emptyPositions)
-	if value != 0.0 {
-		t.Errorf("Expected portfolio value 0.0 for empty positions, got %f", value)
+	if !value.IsZero() {
+		t.Errorf("Expected portfolio value 0 for empty positions, got %s", value)
 	}
 }
 
@@ -113,24 +113,24 @@ func TestShouldBuyAndSell(t *testing.T) {
 	bot := &TradingBot{}
 
 	// Test buy conditions
-	if !bot.shouldBuy(false, 95.0, 100.0) {
+	if !bot.shouldBuy(false, trading212.NewDecimalFromInt(95), trading212.NewDecimalFromInt(100)) {
 		t.Error("Should buy when no position and price below lower band")
 	}
-	if bot.shouldBuy(true, 95.0, 100.0) {
+	if bot.shouldBuy(true, trading212.NewDecimalFromInt(95), trading212.NewDecimalFromInt(100)) {
 		t.Error("Should not buy when already have position")
 	}
-	if bot.shouldBuy(false, 105.0, 100.0) {
+	if bot.shouldBuy(false, trading212.NewDecimalFromInt(105), trading212.NewDecimalFromInt(100)) {
 		t.Error("Should not buy when price above lower band")
 	}
 
 	// Test sell conditions
-	if !bot.shouldSell(true, 105.0, 100.0) {
+	if !bot.shouldSell(true, trading212.NewDecimalFromInt(105), trading212.NewDecimalFromInt(100)) {
 		t.Error("Should sell when have position and price above upper band")
 	}
-	if bot.shouldSell(false, 105.0, 100.0) {
+	if bot.shouldSell(false, trading212.NewDecimalFromInt(105), trading212.NewDecimalFromInt(100)) {
 		t.Error("Should not sell when no position")
 	}
-	if bot.shouldSell(true, 95.0, 100.0) {
+	if bot.shouldSell(true, trading212.NewDecimalFromInt(95), trading212.NewDecimalFromInt(100)) {
 		t.Error("Should not sell when price below upper band")
 	}
 }
@@ -161,7 +161,7 @@ func TestExecuteTrade(t *testing.T) {
 	bot := &TradingBot{}
 
 	// Test no action case
-	result := bot.executeTrade(false, 100.0, 105.0, 95.0, 1)
+	result := bot.executeTrade(false, trading212.NewDecimalFromInt(100), trading212.NewDecimalFromInt(105), trading212.NewDecimalFromInt(95), 1)
 	if !result {
 		t.Error("Should return true when no trade action needed")
 	}
@@ -184,7 +184,7 @@ func TestRetryConfig(t *testing.T) {
 // Benchmark tests
 func BenchmarkCalculateBollingerBands(b *testing.B) {
 	bot := &TradingBot{}
-	currentPrice := 100.0
+	currentPrice := trading212.NewDecimalFromInt(100)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -193,9 +193,9 @@ func BenchmarkCalculateBollingerBands(b *testing.B) {
 }
 
 func BenchmarkCalculatePositionSize(b *testing.B) {
-	bot := &TradingBot{riskPercent: 1.0}
-	portfolioValue := 10000.0
-	currentPrice := 100.0
+	bot := &TradingBot{riskPercent: trading212.NewDecimalFromInt(1)}
+	portfolioValue := trading212.NewDecimalFromInt(10000)
+	currentPrice := trading212.NewDecimalFromInt(100)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
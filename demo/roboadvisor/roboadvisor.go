@@ -3,14 +3,16 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"log"
-	"math"
+	"io"
+	"log/slog"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/analytics"
+	"github.com/0xnu/trading212/classify"
 )
 
 // InvestmentStrategy defines different portfolio strategies
@@ -26,25 +28,25 @@ const (
 
 // AssetAllocation represents the target allocation for different asset classes
 type AssetAllocation struct {
-	Equities      float64 `json:"equities"`
-	Bonds         float64 `json:"bonds"`
-	Technology    float64 `json:"technology"`
-	Healthcare    float64 `json:"healthcare"`
-	Financial     float64 `json:"financial"`
-	Energy        float64 `json:"energy"`
-	REITs         float64 `json:"reits"`
-	International float64 `json:"international"`
+	Equities      trading212.Decimal `json:"equities"`
+	Bonds         trading212.Decimal `json:"bonds"`
+	Technology    trading212.Decimal `json:"technology"`
+	Healthcare    trading212.Decimal `json:"healthcare"`
+	Financial     trading212.Decimal `json:"financial"`
+	Energy        trading212.Decimal `json:"energy"`
+	REITs         trading212.Decimal `json:"reits"`
+	International trading212.Decimal `json:"international"`
 }
 
 // PieConfig represents the configuration for a managed pie
 type PieConfig struct {
-	Name               string             `json:"name"`
-	Strategy           InvestmentStrategy `json:"strategy"`
-	MonthlyAmount      float64            `json:"monthly_amount"`
-	MaxGoal            float64            `json:"max_goal"`
-	Instruments        map[string]float64 `json:"instruments"`
-	RiskTolerance      float64            `json:"risk_tolerance"`
-	RebalanceThreshold float64            `json:"rebalance_threshold"`
+	Name               string                        `json:"name"`
+	Strategy           InvestmentStrategy            `json:"strategy"`
+	MonthlyAmount      trading212.Decimal            `json:"monthly_amount"`
+	MaxGoal            trading212.Decimal            `json:"max_goal"`
+	Instruments        map[string]trading212.Decimal `json:"instruments"`
+	RiskTolerance      trading212.Decimal            `json:"risk_tolerance"`
+	RebalanceThreshold trading212.Decimal            `json:"rebalance_threshold"`
 }
 
 // InstrumentAllocator handles instrument allocation logic
@@ -54,33 +56,44 @@ type InstrumentAllocator struct {
 
 // RoboAdvisor manages automated pie investments
 type RoboAdvisor struct {
-	client            *trading212.Client
-	strategies        map[InvestmentStrategy]AssetAllocation
-	instrumentMapping map[string]string
-	logFile           *os.File
+	client         *trading212.Client
+	strategies     map[InvestmentStrategy]AssetAllocation
+	classification *classify.Mapping
+	logger         *slog.Logger
+	logFile        *os.File
 }
 
-// NewRoboAdvisor creates a new robo-advisor instance
+// NewRoboAdvisor creates a new robo-advisor instance. Its own activity and
+// the client's requests go to one structured logger, writing to stderr and
+// to the log file.
 func NewRoboAdvisor(apiKey string, isDemo bool) *RoboAdvisor {
-	logFile := createLogFile()
+	var output io.Writer = os.Stderr
+	logFile, err := createLogFile()
+	if err == nil {
+		output = io.MultiWriter(os.Stderr, logFile)
+	}
+	logger := slog.New(slog.NewTextHandler(output, nil))
+	if err != nil {
+		logger.Warn("failed to create log file", "err", err)
+	}
+
+	client := trading212.NewClient(apiKey, isDemo)
+	client.SetLogger(logger)
 
 	return &RoboAdvisor{
-		client:            trading212.NewClient(apiKey, isDemo),
-		strategies:        initializeStrategies(),
-		instrumentMapping: initializeInstrumentMapping(),
-		logFile:           logFile,
+		client:         client,
+		strategies:     initializeStrategies(),
+		classification: loadClassification(logger),
+		logger:         logger,
+		logFile:        logFile,
 	}
 }
 
-func createLogFile() *os.File {
+// createLogFile opens a new log file named after the current time
+func createLogFile() (*os.File, error) {
 	timestamp := time.Now().Format("2006-01-02_15-04-05")
 	logFileName := fmt.Sprintf("robo_advisor_%s.log", timestamp)
-	logFile, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		log.Printf("Failed to create log file: %v", err)
-		return nil
-	}
-	return logFile
+	return os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 }
 
 // Close properly closes the robo-advisor resources
@@ -90,20 +103,9 @@ func (ra *RoboAdvisor) Close() {
 	}
 }
 
-// logMessage logs to both console and file
-func (ra *RoboAdvisor) logMessage(message string) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	formattedMessage := fmt.Sprintf("[%s] %s", timestamp, message)
-
-	log.Println(message)
-	if ra.logFile != nil {
-		ra.logFile.WriteString(formattedMessage + "\n")
-	}
-}
-
 // Run executes the monthly robo-advisor process
 func (ra *RoboAdvisor) Run() {
-	ra.logMessage("🤖 Starting Robo-Advisor Monthly Execution")
+	ra.logger.Info("starting monthly execution")
 	defer ra.Close()
 
 	configs := ra.loadConfigurations()
@@ -113,53 +115,41 @@ func (ra *RoboAdvisor) Run() {
 		return
 	}
 
-	// Extract the Free field from the cash response using reflection or type assertion
 	availableCash := ra.extractAvailableCash(cashResponse)
-	ra.logMessage(fmt.Sprintf("💰 Available Cash: £%.2f", availableCash))
+	ra.logger.Info("available cash", "amount", availableCash)
 	ra.processAllPies(configs, availableCash)
 	ra.generateMonthlyReport()
-	ra.logMessage("✅ Robo-Advisor execution completed successfully")
+	ra.logger.Info("monthly execution completed")
 }
 
 // extractAvailableCash extracts the available cash amount from the API response
-func (ra *RoboAdvisor) extractAvailableCash(cashResponse interface{}) float64 {
-
-	// For now, I assume the response has a field we can access
-	// You may need to adjust this based on the actual Trading212 API response structure
-
-	// Temporary approach - you might need to inspect the actual response structure
-	if cashMap, ok := cashResponse.(map[string]interface{}); ok {
-		if free, exists := cashMap["free"]; exists {
-			if freeFloat, ok := free.(float64); ok {
-				return freeFloat
-			}
-		}
+func (ra *RoboAdvisor) extractAvailableCash(cash *trading212.CashInfo) trading212.Decimal {
+	if cash == nil {
+		ra.logger.Warn("no cash in response")
+		return trading212.Decimal{}
 	}
-
-	// If we can't extract the value, log and return 0
-	ra.logMessage("⚠️ Could not extract available cash from response")
-	return 0.0
+	return cash.Free
 }
 
 func (ra *RoboAdvisor) loadConfigurations() []PieConfig {
 	configs, err := ra.loadPieConfigurations()
 	if err != nil {
-		ra.logMessage(fmt.Sprintf("❌ Failed to load configurations: %v", err))
+		ra.logger.Error("failed to load configurations", "err", err)
 		return nil
 	}
 	return configs
 }
 
-func (ra *RoboAdvisor) getCashBalance() interface{} {
+func (ra *RoboAdvisor) getCashBalance() *trading212.CashInfo {
 	cash, err := ra.client.Cash()
 	if err != nil {
-		ra.logMessage(fmt.Sprintf("❌ Failed to get cash balance: %v", err))
+		ra.logger.Error("failed to get cash balance", "err", err)
 		return nil
 	}
 	return cash
 }
 
-func (ra *RoboAdvisor) processAllPies(configs []PieConfig, availableCash float64) {
+func (ra *RoboAdvisor) processAllPies(configs []PieConfig, availableCash trading212.Decimal) {
 	for i, config := range configs {
 		ra.addDelayBetweenPies(i)
 		ra.processPie(config, availableCash)
@@ -181,13 +171,13 @@ func (ra *RoboAdvisor) loadPieConfigurations() ([]PieConfig, error) {
 
 func (ra *RoboAdvisor) parseConfigurationsOrDefault(configsJSON string) ([]PieConfig, error) {
 	if configsJSON == "" {
-		ra.logMessage("📋 Using default pie configurations")
+		ra.logger.Info("using default pie configurations")
 		return ra.getDefaultConfigurations(), nil
 	}
 
 	var configs []PieConfig
 	if err := json.Unmarshal([]byte(configsJSON), &configs); err != nil {
-		ra.logMessage("⚠️ Failed to parse custom configurations, using defaults")
+		ra.logger.Warn("failed to parse custom configurations, using defaults", "err", err)
 		return ra.getDefaultConfigurations(), nil
 	}
 
@@ -200,33 +190,33 @@ func (ra *RoboAdvisor) getDefaultConfigurations() []PieConfig {
 		{
 			Name:               "Conservative Growth",
 			Strategy:           Conservative,
-			MonthlyAmount:      500.0,
-			MaxGoal:            50000.0,
-			RiskTolerance:      0.3,
-			RebalanceThreshold: 0.05,
+			MonthlyAmount:      decimal("500"),
+			MaxGoal:            decimal("50000"),
+			RiskTolerance:      decimal("0.3"),
+			RebalanceThreshold: decimal("0.05"),
 		},
 		{
 			Name:               "Balanced Portfolio",
 			Strategy:           Balanced,
-			MonthlyAmount:      750.0,
-			MaxGoal:            75000.0,
-			RiskTolerance:      0.5,
-			RebalanceThreshold: 0.1,
+			MonthlyAmount:      decimal("750"),
+			MaxGoal:            decimal("75000"),
+			RiskTolerance:      decimal("0.5"),
+			RebalanceThreshold: decimal("0.1"),
 		},
 		{
 			Name:               "Tech Growth",
 			Strategy:           Growth,
-			MonthlyAmount:      1000.0,
-			MaxGoal:            100000.0,
-			RiskTolerance:      0.7,
-			RebalanceThreshold: 0.15,
+			MonthlyAmount:      decimal("1000"),
+			MaxGoal:            decimal("100000"),
+			RiskTolerance:      decimal("0.7"),
+			RebalanceThreshold: decimal("0.15"),
 		},
 	}
 }
 
 // processPie handles the complete pie management process
-func (ra *RoboAdvisor) processPie(config PieConfig, availableCash float64) {
-	ra.logMessage(fmt.Sprintf("🥧 Processing pie: %s", config.Name))
+func (ra *RoboAdvisor) processPie(config PieConfig, availableCash trading212.Decimal) {
+	ra.logger.Info("processing pie", "pie", config.Name)
 
 	pie := ra.findOrCreatePieWithLogging(config)
 	if pie == nil {
@@ -242,7 +232,7 @@ func (ra *RoboAdvisor) processPie(config PieConfig, availableCash float64) {
 func (ra *RoboAdvisor) findOrCreatePieWithLogging(config PieConfig) *trading212.Pie {
 	pie, isNew, err := ra.findOrCreatePie(config)
 	if err != nil {
-		ra.logMessage(fmt.Sprintf("❌ Failed to find/create pie %s: %v", config.Name, err))
+		ra.logger.Error("failed to find or create pie", "pie", config.Name, "err", err)
 		return nil
 	}
 
@@ -252,16 +242,16 @@ func (ra *RoboAdvisor) findOrCreatePieWithLogging(config PieConfig) *trading212.
 
 func (ra *RoboAdvisor) logPieStatus(pie *trading212.Pie, isNew bool) {
 	if isNew {
-		ra.logMessage(fmt.Sprintf("✨ Created new pie: %s (ID: %d)", pie.Name, pie.ID))
+		ra.logger.Info("created pie", "pie", pie.Name, "id", pie.ID)
 	} else {
-		ra.logMessage(fmt.Sprintf("📊 Found existing pie: %s (ID: %d)", pie.Name, pie.ID))
+		ra.logger.Info("found existing pie", "pie", pie.Name, "id", pie.ID)
 	}
 }
 
 func (ra *RoboAdvisor) handleRebalancing(pie *trading212.Pie, config PieConfig) {
 	needsRebalance, err := ra.checkRebalanceNeeded(pie, config)
 	if err != nil {
-		ra.logMessage(fmt.Sprintf("⚠️ Failed to check rebalance for %s: %v", config.Name, err))
+		ra.logger.Warn("failed to check rebalance", "pie", config.Name, "err", err)
 		return
 	}
 
@@ -273,25 +263,25 @@ func (ra *RoboAdvisor) handleRebalancing(pie *trading212.Pie, config PieConfig)
 }
 
 func (ra *RoboAdvisor) executeRebalancing(pie *trading212.Pie, config PieConfig) {
-	ra.logMessage(fmt.Sprintf("⚖️ Rebalancing required for %s", config.Name))
+	ra.logger.Info("rebalancing required", "pie", config.Name)
 	time.Sleep(2 * time.Second)
 
 	if err := ra.rebalancePie(pie, config); err != nil {
-		ra.logMessage(fmt.Sprintf("❌ Rebalance failed for %s: %v", config.Name, err))
+		ra.logger.Error("rebalance failed", "pie", config.Name, "err", err)
 	} else {
-		ra.logMessage(fmt.Sprintf("✅ Successfully rebalanced %s", config.Name))
+		ra.logger.Info("rebalanced", "pie", config.Name)
 	}
 }
 
-func (ra *RoboAdvisor) handleMonthlyInvestment(pie *trading212.Pie, config PieConfig, availableCash float64) {
+func (ra *RoboAdvisor) handleMonthlyInvestment(pie *trading212.Pie, config PieConfig, availableCash trading212.Decimal) {
 	if !ra.shouldInvest(pie, config, availableCash) {
 		return
 	}
 
 	if err := ra.addMonthlyInvestment(pie, config); err != nil {
-		ra.logMessage(fmt.Sprintf("❌ Failed to add investment to %s: %v", config.Name, err))
+		ra.logger.Error("failed to add investment", "pie", config.Name, "err", err)
 	} else {
-		ra.logMessage(fmt.Sprintf("💸 Added £%.2f to %s", config.MonthlyAmount, config.Name))
+		ra.logger.Info("added investment", "pie", config.Name, "amount", config.MonthlyAmount)
 	}
 }
 
@@ -321,14 +311,14 @@ func (ra *RoboAdvisor) findExistingPie(pies []trading212.Pie, name string) *trad
 
 func (ra *RoboAdvisor) createNewPie(config PieConfig) (*trading212.Pie, bool, error) {
 	instruments := ra.generateInstrumentAllocation(config.Strategy)
-	ra.logMessage(fmt.Sprintf("🔍 Creating pie with instruments: %v", instruments))
+	ra.logger.Info("creating pie", "pie", config.Name, "instruments", instruments)
 
 	endDate := time.Now().AddDate(10, 0, 0)
 
 	pie, err := ra.client.PieCreate(
 		"REINVEST",
 		endDate,
-		int(config.MaxGoal),
+		int(config.MaxGoal.Float64()),
 		"PiggyBank",
 		config.Name,
 		instruments,
@@ -342,7 +332,7 @@ func (ra *RoboAdvisor) createNewPie(config PieConfig) (*trading212.Pie, bool, er
 }
 
 // generateInstrumentAllocation creates instrument allocation based on strategy
-func (ra *RoboAdvisor) generateInstrumentAllocation(strategy InvestmentStrategy) map[string]float64 {
+func (ra *RoboAdvisor) generateInstrumentAllocation(strategy InvestmentStrategy) map[string]trading212.Decimal {
 	allocation := ra.strategies[strategy]
 	allocator := &InstrumentAllocator{allocation: allocation}
 
@@ -350,12 +340,12 @@ func (ra *RoboAdvisor) generateInstrumentAllocation(strategy InvestmentStrategy)
 	merged := allocator.mergeInstruments(instruments)
 	normalised := allocator.normaliseWeights(merged)
 
-	ra.logMessage(fmt.Sprintf("📊 Generated allocation for %s strategy: %v", strategy, normalised))
+	ra.logger.Info("generated allocation", "strategy", strategy, "weights", normalised)
 	return normalised
 }
 
-func (ia *InstrumentAllocator) createBaseInstruments() map[string]float64 {
-	instruments := make(map[string]float64)
+func (ia *InstrumentAllocator) createBaseInstruments() map[string]trading212.Decimal {
+	instruments := make(map[string]trading212.Decimal)
 
 	ia.addTechnologyInstruments(instruments)
 	ia.addHealthcareInstruments(instruments)
@@ -369,98 +359,109 @@ func (ia *InstrumentAllocator) createBaseInstruments() map[string]float64 {
 	return instruments
 }
 
-func (ia *InstrumentAllocator) addTechnologyInstruments(instruments map[string]float64) {
-	if ia.allocation.Technology <= 0 {
+func (ia *InstrumentAllocator) addTechnologyInstruments(instruments map[string]trading212.Decimal) {
+	if ia.allocation.Technology.Sign() <= 0 {
 		return
 	}
-	instruments["META"] = ia.allocation.Technology * 0.4
-	instruments["MSFT"] = ia.allocation.Technology * 0.3
-	instruments["NVDA"] = ia.allocation.Technology * 0.3
+	instruments["META"] = ia.allocation.Technology.Mul(decimal("0.4"))
+	instruments["MSFT"] = ia.allocation.Technology.Mul(decimal("0.3"))
+	instruments["NVDA"] = ia.allocation.Technology.Mul(decimal("0.3"))
 }
 
-func (ia *InstrumentAllocator) addHealthcareInstruments(instruments map[string]float64) {
-	if ia.allocation.Healthcare <= 0 {
+func (ia *InstrumentAllocator) addHealthcareInstruments(instruments map[string]trading212.Decimal) {
+	if ia.allocation.Healthcare.Sign() <= 0 {
 		return
 	}
-	instruments["JNJ"] = ia.allocation.Healthcare * 0.6
-	instruments["PFE"] = ia.allocation.Healthcare * 0.4
+	instruments["JNJ"] = ia.allocation.Healthcare.Mul(decimal("0.6"))
+	instruments["PFE"] = ia.allocation.Healthcare.Mul(decimal("0.4"))
 }
 
-func (ia *InstrumentAllocator) addFinancialInstruments(instruments map[string]float64) {
-	if ia.allocation.Financial <= 0 {
+func (ia *InstrumentAllocator) addFinancialInstruments(instruments map[string]trading212.Decimal) {
+	if ia.allocation.Financial.Sign() <= 0 {
 		return
 	}
-	instruments["JPM"] = ia.allocation.Financial * 0.5
-	instruments["WFC"] = ia.allocation.Financial * 0.5
+	instruments["JPM"] = ia.allocation.Financial.Mul(decimal("0.5"))
+	instruments["WFC"] = ia.allocation.Financial.Mul(decimal("0.5"))
 }
 
-func (ia *InstrumentAllocator) addEnergyInstruments(instruments map[string]float64) {
-	if ia.allocation.Energy <= 0 {
+func (ia *InstrumentAllocator) addEnergyInstruments(instruments map[string]trading212.Decimal) {
+	if ia.allocation.Energy.Sign() <= 0 {
 		return
 	}
 	instruments["XOM"] = ia.allocation.Energy
 }
 
-func (ia *InstrumentAllocator) addREITInstruments(instruments map[string]float64) {
-	if ia.allocation.REITs <= 0 {
+func (ia *InstrumentAllocator) addREITInstruments(instruments map[string]trading212.Decimal) {
+	if ia.allocation.REITs.Sign() <= 0 {
 		return
 	}
 	instruments["AMT"] = ia.allocation.REITs
 }
 
-func (ia *InstrumentAllocator) addBondInstruments(instruments map[string]float64) {
-	if ia.allocation.Bonds <= 0 {
+func (ia *InstrumentAllocator) addBondInstruments(instruments map[string]trading212.Decimal) {
+	if ia.allocation.Bonds.Sign() <= 0 {
 		return
 	}
 	instruments["TLT"] = ia.allocation.Bonds
 }
 
-func (ia *InstrumentAllocator) addInternationalInstruments(instruments map[string]float64) {
-	if ia.allocation.International <= 0 {
+func (ia *InstrumentAllocator) addInternationalInstruments(instruments map[string]trading212.Decimal) {
+	if ia.allocation.International.Sign() <= 0 {
 		return
 	}
-	instruments["TSM"] = ia.allocation.International * 0.5
-	instruments["ASML"] = ia.allocation.International * 0.5
+	instruments["TSM"] = ia.allocation.International.Mul(decimal("0.5"))
+	instruments["ASML"] = ia.allocation.International.Mul(decimal("0.5"))
 }
 
-func (ia *InstrumentAllocator) addEquityInstruments(instruments map[string]float64) {
-	if ia.allocation.Equities <= 0 {
+func (ia *InstrumentAllocator) addEquityInstruments(instruments map[string]trading212.Decimal) {
+	if ia.allocation.Equities.Sign() <= 0 {
 		return
 	}
-	instruments["NVDA"] = ia.allocation.Equities * 0.33
-	instruments["TSLA"] = ia.allocation.Equities * 0.33
-	instruments["GOOGL"] = ia.allocation.Equities * 0.34
+	instruments["NVDA"] = ia.allocation.Equities.Mul(decimal("0.33"))
+	instruments["TSLA"] = ia.allocation.Equities.Mul(decimal("0.33"))
+	instruments["GOOGL"] = ia.allocation.Equities.Mul(decimal("0.34"))
 }
 
-func (ia *InstrumentAllocator) mergeInstruments(instruments map[string]float64) map[string]float64 {
-	merged := make(map[string]float64)
+func (ia *InstrumentAllocator) mergeInstruments(instruments map[string]trading212.Decimal) map[string]trading212.Decimal {
+	merged := make(map[string]trading212.Decimal)
 	for ticker, weight := range instruments {
-		merged[ticker] += weight
+		merged[ticker] = merged[ticker].Add(weight)
 	}
 	return merged
 }
 
-func (ia *InstrumentAllocator) normaliseWeights(instruments map[string]float64) map[string]float64 {
+func (ia *InstrumentAllocator) normaliseWeights(instruments map[string]trading212.Decimal) map[string]trading212.Decimal {
 	total := ia.calculateTotal(instruments)
-	if total <= 0 {
+	if total.Sign() <= 0 {
 		return instruments
 	}
 
 	return ia.applyNormalisation(instruments, total)
 }
 
-func (ia *InstrumentAllocator) calculateTotal(instruments map[string]float64) float64 {
-	total := 0.0
+func (ia *InstrumentAllocator) calculateTotal(instruments map[string]trading212.Decimal) trading212.Decimal {
+	var total trading212.Decimal
 	for _, weight := range instruments {
-		total += weight
+		total = total.Add(weight)
 	}
 	return total
 }
 
-func (ia *InstrumentAllocator) applyNormalisation(instruments map[string]float64, total float64) map[string]float64 {
-	normalised := make(map[string]float64)
+// applyNormalisation divides each weight by total and gives any rounding
+// remainder to the largest weight, so the weights sum to exactly one
+func (ia *InstrumentAllocator) applyNormalisation(instruments map[string]trading212.Decimal, total trading212.Decimal) map[string]trading212.Decimal {
+	normalised := make(map[string]trading212.Decimal)
+	remainder := trading212.NewDecimalFromInt(1)
+	largest := ""
 	for ticker, weight := range instruments {
-		normalised[ticker] = weight / total
+		normalised[ticker] = weight.Div(total)
+		remainder = remainder.Sub(normalised[ticker])
+		if largest == "" || weight.Cmp(instruments[largest]) > 0 || (weight.Cmp(instruments[largest]) == 0 && ticker < largest) {
+			largest = ticker
+		}
+	}
+	if largest != "" {
+		normalised[largest] = normalised[largest].Add(remainder)
 	}
 	return normalised
 }
@@ -476,38 +477,37 @@ func (ra *RoboAdvisor) checkRebalanceNeeded(pie *trading212.Pie, config PieConfi
 	return ra.evaluateRebalanceThreshold(detailedPie, targetAllocation, config), nil
 }
 
-func (ra *RoboAdvisor) evaluateRebalanceThreshold(pie *trading212.Pie, targetAllocation map[string]float64, config PieConfig) bool {
+func (ra *RoboAdvisor) evaluateRebalanceThreshold(pie *trading212.Pie, targetAllocation map[string]trading212.Decimal, config PieConfig) bool {
 	for ticker, targetWeight := range targetAllocation {
 		currentWeight := ra.calculateCurrentWeight(pie, ticker)
-		deviation := math.Abs(currentWeight - targetWeight)
+		deviation := currentWeight.Sub(targetWeight).Abs()
 
-		if deviation > config.RebalanceThreshold {
-			ra.logMessage(fmt.Sprintf("📈 %s deviation: %.2f%% (threshold: %.2f%%)",
-				ticker, deviation*100, config.RebalanceThreshold*100))
+		if deviation.Cmp(config.RebalanceThreshold) > 0 {
+			ra.logger.Info("weight outside threshold", "pie", config.Name, "ticker", ticker, "deviation", deviation, "threshold", config.RebalanceThreshold)
 			return true
 		}
 	}
 	return false
 }
 
-func (ra *RoboAdvisor) calculateCurrentWeight(pie *trading212.Pie, ticker string) float64 {
+func (ra *RoboAdvisor) calculateCurrentWeight(pie *trading212.Pie, ticker string) trading212.Decimal {
 	shares, exists := pie.InstrumentShares[ticker]
 	if !exists {
-		return 0.0
+		return trading212.Decimal{}
 	}
 
 	total := ra.calculateTotalShares(pie.InstrumentShares)
-	if total <= 0 {
-		return 0.0
+	if total.Sign() <= 0 {
+		return trading212.Decimal{}
 	}
 
-	return shares / total
+	return shares.Div(total)
 }
 
-func (ra *RoboAdvisor) calculateTotalShares(instrumentShares map[string]float64) float64 {
-	total := 0.0
+func (ra *RoboAdvisor) calculateTotalShares(instrumentShares map[string]trading212.Decimal) trading212.Decimal {
+	var total trading212.Decimal
 	for _, share := range instrumentShares {
-		total += share
+		total = total.Add(share)
 	}
 	return total
 }
@@ -521,7 +521,7 @@ func (ra *RoboAdvisor) rebalancePie(pie *trading212.Pie, config PieConfig) error
 		pie.ID,
 		"REINVEST",
 		endDate,
-		int(config.MaxGoal),
+		int(config.MaxGoal.Float64()),
 		"PiggyBank",
 		config.Name,
 		targetAllocation,
@@ -531,97 +531,131 @@ func (ra *RoboAdvisor) rebalancePie(pie *trading212.Pie, config PieConfig) error
 }
 
 // shouldInvest determines if monthly investment should be made
-func (ra *RoboAdvisor) shouldInvest(pie *trading212.Pie, config PieConfig, availableCash float64) bool {
+func (ra *RoboAdvisor) shouldInvest(pie *trading212.Pie, config PieConfig, availableCash trading212.Decimal) bool {
 	return ra.hasSufficientCash(config, availableCash) && ra.isUnderGoal(pie, config)
 }
 
-func (ra *RoboAdvisor) hasSufficientCash(config PieConfig, availableCash float64) bool {
-	if availableCash >= config.MonthlyAmount {
+func (ra *RoboAdvisor) hasSufficientCash(config PieConfig, availableCash trading212.Decimal) bool {
+	if availableCash.Cmp(config.MonthlyAmount) >= 0 {
 		return true
 	}
 
-	ra.logMessage(fmt.Sprintf("💰 Insufficient cash for %s: £%.2f available, £%.2f needed",
-		config.Name, availableCash, config.MonthlyAmount))
+	ra.logger.Warn("insufficient cash", "pie", config.Name, "available", availableCash, "amount", config.MonthlyAmount)
 	return false
 }
 
 func (ra *RoboAdvisor) isUnderGoal(pie *trading212.Pie, config PieConfig) bool {
-	if float64(pie.Goal) > config.MaxGoal {
+	if trading212.NewDecimalFromInt(int64(pie.Goal)).Cmp(config.MaxGoal) > 0 {
 		return true
 	}
 
-	ra.logMessage(fmt.Sprintf("🎯 Goal reached for %s: £%d (goal: £%.2f)",
-		config.Name, pie.Goal, config.MaxGoal))
+	ra.logger.Info("goal reached", "pie", config.Name, "goal", pie.Goal, "maxGoal", config.MaxGoal)
 	return false
 }
 
 // addMonthlyInvestment adds the monthly investment to the pie
 func (ra *RoboAdvisor) addMonthlyInvestment(pie *trading212.Pie, config PieConfig) error {
-	ra.logMessage(fmt.Sprintf("📈 Investment logic triggered for %s: £%.2f", config.Name, config.MonthlyAmount))
+	ra.logger.Info("investment triggered", "pie", config.Name, "amount", config.MonthlyAmount)
 	return nil
 }
 
 // generateMonthlyReport creates a comprehensive monthly report
 func (ra *RoboAdvisor) generateMonthlyReport() {
-	ra.logMessage("📊 Generating Monthly Portfolio Report")
+	ra.logger.Info("generating monthly report")
 	time.Sleep(2 * time.Second)
 
 	pies, err := ra.client.Pies()
 	if err != nil {
-		ra.logMessage(fmt.Sprintf("❌ Failed to fetch pies for report: %v", err))
+		ra.logger.Error("failed to fetch pies for report", "err", err)
 		return
 	}
 
 	ra.logPortfolioSummary(pies)
-	ra.saveReportToFile(pies, 0.0, 0.0, 0.0)
+	ra.logExposure()
+	totalValue, totalPnL, performance := ra.calculatePerformance(pies, time.Now())
+	ra.logger.Info("performance", "value", totalValue, "pnl", totalPnL, "return", performance)
+	ra.saveReportToFile(pies, totalValue, totalPnL, performance)
+}
+
+// calculatePerformance returns the combined value and profit of the pies and
+// their return on the amount invested. With a single snapshot there are no
+// earlier values to chain, so this is simply value / invested - 1.
+func (ra *RoboAdvisor) calculatePerformance(pies []trading212.Pie, at time.Time) (totalValue, totalPnL trading212.Decimal, performance float64) {
+	points, flows := analytics.PieSeries(analytics.PieSnapshots(pies, at), 0)
+	result, err := analytics.Measure(points, flows, at, at)
+	if err != nil {
+		ra.logger.Warn("failed to measure performance", "err", err)
+		return trading212.Decimal{}, trading212.Decimal{}, 0
+	}
+	return result.EndValue, result.Gain, result.TimeWeighted
 }
 
-func (ra *RoboAdvisor) logPortfolioSummary(pies []trading212.Pie) {
-	totalGoal := ra.calculateTotalGoal(pies)
-	separator := strings.Repeat("=", 50)
+// logExposure logs the sector breakdown of the open positions and any
+// concentrations
+func (ra *RoboAdvisor) logExposure() {
+	positions, err := ra.client.Portfolio()
+	if err != nil {
+		ra.logger.Error("failed to fetch positions for exposure", "err", err)
+		return
+	}
+
+	instruments, err := ra.client.InstrumentList()
+	if err != nil {
+		ra.logger.Warn("failed to fetch instruments, classifying from the mapping only", "err", err)
+	}
 
-	ra.logMessage(separator)
-	ra.logMessage("📈 MONTHLY PORTFOLIO REPORT")
-	ra.logMessage(separator)
+	report := ra.calculateExposure(positions, instruments)
+	for _, sector := range report.Sectors {
+		ra.logger.Info("sector exposure", "sector", sector.Name, "value", sector.Value, "weight", sector.Weight)
+	}
+	for _, warning := range report.Warnings {
+		ra.logger.Warn("concentration", "warning", warning)
+	}
+}
 
+// calculateExposure classifies positions by their reported value, using the
+// instrument metadata for ISIN entries and defaults
+func (ra *RoboAdvisor) calculateExposure(positions []trading212.Position, instruments []trading212.Instrument) *classify.ExposureReport {
+	values := make(map[string]trading212.Decimal, len(positions))
+	for _, position := range positions {
+		values[position.Ticker] = position.Value
+	}
+	classifier := classify.NewClassifier(ra.classification, instruments)
+	return classify.Exposures(values, trading212.Decimal{}, "GBP", classifier, classify.DefaultLimits)
+}
+
+func (ra *RoboAdvisor) logPortfolioSummary(pies []trading212.Pie) {
 	ra.logIndividualPies(pies)
-	ra.logOverallSummary(totalGoal, len(pies))
-	ra.logMessage(separator)
+	ra.logOverallSummary(ra.calculateTotalGoal(pies), len(pies))
 }
 
-func (ra *RoboAdvisor) calculateTotalGoal(pies []trading212.Pie) float64 {
-	totalGoal := 0.0
+func (ra *RoboAdvisor) calculateTotalGoal(pies []trading212.Pie) trading212.Decimal {
+	var totalGoal trading212.Decimal
 	for _, pie := range pies {
-		totalGoal += float64(pie.Goal)
+		totalGoal = totalGoal.Add(trading212.NewDecimalFromInt(int64(pie.Goal)))
 	}
 	return totalGoal
 }
 
 func (ra *RoboAdvisor) logIndividualPies(pies []trading212.Pie) {
 	for _, pie := range pies {
-		ra.logMessage(fmt.Sprintf("🥧 %s:", pie.Name))
-		ra.logMessage(fmt.Sprintf("   Goal: £%d", pie.Goal))
-		ra.logMessage(fmt.Sprintf("   Instruments: %d", len(pie.InstrumentShares)))
-		ra.logMessage(fmt.Sprintf("   Dividend Action: %s", pie.DividendCashAction))
-		ra.logMessage("")
+		ra.logger.Info("pie report", "pie", pie.Name, "goal", pie.Goal, "instruments", len(pie.InstrumentShares), "dividendAction", pie.DividendCashAction)
 	}
 }
 
-func (ra *RoboAdvisor) logOverallSummary(totalGoal float64, pieCount int) {
-	ra.logMessage("📊 PORTFOLIO SUMMARY:")
-	ra.logMessage(fmt.Sprintf("   Total Goals: £%.2f", totalGoal))
-	ra.logMessage(fmt.Sprintf("   Number of Pies: %d", pieCount))
+func (ra *RoboAdvisor) logOverallSummary(totalGoal trading212.Decimal, pieCount int) {
+	ra.logger.Info("portfolio summary", "totalGoal", totalGoal, "pies", pieCount)
 }
 
 // saveReportToFile saves the monthly report to a JSON file
-func (ra *RoboAdvisor) saveReportToFile(pies []trading212.Pie, totalValue, totalPnL, performance float64) {
+func (ra *RoboAdvisor) saveReportToFile(pies []trading212.Pie, totalValue, totalPnL trading212.Decimal, performance float64) {
 	report := ra.createReportData(pies, totalValue, totalPnL, performance)
 	fileName := fmt.Sprintf("monthly_report_%s.json", time.Now().Format("2006-01"))
 
 	ra.writeReportToFile(report, fileName)
 }
 
-func (ra *RoboAdvisor) createReportData(pies []trading212.Pie, totalValue, totalPnL, performance float64) map[string]interface{} {
+func (ra *RoboAdvisor) createReportData(pies []trading212.Pie, totalValue, totalPnL trading212.Decimal, performance float64) map[string]interface{} {
 	return map[string]interface{}{
 		"timestamp":   time.Now().Format(time.RFC3339),
 		"total_value": totalValue,
@@ -635,7 +669,7 @@ func (ra *RoboAdvisor) createReportData(pies []trading212.Pie, totalValue, total
 func (ra *RoboAdvisor) writeReportToFile(report map[string]interface{}, fileName string) {
 	file, err := os.Create(fileName)
 	if err != nil {
-		ra.logMessage(fmt.Sprintf("❌ Failed to create report file: %v", err))
+		ra.logger.Error("failed to create report file", "err", err)
 		return
 	}
 	defer file.Close()
@@ -643,9 +677,9 @@ func (ra *RoboAdvisor) writeReportToFile(report map[string]interface{}, fileName
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(report); err != nil {
-		ra.logMessage(fmt.Sprintf("❌ Failed to write report: %v", err))
+		ra.logger.Error("failed to write report", "file", fileName, "err", err)
 	} else {
-		ra.logMessage(fmt.Sprintf("💾 Report saved to %s", fileName))
+		ra.logger.Info("report saved", "file", fileName)
 	}
 }
 
@@ -653,72 +687,102 @@ func (ra *RoboAdvisor) writeReportToFile(report map[string]interface{}, fileName
 func initializeStrategies() map[InvestmentStrategy]AssetAllocation {
 	return map[InvestmentStrategy]AssetAllocation{
 		Conservative: {
-			Equities:      0.4,
-			Bonds:         0.4,
-			Technology:    0.1,
-			Healthcare:    0.05,
-			Financial:     0.05,
-			International: 0.0,
+			Equities:   decimal("0.4"),
+			Bonds:      decimal("0.4"),
+			Technology: decimal("0.1"),
+			Healthcare: decimal("0.05"),
+			Financial:  decimal("0.05"),
 		},
 		Balanced: {
-			Equities:      0.3,
-			Bonds:         0.2,
-			Technology:    0.25,
-			Healthcare:    0.1,
-			Financial:     0.1,
-			International: 0.05,
+			Equities:      decimal("0.3"),
+			Bonds:         decimal("0.2"),
+			Technology:    decimal("0.25"),
+			Healthcare:    decimal("0.1"),
+			Financial:     decimal("0.1"),
+			International: decimal("0.05"),
 		},
 		Aggressive: {
-			Equities:      0.2,
-			Technology:    0.4,
-			Healthcare:    0.15,
-			Financial:     0.1,
-			Energy:        0.05,
-			International: 0.1,
+			Equities:      decimal("0.2"),
+			Technology:    decimal("0.4"),
+			Healthcare:    decimal("0.15"),
+			Financial:     decimal("0.1"),
+			Energy:        decimal("0.05"),
+			International: decimal("0.1"),
 		},
 		Growth: {
-			Technology:    0.5,
-			Healthcare:    0.2,
-			Equities:      0.15,
-			Financial:     0.1,
-			International: 0.05,
+			Technology:    decimal("0.5"),
+			Healthcare:    decimal("0.2"),
+			Equities:      decimal("0.15"),
+			Financial:     decimal("0.1"),
+			International: decimal("0.05"),
 		},
 		Income: {
-			Bonds:         0.3,
-			REITs:         0.2,
-			Financial:     0.2,
-			Equities:      0.2,
-			International: 0.1,
+			Bonds:         decimal("0.3"),
+			REITs:         decimal("0.2"),
+			Financial:     decimal("0.2"),
+			Equities:      decimal("0.2"),
+			International: decimal("0.1"),
 		},
 	}
 }
 
-// initializeInstrumentMapping maps asset classes to Trading212 tickers
-func initializeInstrumentMapping() map[string]string {
-	return map[string]string{
-		"US_EQUITIES":   "SPY",
-		"TECHNOLOGY":    "AAPL",
-		"HEALTHCARE":    "JNJ",
-		"FINANCIAL":     "JPM",
-		"ENERGY":        "XOM",
-		"REITS":         "AMT",
-		"BONDS":         "TLT",
-		"INTERNATIONAL": "TSM",
-		"EMERGING":      "TSM",
-	}
+// loadClassification loads the instrument classification from the file named
+// by CLASSIFICATION_FILE, falling back to the classification of the
+// instruments the strategies use
+func loadClassification(logger *slog.Logger) *classify.Mapping {
+	if path := os.Getenv("CLASSIFICATION_FILE"); path != "" {
+		mapping, err := classify.LoadMapping(path)
+		if err == nil {
+			return mapping
+		}
+		logger.Warn("failed to load classification, using defaults", "file", path, "err", err)
+	}
+	return defaultClassification()
+}
+
+// defaultClassification classifies the instruments the strategies use by
+// their API tickers, which is how positions are reported
+func defaultClassification() *classify.Mapping {
+	equity := func(sector, region string) classify.Classification {
+		return classify.Classification{Sector: sector, AssetClass: "Equity", Region: region}
+	}
+	return &classify.Mapping{Tickers: map[string]classify.Classification{
+		"SPY_US_EQ":   {Sector: "Broad Market", AssetClass: "Fund", Region: "North America"},
+		"AAPL_US_EQ":  equity("Technology", "North America"),
+		"META_US_EQ":  equity("Technology", "North America"),
+		"MSFT_US_EQ":  equity("Technology", "North America"),
+		"NVDA_US_EQ":  equity("Technology", "North America"),
+		"GOOGL_US_EQ": equity("Technology", "North America"),
+		"TSLA_US_EQ":  equity("Consumer Discretionary", "North America"),
+		"JNJ_US_EQ":   equity("Healthcare", "North America"),
+		"PFE_US_EQ":   equity("Healthcare", "North America"),
+		"JPM_US_EQ":   equity("Financial", "North America"),
+		"WFC_US_EQ":   equity("Financial", "North America"),
+		"XOM_US_EQ":   equity("Energy", "North America"),
+		"AMT_US_EQ":   {Sector: "Real Estate", AssetClass: "REIT", Region: "North America"},
+		"TLT_US_EQ":   {Sector: "Government Bonds", AssetClass: "Bond", Region: "North America"},
+		"TSM_US_EQ":   equity("Technology", "Asia Pacific"),
+		"ASML_US_EQ":  equity("Technology", "Europe"),
+	}}
+}
+
+// decimal parses a constant weight or amount
+func decimal(value string) trading212.Decimal {
+	return trading212.MustParseDecimal(value)
 }
 
 func main() {
 	apiKey := os.Getenv("TRADING212_API_KEY")
 	if apiKey == "" {
-		log.Fatal("❌ TRADING212_API_KEY environment variable is required")
+		slog.Error("TRADING212_API_KEY environment variable is required")
+		os.Exit(1)
 	}
 
 	isDemoStr := os.Getenv("IS_DEMO")
 	isDemo, _ := strconv.ParseBool(isDemoStr)
 	if isDemoStr == "" {
 		isDemo = true
-		log.Println("⚠️ IS_DEMO not set, defaulting to demo mode")
+		slog.Warn("IS_DEMO not set, defaulting to demo mode")
 	}
 
 	advisor := NewRoboAdvisor(apiKey, isDemo)
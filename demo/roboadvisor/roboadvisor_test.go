@@ -1,24 +1,27 @@
 package main
 
 import (
+	"bytes"
+	"log/slog"
+	"math"
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/0xnu/trading212"
 )
 
 func TestCreateLogFile(t *testing.T) {
-	logFile := createLogFile()
-
-	if logFile == nil {
-		t.Error("Expected log file to be created, got nil")
-	} else {
-		defer func() {
-			logFile.Close()
-			// Clean up test file
-			fileName := logFile.Name()
-			os.Remove(fileName)
-		}()
+	logFile, err := createLogFile()
+	if err != nil {
+		t.Fatalf("Expected log file to be created, got %v", err)
 	}
+	defer func() {
+		logFile.Close()
+		// Clean up test file
+		os.Remove(logFile.Name())
+	}()
 }
 
 func TestNewRoboAdvisor(t *testing.T) {
@@ -32,12 +35,16 @@ func TestNewRoboAdvisor(t *testing.T) {
 		t.Error("Expected client to be initialised")
 	}
 
+	if advisor.logger == nil {
+		t.Error("Expected logger to be initialised")
+	}
+
 	if advisor.strategies == nil {
 		t.Error("Expected strategies to be initialised")
 	}
 
-	if advisor.instrumentMapping == nil {
-		t.Error("Expected instrument mapping to be initialised")
+	if advisor.classification == nil {
+		t.Error("Expected classification to be initialised")
 	}
 
 	defer advisor.Close()
@@ -55,7 +62,7 @@ func TestGetDefaultConfigurations(t *testing.T) {
 
 	expectedNames := []string{"Conservative Growth", "Balanced Portfolio", "Tech Growth"}
 	expectedStrategies := []InvestmentStrategy{Conservative, Balanced, Growth}
-	expectedAmounts := []float64{500.0, 750.0, 1000.0}
+	expectedAmounts := []string{"500", "750", "1000"}
 
 	for i, config := range configs {
 		if config.Name != expectedNames[i] {
@@ -66,8 +73,8 @@ func TestGetDefaultConfigurations(t *testing.T) {
 			t.Errorf("Expected strategy %s, got %s", expectedStrategies[i], config.Strategy)
 		}
 
-		if config.MonthlyAmount != expectedAmounts[i] {
-			t.Errorf("Expected monthly amount %.2f, got %.2f", expectedAmounts[i], config.MonthlyAmount)
+		if config.MonthlyAmount.String() != expectedAmounts[i] {
+			t.Errorf("Expected monthly amount %s, got %s", expectedAmounts[i], config.MonthlyAmount)
 		}
 	}
 }
@@ -99,9 +106,9 @@ func TestParseConfigurationsOrDefault(t *testing.T) {
 
 func TestInstrumentAllocator(t *testing.T) {
 	allocation := AssetAllocation{
-		Technology: 0.5,
-		Healthcare: 0.3,
-		Financial:  0.2,
+		Technology: decimal("0.5"),
+		Healthcare: decimal("0.3"),
+		Financial:  decimal("0.2"),
 	}
 
 	allocator := &InstrumentAllocator{allocation: allocation}
@@ -116,22 +123,21 @@ func TestInstrumentAllocator(t *testing.T) {
 	// Test normalisation
 	normalised := allocator.normaliseWeights(instruments)
 
-	total := 0.0
+	var total trading212.Decimal
 	for _, weight := range normalised {
-		total += weight
+		total = total.Add(weight)
 	}
 
-	// Should sum to approximately 1.0
-	if total < 0.99 || total > 1.01 {
-		t.Errorf("Expected normalised weights to sum to 1.0, got %.4f", total)
+	if total.String() != "1" {
+		t.Errorf("Expected normalised weights to sum to 1, got %s", total)
 	}
 }
 
 func TestAddTechnologyInstruments(t *testing.T) {
-	allocation := AssetAllocation{Technology: 0.6}
+	allocation := AssetAllocation{Technology: decimal("0.6")}
 	allocator := &InstrumentAllocator{allocation: allocation}
 
-	instruments := make(map[string]float64)
+	instruments := make(map[string]trading212.Decimal)
 	allocator.addTechnologyInstruments(instruments)
 
 	expectedTickers := []string{"META", "MSFT", "NVDA"}
@@ -142,9 +148,9 @@ func TestAddTechnologyInstruments(t *testing.T) {
 	}
 
 	// Test with zero allocation
-	allocation.Technology = 0.0
+	allocation.Technology = trading212.Decimal{}
 	allocator.allocation = allocation
-	instruments = make(map[string]float64)
+	instruments = make(map[string]trading212.Decimal)
 	allocator.addTechnologyInstruments(instruments)
 
 	if len(instruments) != 0 {
@@ -155,43 +161,50 @@ func TestAddTechnologyInstruments(t *testing.T) {
 func TestCalculateTotal(t *testing.T) {
 	allocator := &InstrumentAllocator{}
 
-	instruments := map[string]float64{
-		"AAPL":  0.3,
-		"MSFT":  0.4,
-		"GOOGL": 0.3,
+	instruments := map[string]trading212.Decimal{
+		"AAPL":  decimal("0.3"),
+		"MSFT":  decimal("0.4"),
+		"GOOGL": decimal("0.3"),
 	}
 
 	total := allocator.calculateTotal(instruments)
-	expected := 1.0
-
-	if total != expected {
-		t.Errorf("Expected total %.2f, got %.2f", expected, total)
+	if total.String() != "1" {
+		t.Errorf("Expected total 1, got %s", total)
 	}
 }
 
 func TestApplyNormalisation(t *testing.T) {
 	allocator := &InstrumentAllocator{}
 
-	instruments := map[string]float64{
-		"AAPL":  0.6,
-		"MSFT":  0.8,
-		"GOOGL": 0.6,
+	instruments := map[string]trading212.Decimal{
+		"AAPL":  decimal("0.6"),
+		"MSFT":  decimal("0.8"),
+		"GOOGL": decimal("0.6"),
 	}
 
-	total := 2.0
-	normalised := allocator.applyNormalisation(instruments, total)
+	normalised := allocator.applyNormalisation(instruments, decimal("2"))
 
-	expectedNormalised := map[string]float64{
-		"AAPL":  0.3,
-		"MSFT":  0.4,
-		"GOOGL": 0.3,
+	expectedNormalised := map[string]string{
+		"AAPL":  "0.3",
+		"MSFT":  "0.4",
+		"GOOGL": "0.3",
 	}
 
 	for ticker, expectedWeight := range expectedNormalised {
-		if normalised[ticker] != expectedWeight {
-			t.Errorf("Expected %s weight %.2f, got %.2f", ticker, expectedWeight, normalised[ticker])
+		if normalised[ticker].String() != expectedWeight {
+			t.Errorf("Expected %s weight %s, got %s", ticker, expectedWeight, normalised[ticker])
 		}
 	}
+
+	// Thirds do not divide exactly, so the remainder goes to the largest weight
+	thirds := allocator.applyNormalisation(map[string]trading212.Decimal{
+		"AAPL": decimal("1"),
+		"MSFT": decimal("1"),
+		"TSLA": decimal("1"),
+	}, decimal("3"))
+	if thirds["AAPL"].String() != "0.33333334" || thirds["MSFT"].String() != "0.33333333" || thirds["TSLA"].String() != "0.33333333" {
+		t.Errorf("Expected thirds summing to 1, got %v", thirds)
+	}
 }
 
 func TestHasSufficientCash(t *testing.T) {
@@ -200,21 +213,26 @@ func TestHasSufficientCash(t *testing.T) {
 
 	config := PieConfig{
 		Name:          "Test Pie",
-		MonthlyAmount: 500.0,
+		MonthlyAmount: decimal("500"),
 	}
 
 	// Test sufficient cash
-	if !advisor.hasSufficientCash(config, 1000.0) {
+	if !advisor.hasSufficientCash(config, decimal("1000")) {
 		t.Error("Expected true for sufficient cash")
 	}
 
-	// Test insufficient cash
-	if advisor.hasSufficientCash(config, 250.0) {
+	// Test insufficient cash, logged with the pie and amounts
+	var output bytes.Buffer
+	advisor.logger = slog.New(slog.NewTextHandler(&output, nil))
+	if advisor.hasSufficientCash(config, decimal("250")) {
 		t.Error("Expected false for insufficient cash")
 	}
+	if want := `msg="insufficient cash" pie="Test Pie" available=250 amount=500`; !strings.Contains(output.String(), want) {
+		t.Errorf("Expected log to contain %q, got %q", want, output.String())
+	}
 
 	// Test exact amount
-	if !advisor.hasSufficientCash(config, 500.0) {
+	if !advisor.hasSufficientCash(config, decimal("500")) {
 		t.Error("Expected true for exact cash amount")
 	}
 }
@@ -223,35 +241,16 @@ func TestExtractAvailableCash(t *testing.T) {
 	advisor := NewRoboAdvisor("test-api-key", true)
 	defer advisor.Close()
 
-	// Test with proper map structure
-	cashResponse := map[string]interface{}{
-		"free": 1500.50,
+	// Test with a cash response
+	cash := advisor.extractAvailableCash(&trading212.CashInfo{Free: decimal("1500.50"), Total: decimal("2000")})
+	if cash.String() != "1500.5" {
+		t.Errorf("Expected cash 1500.50, got %s", cash)
 	}
 
-	cash := advisor.extractAvailableCash(cashResponse)
-	expected := 1500.50
-
-	if cash != expected {
-		t.Errorf("Expected cash %.2f, got %.2f", expected, cash)
-	}
-
-	// Test with invalid structure
-	invalidResponse := "invalid"
-	cash = advisor.extractAvailableCash(invalidResponse)
-
-	if cash != 0.0 {
-		t.Errorf("Expected 0.0 for invalid response, got %.2f", cash)
-	}
-
-	// Test with missing free field
-	missingFreeResponse := map[string]interface{}{
-		"total": 2000.0,
-	}
-
-	cash = advisor.extractAvailableCash(missingFreeResponse)
-
-	if cash != 0.0 {
-		t.Errorf("Expected 0.0 for missing free field, got %.2f", cash)
+	// Test with no response
+	cash = advisor.extractAvailableCash(nil)
+	if !cash.IsZero() {
+		t.Errorf("Expected 0 for no response, got %s", cash)
 	}
 }
 
@@ -283,25 +282,23 @@ func TestCalculateTotalShares(t *testing.T) {
 	advisor := NewRoboAdvisor("test-api-key", true)
 	defer advisor.Close()
 
-	shares := map[string]float64{
-		"AAPL":  10.5,
-		"MSFT":  15.0,
-		"GOOGL": 8.25,
+	shares := map[string]trading212.Decimal{
+		"AAPL":  decimal("10.5"),
+		"MSFT":  decimal("15"),
+		"GOOGL": decimal("8.25"),
 	}
 
 	total := advisor.calculateTotalShares(shares)
-	expected := 33.75
-
-	if total != expected {
-		t.Errorf("Expected total shares %.2f, got %.2f", expected, total)
+	if total.String() != "33.75" {
+		t.Errorf("Expected total shares 33.75, got %s", total)
 	}
 
 	// Test empty shares
-	emptyShares := make(map[string]float64)
+	emptyShares := make(map[string]trading212.Decimal)
 	total = advisor.calculateTotalShares(emptyShares)
 
-	if total != 0.0 {
-		t.Errorf("Expected 0.0 for empty shares, got %.2f", total)
+	if !total.IsZero() {
+		t.Errorf("Expected 0 for empty shares, got %s", total)
 	}
 }
 
@@ -346,49 +343,72 @@ func TestInitializeStrategies(t *testing.T) {
 
 	// Test Conservative strategy allocation
 	conservative := strategies[Conservative]
-	if conservative.Equities != 0.4 {
-		t.Errorf("Expected Conservative equities 0.4, got %.2f", conservative.Equities)
+	if conservative.Equities.String() != "0.4" {
+		t.Errorf("Expected Conservative equities 0.4, got %s", conservative.Equities)
+	}
+
+	if conservative.Bonds.String() != "0.4" {
+		t.Errorf("Expected Conservative bonds 0.4, got %s", conservative.Bonds)
+	}
+}
+
+func TestDefaultClassification(t *testing.T) {
+	mapping := defaultClassification()
+
+	expectedSectors := map[string]string{
+		"SPY_US_EQ":  "Broad Market",
+		"AAPL_US_EQ": "Technology",
+		"JNJ_US_EQ":  "Healthcare",
+		"JPM_US_EQ":  "Financial",
+		"XOM_US_EQ":  "Energy",
+		"AMT_US_EQ":  "Real Estate",
+		"TLT_US_EQ":  "Government Bonds",
+		"TSM_US_EQ":  "Technology",
 	}
 
-	if conservative.Bonds != 0.4 {
-		t.Errorf("Expected Conservative bonds 0.4, got %.2f", conservative.Bonds)
+	for ticker, expectedSector := range expectedSectors {
+		if classification, exists := mapping.Tickers[ticker]; !exists || classification.Sector != expectedSector {
+			t.Errorf("Expected %s in sector %s, got %s", ticker, expectedSector, classification.Sector)
+		}
 	}
 }
 
-func TestInitializeInstrumentMapping(t *testing.T) {
-	mapping := initializeInstrumentMapping()
+func TestCalculateExposure(t *testing.T) {
+	advisor := NewRoboAdvisor("test-api-key", true)
+	defer advisor.Close()
 
-	expectedMappings := map[string]string{
-		"US_EQUITIES":   "SPY",
-		"TECHNOLOGY":    "AAPL",
-		"HEALTHCARE":    "JNJ",
-		"FINANCIAL":     "JPM",
-		"ENERGY":        "XOM",
-		"REITS":         "AMT",
-		"BONDS":         "TLT",
-		"INTERNATIONAL": "TSM",
-		"EMERGING":      "TSM",
+	positions := []trading212.Position{
+		{Ticker: "AAPL_US_EQ", Value: trading212.NewDecimalFromInt(600)},
+		{Ticker: "MSFT_US_EQ", Value: trading212.NewDecimalFromInt(200)},
+		{Ticker: "TLT_US_EQ", Value: trading212.NewDecimalFromInt(200)},
 	}
 
-	if len(mapping) != len(expectedMappings) {
-		t.Errorf("Expected %d mappings, got %d", len(expectedMappings), len(mapping))
+	instruments := []trading212.Instrument{{Ticker: "VUKEl_EQ", Type: "ETF", CurrencyCode: "GBP"}}
+
+	report := advisor.calculateExposure(positions, nil)
+	if len(report.Sectors) != 2 || report.Sectors[0].Name != "Technology" || report.Sectors[0].Weight != 0.8 {
+		t.Errorf("Expected 80%% technology exposure, got %+v", report.Sectors)
+	}
+	if len(report.Warnings) == 0 {
+		t.Error("Expected concentration warnings")
 	}
 
-	for key, expectedValue := range expectedMappings {
-		if value, exists := mapping[key]; !exists || value != expectedValue {
-			t.Errorf("Expected mapping %s -> %s, got %s", key, expectedValue, value)
-		}
+	// Instruments outside the mapping are classified from their metadata
+	positions = append(positions, trading212.Position{Ticker: "VUKEl_EQ", Value: trading212.NewDecimalFromInt(1000)})
+	report = advisor.calculateExposure(positions, instruments)
+	if len(report.Regions) != 2 || report.Regions[1].Name != "United Kingdom" || report.Regions[1].Weight != 0.5 {
+		t.Errorf("Expected 50%% UK exposure, got %+v", report.Regions)
 	}
 }
 
 // Benchmark tests for performance
 func BenchmarkCreateBaseInstruments(b *testing.B) {
 	allocation := AssetAllocation{
-		Technology: 0.4,
-		Healthcare: 0.2,
-		Financial:  0.2,
-		Energy:     0.1,
-		REITs:      0.1,
+		Technology: decimal("0.4"),
+		Healthcare: decimal("0.2"),
+		Financial:  decimal("0.2"),
+		Energy:     decimal("0.1"),
+		REITs:      decimal("0.1"),
 	}
 
 	allocator := &InstrumentAllocator{allocation: allocation}
@@ -401,12 +421,12 @@ func BenchmarkCreateBaseInstruments(b *testing.B) {
 
 func BenchmarkNormaliseWeights(b *testing.B) {
 	allocator := &InstrumentAllocator{}
-	instruments := map[string]float64{
-		"AAPL":  0.3,
-		"MSFT":  0.4,
-		"GOOGL": 0.3,
-		"META":  0.2,
-		"NVDA":  0.1,
+	instruments := map[string]trading212.Decimal{
+		"AAPL":  decimal("0.3"),
+		"MSFT":  decimal("0.4"),
+		"GOOGL": decimal("0.3"),
+		"META":  decimal("0.2"),
+		"NVDA":  decimal("0.1"),
 	}
 
 	b.ResetTimer()
@@ -414,3 +434,19 @@ func BenchmarkNormaliseWeights(b *testing.B) {
 		allocator.normaliseWeights(instruments)
 	}
 }
+
+func TestCalculatePerformance(t *testing.T) {
+	advisor := NewRoboAdvisor("test-api-key", true)
+	defer advisor.Close()
+
+	pies := []trading212.Pie{
+		{ID: 1, Result: &trading212.PieResult{InvestedValue: trading212.NewDecimalFromInt(1000), Value: trading212.NewDecimalFromInt(1100), Result: trading212.NewDecimalFromInt(100)}},
+		{ID: 2, Result: &trading212.PieResult{InvestedValue: trading212.NewDecimalFromInt(1000), Value: trading212.NewDecimalFromInt(1000)}},
+		{ID: 3},
+	}
+
+	totalValue, totalPnL, performance := advisor.calculatePerformance(pies, time.Now())
+	if totalValue.String() != "2100" || totalPnL.String() != "100" || math.Abs(performance-0.05) > 1e-9 {
+		t.Errorf("calculatePerformance() = %s, %s, %.4f", totalValue, totalPnL, performance)
+	}
+}
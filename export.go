@@ -0,0 +1,194 @@
+package trading212
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportRow is one line of the account history CSV export. Amounts keep the
+// currency given in the export, which is the account currency for totals,
+// results, taxes and fees and the instrument currency for prices.
+type ExportRow struct {
+	Action                 string    `json:"action"`
+	Time                   time.Time `json:"time"`
+	ISIN                   string    `json:"isin,omitempty"`
+	Ticker                 string    `json:"ticker,omitempty"`
+	Name                   string    `json:"name,omitempty"`
+	Shares                 float64   `json:"shares,omitempty"`
+	Price                  Decimal   `json:"price,omitzero"`
+	PriceCurrency          string    `json:"priceCurrency,omitempty"`
+	ExchangeRate           Decimal   `json:"exchangeRate,omitzero"`
+	Result                 Decimal   `json:"result,omitzero"`
+	ResultCurrency         string    `json:"resultCurrency,omitempty"`
+	Total                  Decimal   `json:"total"`
+	TotalCurrency          string    `json:"totalCurrency"`
+	WithholdingTax         Decimal   `json:"withholdingTax,omitzero"`
+	WithholdingTaxCurrency string    `json:"withholdingTaxCurrency,omitempty"`
+	StampDuty              Decimal   `json:"stampDuty,omitzero"`
+	TransactionTax         Decimal   `json:"transactionTax,omitzero"`
+	ConversionFee          Decimal   `json:"conversionFee,omitzero"`
+	Notes                  string    `json:"notes,omitempty"`
+	ID                     string    `json:"id,omitempty"`
+}
+
+// exportTimeLayouts are the timestamp formats used by exports over time
+var exportTimeLayouts = []string{"2006-01-02 15:04:05.000", "2006-01-02 15:04:05", time.RFC3339}
+
+// exportCurrencyHeader matches headers such as "Total (GBP)" used by older
+// exports instead of a separate currency column
+var exportCurrencyHeader = regexp.MustCompile(`^(.+) \(([A-Z]{3})\)$`)
+
+// IsBuy reports whether the row is a market, limit or stop buy
+func (r ExportRow) IsBuy() bool {
+	return strings.HasSuffix(strings.ToLower(r.Action), " buy")
+}
+
+// IsSell reports whether the row is a market, limit or stop sell
+func (r ExportRow) IsSell() bool {
+	return strings.HasSuffix(strings.ToLower(r.Action), " sell")
+}
+
+// IsDividend reports whether the row is a dividend payment
+func (r ExportRow) IsDividend() bool {
+	return strings.HasPrefix(strings.ToLower(r.Action), "dividend")
+}
+
+// Fees returns stamp duty, transaction tax and currency conversion fees, which
+// the export reports in the account currency
+func (r ExportRow) Fees() Decimal {
+	return SumDecimals(r.StampDuty, r.TransactionTax, r.ConversionFee)
+}
+
+// ParseExportCSV reads the account history CSV export. Columns are matched by
+// header name, so exports with extra, missing or reordered columns are read.
+func ParseExportCSV(r io.Reader) ([]ExportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export header: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	fixedCurrencies := make(map[string]string)
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if match := exportCurrencyHeader.FindStringSubmatch(name); match != nil && !strings.HasPrefix(name, "Currency") {
+			name = match[1]
+			fixedCurrencies[name] = match[2]
+		}
+		columns[name] = i
+	}
+	if _, exists := columns["Action"]; !exists {
+		return nil, fmt.Errorf("export has no Action column")
+	}
+
+	var rows []ExportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read export line %d: %v", line, err)
+		}
+
+		row, err := parseExportRecord(record, columns, fixedCurrencies)
+		if err != nil {
+			return nil, fmt.Errorf("export line %d: %v", line, err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// parseExportRecord converts one CSV record into a row
+func parseExportRecord(record []string, columns map[string]int, fixedCurrencies map[string]string) (ExportRow, error) {
+	text := func(name string) string {
+		if i, exists := columns[name]; exists && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	currency := func(name string) string {
+		if code := text("Currency (" + name + ")"); code != "" {
+			return code
+		}
+		return fixedCurrencies[name]
+	}
+
+	var err error
+	amount := func(name string) Decimal {
+		value := text(name)
+		if err != nil || value == "" || strings.EqualFold(value, "Not available") {
+			return Decimal{}
+		}
+		var d Decimal
+		d, err = ParseDecimal(value)
+		if err != nil {
+			err = fmt.Errorf("invalid %s %q", name, value)
+		}
+		return d
+	}
+
+	row := ExportRow{
+		Action:                 text("Action"),
+		ISIN:                   text("ISIN"),
+		Ticker:                 text("Ticker"),
+		Name:                   text("Name"),
+		Price:                  amount("Price / share"),
+		PriceCurrency:          currency("Price / share"),
+		ExchangeRate:           amount("Exchange rate"),
+		Result:                 amount("Result"),
+		ResultCurrency:         currency("Result"),
+		Total:                  amount("Total"),
+		TotalCurrency:          currency("Total"),
+		WithholdingTax:         amount("Withholding tax"),
+		WithholdingTaxCurrency: currency("Withholding tax"),
+		StampDuty:              amount("Stamp duty reserve tax"),
+		TransactionTax:         amount("French transaction tax"),
+		ConversionFee:          amount("Currency conversion fee"),
+		Notes:                  text("Notes"),
+		ID:                     text("ID"),
+	}
+	if err != nil {
+		return ExportRow{}, err
+	}
+
+	if shares := text("No. of shares"); shares != "" {
+		if row.Shares, err = strconv.ParseFloat(shares, 64); err != nil {
+			return ExportRow{}, fmt.Errorf("invalid No. of shares %q", shares)
+		}
+	}
+	if row.Time, err = parseExportTime(text("Time")); err != nil {
+		return ExportRow{}, err
+	}
+	return row, nil
+}
+
+// parseExportTime parses an export timestamp, which is in UTC
+func parseExportTime(value string) (time.Time, error) {
+	for _, layout := range exportTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid Time %q", value)
+}
+
+// ParseDate parses a date in files such as rates, prices and corporate
+// actions, written either as YYYY-MM-DD or as an RFC 3339 timestamp
+func ParseDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,92 @@
+package trading212
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseExportCSV tests reading export rows by header name
+func TestParseExportCSV(t *testing.T) {
+	export := "\ufeffAction,Time,ISIN,Ticker,Name,No. of shares,Price / share,Currency (Price / share),Exchange rate,Result,Currency (Result),Total,Currency (Total),Stamp duty reserve tax,Currency (Stamp duty reserve tax),Currency conversion fee,Currency (Currency conversion fee),Notes,ID\n" +
+		"Market buy,2024-01-15 14:30:00,US0378331005,AAPL,Apple,2.5,180.50,USD,1.27,,,355.71,GBP,,,0.53,GBP,,EOF1\n" +
+		"Market sell,2024-03-01 09:00:00.123,GB0007980591,BP,BP,10,480,GBX,100,12.30,GBP,47.95,GBP,,,,,,EOF2\n" +
+		"Deposit,2024-01-01 08:00:00,,,,,,,Not available,,,1000,GBP,,,,,Bank transfer,DEP1\n"
+
+	rows, err := ParseExportCSV(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("ParseExportCSV() error = %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("ParseExportCSV() returned %d rows, want 3", len(rows))
+	}
+
+	buy := rows[0]
+	if !buy.IsBuy() || buy.IsSell() || buy.Shares != 2.5 || buy.Price != MustParseDecimal("180.5") || buy.PriceCurrency != "USD" {
+		t.Errorf("buy = %+v", buy)
+	}
+	if buy.ExchangeRate != MustParseDecimal("1.27") || buy.Fees() != MustParseDecimal("0.53") || buy.TotalCurrency != "GBP" {
+		t.Errorf("buy rate and fees = %+v", buy)
+	}
+	if !buy.Time.Equal(time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("buy time = %v", buy.Time)
+	}
+
+	if sell := rows[1]; !sell.IsSell() || sell.Result != MustParseDecimal("12.3") || sell.ResultCurrency != "GBP" {
+		t.Errorf("sell = %+v", sell)
+	}
+	if deposit := rows[2]; deposit.Action != "Deposit" || !deposit.ExchangeRate.IsZero() || deposit.Notes != "Bank transfer" {
+		t.Errorf("deposit = %+v", deposit)
+	}
+}
+
+// TestParseExportCSVCurrencyHeaders tests older exports with the currency in
+// the column name
+func TestParseExportCSVCurrencyHeaders(t *testing.T) {
+	export := "Action,Time,Ticker,No. of shares,Price / share,Currency (Price / share),Result (EUR),Total (EUR)\n" +
+		"Limit sell,2023-06-01 10:00:00,SAP,1,120,EUR,5.5,120\n"
+
+	rows, err := ParseExportCSV(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("ParseExportCSV() error = %v", err)
+	}
+	if rows[0].TotalCurrency != "EUR" || rows[0].ResultCurrency != "EUR" || rows[0].Total != NewDecimalFromInt(120) {
+		t.Errorf("row = %+v", rows[0])
+	}
+}
+
+// TestParseExportCSVErrors tests malformed exports
+func TestParseExportCSVErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"Time,Total\n2024-01-01 00:00:00,1\n",
+		"Action,Time,Total\nDeposit,yesterday,1\n",
+		"Action,Time,Total\nDeposit,2024-01-01 00:00:00,lots\n",
+	}
+
+	for _, export := range tests {
+		if _, err := ParseExportCSV(strings.NewReader(export)); err == nil {
+			t.Errorf("Expected error for %q", export)
+		}
+	}
+}
+
+// TestParseDate tests dates and timestamps in data files
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{value: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2024-03-01T09:30:00Z", want: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.value)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseDate(%q) = %v, %v", tt.value, got, err)
+		}
+	}
+	if _, err := ParseDate("01/03/2024"); err == nil {
+		t.Error("Expected error for an unsupported date format")
+	}
+}
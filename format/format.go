@@ -0,0 +1,250 @@
+// Package format renders Trading212 results as aligned tables, JSON,
+// newline-delimited JSON or CSV.
+package format
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Format identifies an output format
+type Format string
+
+const (
+	Table  Format = "table"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+	CSV    Format = "csv"
+)
+
+// Formats lists the supported output formats
+var Formats = []Format{Table, JSON, NDJSON, CSV}
+
+// Options controls how values are rendered
+type Options struct {
+	// Currency is the account currency code used to format money columns in tables
+	Currency string
+}
+
+// Parse returns the format with the given name
+func Parse(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q, must be one of %v", name, Formats)
+}
+
+// Write renders v to w in the given format. v may be a struct, a map, or a
+// slice of either, optionally behind pointers.
+func Write(w io.Writer, format Format, v interface{}, opts Options) error {
+	switch format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case NDJSON:
+		return writeNDJSON(w, v)
+	case CSV:
+		return writeCSV(w, v)
+	case Table:
+		return writeTable(w, v, opts)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// writeNDJSON writes one JSON document per element
+func writeNDJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	for _, item := range elements(v) {
+		if err := encoder.Encode(item.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes a header row followed by one row per element
+func writeCSV(w io.Writer, v interface{}) error {
+	columns, rows := tabulate(v)
+	if len(columns) == 0 {
+		return nil
+	}
+
+	writer := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.name
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = formatRaw(row[col.name])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeTable writes an aligned, human-readable table
+func writeTable(w io.Writer, v interface{}, opts Options) error {
+	columns, rows := tabulate(v)
+	if len(columns) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = headerName(col.name)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			cells[i] = formatHuman(col, row[col.name], opts)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// column is a named column of tabular output, with the currency of its
+// amounts when the field has a currency tag
+type column struct {
+	name     string
+	currency string
+}
+
+// tabulate flattens v into columns and rows keyed by column name
+func tabulate(v interface{}) ([]column, []map[string]reflect.Value) {
+	var columns []column
+	seen := make(map[string]bool)
+	var rows []map[string]reflect.Value
+
+	addColumn := func(col column) {
+		if !seen[col.name] {
+			seen[col.name] = true
+			columns = append(columns, col)
+		}
+	}
+
+	for _, item := range elements(v) {
+		row := make(map[string]reflect.Value)
+
+		switch item.Kind() {
+		case reflect.Struct:
+			for _, field := range structFields(item.Type()) {
+				addColumn(column{name: field.name, currency: field.currency})
+				row[field.name] = item.FieldByIndex(field.index)
+			}
+		case reflect.Map:
+			keys := item.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+			for _, key := range keys {
+				name := fmt.Sprint(key.Interface())
+				addColumn(column{name: name})
+				row[name] = item.MapIndex(key)
+			}
+		default:
+			addColumn(column{name: "value"})
+			row["value"] = item
+		}
+
+		rows = append(rows, row)
+	}
+
+	return columns, rows
+}
+
+// elements returns the dereferenced elements of a slice, or v itself
+func elements(v interface{}) []reflect.Value {
+	value := indirect(reflect.ValueOf(v))
+	if !value.IsValid() {
+		return nil
+	}
+
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return []reflect.Value{value}
+	}
+
+	items := make([]reflect.Value, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		if item := indirect(value.Index(i)); item.IsValid() {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// indirect dereferences pointers and interfaces
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// field is an exported struct field, its output name and the currency named
+// by its currency tag
+type field struct {
+	name     string
+	currency string
+	index    []int
+}
+
+// structFields returns the exported fields of a struct type named by their
+// json tags
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := sf.Name
+		if tag, _, _ := strings.Cut(sf.Tag.Get("json"), ","); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		fields = append(fields, field{name: name, currency: sf.Tag.Get("currency"), index: sf.Index})
+	}
+	return fields
+}
+
+// headerName converts a camelCase field name into an upper-case table header
+func headerName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteRune('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
@@ -0,0 +1,146 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// TestParse tests format name parsing
+func TestParse(t *testing.T) {
+	for _, name := range []string{"table", "JSON", "ndjson", "csv"} {
+		if _, err := Parse(name); err != nil {
+			t.Errorf("Parse(%q) error = %v", name, err)
+		}
+	}
+	if _, err := Parse("yaml"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+// TestWriteTable tests aligned table output with currency formatting
+func TestWriteTable(t *testing.T) {
+	positions := []trading212.Position{
+		{Ticker: "AAPL", Quantity: 10.5, Value: trading212.MustParseDecimal("1500.75")},
+		{Ticker: "GOOGL", Quantity: 5, Value: trading212.NewDecimalFromInt(12500)},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, Table, positions, Options{Currency: "GBP"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("table has %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "TICKER") || !strings.Contains(lines[0], "QUANTITY") {
+		t.Errorf("header = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "£1,500.75") || !strings.Contains(lines[2], "£12,500.00") {
+		t.Errorf("rows not currency formatted:\n%s", buf.String())
+	}
+	if strings.Index(lines[1], "10.5") != strings.Index(lines[2], "5") {
+		t.Errorf("columns not aligned:\n%s", buf.String())
+	}
+}
+
+// TestWriteTableCurrencyTag tests that a currency tag, not the field name,
+// sets the currency of a money column
+func TestWriteTableCurrencyTag(t *testing.T) {
+	rows := []struct {
+		Amount trading212.Decimal `json:"amount"`
+		Euro   trading212.Decimal `json:"amountInEuro" currency:"EUR"`
+		Neuro  trading212.Decimal `json:"neuroValue"`
+	}{{Amount: trading212.NewDecimalFromInt(10), Euro: trading212.NewDecimalFromInt(12), Neuro: trading212.NewDecimalFromInt(3)}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, Table, rows, Options{Currency: "GBP"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if fields := strings.Fields(strings.Split(strings.TrimSpace(buf.String()), "\n")[1]); strings.Join(fields, " ") != "£10.00 €12.00 £3.00" {
+		t.Errorf("row = %q, want £10.00 €12.00 £3.00", fields)
+	}
+
+	buf.Reset()
+	dividends := []trading212.Dividend{{Ticker: "AAPL", AmountInEuro: trading212.MustParseDecimal("4.5")}}
+	if err := Write(&buf, Table, dividends, Options{Currency: "GBP"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "€4.50") {
+		t.Errorf("dividend AMOUNT_IN_EURO not in euros:\n%s", buf.String())
+	}
+}
+
+// TestWriteCSV tests CSV output of a single struct
+func TestWriteCSV(t *testing.T) {
+	cash := &trading212.CashInfo{Free: trading212.MustParseDecimal("1000.5"), Total: trading212.NewDecimalFromInt(5000)}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, CSV, cash, Options{Currency: "GBP"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := "free,total,pieOrders,interest,cashForInvestment\n1000.5,5000,0,0,0\n"
+	if buf.String() != want {
+		t.Errorf("CSV = %q, want %q", buf.String(), want)
+	}
+}
+
+// TestWriteNDJSON tests one JSON document per element
+func TestWriteNDJSON(t *testing.T) {
+	orders := []trading212.HistoricalOrder{
+		{ID: 1, Ticker: "AAPL", DateExecuted: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Ticker: "MSFT"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, NDJSON, orders, Options{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("NDJSON has %d lines, want 2", len(lines))
+	}
+	var decoded trading212.HistoricalOrder
+	if err := json.Unmarshal([]byte(lines[1]), &decoded); err != nil || decoded.ID != 2 {
+		t.Errorf("line 2 = %s, err %v", lines[1], err)
+	}
+}
+
+// TestWriteMaps tests tabulating untyped API items with differing keys
+func TestWriteMaps(t *testing.T) {
+	items := []interface{}{
+		map[string]interface{}{"type": "DEPOSIT", "amount": 100.0},
+		map[string]interface{}{"type": "FEE", "reference": "abc"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, CSV, items, Options{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := "amount,type,reference\n100,DEPOSIT,\n,FEE,abc\n"
+	if buf.String() != want {
+		t.Errorf("CSV = %q, want %q", buf.String(), want)
+	}
+}
+
+// TestWriteEmpty tests that empty results produce no output
+func TestWriteEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Table, []trading212.Position{}, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(&buf, CSV, nil, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("output = %q, want empty", buf.String())
+	}
+}
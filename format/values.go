@@ -0,0 +1,198 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// currencySymbols maps currency codes to their display prefix
+var currencySymbols = map[string]string{
+	"GBP": "£",
+	"USD": "$",
+	"EUR": "€",
+	"JPY": "¥",
+}
+
+// moneyWords identify field names that hold monetary amounts
+var moneyWords = []string{"price", "value", "amount", "cash", "free", "total", "interest", "ppl", "result", "cost", "invested", "pieorders"}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	decimalType = reflect.TypeOf(trading212.Decimal{})
+	moneyType   = reflect.TypeOf(trading212.Money{})
+)
+
+// FormatMoney formats an amount with thousands separators, two decimal places and
+// the currency symbol, e.g. £1,234.56. GBX amounts are shown in pence.
+func FormatMoney(amount float64, currency string) string {
+	return formatMoney(amount < 0, strconv.FormatFloat(math.Abs(amount), 'f', 2, 64), currency)
+}
+
+// FormatDecimalMoney formats a Decimal amount like FormatMoney, rounding half
+// to even to the currency's minor unit without passing through float64
+func FormatDecimalMoney(amount trading212.Decimal, currency string) string {
+	places := trading212.CurrencyPlaces(currency)
+	rounded := amount.Round(places, trading212.RoundHalfEven)
+	return formatMoney(rounded.Sign() < 0, rounded.Abs().StringFixed(places), currency)
+}
+
+// formatMoney adds the sign, thousands separators and currency to an unsigned
+// fixed-point amount
+func formatMoney(negative bool, amount string, currency string) string {
+	sign := ""
+	if negative {
+		sign = "-"
+	}
+
+	digits := groupThousands(amount)
+	currency = strings.ToUpper(currency)
+
+	switch {
+	case currency == "":
+		return sign + digits
+	case currency == "GBX":
+		return sign + digits + "p"
+	case currencySymbols[currency] != "":
+		return sign + currencySymbols[currency] + digits
+	default:
+		return sign + digits + " " + currency
+	}
+}
+
+// groupThousands inserts commas into the integer part of a formatted number
+func groupThousands(number string) string {
+	integer, fraction, hasFraction := strings.Cut(number, ".")
+
+	var b strings.Builder
+	for i, r := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+
+	if hasFraction {
+		b.WriteByte('.')
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// isMoneyField reports whether a field name looks like a monetary amount
+func isMoneyField(name string) bool {
+	lower := strings.ToLower(name)
+	if strings.Contains(lower, "quantity") || strings.Contains(lower, "coef") {
+		return false
+	}
+	for _, word := range moneyWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatHuman formats a cell of col for table output
+func formatHuman(col column, value reflect.Value, opts Options) string {
+	value = indirect(value)
+	if !value.IsValid() {
+		return ""
+	}
+
+	if value.Type() == timeType {
+		t := value.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04")
+	}
+
+	switch value.Type() {
+	case decimalType:
+		amount := value.Interface().(trading212.Decimal)
+		if isMoneyField(col.name) {
+			return FormatDecimalMoney(amount, col.fieldCurrency(opts))
+		}
+		return amount.String()
+	case moneyType:
+		money := value.Interface().(trading212.Money)
+		currency := money.Currency
+		if currency == "" {
+			currency = opts.Currency
+		}
+		return FormatDecimalMoney(money.Amount, currency)
+	}
+
+	if value.Kind() == reflect.Float32 || value.Kind() == reflect.Float64 {
+		amount := value.Float()
+		if isMoneyField(col.name) {
+			return FormatMoney(amount, col.fieldCurrency(opts))
+		}
+		return formatFloat(amount)
+	}
+
+	return formatRaw(value)
+}
+
+// fieldCurrency returns the currency of the column's amounts, which is the
+// account currency unless its field has a currency tag
+func (col column) fieldCurrency(opts Options) string {
+	if col.currency != "" {
+		return col.currency
+	}
+	return opts.Currency
+}
+
+// formatRaw formats a cell for machine-readable output
+func formatRaw(value reflect.Value) string {
+	value = indirect(value)
+	if !value.IsValid() {
+		return ""
+	}
+
+	if value.Type() == timeType {
+		t := value.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	if stringer, ok := value.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return formatFloat(value.Float())
+	default:
+		data, err := json.Marshal(value.Interface())
+		if err != nil {
+			return fmt.Sprint(value.Interface())
+		}
+		return string(data)
+	}
+}
+
+// formatFloat formats a float without exponent or trailing zeros
+func formatFloat(f float64) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
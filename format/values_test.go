@@ -0,0 +1,64 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/0xnu/trading212"
+)
+
+// TestFormatMoney tests currency formatting
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{amount: 1234.567, currency: "GBP", want: "£1,234.57"},
+		{amount: -12, currency: "usd", want: "-$12.00"},
+		{amount: 1000000, currency: "EUR", want: "€1,000,000.00"},
+		{amount: 250.5, currency: "GBX", want: "250.50p"},
+		{amount: 99.9, currency: "CHF", want: "99.90 CHF"},
+		{amount: 123, currency: "", want: "123.00"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatMoney(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("FormatMoney(%v, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+// TestFormatDecimalMoney tests currency formatting of exact amounts
+func TestFormatDecimalMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     string
+	}{
+		{amount: "1234.565", currency: "GBP", want: "£1,234.56"},
+		{amount: "1234.575", currency: "GBP", want: "£1,234.58"},
+		{amount: "-0.004", currency: "USD", want: "$0.00"},
+		{amount: "1500.5", currency: "JPY", want: "¥1,500"},
+		{amount: "250.5", currency: "GBX", want: "250.50p"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDecimalMoney(trading212.MustParseDecimal(tt.amount), tt.currency); got != tt.want {
+			t.Errorf("FormatDecimalMoney(%s, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+// TestIsMoneyField tests monetary column detection
+func TestIsMoneyField(t *testing.T) {
+	for _, name := range []string{"value", "fillPrice", "cashForInvestment", "amountInEuro", "ppl"} {
+		if !isMoneyField(name) {
+			t.Errorf("isMoneyField(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"quantity", "filledQuantity", "ticker", "id"} {
+		if isMoneyField(name) {
+			t.Errorf("isMoneyField(%q) = true, want false", name)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Package fx converts amounts between currencies and values portfolios in the
+// account currency, keeping price and exchange rate gains apart.
+package fx
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// RateSource provides exchange rates. Rate returns the number of units of to
+// bought by one unit of from at the given time.
+type RateSource interface {
+	Rate(from, to string, at time.Time) (trading212.Decimal, error)
+}
+
+// Pair identifies a direction of exchange between two ISO 4217 currencies
+type Pair struct {
+	From string
+	To   string
+}
+
+// StaticRates is a fixed table of rates used regardless of time
+type StaticRates map[Pair]trading212.Decimal
+
+// Rate returns the table's rate for the pair
+func (s StaticRates) Rate(from, to string, at time.Time) (trading212.Decimal, error) {
+	if rate, exists := s[Pair{From: strings.ToUpper(from), To: strings.ToUpper(to)}]; exists && rate.Sign() > 0 {
+		return rate, nil
+	}
+	return trading212.Decimal{}, fmt.Errorf("no %s/%s rate", from, to)
+}
+
+// Sources tries each rate source in turn and returns the first rate found
+type Sources []RateSource
+
+// Rate returns the first source's rate for the pair
+func (s Sources) Rate(from, to string, at time.Time) (trading212.Decimal, error) {
+	for _, source := range s {
+		if source == nil {
+			continue
+		}
+		if rate, err := source.Rate(from, to, at); err == nil {
+			return rate, nil
+		}
+	}
+	return trading212.Decimal{}, fmt.Errorf("no %s/%s rate", from, to)
+}
+
+// datedRate is a rate observed at a point in time
+type datedRate struct {
+	at   time.Time
+	rate trading212.Decimal
+}
+
+// HistoricalRates holds rates observed over time. A lookup returns the latest
+// rate observed on or before the requested time.
+type HistoricalRates struct {
+	rates map[Pair][]datedRate
+}
+
+// NewHistoricalRates returns an empty rate history
+func NewHistoricalRates() *HistoricalRates {
+	return &HistoricalRates{rates: make(map[Pair][]datedRate)}
+}
+
+// Add records a rate observed at the given time
+func (h *HistoricalRates) Add(from, to string, at time.Time, rate trading212.Decimal) {
+	pair := Pair{From: strings.ToUpper(from), To: strings.ToUpper(to)}
+	rates := h.rates[pair]
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].at.After(at) })
+	rates = append(rates, datedRate{})
+	copy(rates[i+1:], rates[i:])
+	rates[i] = datedRate{at: at, rate: rate}
+	h.rates[pair] = rates
+}
+
+// Rate returns the latest rate for the pair observed on or before at
+func (h *HistoricalRates) Rate(from, to string, at time.Time) (trading212.Decimal, error) {
+	rates := h.rates[Pair{From: strings.ToUpper(from), To: strings.ToUpper(to)}]
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].at.After(at) })
+	if i == 0 {
+		return trading212.Decimal{}, fmt.Errorf("no %s/%s rate on or before %s", from, to, at.Format("2006-01-02"))
+	}
+	return rates[i-1].rate, nil
+}
+
+// LoadRatesCSV reads rates from a CSV file with the header date,from,to,rate.
+// Dates are YYYY-MM-DD or RFC 3339 timestamps; a date applies from midnight UTC.
+func LoadRatesCSV(r io.Reader) (*HistoricalRates, error) {
+	reader := csv.NewReader(r)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("rates file is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "from", "to", "rate"} {
+		if _, exists := columns[name]; !exists {
+			return nil, fmt.Errorf("rates file has no %s column", name)
+		}
+	}
+
+	rates := NewHistoricalRates()
+	for line, record := range records[1:] {
+		field := func(name string) string { return strings.TrimSpace(record[columns[name]]) }
+
+		at, err := trading212.ParseDate(field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("rates line %d: %v", line+2, err)
+		}
+		rate, err := trading212.ParseDecimal(field("rate"))
+		if err != nil || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("rates line %d: invalid rate %q", line+2, field("rate"))
+		}
+		rates.Add(field("from"), field("to"), at, rate)
+	}
+	return rates, nil
+}
+
+// ExportRates collects the exchange rates applied to trades in an account
+// history export. The export's rate is the number of units of the instrument
+// currency per unit of the account currency.
+func ExportRates(rows []trading212.ExportRow) *HistoricalRates {
+	rates := NewHistoricalRates()
+	for _, row := range rows {
+		from, to := strings.ToUpper(row.TotalCurrency), strings.ToUpper(row.PriceCurrency)
+		if row.ExchangeRate.Sign() <= 0 || from == "" || to == "" || from == to || to == "GBX" {
+			continue
+		}
+		rates.Add(from, to, row.Time, row.ExchangeRate)
+	}
+	return rates
+}
+
+// Rate returns the rate from one currency to another. Same-currency and GBX
+// pence conversions need no source; other pairs are looked up in either
+// direction.
+func Rate(source RateSource, from, to string, at time.Time) (trading212.Decimal, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	scale := trading212.NewDecimalFromInt(1)
+	if from == "GBX" {
+		from, scale = "GBP", trading212.NewDecimal(1, -2)
+	}
+	if to == "GBX" {
+		to, scale = "GBP", scale.MulInt(100)
+	}
+	if from == to {
+		return scale, nil
+	}
+	if source == nil {
+		return trading212.Decimal{}, fmt.Errorf("no %s/%s rate", from, to)
+	}
+
+	if rate, err := source.Rate(from, to, at); err == nil {
+		return rate.Mul(scale), nil
+	}
+	inverse, err := source.Rate(to, from, at)
+	if err != nil || inverse.Sign() <= 0 {
+		return trading212.Decimal{}, fmt.Errorf("no %s/%s rate", from, to)
+	}
+	return scale.Div(inverse), nil
+}
+
+// Convert returns the amount in another currency at the rate in force at the
+// given time
+func Convert(source RateSource, amount trading212.Money, to string, at time.Time) (trading212.Money, error) {
+	rate, err := Rate(source, amount.Currency, to, at)
+	if err != nil {
+		return trading212.Money{}, err
+	}
+	return amount.Convert(rate, to), nil
+}
+
+// TradeValue returns the gross value and fees of a trade in the given
+// currency. The trade's own value is used when known, otherwise its price is
+// converted at the rate on the trade date.
+func TradeValue(source RateSource, trade trading212.Trade, currency string) (value, fees trading212.Decimal, err error) {
+	if trade.Value.IsZero() {
+		rate, err := Rate(source, trade.PriceCurrency, currency, trade.Time)
+		if err != nil {
+			return trading212.Decimal{}, trading212.Decimal{}, err
+		}
+		value = trade.Price.Mul(trade.Shares()).Mul(rate)
+	} else {
+		rate, err := Rate(source, trade.Currency, currency, trade.Time)
+		if err != nil {
+			return trading212.Decimal{}, trading212.Decimal{}, err
+		}
+		value = trade.Value.Mul(rate)
+	}
+
+	if !trade.Fees.IsZero() {
+		rate, err := Rate(source, trade.Currency, currency, trade.Time)
+		if err != nil {
+			return trading212.Decimal{}, trading212.Decimal{}, err
+		}
+		fees = trade.Fees.Mul(rate)
+	}
+	return value, fees, nil
+}
@@ -0,0 +1,105 @@
+package fx
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// TestRate tests direct, inverse, same-currency and pence conversions
+func TestRate(t *testing.T) {
+	rates := StaticRates{
+		{From: "GBP", To: "USD"}: trading212.MustParseDecimal("1.25"),
+		{From: "EUR", To: "GBP"}: trading212.MustParseDecimal("0.85"),
+	}
+	now := time.Now()
+
+	tests := []struct {
+		from, to string
+		want     string
+		wantErr  bool
+	}{
+		{from: "GBP", to: "GBP", want: "1"},
+		{from: "GBX", to: "GBP", want: "0.01"},
+		{from: "GBP", to: "GBX", want: "100"},
+		{from: "gbp", to: "usd", want: "1.25"},
+		{from: "USD", to: "GBP", want: "0.8"},
+		{from: "EUR", to: "GBX", want: "85"},
+		{from: "USD", to: "GBX", want: "80"},
+		{from: "GBX", to: "USD", want: "0.0125"},
+		{from: "USD", to: "JPY", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Rate(rates, tt.from, tt.to, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Rate(%s, %s) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("Rate(%s, %s) = %s, want %s", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if _, err := Rate(nil, "USD", "GBP", now); err == nil {
+		t.Error("Expected error without a rate source")
+	}
+}
+
+// TestHistoricalRates tests that lookups use the latest earlier rate
+func TestHistoricalRates(t *testing.T) {
+	rates, err := LoadRatesCSV(strings.NewReader("date,from,to,rate\n2024-02-01,GBP,USD,1.30\n2024-01-01,GBP,USD,1.25\n"))
+	if err != nil {
+		t.Fatalf("LoadRatesCSV() error = %v", err)
+	}
+
+	tests := []struct {
+		at      time.Time
+		want    string
+		wantErr bool
+	}{
+		{at: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), wantErr: true},
+		{at: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), want: "1.25"},
+		{at: time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC), want: "1.25"},
+		{at: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), want: "1.3"},
+	}
+
+	for _, tt := range tests {
+		got, err := rates.Rate("GBP", "USD", tt.at)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Rate(%v) error = %v, wantErr %v", tt.at, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("Rate(%v) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "date,from,to\n", "date,from,to,rate\nsoon,GBP,USD,1\n", "date,from,to,rate\n2024-01-01,GBP,USD,-1\n"} {
+		if _, err := LoadRatesCSV(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+// TestExportRates tests rates implied by export rows
+func TestExportRates(t *testing.T) {
+	rows := []trading212.ExportRow{
+		{Action: "Market buy", Time: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), PriceCurrency: "USD", TotalCurrency: "GBP", ExchangeRate: trading212.MustParseDecimal("1.27")},
+		{Action: "Market buy", Time: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), PriceCurrency: "GBX", TotalCurrency: "GBP", ExchangeRate: trading212.NewDecimalFromInt(100)},
+		{Action: "Deposit", Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), TotalCurrency: "GBP"},
+	}
+	rates := ExportRates(rows)
+
+	got, err := Rate(rates, "USD", "GBP", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || got.String() != "0.78740157" {
+		t.Errorf("Rate(USD, GBP) = %s, %v", got, err)
+	}
+
+	amount, err := Convert(rates, trading212.NewMoney(trading212.NewDecimalFromInt(127), "USD"), "GBP", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || amount.Round(trading212.RoundHalfEven).String() != "100.00 GBP" {
+		t.Errorf("Convert() = %v, %v", amount, err)
+	}
+}
@@ -0,0 +1,147 @@
+package fx
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// Holding is a position valued in the account currency. Prices are Money in
+// the instrument currency as quoted, so GBX instruments are priced in pence.
+type Holding struct {
+	Ticker       string             `json:"ticker"`
+	Name         string             `json:"name,omitempty"`
+	Quantity     float64            `json:"quantity"`
+	Currency     string             `json:"currency"`
+	AveragePrice trading212.Money   `json:"averagePrice"`
+	CurrentPrice trading212.Money   `json:"currentPrice"`
+	OpenRate     trading212.Decimal `json:"openRate"`
+	Rate         trading212.Decimal `json:"rate"`
+	Cost         trading212.Decimal `json:"cost"`
+	Value        trading212.Decimal `json:"value"`
+	PricePnL     trading212.Decimal `json:"pricePnl"`
+	FXPnL        trading212.Decimal `json:"fxPnl"`
+	PnL          trading212.Decimal `json:"pnl"`
+}
+
+// Valuation is a portfolio valued in the account currency
+type Valuation struct {
+	Currency string             `json:"currency"`
+	At       time.Time          `json:"at"`
+	Holdings []Holding          `json:"holdings"`
+	Cash     trading212.Decimal `json:"cash"`
+	Cost     trading212.Decimal `json:"cost"`
+	Value    trading212.Decimal `json:"value"`
+	PricePnL trading212.Decimal `json:"pricePnl"`
+	FXPnL    trading212.Decimal `json:"fxPnl"`
+	PnL      trading212.Decimal `json:"pnl"`
+	Total    trading212.Decimal `json:"total"`
+	Warnings []string           `json:"warnings,omitempty"`
+}
+
+// ValuePortfolio values positions in the account currency. Each position's
+// currency comes from the instrument metadata. Its cost is converted at the
+// rate on its initial fill date and its value at the rate in force at the
+// given time, so that
+//
+//	PricePnL = (current price - average price) × quantity × current rate
+//	FXPnL    = average price × quantity × (current rate - opening rate)
+//
+// and PnL is their sum. Positions without a current price fall back to the
+// value and P&L reported by the API. Free cash is added to the total when
+// cash is not nil.
+func ValuePortfolio(positions []trading212.Position, instruments []trading212.Instrument, accountCurrency string, cash *trading212.CashInfo, source RateSource, at time.Time) (*Valuation, error) {
+	currencies := make(map[string]trading212.Instrument, len(instruments))
+	for _, instrument := range instruments {
+		currencies[instrument.Ticker] = instrument
+	}
+
+	valuation := &Valuation{Currency: strings.ToUpper(accountCurrency), At: at}
+	for _, position := range positions {
+		instrument, found := currencies[position.Ticker]
+		if !found || instrument.CurrencyCode == "" {
+			instrument.CurrencyCode = valuation.Currency
+			valuation.Warnings = append(valuation.Warnings, fmt.Sprintf("no instrument currency for %s, assuming %s", position.Ticker, valuation.Currency))
+		}
+
+		holding, warning, err := valueHolding(position, instrument, valuation.Currency, source, at)
+		if err != nil {
+			return nil, fmt.Errorf("failed to value %s: %v", position.Ticker, err)
+		}
+		if warning != "" {
+			valuation.Warnings = append(valuation.Warnings, warning)
+		}
+
+		valuation.Holdings = append(valuation.Holdings, holding)
+		valuation.Cost = valuation.Cost.Add(holding.Cost)
+		valuation.Value = valuation.Value.Add(holding.Value)
+		valuation.PricePnL = valuation.PricePnL.Add(holding.PricePnL)
+		valuation.FXPnL = valuation.FXPnL.Add(holding.FXPnL)
+		valuation.PnL = valuation.PnL.Add(holding.PnL)
+	}
+
+	if cash != nil {
+		valuation.Cash = cash.Free
+	}
+	valuation.Total = valuation.Value.Add(valuation.Cash)
+	return valuation, nil
+}
+
+// valueHolding values one position, returning a warning when the split between
+// price and FX P&L is unavailable
+func valueHolding(position trading212.Position, instrument trading212.Instrument, accountCurrency string, source RateSource, at time.Time) (Holding, string, error) {
+	currency := strings.ToUpper(instrument.CurrencyCode)
+	holding := Holding{
+		Ticker:       position.Ticker,
+		Name:         instrument.Name,
+		Quantity:     position.Quantity,
+		Currency:     currency,
+		AveragePrice: trading212.NewMoney(position.AveragePrice, currency),
+		CurrentPrice: trading212.NewMoney(position.CurrentPrice, currency),
+	}
+
+	if position.CurrentPrice.IsZero() {
+		holding.Value = position.Value
+		holding.PnL = position.PPL
+		holding.FXPnL = position.FxPPL
+		holding.PricePnL = position.PPL.Sub(position.FxPPL)
+		holding.Cost = position.Value.Sub(position.PPL)
+		return holding, "no current price for " + position.Ticker + ", using the value reported by the API", nil
+	}
+
+	rate, err := Rate(source, holding.Currency, accountCurrency, at)
+	if err != nil {
+		return Holding{}, "", err
+	}
+	holding.Rate = rate
+
+	var warning string
+	holding.OpenRate = rate
+	if holding.Currency != accountCurrency && !isPence(holding.Currency, accountCurrency) {
+		openRate, err := Rate(source, holding.Currency, accountCurrency, position.InitialFillDate)
+		if err == nil && !position.InitialFillDate.IsZero() {
+			holding.OpenRate = openRate
+		} else {
+			warning = "no opening rate for " + position.Ticker + ", FX P&L is included in price P&L"
+		}
+	}
+
+	quantity := trading212.NewDecimalFromFloat(position.Quantity)
+	cost := position.AveragePrice.Mul(quantity)
+	value := position.CurrentPrice.Mul(quantity)
+
+	holding.Cost = cost.Mul(holding.OpenRate)
+	holding.Value = value.Mul(rate)
+	holding.PricePnL = value.Sub(cost).Mul(rate)
+	holding.FXPnL = cost.Mul(rate.Sub(holding.OpenRate))
+	holding.PnL = holding.PricePnL.Add(holding.FXPnL)
+	return holding, warning, nil
+}
+
+// isPence reports whether one currency is the pence unit of the other, which
+// converts at a fixed rate
+func isPence(a, b string) bool {
+	return (a == "GBX" && b == "GBP") || (a == "GBP" && b == "GBX")
+}
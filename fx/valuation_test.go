@@ -0,0 +1,87 @@
+package fx
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// TestValuePortfolio tests valuation in the account currency with FX P&L
+// separated from price P&L
+func TestValuePortfolio(t *testing.T) {
+	opened := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	rates := NewHistoricalRates()
+	rates.Add("GBP", "USD", opened, trading212.MustParseDecimal("1.25"))
+	rates.Add("GBP", "USD", now, trading212.MustParseDecimal("1.6"))
+
+	instruments := []trading212.Instrument{
+		{Ticker: "AAPL_US_EQ", Name: "Apple", CurrencyCode: "USD"},
+		{Ticker: "BPl_EQ", Name: "BP", CurrencyCode: "GBX"},
+		{Ticker: "VUSAl_EQ", Name: "Vanguard S&P 500", CurrencyCode: "GBP"},
+	}
+	positions := []trading212.Position{
+		{Ticker: "AAPL_US_EQ", Quantity: 10, AveragePrice: trading212.NewDecimalFromInt(100), CurrentPrice: trading212.NewDecimalFromInt(120), InitialFillDate: opened},
+		{Ticker: "BPl_EQ", Quantity: 100, AveragePrice: trading212.NewDecimalFromInt(450), CurrentPrice: trading212.NewDecimalFromInt(500)},
+		{Ticker: "VUSAl_EQ", Quantity: 2, Value: trading212.NewDecimalFromInt(170), PPL: trading212.NewDecimalFromInt(10)},
+	}
+	cash := &trading212.CashInfo{Free: trading212.NewDecimalFromInt(50)}
+
+	valuation, err := ValuePortfolio(positions, instruments, "GBP", cash, rates, now)
+	if err != nil {
+		t.Fatalf("ValuePortfolio() error = %v", err)
+	}
+
+	tests := []struct {
+		holding                      Holding
+		value, cost, pricePnL, fxPnL string
+	}{
+		// 1000 USD cost at 0.8 = 800 GBP, 1200 USD value at 0.625 = 750 GBP:
+		// +200 USD is +125 GBP of price P&L and the weaker dollar is -175 GBP.
+		{holding: valuation.Holdings[0], value: "750", cost: "800", pricePnL: "125", fxPnL: "-175"},
+		{holding: valuation.Holdings[1], value: "500", cost: "450", pricePnL: "50", fxPnL: "0"},
+		{holding: valuation.Holdings[2], value: "170", cost: "160", pricePnL: "10", fxPnL: "0"},
+	}
+	for _, tt := range tests {
+		h := tt.holding
+		if h.Value.String() != tt.value || h.Cost.String() != tt.cost || h.PricePnL.String() != tt.pricePnL || h.FXPnL.String() != tt.fxPnL {
+			t.Errorf("%s = value %s cost %s price %s fx %s, want %s %s %s %s", h.Ticker, h.Value, h.Cost, h.PricePnL, h.FXPnL, tt.value, tt.cost, tt.pricePnL, tt.fxPnL)
+		}
+		if h.PnL != h.PricePnL.Add(h.FXPnL) || h.PnL != h.Value.Sub(h.Cost) {
+			t.Errorf("%s P&L %s does not reconcile", h.Ticker, h.PnL)
+		}
+	}
+
+	if price := valuation.Holdings[0].CurrentPrice; price.String() != "120.00 USD" {
+		t.Errorf("CurrentPrice = %s, want 120.00 USD", price)
+	}
+
+	if valuation.Value.String() != "1420" || valuation.PnL.String() != "10" || valuation.FXPnL.String() != "-175" || valuation.Total.String() != "1470" {
+		t.Errorf("totals = value %s pnl %s fx %s total %s", valuation.Value, valuation.PnL, valuation.FXPnL, valuation.Total)
+	}
+	if len(valuation.Warnings) != 1 || !strings.Contains(valuation.Warnings[0], "VUSAl_EQ") {
+		t.Errorf("Warnings = %v", valuation.Warnings)
+	}
+}
+
+// TestValuePortfolioMissingRate tests that positions needing an unknown rate
+// are reported rather than valued at the wrong rate
+func TestValuePortfolioMissingRate(t *testing.T) {
+	instruments := []trading212.Instrument{{Ticker: "SAP", CurrencyCode: "EUR"}}
+	positions := []trading212.Position{{Ticker: "SAP", Quantity: 1, AveragePrice: trading212.NewDecimalFromInt(100), CurrentPrice: trading212.NewDecimalFromInt(110)}}
+
+	if _, err := ValuePortfolio(positions, instruments, "GBP", nil, StaticRates{}, time.Now()); err == nil {
+		t.Error("Expected error for a missing EUR/GBP rate")
+	}
+
+	valuation, err := ValuePortfolio(positions, instruments, "GBP", nil, StaticRates{{From: "EUR", To: "GBP"}: trading212.MustParseDecimal("0.85")}, time.Now())
+	if err != nil {
+		t.Fatalf("ValuePortfolio() error = %v", err)
+	}
+	if !valuation.FXPnL.IsZero() || valuation.PnL.String() != "8.5" || len(valuation.Warnings) != 1 {
+		t.Errorf("valuation = %+v", valuation)
+	}
+}
@@ -0,0 +1,164 @@
+package trading212
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// HistoricalOrder represents an order from the account history
+type HistoricalOrder struct {
+	ID              int64     `json:"id"`
+	ParentOrder     int64     `json:"parentOrder"`
+	Ticker          string    `json:"ticker"`
+	Type            string    `json:"type"`
+	Status          string    `json:"status"`
+	Executor        string    `json:"executor"`
+	TimeValidity    string    `json:"timeValidity"`
+	OrderedQuantity float64   `json:"orderedQuantity"`
+	OrderedValue    Decimal   `json:"orderedValue"`
+	FilledQuantity  float64   `json:"filledQuantity"`
+	FilledValue     Decimal   `json:"filledValue"`
+	LimitPrice      Decimal   `json:"limitPrice"`
+	StopPrice       Decimal   `json:"stopPrice"`
+	FillID          int64     `json:"fillId"`
+	FillPrice       Decimal   `json:"fillPrice"`
+	FillResult      Decimal   `json:"fillResult"`
+	FillType        string    `json:"fillType"`
+	DateCreated     time.Time `json:"dateCreated"`
+	DateExecuted    time.Time `json:"dateExecuted"`
+	DateModified    time.Time `json:"dateModified"`
+	Taxes           []Tax     `json:"taxes"`
+}
+
+// Tax represents a tax or fee charged on an order fill
+type Tax struct {
+	FillID      string    `json:"fillId"`
+	Name        string    `json:"name"`
+	Quantity    Decimal   `json:"quantity"`
+	TimeCharged time.Time `json:"timeCharged"`
+}
+
+// Dividend represents a paid dividend
+type Dividend struct {
+	Ticker              string    `json:"ticker"`
+	Reference           string    `json:"reference"`
+	Type                string    `json:"type"`
+	Quantity            float64   `json:"quantity"`
+	Amount              Decimal   `json:"amount"`
+	AmountInEuro        Decimal   `json:"amountInEuro" currency:"EUR"`
+	GrossAmountPerShare Decimal   `json:"grossAmountPerShare"`
+	PaidOn              time.Time `json:"paidOn"`
+}
+
+// Transaction represents a deposit, withdrawal, fee or transfer
+type Transaction struct {
+	Type      string    `json:"type"`
+	Reference string    `json:"reference"`
+	Amount    Decimal   `json:"amount"`
+	DateTime  time.Time `json:"dateTime"`
+}
+
+// HistoricalOrders fetches historical orders as typed records
+func (c *Client) HistoricalOrders(cursor int, ticker string, limit int) ([]HistoricalOrder, error) {
+	response, err := c.get("equity/history/orders", historyParams(cursor, ticker, limit), "v0")
+	if err != nil {
+		return nil, err
+	}
+	return processPages[HistoricalOrder](c, response)
+}
+
+// DividendHistory fetches paid dividends as typed records
+func (c *Client) DividendHistory(cursor int, ticker string, limit int) ([]Dividend, error) {
+	response, err := c.get("history/dividends", historyParams(cursor, ticker, limit), "v0")
+	if err != nil {
+		return nil, err
+	}
+	return processPages[Dividend](c, response)
+}
+
+// TransactionHistory fetches account transactions as typed records
+func (c *Client) TransactionHistory(cursor, limit int) ([]Transaction, error) {
+	params := url.Values{}
+	if cursor > 0 {
+		params.Set("cursor", strconv.Itoa(cursor))
+	}
+	params.Set("limit", strconv.Itoa(limit))
+
+	response, err := c.get("history/transactions", params, "v0")
+	if err != nil {
+		return nil, err
+	}
+	return processPages[Transaction](c, response)
+}
+
+// Page is one page of a history endpoint, newest records first.
+// NextPagePath is empty on the last page.
+type Page[T any] struct {
+	Items        []T    `json:"items"`
+	NextPagePath string `json:"nextPagePath,omitempty"`
+}
+
+// HistoricalOrdersPage fetches a single page of historical orders
+func (c *Client) HistoricalOrdersPage(cursor int, ticker string, limit int) (*Page[HistoricalOrder], error) {
+	response, err := c.get("equity/history/orders", historyParams(cursor, ticker, limit), "v0")
+	if err != nil {
+		return nil, err
+	}
+	return decodePage[HistoricalOrder](response)
+}
+
+// DividendHistoryPage fetches a single page of paid dividends
+func (c *Client) DividendHistoryPage(cursor int, ticker string, limit int) (*Page[Dividend], error) {
+	response, err := c.get("history/dividends", historyParams(cursor, ticker, limit), "v0")
+	if err != nil {
+		return nil, err
+	}
+	return decodePage[Dividend](response)
+}
+
+// TransactionHistoryPage fetches a single page of account transactions
+func (c *Client) TransactionHistoryPage(cursor, limit int) (*Page[Transaction], error) {
+	params := url.Values{}
+	if cursor > 0 {
+		params.Set("cursor", strconv.Itoa(cursor))
+	}
+	params.Set("limit", strconv.Itoa(limit))
+
+	response, err := c.get("history/transactions", params, "v0")
+	if err != nil {
+		return nil, err
+	}
+	return decodePage[Transaction](response)
+}
+
+// FetchPage fetches the page at a NextPagePath, which can be stored to resume
+// paging later
+func FetchPage[T any](c *Client, path string) (*Page[T], error) {
+	response, err := c.getURL(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodePage[T](response)
+}
+
+// decodePage decodes one page of a history endpoint
+func decodePage[T any](data []byte) (*Page[T], error) {
+	var page Page[T]
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// historyParams builds the query parameters shared by history endpoints
+func historyParams(cursor int, ticker string, limit int) url.Values {
+	params := url.Values{}
+	params.Set("cursor", strconv.Itoa(cursor))
+	params.Set("limit", strconv.Itoa(limit))
+	if ticker != "" {
+		params.Set("ticker", ticker)
+	}
+	return params
+}
@@ -0,0 +1,116 @@
+package trading212
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestHistoricalOrdersPagination tests that typed history follows nextPagePath
+func TestHistoricalOrdersPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "next" {
+			fmt.Fprint(w, `{"items":[{"id":2,"ticker":"MSFT_US_EQ","filledQuantity":1.5,"dateExecuted":"2025-01-02T10:00:00Z"}]}`)
+			return
+		}
+		if r.URL.Query().Get("ticker") != "AAPL_US_EQ" {
+			t.Errorf("Expected ticker filter, got %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"items":[{"id":1,"ticker":"AAPL_US_EQ","fillPrice":150.25,"taxes":[{"name":"STAMP_DUTY","quantity":0.5}]}],"nextPagePath":"/api/v0/equity/history/orders?cursor=next"}`)
+	}))
+	defer server.Close()
+
+	orders, err := newTestClient(server.URL).HistoricalOrders(0, "AAPL_US_EQ", 50)
+	if err != nil {
+		t.Fatalf("HistoricalOrders() error = %v", err)
+	}
+
+	if len(orders) != 2 {
+		t.Fatalf("HistoricalOrders() returned %d orders, want 2", len(orders))
+	}
+	if orders[0].ID != 1 || orders[0].FillPrice != MustParseDecimal("150.25") || orders[0].Taxes[0].Quantity != MustParseDecimal("0.5") {
+		t.Errorf("orders[0] = %+v", orders[0])
+	}
+	if orders[1].ID != 2 || !orders[1].DateExecuted.Equal(time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("orders[1] = %+v", orders[1])
+	}
+}
+
+// TestHistoricalOrdersPage tests fetching one page and resuming from its path
+func TestHistoricalOrdersPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "next" {
+			fmt.Fprint(w, `{"items":[{"id":2}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"items":[{"id":3}],"nextPagePath":"/api/v0/equity/history/orders?cursor=next"}`)
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL)
+
+	page, err := client.HistoricalOrdersPage(0, "", 1)
+	if err != nil {
+		t.Fatalf("HistoricalOrdersPage() error = %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != 3 || page.NextPagePath == "" {
+		t.Fatalf("HistoricalOrdersPage() = %+v", page)
+	}
+
+	next, err := FetchPage[HistoricalOrder](client, page.NextPagePath)
+	if err != nil {
+		t.Fatalf("FetchPage() error = %v", err)
+	}
+	if len(next.Items) != 1 || next.Items[0].ID != 2 || next.NextPagePath != "" {
+		t.Errorf("FetchPage() = %+v", next)
+	}
+}
+
+// TestProcessItemsKeepsEarlierPages tests that later pages do not overwrite earlier items
+func TestProcessItemsKeepsEarlierPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items":[{"type":"DEPOSIT"}]}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	items, err := client.processItems([]byte(`{"items":[{"type":"FEE"}],"nextPagePath":"/next"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].(map[string]interface{})["type"] != "FEE" {
+		t.Errorf("processItems() = %v", items)
+	}
+}
+
+// TestDividendAndTransactionHistory tests typed dividends and transactions
+func TestDividendAndTransactionHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/history/dividends":
+			fmt.Fprint(w, `{"items":[{"ticker":"KO_US_EQ","amount":8.5,"grossAmountPerShare":0.5,"quantity":20,"paidOn":"2025-04-01T00:00:00Z"}]}`)
+		case "/api/v0/history/transactions":
+			fmt.Fprint(w, `{"items":[{"type":"DEPOSIT","amount":500,"dateTime":"2025-03-01T09:00:00Z"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+
+	dividends, err := client.DividendHistory(0, "", 50)
+	if err != nil {
+		t.Fatalf("DividendHistory() error = %v", err)
+	}
+	if len(dividends) != 1 || dividends[0].Amount != MustParseDecimal("8.5") || dividends[0].Quantity != 20 {
+		t.Errorf("DividendHistory() = %+v", dividends)
+	}
+
+	transactions, err := client.TransactionHistory(0, 50)
+	if err != nil {
+		t.Fatalf("TransactionHistory() error = %v", err)
+	}
+	if len(transactions) != 1 || transactions[0].Type != "DEPOSIT" || transactions[0].Amount != NewDecimalFromInt(500) {
+		t.Errorf("TransactionHistory() = %+v", transactions)
+	}
+}
@@ -0,0 +1,62 @@
+package trading212
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Instrument represents tradeable instrument metadata
+type Instrument struct {
+	Ticker            string  `json:"ticker"`
+	Type              string  `json:"type"`
+	ISIN              string  `json:"isin"`
+	CurrencyCode      string  `json:"currencyCode"`
+	Name              string  `json:"name"`
+	ShortName         string  `json:"shortName"`
+	MaxOpenQuantity   float64 `json:"maxOpenQuantity"`
+	WorkingScheduleID int     `json:"workingScheduleId"`
+	AddedOn           string  `json:"addedOn"`
+}
+
+// InstrumentList fetches tradeable instruments metadata as typed instruments
+func (c *Client) InstrumentList() ([]Instrument, error) {
+	response, err := c.get("equity/metadata/instruments", nil, "v0")
+	if err != nil {
+		return nil, err
+	}
+
+	var instruments []Instrument
+	if err := json.Unmarshal(response, &instruments); err != nil {
+		return nil, err
+	}
+
+	return instruments, nil
+}
+
+// SearchInstruments returns instruments whose ticker, short name, name or ISIN
+// contains the query, case-insensitively. Exact ticker and short name matches are
+// listed first.
+func SearchInstruments(instruments []Instrument, query string) []Instrument {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	var exact, partial []Instrument
+	for _, instrument := range instruments {
+		ticker := strings.ToLower(instrument.Ticker)
+		shortName := strings.ToLower(instrument.ShortName)
+
+		switch {
+		case ticker == query || shortName == query:
+			exact = append(exact, instrument)
+		case strings.Contains(ticker, query),
+			strings.Contains(shortName, query),
+			strings.Contains(strings.ToLower(instrument.Name), query),
+			strings.Contains(strings.ToLower(instrument.ISIN), query):
+			partial = append(partial, instrument)
+		}
+	}
+
+	return append(exact, partial...)
+}
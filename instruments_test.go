@@ -0,0 +1,55 @@
+package trading212
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestInstrumentList tests fetching typed instruments
+func TestInstrumentList(t *testing.T) {
+	mockResponse := []Instrument{
+		{Ticker: "AAPL_US_EQ", ShortName: "AAPL", Name: "Apple", CurrencyCode: "USD", Type: "STOCK"},
+		{Ticker: "VUSAl_EQ", ShortName: "VUSA", Name: "Vanguard S&P 500", CurrencyCode: "GBP", Type: "ETF"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/equity/metadata/instruments" {
+			t.Errorf("Expected path /api/v0/equity/metadata/instruments, got %s", r.URL.Path)
+		}
+		writeJSONResponse(t, w, mockResponse)
+	}))
+	defer server.Close()
+
+	instruments, err := newTestClient(server.URL).InstrumentList()
+	if err != nil {
+		t.Fatalf("InstrumentList() error = %v", err)
+	}
+	if len(instruments) != 2 || instruments[1].CurrencyCode != "GBP" {
+		t.Errorf("InstrumentList() = %+v", instruments)
+	}
+}
+
+// TestSearchInstruments tests instrument search ordering and matching
+func TestSearchInstruments(t *testing.T) {
+	instruments := []Instrument{
+		{Ticker: "AAPLX_EQ", ShortName: "AAPLX", Name: "Apple Leveraged"},
+		{Ticker: "AAPL_US_EQ", ShortName: "AAPL", Name: "Apple", ISIN: "US0378331005"},
+		{Ticker: "MSFT_US_EQ", ShortName: "MSFT", Name: "Microsoft"},
+	}
+
+	results := SearchInstruments(instruments, "aapl")
+	if len(results) != 2 || results[0].ShortName != "AAPL" {
+		t.Errorf("SearchInstruments(aapl) = %+v, want exact match first", results)
+	}
+
+	if results := SearchInstruments(instruments, "US0378"); len(results) != 1 {
+		t.Errorf("SearchInstruments(ISIN) = %+v, want 1 result", results)
+	}
+	if results := SearchInstruments(instruments, "micro"); len(results) != 1 || results[0].ShortName != "MSFT" {
+		t.Errorf("SearchInstruments(micro) = %+v", results)
+	}
+	if results := SearchInstruments(instruments, " "); results != nil {
+		t.Errorf("SearchInstruments(empty) = %+v, want nil", results)
+	}
+}
@@ -0,0 +1,219 @@
+// Package journal keeps a local copy of the account's order, dividend and
+// transaction history, so that reports can be run without downloading the
+// whole history each time. Sync fetches only records newer than those
+// already stored, and resumes an interrupted first download where it stopped.
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// Endpoint names
+const (
+	Orders       = "orders"
+	Dividends    = "dividends"
+	Transactions = "transactions"
+)
+
+// Endpoints lists every endpoint the journal keeps, in the order they sync
+var Endpoints = []string{Orders, Dividends, Transactions}
+
+// stateFile holds the sync state of every endpoint
+const stateFile = "state.json"
+
+// State records how far an endpoint has been synced. Newest is the time of
+// the newest record stored. Cursor is the next page of an interrupted first
+// download, which Complete marks as finished. CatchUp is the next page of an
+// interrupted sync of newer records, which have not yet reached those stored
+// before it.
+type State struct {
+	Newest   time.Time `json:"newest,omitzero"`
+	Cursor   string    `json:"cursor,omitempty"`
+	CatchUp  string    `json:"catchUp,omitempty"`
+	Complete bool      `json:"complete"`
+	Synced   time.Time `json:"synced,omitzero"`
+	Records  int       `json:"records"`
+}
+
+// Full reports whether the endpoint's whole history is stored: the first
+// download has finished and no later sync left a gap
+func (s State) Full() bool {
+	return s.Complete && s.CatchUp == ""
+}
+
+// Journal is a directory holding one JSON Lines file per endpoint, oldest
+// record first, and the sync state
+type Journal struct {
+	dir string
+}
+
+// Open returns the journal in dir. The directory is created by the first sync.
+func Open(dir string) *Journal {
+	return &Journal{dir: dir}
+}
+
+// Dir returns the directory the journal is kept in
+func (j *Journal) Dir() string {
+	return j.dir
+}
+
+// Orders returns the stored historical orders, oldest first
+func (j *Journal) Orders() ([]trading212.HistoricalOrder, error) {
+	return load[trading212.HistoricalOrder](j.path(Orders), orderTime)
+}
+
+// Dividends returns the stored dividends, oldest first
+func (j *Journal) Dividends() ([]trading212.Dividend, error) {
+	return load[trading212.Dividend](j.path(Dividends), dividendTime)
+}
+
+// Transactions returns the stored transactions, oldest first
+func (j *Journal) Transactions() ([]trading212.Transaction, error) {
+	return load[trading212.Transaction](j.path(Transactions), transactionTime)
+}
+
+// States returns the sync state of each endpoint. Endpoints never synced are
+// missing.
+func (j *Journal) States() (map[string]State, error) {
+	states := make(map[string]State)
+	data, err := os.ReadFile(filepath.Join(j.dir, stateFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to read journal state: %v", err)
+	}
+	return states, nil
+}
+
+// saveState stores the sync state of one endpoint
+func (j *Journal) saveState(endpoint string, state State) error {
+	states, err := j.States()
+	if err != nil {
+		return err
+	}
+	states[endpoint] = state
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(j.dir, stateFile), append(data, '\n'))
+}
+
+// path returns the file of an endpoint's records
+func (j *Journal) path(endpoint string) string {
+	return filepath.Join(j.dir, endpoint+".jsonl")
+}
+
+// orderKey identifies a historical order
+func orderKey(order trading212.HistoricalOrder) string {
+	return strconv.FormatInt(order.ID, 10)
+}
+
+// orderTime is when an order was placed
+func orderTime(order trading212.HistoricalOrder) time.Time {
+	if order.DateCreated.IsZero() {
+		return order.DateExecuted
+	}
+	return order.DateCreated
+}
+
+// dividendKey identifies a dividend by its reference, or by what was paid
+// when it has none
+func dividendKey(dividend trading212.Dividend) string {
+	if dividend.Reference != "" {
+		return dividend.Reference
+	}
+	return fmt.Sprintf("%s/%s/%s", dividend.Ticker, dividend.PaidOn.Format(time.RFC3339), dividend.Amount)
+}
+
+// dividendTime is when a dividend was paid
+func dividendTime(dividend trading212.Dividend) time.Time {
+	return dividend.PaidOn
+}
+
+// transactionKey identifies a transaction by its reference, or by its
+// details when it has none
+func transactionKey(transaction trading212.Transaction) string {
+	if transaction.Reference != "" {
+		return transaction.Reference
+	}
+	return fmt.Sprintf("%s/%s/%s", transaction.Type, transaction.DateTime.Format(time.RFC3339), transaction.Amount)
+}
+
+// transactionTime is when a transaction happened
+func transactionTime(transaction trading212.Transaction) time.Time {
+	return transaction.DateTime
+}
+
+// load reads an endpoint's records, sorted oldest first by at
+func load[T any](path string, at func(T) time.Time) ([]T, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []T
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var record T
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", path, i+1, err)
+		}
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return at(records[i]).Before(at(records[j])) })
+	return records, nil
+}
+
+// save replaces an endpoint's records
+func save[T any](path string, records []T) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return writeFile(path, buffer.Bytes())
+}
+
+// writeFile replaces a file atomically, so that an interrupted sync leaves
+// the previous version in place
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
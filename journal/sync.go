@@ -0,0 +1,229 @@
+package journal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// maxPageSize is the largest page the history endpoints return
+const maxPageSize = 50
+
+// Options control a sync
+type Options struct {
+	// Endpoints to sync, by default all of Endpoints
+	Endpoints []string
+	// Limit is the page size, by default the maximum of 50
+	Limit int
+	// Progress, when set, is called after each page is stored
+	Progress func(Progress)
+}
+
+// Progress reports the pages of an endpoint fetched so far
+type Progress struct {
+	Endpoint string `json:"endpoint"`
+	Pages    int    `json:"pages"`
+	Fetched  int    `json:"fetched"`
+	Added    int    `json:"added"`
+	Backfill bool   `json:"backfill"`
+}
+
+// Result reports what a sync did for one endpoint
+type Result struct {
+	Endpoint string    `json:"endpoint"`
+	Pages    int       `json:"pages"`
+	Fetched  int       `json:"fetched"`
+	Added    int       `json:"added"`
+	Records  int       `json:"records"`
+	Newest   time.Time `json:"newest,omitzero"`
+	Complete bool      `json:"complete"`
+}
+
+// Sync fetches the records added since the last sync from each endpoint. It
+// pages from the newest record back to the first one already stored, then
+// continues an interrupted first download from its cursor. Records are
+// stored and the state saved after every page, with the cursor of the next
+// page until a stored record is reached, so a failed sync loses at most one
+// page of work and the next sync fills the gap it left first.
+func (j *Journal) Sync(client *trading212.Client, options Options) ([]Result, error) {
+	endpoints := options.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = Endpoints
+	}
+	if options.Limit <= 0 || options.Limit > maxPageSize {
+		options.Limit = maxPageSize
+	}
+
+	results := make([]Result, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		var result Result
+		var err error
+		switch endpoint {
+		case Orders:
+			result, err = syncEndpoint(j, client, endpoint, source[trading212.HistoricalOrder]{
+				first: func(limit int) (*trading212.Page[trading212.HistoricalOrder], error) {
+					return client.HistoricalOrdersPage(0, "", limit)
+				},
+				key: orderKey,
+				at:  orderTime,
+			}, options)
+		case Dividends:
+			result, err = syncEndpoint(j, client, endpoint, source[trading212.Dividend]{
+				first: func(limit int) (*trading212.Page[trading212.Dividend], error) {
+					return client.DividendHistoryPage(0, "", limit)
+				},
+				key: dividendKey,
+				at:  dividendTime,
+			}, options)
+		case Transactions:
+			result, err = syncEndpoint(j, client, endpoint, source[trading212.Transaction]{
+				first: func(limit int) (*trading212.Page[trading212.Transaction], error) {
+					return client.TransactionHistoryPage(0, limit)
+				},
+				key: transactionKey,
+				at:  transactionTime,
+			}, options)
+		default:
+			return results, fmt.Errorf("unknown history endpoint %q, expected orders, dividends or transactions", endpoint)
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to sync %s: %v", endpoint, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// source describes how to page through an endpoint and identify its records
+type source[T any] struct {
+	first func(limit int) (*trading212.Page[T], error)
+	key   func(T) string
+	at    func(T) time.Time
+}
+
+// syncEndpoint brings one endpoint's records up to date
+func syncEndpoint[T any](j *Journal, client *trading212.Client, endpoint string, src source[T], options Options) (Result, error) {
+	result := Result{Endpoint: endpoint}
+	states, err := j.States()
+	if err != nil {
+		return result, err
+	}
+	state := states[endpoint]
+
+	stored, err := load(j.path(endpoint), src.at)
+	if err != nil {
+		return result, err
+	}
+	records := make(map[string]T, len(stored))
+	for _, record := range stored {
+		records[src.key(record)] = record
+	}
+
+	// merge adds a page's records, reporting whether any was already stored
+	merge := func(page *trading212.Page[T]) (reached bool) {
+		result.Pages++
+		result.Fetched += len(page.Items)
+		for _, item := range page.Items {
+			key := src.key(item)
+			if _, known := records[key]; known {
+				reached = true
+			} else {
+				result.Added++
+			}
+			records[key] = item
+			if at := src.at(item); at.After(state.Newest) {
+				state.Newest = at
+			}
+		}
+		return reached
+	}
+
+	// commit stores the records before the state, so that an interruption
+	// in between only refetches a page
+	commit := func(backfill bool) error {
+		sorted := make([]T, 0, len(records))
+		for _, record := range records {
+			sorted = append(sorted, record)
+		}
+		sort.SliceStable(sorted, func(i, k int) bool {
+			if a, b := src.at(sorted[i]), src.at(sorted[k]); !a.Equal(b) {
+				return a.Before(b)
+			}
+			return src.key(sorted[i]) < src.key(sorted[k])
+		})
+		if err := save(j.path(endpoint), sorted); err != nil {
+			return err
+		}
+
+		state.Records = len(sorted)
+		state.Synced = time.Now().UTC()
+		if err := j.saveState(endpoint, state); err != nil {
+			return err
+		}
+		if options.Progress != nil {
+			options.Progress(Progress{Endpoint: endpoint, Pages: result.Pages, Fetched: result.Fetched, Added: result.Added, Backfill: backfill})
+		}
+		return nil
+	}
+
+	// Finish catching up from where an earlier sync failed before fetching
+	// newer records, so that there is never more than one gap to fill
+	for state.CatchUp != "" {
+		page, err := trading212.FetchPage[T](client, state.CatchUp)
+		if err != nil {
+			return result, err
+		}
+		state.CatchUp = ""
+		if !merge(page) {
+			state.CatchUp = page.NextPagePath
+		}
+		if err := commit(false); err != nil {
+			return result, err
+		}
+	}
+
+	initial := !state.Complete && state.Cursor == ""
+	page, err := src.first(options.Limit)
+	for err == nil {
+		reached := merge(page)
+		if initial {
+			state.Cursor = page.NextPagePath
+			state.Complete = page.NextPagePath == ""
+		} else {
+			state.CatchUp = ""
+			if !reached {
+				state.CatchUp = page.NextPagePath
+			}
+		}
+		if err := commit(initial); err != nil {
+			return result, err
+		}
+		if reached || page.NextPagePath == "" {
+			break
+		}
+		page, err = trading212.FetchPage[T](client, page.NextPagePath)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	for !state.Complete && state.Cursor != "" {
+		page, err := trading212.FetchPage[T](client, state.Cursor)
+		if err != nil {
+			return result, err
+		}
+		merge(page)
+		state.Cursor = page.NextPagePath
+		state.Complete = page.NextPagePath == ""
+		if err := commit(true); err != nil {
+			return result, err
+		}
+	}
+
+	result.Records = len(records)
+	result.Newest = state.Newest
+	result.Complete = state.Complete
+	return result, nil
+}
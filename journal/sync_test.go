@@ -0,0 +1,187 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// history is a mock history API serving orders newest first, with the ID of
+// the last order on a page as the cursor, like the real endpoint
+type history struct {
+	orders   []trading212.HistoricalOrder
+	requests []string
+	failOn   string
+}
+
+// add records an order placed on the given day of January 2024
+func (h *history) add(id int64, day int) {
+	h.orders = append(h.orders, trading212.HistoricalOrder{
+		ID:          id,
+		Ticker:      "AAPL_US_EQ",
+		Status:      "FILLED",
+		DateCreated: time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC),
+	})
+}
+
+// serve answers history requests, failing the request matching failOn once
+func (h *history) serve(t *testing.T) *trading212.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.requests = append(h.requests, r.URL.RequestURI())
+		if h.failOn != "" && r.URL.RequestURI() == h.failOn {
+			h.failOn = ""
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		page := trading212.Page[trading212.HistoricalOrder]{Items: []trading212.HistoricalOrder{}}
+		switch r.URL.Path {
+		case "/api/v0/equity/history/orders":
+			cursor, _ := strconv.ParseInt(r.URL.Query().Get("cursor"), 10, 64)
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			for i := len(h.orders) - 1; i >= 0; i-- {
+				if cursor > 0 && h.orders[i].ID >= cursor {
+					continue
+				}
+				if len(page.Items) == limit {
+					last := page.Items[len(page.Items)-1].ID
+					page.NextPagePath = fmt.Sprintf("/api/v0/equity/history/orders?cursor=%d&limit=%d", last, limit)
+					break
+				}
+				page.Items = append(page.Items, h.orders[i])
+			}
+		case "/api/v0/history/dividends", "/api/v0/history/transactions":
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+
+	client := trading212.NewClient("test-api-key", true)
+	client.SetBaseURL(server.URL)
+	return client
+}
+
+// TestSync tests a first download, an incremental sync and resuming an
+// interrupted download and an interrupted catch-up
+func TestSync(t *testing.T) {
+	h := &history{}
+	for id := int64(1); id <= 5; id++ {
+		h.add(id, int(id))
+	}
+	client := h.serve(t)
+	journal := Open(t.TempDir())
+	options := Options{Endpoints: []string{Orders}, Limit: 2}
+
+	// The second page fails, leaving its cursor for the next sync
+	h.failOn = "/api/v0/equity/history/orders?cursor=4&limit=2"
+	if _, err := journal.Sync(client, options); err == nil {
+		t.Fatal("Expected the failed page to stop the sync")
+	}
+	states, err := journal.States()
+	if err != nil {
+		t.Fatalf("States() error = %v", err)
+	}
+	if state := states[Orders]; state.Complete || state.Records != 2 || state.Cursor == "" {
+		t.Fatalf("state after interruption = %+v, want 2 records and a cursor", state)
+	}
+
+	var progress []Progress
+	options.Progress = func(p Progress) { progress = append(progress, p) }
+	h.requests = nil
+	results, err := journal.Sync(client, options)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(h.requests) != 3 {
+		t.Errorf("resumed sync made requests %v, want the first page then the two remaining", h.requests)
+	}
+	if result := results[0]; result.Added != 3 || result.Records != 5 || !result.Complete || !result.Newest.Equal(time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("resumed sync = %+v", result)
+	}
+	if len(progress) != 3 || !progress[2].Backfill || progress[2].Added != 3 {
+		t.Errorf("progress = %+v", progress)
+	}
+
+	// Only the page with the new order is fetched
+	h.add(6, 6)
+	h.add(7, 7)
+	h.requests = nil
+	results, err = journal.Sync(client, options)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(h.requests) != 2 || results[0].Added != 2 || results[0].Fetched != 4 || results[0].Records != 7 {
+		t.Errorf("incremental sync = %+v after requests %v", results[0], h.requests)
+	}
+
+	// A catch-up that fails after its first page is resumed by the next
+	// sync rather than leaving a gap behind the newer records
+	for id := int64(8); id <= 12; id++ {
+		h.add(id, int(id))
+	}
+	h.failOn = "/api/v0/equity/history/orders?cursor=11&limit=2"
+	if _, err := journal.Sync(client, options); err == nil {
+		t.Fatal("Expected the failed page to stop the sync")
+	}
+	h.add(13, 13)
+	h.requests = nil
+	results, err = journal.Sync(client, options)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(h.requests) != 3 || h.requests[0] != "/api/v0/equity/history/orders?cursor=11&limit=2" {
+		t.Errorf("catch-up requests = %v, want the failed page, the next and then the newest", h.requests)
+	}
+	if results[0].Added != 4 || results[0].Records != 13 {
+		t.Errorf("catch-up sync = %+v", results[0])
+	}
+	if states, _ := journal.States(); states[Orders].CatchUp != "" {
+		t.Errorf("state after catch-up = %+v, want no catch-up cursor", states[Orders])
+	}
+
+	orders, err := journal.Orders()
+	if err != nil {
+		t.Fatalf("Orders() error = %v", err)
+	}
+	if len(orders) != 13 {
+		t.Fatalf("Orders() = %d orders, want 13 without gaps or duplicates", len(orders))
+	}
+	for i, order := range orders {
+		if order.ID != int64(i+1) {
+			t.Errorf("Orders()[%d].ID = %d, want oldest first", i, order.ID)
+		}
+	}
+}
+
+// TestSyncUnknownEndpoint tests that an unknown endpoint is rejected
+func TestSyncUnknownEndpoint(t *testing.T) {
+	client := (&history{}).serve(t)
+	if _, err := Open(t.TempDir()).Sync(client, Options{Endpoints: []string{"pies"}}); err == nil {
+		t.Error("Expected an error for an unknown endpoint")
+	}
+}
+
+// TestRecordKeys tests that records without a reference are still told apart
+func TestRecordKeys(t *testing.T) {
+	paid := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	a := trading212.Dividend{Ticker: "AAPL_US_EQ", PaidOn: paid, Amount: trading212.MustParseDecimal("1.5")}
+	b := trading212.Dividend{Ticker: "MSFT_US_EQ", PaidOn: paid, Amount: trading212.MustParseDecimal("1.5")}
+	if dividendKey(a) == dividendKey(b) {
+		t.Errorf("dividendKey() = %q for both dividends", dividendKey(a))
+	}
+	a.Reference = "ref-1"
+	if dividendKey(a) != "ref-1" {
+		t.Errorf("dividendKey() = %q, want the reference", dividendKey(a))
+	}
+	if transactionKey(trading212.Transaction{Reference: "tx-1"}) != "tx-1" {
+		t.Error("Expected transactionKey to use the reference")
+	}
+}
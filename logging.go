@@ -0,0 +1,177 @@
+package trading212
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const redacted = "****"
+
+// sensitiveKeys are JSON fields masked in logged request and response bodies
+var sensitiveKeys = map[string]bool{
+	"apikey":        true,
+	"apisecret":     true,
+	"accountid":     true,
+	"accountnumber": true,
+	"authorization": true,
+}
+
+// LogValue implements slog.LogValuer so the client never logs its credentials
+func (c *Client) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+// LogValue implements slog.LogValuer so the API key is always redacted
+func (a APIKeyAuth) LogValue() slog.Value {
+	return slog.StringValue(a.String())
+}
+
+// LogValue implements slog.LogValuer so the key and secret are always redacted
+func (a BasicAuth) LogValue() slog.Value {
+	return slog.StringValue(a.String())
+}
+
+// logRequest logs the outcome of a single request attempt
+func (c *Client) logRequest(req *http.Request, resp *http.Response, body []byte, attempt int, latency time.Duration, err error) {
+	if c.logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("endpoint", req.URL.Path),
+		slog.Int("attempt", attempt),
+		slog.Duration("latency", latency),
+	}
+
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", redactSecrets(err.Error(), req)))
+	}
+
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+		if limit, ok := parseRateLimit(resp.Header); ok {
+			attrs = append(attrs, slog.Group("ratelimit",
+				slog.Int("limit", limit.Limit),
+				slog.Int("remaining", limit.Remaining),
+				slog.Time("reset", limit.Reset),
+			))
+		}
+		if resp.StatusCode >= 400 && level < slog.LevelWarn {
+			level = slog.LevelWarn
+		}
+	}
+
+	ctx := req.Context()
+	c.logger.LogAttrs(ctx, level, "trading212 request", attrs...)
+
+	if c.logger.Enabled(ctx, slog.LevelDebug) {
+		c.logBodies(ctx, req, body)
+	}
+}
+
+// logBodies dumps the redacted request and response bodies at debug level
+func (c *Client) logBodies(ctx context.Context, req *http.Request, responseBody []byte) {
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("endpoint", req.URL.Path),
+	}
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			attrs = append(attrs, slog.String("request_body", redactBody(data, req)))
+		}
+	}
+	if responseBody != nil {
+		attrs = append(attrs, slog.String("response_body", redactBody(responseBody, req)))
+	}
+
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "trading212 request body", attrs...)
+}
+
+// logRetry logs that a request is about to be retried
+func (c *Client) logRetry(req *http.Request, resp *http.Response, attempt int, delay time.Duration) {
+	if c.logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("endpoint", req.URL.Path),
+		slog.Int("attempt", attempt),
+		slog.Duration("delay", delay),
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+
+	c.logger.LogAttrs(req.Context(), slog.LevelWarn, "trading212 retrying request", attrs...)
+}
+
+// logWarning logs a non-fatal problem, falling back to the standard logger
+func (c *Client) logWarning(message string, err error) {
+	if c.logger == nil {
+		log.Printf("Warning: %s: %v", message, err)
+		return
+	}
+	c.logger.Warn(message, slog.String("error", err.Error()))
+}
+
+// redactBody masks sensitive fields in a JSON body and any credentials echoed in it
+func redactBody(body []byte, req *http.Request) string {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return redactSecrets(string(body), req)
+	}
+
+	accountInfo := strings.HasSuffix(req.URL.Path, "equity/account/info")
+	masked, err := json.Marshal(redactValue(data, accountInfo))
+	if err != nil {
+		return redactSecrets(string(body), req)
+	}
+	return redactSecrets(string(masked), req)
+}
+
+// redactValue walks a decoded JSON value masking sensitive keys. The account info
+// endpoint's id field is the account identifier, so it is masked there too.
+func redactValue(value interface{}, accountInfo bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			lower := strings.ToLower(key)
+			if sensitiveKeys[lower] || (accountInfo && lower == "id") {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(item, accountInfo)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, accountInfo)
+		}
+	}
+	return value
+}
+
+// redactSecrets removes the request's credentials from text
+func redactSecrets(text string, req *http.Request) string {
+	authorization := req.Header.Get("Authorization")
+	if len(authorization) < 8 {
+		return text
+	}
+
+	text = strings.ReplaceAll(text, authorization, redacted)
+	if fields := strings.Fields(authorization); len(fields) == 2 {
+		text = strings.ReplaceAll(text, fields[1], redacted)
+	}
+	return text
+}
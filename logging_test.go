@@ -0,0 +1,85 @@
+package trading212
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestClientLogging tests structured request logging with redaction
+func TestClientLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ratelimit-limit", "1")
+		w.Header().Set("x-ratelimit-remaining", "0")
+		w.Header().Set("x-ratelimit-reset", "1751900000")
+		writeJSONResponse(t, w, map[string]interface{}{
+			"currencyCode": "GBP",
+			"id":           98765432,
+			"type":         "LIVE",
+			"echo":         "secret-api-key-123",
+		})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient("secret-api-key-123", true)
+	client.host = server.URL
+	client.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if _, err := client.AccountInfo(); err != nil {
+		t.Fatalf("AccountInfo() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{`"method":"GET"`, `"endpoint":"/api/v0/equity/account/info"`, `"status":200`, `"attempt":1`, `"remaining":0`, `response_body`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Log output missing %s: %s", want, output)
+		}
+	}
+	for _, secret := range []string{"secret-api-key-123", "98765432"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Log output leaked %s: %s", secret, output)
+		}
+	}
+}
+
+// TestClientLoggingInfoLevel tests that bodies are only dumped at debug level
+func TestClientLoggingInfoLevel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(t, w, CashInfo{Free: NewDecimalFromInt(1)})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient("test-api-key", true)
+	client.host = server.URL
+	client.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	if _, err := client.Cash(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "response_body") {
+		t.Errorf("Unexpected body dump at info level: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "endpoint=/api/v0/equity/account/cash") {
+		t.Errorf("Missing request log: %s", buf.String())
+	}
+}
+
+// TestRedactBody tests masking of sensitive JSON fields
+func TestRedactBody(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/v0/equity/orders/limit", nil)
+	req.Header.Set("Authorization", "my-long-api-key")
+
+	got := redactBody([]byte(`{"id":5,"accountId":"A1","nested":[{"apiKey":"k"}],"note":"my-long-api-key"}`), req)
+	if strings.Contains(got, "A1") || strings.Contains(got, `"k"`) || strings.Contains(got, "my-long-api-key") {
+		t.Errorf("redactBody() = %s, expected sensitive values masked", got)
+	}
+	if !strings.Contains(got, `"id":5`) {
+		t.Errorf("redactBody() = %s, expected order id to be kept", got)
+	}
+}
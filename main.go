@@ -5,19 +5,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 // Client represents the Trading212 REST API client
 type Client struct {
-	apiKey     string
+	auth       Authenticator
 	host       string
 	httpClient *http.Client
+	logger     *slog.Logger
+	metrics    MetricsRecorder
+	maxRetries int
+	mu         sync.Mutex
+	rateLimit  RateLimit
 }
 
 // Order represents an order structure
@@ -25,16 +31,21 @@ type Order struct {
 	ID           int     `json:"id"`
 	Ticker       string  `json:"ticker"`
 	Quantity     int     `json:"quantity"`
-	LimitPrice   float64 `json:"limitPrice,omitempty"`
-	StopPrice    float64 `json:"stopPrice,omitempty"`
+	LimitPrice   Decimal `json:"limitPrice,omitzero"`
+	StopPrice    Decimal `json:"stopPrice,omitzero"`
 	TimeValidity string  `json:"timeValidity"`
 }
 
 // Position represents a portfolio position
 type Position struct {
-	Ticker   string  `json:"ticker"`
-	Quantity float64 `json:"quantity"`
-	Value    float64 `json:"value"`
+	Ticker          string    `json:"ticker"`
+	Quantity        float64   `json:"quantity"`
+	Value           Decimal   `json:"value"`
+	AveragePrice    Decimal   `json:"averagePrice,omitzero"`
+	CurrentPrice    Decimal   `json:"currentPrice,omitzero"`
+	PPL             Decimal   `json:"ppl,omitzero"`
+	FxPPL           Decimal   `json:"fxPpl,omitzero"`
+	InitialFillDate time.Time `json:"initialFillDate,omitzero"`
 }
 
 // Pie represents a Trading212 pie
@@ -45,7 +56,19 @@ type Pie struct {
 	Goal               int                `json:"goal"`
 	EndDate            string             `json:"endDate"`
 	DividendCashAction string             `json:"dividendCashAction"`
-	InstrumentShares   map[string]float64 `json:"instrumentShares"`
+	InstrumentShares   map[string]Decimal `json:"instrumentShares"`
+	Cash               Decimal            `json:"cash,omitzero"`
+	Progress           float64            `json:"progress,omitempty"`
+	Status             string             `json:"status,omitempty"`
+	Result             *PieResult         `json:"result,omitempty"`
+}
+
+// PieResult represents the performance of a pie's investments
+type PieResult struct {
+	InvestedValue Decimal `json:"investedValue"`
+	Value         Decimal `json:"value"`
+	Result        Decimal `json:"result"`
+	ResultCoef    float64 `json:"resultCoef"`
 }
 
 // PaginatedResponse represents a paginated API response
@@ -56,11 +79,11 @@ type PaginatedResponse struct {
 
 // CashInfo represents account cash information
 type CashInfo struct {
-	Free              float64 `json:"free"`
-	Total             float64 `json:"total"`
-	PieOrders         float64 `json:"pieOrders"`
-	Interest          float64 `json:"interest"`
-	CashForInvestment float64 `json:"cashForInvestment"`
+	Free              Decimal `json:"free"`
+	Total             Decimal `json:"total"`
+	PieOrders         Decimal `json:"pieOrders"`
+	Interest          Decimal `json:"interest"`
+	CashForInvestment Decimal `json:"cashForInvestment"`
 }
 
 // AccountInfo represents account information
@@ -87,18 +110,40 @@ type DataIncluded struct {
 
 // NewClient creates a new Trading212 client
 func NewClient(apiKey string, demo bool) *Client {
+	return NewClientWithAuth(APIKeyAuth{APIKey: apiKey}, demo)
+}
+
+// NewClientWithAuth creates a new Trading212 client using the given authenticator
+func NewClientWithAuth(auth Authenticator, demo bool) *Client {
 	host := "https://live.trading212.com"
 	if demo {
 		host = "https://demo.trading212.com"
 	}
 
 	return &Client{
-		apiKey:     apiKey,
+		auth:       auth,
 		host:       host,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
+// SetBaseURL overrides the API host, e.g. to route requests through a proxy or
+// a test server
+func (c *Client) SetBaseURL(baseURL string) {
+	c.host = strings.TrimSuffix(baseURL, "/")
+}
+
+// SetLogger enables structured logging of API requests. Pass nil to disable it.
+func (c *Client) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+// SetMaxRetries sets how many times rate-limited or failed requests are retried.
+// Order placement is only retried when the API rejected it with a rate limit.
+func (c *Client) SetMaxRetries(maxRetries int) {
+	c.maxRetries = maxRetries
+}
+
 // get performs a GET request to the API
 func (c *Client) get(endpoint string, params url.Values, apiVersion string) ([]byte, error) {
 	url := fmt.Sprintf("%s/api/%s/%s", c.host, apiVersion, endpoint)
@@ -111,7 +156,9 @@ func (c *Client) get(endpoint string, params url.Values, apiVersion string) ([]b
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", c.apiKey)
+	if err := c.auth.Authenticate(req); err != nil {
+		return nil, err
+	}
 	return c.processRequest(req)
 }
 
@@ -129,7 +176,9 @@ func (c *Client) post(endpoint string, data interface{}, apiVersion string) ([]b
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", c.apiKey)
+	if err := c.auth.Authenticate(req); err != nil {
+		return nil, err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	return c.processRequest(req)
 }
@@ -143,7 +192,9 @@ func (c *Client) getURL(urlPath string) ([]byte, error) {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", c.apiKey)
+	if err := c.auth.Authenticate(req); err != nil {
+		return nil, err
+	}
 	return c.processRequest(req)
 }
 
@@ -156,58 +207,93 @@ func (c *Client) deleteURL(urlPath string) ([]byte, error) {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", c.apiKey)
+	if err := c.auth.Authenticate(req); err != nil {
+		return nil, err
+	}
 	return c.processRequest(req)
 }
 
-// processRequest executes HTTP request and handles response
+// processRequest executes HTTP request and handles response, retrying failed
+// attempts up to the configured maximum
 func (c *Client) processRequest(req *http.Request) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		body, resp, err := c.doRequest(req, attempt)
+		if err == nil && resp.StatusCode < 400 {
+			return body, nil
+		}
+
+		if attempt > c.maxRetries || !isRetryable(req.Method, resp, err) {
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		}
+
+		delay := retryDelay(resp, attempt)
+		c.logRetry(req, resp, attempt, delay)
+		c.recordRetry(req, resp, delay)
+		time.Sleep(delay)
+
+		if req, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// doRequest performs a single HTTP attempt and records its outcome
+func (c *Client) doRequest(req *http.Request, attempt int) ([]byte, *http.Response, error) {
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		c.logRequest(req, nil, nil, attempt, time.Since(start), err)
+		c.recordRequest(req, 0, time.Since(start))
+		return nil, nil, err
 	}
 
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
-			log.Printf("Warning: failed to close response body: %v", closeErr)
+			c.logWarning("failed to close response body", closeErr)
 		}
 	}()
 
 	body, err := io.ReadAll(resp.Body)
+	c.updateRateLimit(resp.Header)
+	c.logRequest(req, resp, body, attempt, time.Since(start), err)
+	c.recordRequest(req, resp.StatusCode, time.Since(start))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
-	}
-
-	return body, nil
+	return body, resp, nil
 }
 
 // processItems handles paginated responses
 func (c *Client) processItems(initialResponse []byte) ([]interface{}, error) {
-	var response PaginatedResponse
-	if err := json.Unmarshal(initialResponse, &response); err != nil {
-		return nil, err
-	}
+	return processPages[interface{}](c, initialResponse)
+}
 
-	items := response.Items
+// processPages decodes a paginated response and follows nextPagePath until every
+// page has been fetched
+func processPages[T any](c *Client, initialResponse []byte) ([]T, error) {
+	var items []T
+	data := initialResponse
 
-	for response.NextPagePath != "" {
-		nextData, err := c.getURL(response.NextPagePath)
+	for {
+		page, err := decodePage[T](data)
 		if err != nil {
 			return nil, err
 		}
 
-		if err := json.Unmarshal(nextData, &response); err != nil {
-			return nil, err
+		items = append(items, page.Items...)
+		if page.NextPagePath == "" {
+			return items, nil
 		}
 
-		items = append(items, response.Items...)
+		data, err = c.getURL(page.NextPagePath)
+		if err != nil {
+			return nil, err
+		}
 	}
-
-	return items, nil
 }
 
 // validateTimeValidity validates time validity parameter
@@ -256,7 +342,7 @@ func validateIcon(icon string) error {
 }
 
 // validateInstrumentShares validates instrument shares map
-func validateInstrumentShares(instrumentShares map[string]float64) error {
+func validateInstrumentShares(instrumentShares map[string]Decimal) error {
 	if len(instrumentShares) == 0 {
 		return fmt.Errorf("instrument_shares cannot be empty")
 	}
@@ -265,7 +351,7 @@ func validateInstrumentShares(instrumentShares map[string]float64) error {
 		if ticker == "" {
 			return fmt.Errorf("instrument identifiers must be non-empty strings")
 		}
-		if shares <= 0 {
+		if shares.Sign() <= 0 {
 			return fmt.Errorf("number of shares must be greater than zero")
 		}
 	}
@@ -490,7 +576,7 @@ func (c *Client) EquityOrderCancel(id int) error {
 }
 
 // EquityOrderPlaceLimit places a limit order
-func (c *Client) EquityOrderPlaceLimit(ticker string, quantity int, limitPrice float64, timeValidity string) (*Order, error) {
+func (c *Client) EquityOrderPlaceLimit(ticker string, quantity int, limitPrice Decimal, timeValidity string) (*Order, error) {
 	if err := validateTimeValidity(timeValidity); err != nil {
 		return nil, err
 	}
@@ -536,7 +622,7 @@ func (c *Client) EquityOrderPlaceMarket(ticker string, quantity int) (*Order, er
 }
 
 // EquityOrderPlaceStop places a stop order
-func (c *Client) EquityOrderPlaceStop(ticker string, quantity int, stopPrice float64, timeValidity string) (*Order, error) {
+func (c *Client) EquityOrderPlaceStop(ticker string, quantity int, stopPrice Decimal, timeValidity string) (*Order, error) {
 	if err := validateTimeValidity(timeValidity); err != nil {
 		return nil, err
 	}
@@ -562,7 +648,7 @@ func (c *Client) EquityOrderPlaceStop(ticker string, quantity int, stopPrice flo
 }
 
 // EquityOrderPlaceStopLimit places a stop-limit order
-func (c *Client) EquityOrderPlaceStopLimit(ticker string, quantity int, stopPrice, limitPrice float64, timeValidity string) (*Order, error) {
+func (c *Client) EquityOrderPlaceStopLimit(ticker string, quantity int, stopPrice, limitPrice Decimal, timeValidity string) (*Order, error) {
 	if err := validateTimeValidity(timeValidity); err != nil {
 		return nil, err
 	}
@@ -604,7 +690,7 @@ func (c *Client) Pies() ([]Pie, error) {
 }
 
 // PieCreate creates a new pie
-func (c *Client) PieCreate(dividendCashAction string, endDate time.Time, goal int, icon, name string, instrumentShares map[string]float64) (*Pie, error) {
+func (c *Client) PieCreate(dividendCashAction string, endDate time.Time, goal int, icon, name string, instrumentShares map[string]Decimal) (*Pie, error) {
 	if err := validateDividendCashAction(dividendCashAction); err != nil {
 		return nil, err
 	}
@@ -664,7 +750,7 @@ func (c *Client) Pie(id int) (*Pie, error) {
 }
 
 // PieUpdate updates existing pie
-func (c *Client) PieUpdate(id int, dividendCashAction, endDate string, goal int, icon, name string, instrumentShares map[string]float64) (*Pie, error) {
+func (c *Client) PieUpdate(id int, dividendCashAction, endDate string, goal int, icon, name string, instrumentShares map[string]Decimal) (*Pie, error) {
 	if err := validateDividendCashAction(dividendCashAction); err != nil {
 		return nil, err
 	}
@@ -700,12 +786,12 @@ func (c *Client) PieUpdate(id int, dividendCashAction, endDate string, goal int,
 	return &pie, nil
 }
 
-// String returns string representation of the client
+// String returns string representation of the client with credentials redacted
 func (c *Client) String() string {
 	demo := strings.Contains(c.host, "demo")
-	apiKeySuffix := ""
-	if len(c.apiKey) >= 4 {
-		apiKeySuffix = c.apiKey[len(c.apiKey)-4:]
+	credentials := "auth=****"
+	if stringer, ok := c.auth.(fmt.Stringer); ok {
+		credentials = stringer.String()
 	}
-	return fmt.Sprintf("Trading212(api_key=****%s, demo=%t)", apiKeySuffix, demo)
+	return fmt.Sprintf("Trading212(%s, demo=%t)", credentials, demo)
 }
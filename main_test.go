@@ -36,8 +36,8 @@ func TestNewClient(t *testing.T) {
 			if client.host != tt.want {
 				t.Errorf("NewClient() host = %v, want %v", client.host, tt.want)
 			}
-			if client.apiKey != tt.apiKey {
-				t.Errorf("NewClient() apiKey = %v, want %v", client.apiKey, tt.apiKey)
+			if client.auth != (APIKeyAuth{APIKey: tt.apiKey}) {
+				t.Errorf("NewClient() auth = %v, want api key %v", client.auth, tt.apiKey)
 			}
 			if client.httpClient == nil {
 				t.Error("NewClient() httpClient is nil")
@@ -206,40 +206,40 @@ func TestValidateIcon(t *testing.T) {
 func TestValidateInstrumentShares(t *testing.T) {
 	tests := []struct {
 		name             string
-		instrumentShares map[string]float64
+		instrumentShares map[string]Decimal
 		wantErr          bool
 	}{
 		{
 			name: "valid shares",
-			instrumentShares: map[string]float64{
-				"AAPL":  50.0,
-				"GOOGL": 30.0,
+			instrumentShares: map[string]Decimal{
+				"AAPL":  NewDecimalFromInt(50),
+				"GOOGL": NewDecimalFromInt(30),
 			},
 			wantErr: false,
 		},
 		{
 			name:             "empty shares",
-			instrumentShares: map[string]float64{},
+			instrumentShares: map[string]Decimal{},
 			wantErr:          true,
 		},
 		{
 			name: "zero shares",
-			instrumentShares: map[string]float64{
-				"AAPL": 0.0,
+			instrumentShares: map[string]Decimal{
+				"AAPL": {},
 			},
 			wantErr: true,
 		},
 		{
 			name: "negative shares",
-			instrumentShares: map[string]float64{
-				"AAPL": -10.0,
+			instrumentShares: map[string]Decimal{
+				"AAPL": NewDecimalFromInt(-10),
 			},
 			wantErr: true,
 		},
 		{
 			name: "empty ticker",
-			instrumentShares: map[string]float64{
-				"": 50.0,
+			instrumentShares: map[string]Decimal{
+				"": NewDecimalFromInt(50),
 			},
 			wantErr: true,
 		},
@@ -315,11 +315,11 @@ func writeErrorResponse(t *testing.T, w http.ResponseWriter, statusCode int, mes
 // TestClientCash tests the Cash method with mock server
 func TestClientCash(t *testing.T) {
 	mockResponse := CashInfo{
-		Free:              1000.50,
-		Total:             5000.00,
-		PieOrders:         200.00,
-		Interest:          10.25,
-		CashForInvestment: 800.25,
+		Free:              MustParseDecimal("1000.50"),
+		Total:             MustParseDecimal("5000.00"),
+		PieOrders:         MustParseDecimal("200.00"),
+		Interest:          MustParseDecimal("10.25"),
+		CashForInvestment: MustParseDecimal("800.25"),
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -389,12 +389,12 @@ func TestClientPortfolio(t *testing.T) {
 		{
 			Ticker:   "AAPL",
 			Quantity: 10.5,
-			Value:    1500.75,
+			Value:    MustParseDecimal("1500.75"),
 		},
 		{
 			Ticker:   "GOOGL",
 			Quantity: 5.0,
-			Value:    2500.00,
+			Value:    MustParseDecimal("2500.00"),
 		},
 	}
 
@@ -0,0 +1,65 @@
+package trading212
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricsRecorder receives API usage measurements from the client. Endpoints are
+// normalised so that order IDs, pie IDs and tickers do not create new series.
+type MetricsRecorder interface {
+	// ObserveRequest records a completed attempt; status is 0 for transport errors
+	ObserveRequest(method, endpoint string, status int, latency time.Duration)
+	// ObserveRetry records that an attempt is about to be retried
+	ObserveRetry(method, endpoint string)
+	// ObserveRateLimitWait records time spent waiting for the rate limit to reset
+	ObserveRateLimitWait(endpoint string, wait time.Duration)
+}
+
+// SetMetrics sets the recorder notified about every request. Pass nil to disable it.
+func (c *Client) SetMetrics(metrics MetricsRecorder) {
+	c.metrics = metrics
+}
+
+// recordRequest reports a completed attempt to the metrics recorder
+func (c *Client) recordRequest(req *http.Request, status int, latency time.Duration) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.ObserveRequest(req.Method, normaliseEndpoint(req.URL.Path), status, latency)
+}
+
+// recordRetry reports a retry, and the wait when it was caused by the rate limit
+func (c *Client) recordRetry(req *http.Request, resp *http.Response, delay time.Duration) {
+	if c.metrics == nil {
+		return
+	}
+
+	endpoint := normaliseEndpoint(req.URL.Path)
+	c.metrics.ObserveRetry(req.Method, endpoint)
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		c.metrics.ObserveRateLimitWait(endpoint, delay)
+	}
+}
+
+// normaliseEndpoint turns a request path into a low-cardinality endpoint label,
+// e.g. /api/v0/equity/orders/123 becomes equity/orders/{id}
+func normaliseEndpoint(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	segments := strings.Split(path, "/")
+	if len(segments) >= 2 && segments[0] == "api" {
+		segments = segments[2:]
+	}
+
+	for i, segment := range segments {
+		if _, err := strconv.ParseInt(segment, 10, 64); err == nil {
+			segments[i] = "{id}"
+		} else if i == 2 && segments[0] == "equity" && segments[1] == "portfolio" {
+			segments[i] = "{ticker}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,81 @@
+package trading212
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// recordingMetrics is a MetricsRecorder that stores observations
+type recordingMetrics struct {
+	requests []string
+	retries  int
+	waits    int
+}
+
+func (m *recordingMetrics) ObserveRequest(method, endpoint string, status int, latency time.Duration) {
+	m.requests = append(m.requests, method+" "+endpoint)
+}
+
+func (m *recordingMetrics) ObserveRetry(method, endpoint string) {
+	m.retries++
+}
+
+func (m *recordingMetrics) ObserveRateLimitWait(endpoint string, wait time.Duration) {
+	m.waits++
+}
+
+// TestNormaliseEndpoint tests endpoint label normalisation
+func TestNormaliseEndpoint(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api/v0/equity/account/cash", want: "equity/account/cash"},
+		{path: "/api/v0/equity/orders/12345", want: "equity/orders/{id}"},
+		{path: "/api/v0/equity/pies/42", want: "equity/pies/{id}"},
+		{path: "/api/v0/equity/portfolio/AAPL_US_EQ", want: "equity/portfolio/{ticker}"},
+		{path: "/equity/orders/7", want: "equity/orders/{id}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := normaliseEndpoint(tt.path); got != tt.want {
+				t.Errorf("normaliseEndpoint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClientMetrics tests that the client reports requests, retries and rate limit waits
+func TestClientMetrics(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("x-ratelimit-limit", "1")
+			w.Header().Set("x-ratelimit-reset", "1")
+			writeErrorResponse(t, w, http.StatusTooManyRequests, "slow down")
+			return
+		}
+		writeJSONResponse(t, w, Order{ID: 1})
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	client := newTestClient(server.URL)
+	client.SetMaxRetries(1)
+	client.SetMetrics(metrics)
+
+	if _, err := client.EquityOrder(99); err != nil {
+		t.Fatalf("EquityOrder() error = %v", err)
+	}
+
+	if len(metrics.requests) != 2 || metrics.requests[0] != "GET equity/orders/{id}" {
+		t.Errorf("requests = %v, want two GET equity/orders/{id}", metrics.requests)
+	}
+	if metrics.retries != 1 || metrics.waits != 1 {
+		t.Errorf("retries = %d, waits = %d, want 1 and 1", metrics.retries, metrics.waits)
+	}
+}
@@ -0,0 +1,124 @@
+package trading212
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// currencyPlaces lists currencies whose minor unit is not one hundredth
+var currencyPlaces = map[string]int32{
+	"JPY": 0,
+	"KRW": 0,
+	"HUF": 0,
+}
+
+// Money is an amount in a currency. Arithmetic between amounts in different
+// currencies is an error; use Convert with an exchange rate first.
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// NewMoney returns an amount in the given ISO 4217 currency, or GBX for pence
+func NewMoney(amount Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// CurrencyPlaces returns the number of decimal places in the currency's minor unit
+func CurrencyPlaces(currency string) int32 {
+	if places, exists := currencyPlaces[strings.ToUpper(currency)]; exists {
+		return places
+	}
+	return 2
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.currency(other)}, nil
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Sub(other.Amount), Currency: m.currency(other)}, nil
+}
+
+// Mul returns m scaled by factor, such as a quantity or a weight
+func (m Money) Mul(factor Decimal) Money {
+	return Money{Amount: m.Amount.Mul(factor), Currency: m.Currency}
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{Amount: m.Amount.Neg(), Currency: m.Currency}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+// Round rounds the amount to the currency's minor unit
+func (m Money) Round(mode RoundingMode) Money {
+	return Money{Amount: m.Amount.Round(CurrencyPlaces(m.Currency), mode), Currency: m.Currency}
+}
+
+// Convert returns the amount in another currency, where rate is the number of
+// units of currency per unit of m's currency
+func (m Money) Convert(rate Decimal, currency string) Money {
+	return NewMoney(m.Amount.Mul(rate), currency)
+}
+
+// String formats the amount to the currency's minor unit followed by its code,
+// e.g. "1234.50 GBP"
+func (m Money) String() string {
+	amount := m.Amount.StringFixed(CurrencyPlaces(m.Currency))
+	if m.Currency == "" {
+		return amount
+	}
+	return amount + " " + m.Currency
+}
+
+// UnmarshalJSON decodes {"amount": ..., "currency": ...} or a bare number,
+// which leaves the currency empty
+func (m *Money) UnmarshalJSON(data []byte) error {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "null" {
+		return nil
+	}
+	if !strings.HasPrefix(trimmed, "{") {
+		m.Currency = ""
+		return m.Amount.UnmarshalJSON(data)
+	}
+
+	type plain Money
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*m = NewMoney(decoded.Amount, decoded.Currency)
+	return nil
+}
+
+// sameCurrency checks that two amounts can be combined. An empty currency
+// matches any other.
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != "" && other.Currency != "" && m.Currency != other.Currency {
+		return fmt.Errorf("currency mismatch: %s and %s", m.Currency, other.Currency)
+	}
+	return nil
+}
+
+// currency returns the currency of the result of combining m and other
+func (m Money) currency(other Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return other.Currency
+}
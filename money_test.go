@@ -0,0 +1,61 @@
+package trading212
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestMoneyArithmetic tests currency checks and rounding to the minor unit
+func TestMoneyArithmetic(t *testing.T) {
+	gbp := NewMoney(MustParseDecimal("10.005"), "gbp")
+	if gbp.Currency != "GBP" {
+		t.Errorf("Currency = %q, want GBP", gbp.Currency)
+	}
+
+	sum, err := gbp.Add(NewMoney(MustParseDecimal("2.5"), "GBP"))
+	if err != nil || sum.String() != "12.50 GBP" {
+		t.Errorf("Add() = %v, %v", sum, err)
+	}
+	if _, err := gbp.Sub(NewMoney(NewDecimalFromInt(1), "USD")); err == nil {
+		t.Error("Expected error subtracting USD from GBP")
+	}
+	if untyped, err := gbp.Add(NewMoney(NewDecimalFromInt(1), "")); err != nil || untyped.Currency != "GBP" {
+		t.Errorf("Add() with no currency = %v, %v", untyped, err)
+	}
+
+	if got := gbp.Round(RoundHalfUp).String(); got != "10.01 GBP" {
+		t.Errorf("Round(RoundHalfUp) = %s", got)
+	}
+	if got := gbp.Round(RoundHalfEven).String(); got != "10.00 GBP" {
+		t.Errorf("Round(RoundHalfEven) = %s", got)
+	}
+	if got := NewMoney(MustParseDecimal("1500.5"), "JPY").String(); got != "1500 JPY" {
+		t.Errorf("JPY String() = %s", got)
+	}
+	if got := NewMoney(NewDecimalFromInt(100), "USD").Convert(MustParseDecimal("0.79"), "GBP").String(); got != "79.00 GBP" {
+		t.Errorf("Convert() = %s", got)
+	}
+}
+
+// TestMoneyJSON tests decoding an object or a bare number
+func TestMoneyJSON(t *testing.T) {
+	var m Money
+	if err := json.Unmarshal([]byte(`{"amount": 12.5, "currency": "eur"}`), &m); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if m != NewMoney(MustParseDecimal("12.5"), "EUR") {
+		t.Errorf("Unmarshal() = %+v", m)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil || string(data) != `{"amount":12.5,"currency":"EUR"}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+
+	if err := json.Unmarshal([]byte(`99.99`), &m); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if m.Amount != MustParseDecimal("99.99") || m.Currency != "" {
+		t.Errorf("Unmarshal() = %+v", m)
+	}
+}
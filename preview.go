@@ -0,0 +1,259 @@
+package trading212
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Order types accepted by OrderRequest
+const (
+	OrderTypeMarket    = "MARKET"
+	OrderTypeLimit     = "LIMIT"
+	OrderTypeStop      = "STOP"
+	OrderTypeStopLimit = "STOP_LIMIT"
+)
+
+// OrderRequest describes an order of any type before it is submitted. Sell
+// orders use a negative quantity, as the API expects.
+type OrderRequest struct {
+	Ticker       string  `json:"ticker"`
+	Quantity     int     `json:"quantity"`
+	Type         string  `json:"type"`
+	LimitPrice   Decimal `json:"limitPrice,omitzero"`
+	StopPrice    Decimal `json:"stopPrice,omitzero"`
+	TimeValidity string  `json:"timeValidity,omitempty"`
+
+	// FXRate converts one unit of the instrument currency into the account
+	// currency. When zero, only same-currency and GBX to GBP orders are valued
+	// in the account currency.
+	FXRate Decimal `json:"-"`
+}
+
+// OrderPreview summarises the expected effect of an order on the account
+type OrderPreview struct {
+	Ticker             string   `json:"ticker"`
+	Name               string   `json:"name"`
+	Side               string   `json:"side"`
+	Quantity           int      `json:"quantity"`
+	Type               string   `json:"type"`
+	LimitPrice         Decimal  `json:"limitPrice,omitzero"`
+	StopPrice          Decimal  `json:"stopPrice,omitzero"`
+	TimeValidity       string   `json:"timeValidity,omitempty"`
+	InstrumentCurrency string   `json:"instrumentCurrency"`
+	AccountCurrency    string   `json:"accountCurrency"`
+	EstimatedPrice     Decimal  `json:"estimatedPrice"`
+	FXRate             Decimal  `json:"fxRate"`
+	EstimatedValue     Decimal  `json:"estimatedValue"`
+	Estimated          bool     `json:"estimated"`
+	PositionQuantity   float64  `json:"positionQuantity"`
+	PositionAfter      float64  `json:"positionAfter"`
+	FreeCash           Decimal  `json:"freeCash"`
+	FreeCashAfter      Decimal  `json:"freeCashAfter"`
+	Warnings           []string `json:"warnings,omitempty"`
+}
+
+// Validate checks that the request has a quantity and the prices its type requires
+func (r OrderRequest) Validate() error {
+	if r.Ticker == "" {
+		return fmt.Errorf("ticker is required")
+	}
+	if r.Quantity == 0 {
+		return fmt.Errorf("quantity must not be zero")
+	}
+
+	needsLimit := r.Type == OrderTypeLimit || r.Type == OrderTypeStopLimit
+	needsStop := r.Type == OrderTypeStop || r.Type == OrderTypeStopLimit
+
+	switch {
+	case r.Type != OrderTypeMarket && !needsLimit && !needsStop:
+		return fmt.Errorf("unknown order type %q", r.Type)
+	case needsLimit && r.LimitPrice.Sign() <= 0:
+		return fmt.Errorf("%s orders require a limit price", r.Type)
+	case needsStop && r.StopPrice.Sign() <= 0:
+		return fmt.Errorf("%s orders require a stop price", r.Type)
+	}
+
+	if r.Type != OrderTypeMarket {
+		return validateTimeValidity(r.TimeValidity)
+	}
+	return nil
+}
+
+// Side returns BUY or SELL
+func (r OrderRequest) Side() string {
+	if r.Quantity < 0 {
+		return "SELL"
+	}
+	return "BUY"
+}
+
+// PlaceOrder submits the request using the endpoint for its type
+func (c *Client) PlaceOrder(r OrderRequest) (*Order, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch r.Type {
+	case OrderTypeLimit:
+		return c.EquityOrderPlaceLimit(r.Ticker, r.Quantity, r.LimitPrice, r.TimeValidity)
+	case OrderTypeStop:
+		return c.EquityOrderPlaceStop(r.Ticker, r.Quantity, r.StopPrice, r.TimeValidity)
+	case OrderTypeStopLimit:
+		return c.EquityOrderPlaceStopLimit(r.Ticker, r.Quantity, r.StopPrice, r.LimitPrice, r.TimeValidity)
+	default:
+		return c.EquityOrderPlaceMarket(r.Ticker, r.Quantity)
+	}
+}
+
+// OrderPreview fetches the instrument, account currency, cash and portfolio and
+// estimates the effect of the order without submitting it. The instrument list
+// endpoint is heavily rate limited, so callers previewing many orders should
+// use PreviewOrder with a cached list instead.
+func (c *Client) OrderPreview(r OrderRequest) (*OrderPreview, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	instruments, err := c.InstrumentList()
+	if err != nil {
+		return nil, err
+	}
+	account, err := c.AccountInfo()
+	if err != nil {
+		return nil, err
+	}
+	cash, err := c.Cash()
+	if err != nil {
+		return nil, err
+	}
+	portfolio, err := c.Portfolio()
+	if err != nil {
+		return nil, err
+	}
+
+	return PreviewOrder(r, instruments, account.CurrencyCode, cash, portfolio)
+}
+
+// PreviewOrder estimates the effect of an order from already fetched account
+// data. The execution price is the limit price, then the stop price, then the
+// current price of an open position. Problems that would not stop the API from
+// accepting the order, such as insufficient cash, are reported as warnings.
+func PreviewOrder(r OrderRequest, instruments []Instrument, accountCurrency string, cash *CashInfo, portfolio []Position) (*OrderPreview, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	instrument, found := findInstrument(instruments, r.Ticker)
+	if !found {
+		return nil, fmt.Errorf("unknown instrument %q", r.Ticker)
+	}
+
+	preview := &OrderPreview{
+		Ticker:             r.Ticker,
+		Name:               instrument.Name,
+		Side:               r.Side(),
+		Quantity:           abs(r.Quantity),
+		Type:               r.Type,
+		LimitPrice:         r.LimitPrice,
+		StopPrice:          r.StopPrice,
+		TimeValidity:       r.TimeValidity,
+		InstrumentCurrency: instrument.CurrencyCode,
+		AccountCurrency:    accountCurrency,
+	}
+	if r.Type == OrderTypeMarket {
+		preview.TimeValidity = ""
+	}
+	if cash != nil {
+		preview.FreeCash = cash.Free
+	}
+
+	var currentPrice Decimal
+	for _, position := range portfolio {
+		if position.Ticker == r.Ticker {
+			preview.PositionQuantity = position.Quantity
+			currentPrice = position.CurrentPrice
+			break
+		}
+	}
+	preview.PositionAfter = preview.PositionQuantity + float64(r.Quantity)
+
+	switch {
+	case r.LimitPrice.Sign() > 0:
+		preview.EstimatedPrice = r.LimitPrice
+	case r.StopPrice.Sign() > 0:
+		preview.EstimatedPrice = r.StopPrice
+	default:
+		preview.EstimatedPrice = currentPrice
+	}
+
+	preview.FXRate = r.FXRate
+	if preview.FXRate.IsZero() {
+		preview.FXRate = impliedFXRate(instrument.CurrencyCode, accountCurrency)
+	}
+
+	switch {
+	case preview.EstimatedPrice.IsZero():
+		preview.Warnings = append(preview.Warnings, "no current price is known for "+r.Ticker+", estimated value is unavailable")
+	case preview.FXRate.IsZero():
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("no exchange rate from %s to %s, estimated value is unavailable", instrument.CurrencyCode, accountCurrency))
+	default:
+		preview.Estimated = true
+		value := preview.EstimatedPrice.MulInt(int64(preview.Quantity)).Mul(preview.FXRate)
+		preview.EstimatedValue = value.Round(CurrencyPlaces(accountCurrency), RoundHalfEven)
+	}
+
+	preview.FreeCashAfter = preview.FreeCash
+	if preview.Estimated {
+		if r.Quantity > 0 {
+			preview.FreeCashAfter = preview.FreeCash.Sub(preview.EstimatedValue)
+		} else {
+			preview.FreeCashAfter = preview.FreeCash.Add(preview.EstimatedValue)
+		}
+	}
+
+	if preview.Estimated && preview.FreeCashAfter.Sign() < 0 {
+		preview.Warnings = append(preview.Warnings, "insufficient free cash for this order")
+	}
+	if r.Quantity < 0 && preview.PositionAfter < 0 {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("selling %d shares but only %g are held", preview.Quantity, preview.PositionQuantity))
+	}
+	if instrument.MaxOpenQuantity > 0 && preview.PositionAfter > instrument.MaxOpenQuantity {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("position would exceed the maximum open quantity of %g", instrument.MaxOpenQuantity))
+	}
+
+	return preview, nil
+}
+
+// findInstrument returns the instrument with the given ticker
+func findInstrument(instruments []Instrument, ticker string) (Instrument, bool) {
+	for _, instrument := range instruments {
+		if instrument.Ticker == ticker {
+			return instrument, true
+		}
+	}
+	return Instrument{}, false
+}
+
+// impliedFXRate returns the conversion rate between currencies that need no
+// market data, or zero when a quoted rate is required
+func impliedFXRate(from, to string) Decimal {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	switch {
+	case from == to:
+		return NewDecimalFromInt(1)
+	case from == "GBX" && to == "GBP":
+		return NewDecimal(1, -2)
+	case from == "GBP" && to == "GBX":
+		return NewDecimalFromInt(100)
+	default:
+		return Decimal{}
+	}
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
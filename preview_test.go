@@ -0,0 +1,203 @@
+package trading212
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var previewInstruments = []Instrument{
+	{Ticker: "AAPL_US_EQ", Name: "Apple", CurrencyCode: "USD"},
+	{Ticker: "VUSAl_EQ", Name: "Vanguard S&P 500", CurrencyCode: "GBP"},
+	{Ticker: "BPl_EQ", Name: "BP", CurrencyCode: "GBX", MaxOpenQuantity: 1000},
+}
+
+// TestOrderRequestValidate tests order request validation
+func TestOrderRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request OrderRequest
+		wantErr bool
+	}{
+		{name: "market", request: OrderRequest{Ticker: "AAPL", Quantity: 1, Type: OrderTypeMarket}},
+		{name: "limit", request: OrderRequest{Ticker: "AAPL", Quantity: -1, Type: OrderTypeLimit, LimitPrice: NewDecimalFromInt(10), TimeValidity: "GTC"}},
+		{name: "zero quantity", request: OrderRequest{Ticker: "AAPL", Type: OrderTypeMarket}, wantErr: true},
+		{name: "missing ticker", request: OrderRequest{Quantity: 1, Type: OrderTypeMarket}, wantErr: true},
+		{name: "unknown type", request: OrderRequest{Ticker: "AAPL", Quantity: 1, Type: "TRAILING"}, wantErr: true},
+		{name: "stop without price", request: OrderRequest{Ticker: "AAPL", Quantity: 1, Type: OrderTypeStop, TimeValidity: "DAY"}, wantErr: true},
+		{name: "stop-limit without limit", request: OrderRequest{Ticker: "AAPL", Quantity: 1, Type: OrderTypeStopLimit, StopPrice: NewDecimalFromInt(5), TimeValidity: "DAY"}, wantErr: true},
+		{name: "invalid validity", request: OrderRequest{Ticker: "AAPL", Quantity: 1, Type: OrderTypeLimit, LimitPrice: NewDecimalFromInt(10), TimeValidity: "WEEK"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.request.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestPreviewOrder tests order estimates, cash and position effects and warnings
+func TestPreviewOrder(t *testing.T) {
+	cash := &CashInfo{Free: NewDecimalFromInt(1000)}
+	portfolio := []Position{
+		{Ticker: "VUSAl_EQ", Quantity: 4, CurrentPrice: NewDecimalFromInt(80)},
+		{Ticker: "BPl_EQ", Quantity: 10, CurrentPrice: NewDecimalFromInt(450)},
+	}
+
+	tests := []struct {
+		name          string
+		request       OrderRequest
+		wantEstimated bool
+		wantValue     string
+		wantCashAfter string
+		wantPosition  float64
+		wantWarning   string
+	}{
+		{
+			name:          "market buy at current price",
+			request:       OrderRequest{Ticker: "VUSAl_EQ", Quantity: 5, Type: OrderTypeMarket},
+			wantEstimated: true, wantValue: "400", wantCashAfter: "600", wantPosition: 9,
+		},
+		{
+			name:          "limit sell in pence",
+			request:       OrderRequest{Ticker: "BPl_EQ", Quantity: -10, Type: OrderTypeLimit, LimitPrice: NewDecimalFromInt(500), TimeValidity: "DAY"},
+			wantEstimated: true, wantValue: "50", wantCashAfter: "1050", wantPosition: 0,
+		},
+		{
+			name:          "insufficient cash",
+			request:       OrderRequest{Ticker: "VUSAl_EQ", Quantity: 20, Type: OrderTypeMarket},
+			wantEstimated: true, wantValue: "1600", wantCashAfter: "-600", wantPosition: 24,
+			wantWarning: "insufficient free cash",
+		},
+		{
+			name:          "selling more than held",
+			request:       OrderRequest{Ticker: "VUSAl_EQ", Quantity: -6, Type: OrderTypeMarket},
+			wantEstimated: true, wantValue: "480", wantCashAfter: "1480", wantPosition: -2,
+			wantWarning: "only 4 are held",
+		},
+		{
+			name:      "foreign currency without rate",
+			request:   OrderRequest{Ticker: "AAPL_US_EQ", Quantity: 1, Type: OrderTypeLimit, LimitPrice: NewDecimalFromInt(200), TimeValidity: "GTC"},
+			wantValue: "0", wantCashAfter: "1000", wantPosition: 1,
+			wantWarning: "no exchange rate from USD to GBP",
+		},
+		{
+			name:          "foreign currency with rate",
+			request:       OrderRequest{Ticker: "AAPL_US_EQ", Quantity: 2, Type: OrderTypeLimit, LimitPrice: NewDecimalFromInt(200), TimeValidity: "GTC", FXRate: MustParseDecimal("0.8")},
+			wantEstimated: true, wantValue: "320", wantCashAfter: "680", wantPosition: 2,
+		},
+		{
+			name:      "market order without price",
+			request:   OrderRequest{Ticker: "AAPL_US_EQ", Quantity: 1, Type: OrderTypeMarket},
+			wantValue: "0", wantCashAfter: "1000", wantPosition: 1,
+			wantWarning: "no current price",
+		},
+		{
+			name:          "above maximum open quantity",
+			request:       OrderRequest{Ticker: "BPl_EQ", Quantity: 995, Type: OrderTypeLimit, LimitPrice: NewDecimalFromInt(1), TimeValidity: "DAY"},
+			wantEstimated: true, wantValue: "9.95", wantCashAfter: "990.05", wantPosition: 1005,
+			wantWarning: "maximum open quantity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview, err := PreviewOrder(tt.request, previewInstruments, "GBP", cash, portfolio)
+			if err != nil {
+				t.Fatalf("PreviewOrder() error = %v", err)
+			}
+
+			if preview.Estimated != tt.wantEstimated || preview.EstimatedValue.String() != tt.wantValue {
+				t.Errorf("estimate = %v %v, want %v %v", preview.Estimated, preview.EstimatedValue, tt.wantEstimated, tt.wantValue)
+			}
+			if preview.FreeCashAfter.String() != tt.wantCashAfter {
+				t.Errorf("FreeCashAfter = %v, want %v", preview.FreeCashAfter, tt.wantCashAfter)
+			}
+			if preview.PositionAfter != tt.wantPosition {
+				t.Errorf("PositionAfter = %v, want %v", preview.PositionAfter, tt.wantPosition)
+			}
+
+			warnings := strings.Join(preview.Warnings, "; ")
+			if tt.wantWarning == "" && warnings != "" {
+				t.Errorf("unexpected warnings %q", warnings)
+			}
+			if !strings.Contains(warnings, tt.wantWarning) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.wantWarning)
+			}
+		})
+	}
+
+	if _, err := PreviewOrder(OrderRequest{Ticker: "MISSING", Quantity: 1, Type: OrderTypeMarket}, previewInstruments, "GBP", cash, portfolio); err == nil {
+		t.Error("Expected error for unknown instrument")
+	}
+}
+
+// TestClientOrderPreview tests that the preview is built from the account endpoints
+func TestClientOrderPreview(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("OrderPreview must not submit anything, got %s %s", r.Method, r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/api/v0/equity/metadata/instruments":
+			writeJSONResponse(t, w, previewInstruments)
+		case "/api/v0/equity/account/info":
+			writeJSONResponse(t, w, AccountInfo{CurrencyCode: "GBP"})
+		case "/api/v0/equity/account/cash":
+			writeJSONResponse(t, w, CashInfo{Free: NewDecimalFromInt(500)})
+		case "/api/v0/equity/portfolio":
+			writeJSONResponse(t, w, []Position{{Ticker: "VUSAl_EQ", Quantity: 1, CurrentPrice: NewDecimalFromInt(80)}})
+		default:
+			writeErrorResponse(t, w, http.StatusNotFound, "not found")
+		}
+	}))
+	defer server.Close()
+
+	preview, err := newTestClient(server.URL).OrderPreview(OrderRequest{Ticker: "VUSAl_EQ", Quantity: 2, Type: OrderTypeMarket})
+	if err != nil {
+		t.Fatalf("OrderPreview() error = %v", err)
+	}
+	if preview.Name != "Vanguard S&P 500" || preview.Side != "BUY" || preview.EstimatedValue != NewDecimalFromInt(160) || preview.FreeCashAfter != NewDecimalFromInt(340) {
+		t.Errorf("OrderPreview() = %+v", preview)
+	}
+}
+
+// TestClientPlaceOrder tests that each order type is sent to its endpoint
+func TestClientPlaceOrder(t *testing.T) {
+	tests := []struct {
+		request OrderRequest
+		path    string
+	}{
+		{request: OrderRequest{Ticker: "AAPL", Quantity: 1, Type: OrderTypeMarket}, path: "/api/v0/equity/orders/market"},
+		{request: OrderRequest{Ticker: "AAPL", Quantity: 1, Type: OrderTypeLimit, LimitPrice: NewDecimalFromInt(1), TimeValidity: "DAY"}, path: "/api/v0/equity/orders/limit"},
+		{request: OrderRequest{Ticker: "AAPL", Quantity: 1, Type: OrderTypeStop, StopPrice: NewDecimalFromInt(1), TimeValidity: "DAY"}, path: "/api/v0/equity/orders/stop"},
+		{request: OrderRequest{Ticker: "AAPL", Quantity: -1, Type: OrderTypeStopLimit, StopPrice: NewDecimalFromInt(1), LimitPrice: NewDecimalFromInt(1), TimeValidity: "GTC"}, path: "/api/v0/equity/orders/stop_limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.request.Type, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" || r.URL.Path != tt.path {
+					t.Errorf("unexpected request %s %s, want POST %s", r.Method, r.URL.Path, tt.path)
+				}
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				if body["quantity"] != float64(tt.request.Quantity) {
+					t.Errorf("quantity = %v, want %d", body["quantity"], tt.request.Quantity)
+				}
+				writeJSONResponse(t, w, Order{ID: 1, Ticker: "AAPL", Quantity: tt.request.Quantity})
+			}))
+			defer server.Close()
+
+			if _, err := newTestClient(server.URL).PlaceOrder(tt.request); err != nil {
+				t.Errorf("PlaceOrder() error = %v", err)
+			}
+		})
+	}
+}
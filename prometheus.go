@@ -0,0 +1,176 @@
+package trading212
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLatencyBuckets are the request latency histogram bounds in seconds
+var defaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics is a MetricsRecorder that exposes API usage in the Prometheus
+// text exposition format. It implements http.Handler so it can be mounted on an
+// http.ServeMux, e.g. mux.Handle("/metrics", metrics).
+type PrometheusMetrics struct {
+	mu             sync.Mutex
+	buckets        []float64
+	requests       map[string]float64
+	errors         map[string]float64
+	retries        map[string]float64
+	rateLimitWaits map[string]float64
+	rateLimitTime  map[string]float64
+	latency        map[string]*histogram
+}
+
+// histogram holds cumulative bucket counts for one label set
+type histogram struct {
+	counts []float64
+	sum    float64
+	count  float64
+}
+
+// NewPrometheusMetrics creates an empty Prometheus metrics recorder
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{
+		buckets:        defaultLatencyBuckets,
+		requests:       make(map[string]float64),
+		errors:         make(map[string]float64),
+		retries:        make(map[string]float64),
+		rateLimitWaits: make(map[string]float64),
+		rateLimitTime:  make(map[string]float64),
+		latency:        make(map[string]*histogram),
+	}
+}
+
+// ObserveRequest records a completed request attempt
+func (p *PrometheusMetrics) ObserveRequest(method, endpoint string, status int, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	statusLabel := strconv.Itoa(status)
+	if status == 0 {
+		statusLabel = "error"
+	}
+
+	p.requests[labels("method", method, "endpoint", endpoint, "status", statusLabel)]++
+	if status == 0 || status >= 400 {
+		p.errors[labels("method", method, "endpoint", endpoint, "status", statusLabel)]++
+	}
+
+	key := labels("method", method, "endpoint", endpoint)
+	h, exists := p.latency[key]
+	if !exists {
+		h = &histogram{counts: make([]float64, len(p.buckets))}
+		p.latency[key] = h
+	}
+
+	seconds := latency.Seconds()
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// ObserveRetry records a retried request
+func (p *PrometheusMetrics) ObserveRetry(method, endpoint string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.retries[labels("method", method, "endpoint", endpoint)]++
+}
+
+// ObserveRateLimitWait records time spent waiting for the rate limit to reset
+func (p *PrometheusMetrics) ObserveRateLimitWait(endpoint string, wait time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := labels("endpoint", endpoint)
+	p.rateLimitWaits[key]++
+	p.rateLimitTime[key] += wait.Seconds()
+}
+
+// ServeHTTP writes all metrics in the Prometheus text format
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := p.WriteTo(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WriteTo writes all metrics in the Prometheus text format
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	writeCounter(&b, "trading212_requests_total", "Total API requests by endpoint and status.", p.requests)
+	writeCounter(&b, "trading212_request_errors_total", "Failed API requests by endpoint and status.", p.errors)
+	p.writeLatency(&b)
+	writeCounter(&b, "trading212_retries_total", "Retried API requests by endpoint.", p.retries)
+	writeCounter(&b, "trading212_rate_limit_waits_total", "Waits caused by the API rate limit.", p.rateLimitWaits)
+	writeCounter(&b, "trading212_rate_limit_wait_seconds_total", "Time spent waiting for the API rate limit.", p.rateLimitTime)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// writeLatency writes the request latency histogram
+func (p *PrometheusMetrics) writeLatency(b *strings.Builder) {
+	const name = "trading212_request_duration_seconds"
+	fmt.Fprintf(b, "# HELP %s API request latency.\n# TYPE %s histogram\n", name, name)
+
+	for _, key := range sortedKeys(p.latency) {
+		h := p.latency[key]
+		for i, bound := range p.buckets {
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %s\n", name, key, formatFloat(bound), formatFloat(h.counts[i]))
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %s\n", name, key, formatFloat(h.count))
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, key, formatFloat(h.sum))
+		fmt.Fprintf(b, "%s_count{%s} %s\n", name, key, formatFloat(h.count))
+	}
+}
+
+// writeCounter writes a counter family with one sample per label set
+func writeCounter(b *strings.Builder, name, help string, values map[string]float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s{%s} %s\n", name, key, formatFloat(values[key]))
+	}
+}
+
+// labels formats name/value pairs as an escaped Prometheus label set
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", pairs[i], escapeLabel(pairs[i+1])))
+	}
+	return strings.Join(parts, ",")
+}
+
+// escapeLabel escapes a label value for the text format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatFloat formats a sample value
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// sortedKeys returns map keys in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
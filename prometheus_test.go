@@ -0,0 +1,66 @@
+package trading212
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPrometheusMetrics tests the text exposition output
+func TestPrometheusMetrics(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	metrics.ObserveRequest("GET", "equity/portfolio", 200, 80*time.Millisecond)
+	metrics.ObserveRequest("GET", "equity/portfolio", 429, 2*time.Second)
+	metrics.ObserveRequest("POST", "equity/orders/limit", 0, time.Second)
+	metrics.ObserveRetry("GET", "equity/portfolio")
+	metrics.ObserveRateLimitWait("equity/portfolio", 1500*time.Millisecond)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(body)
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %v", resp.Header.Get("Content-Type"))
+	}
+
+	expected := []string{
+		"# TYPE trading212_requests_total counter",
+		`trading212_requests_total{method="GET",endpoint="equity/portfolio",status="200"} 1`,
+		`trading212_request_errors_total{method="GET",endpoint="equity/portfolio",status="429"} 1`,
+		`trading212_request_errors_total{method="POST",endpoint="equity/orders/limit",status="error"} 1`,
+		"# TYPE trading212_request_duration_seconds histogram",
+		`trading212_request_duration_seconds_bucket{method="GET",endpoint="equity/portfolio",le="0.1"} 1`,
+		`trading212_request_duration_seconds_bucket{method="GET",endpoint="equity/portfolio",le="+Inf"} 2`,
+		`trading212_request_duration_seconds_count{method="GET",endpoint="equity/portfolio"} 2`,
+		`trading212_retries_total{method="GET",endpoint="equity/portfolio"} 1`,
+		`trading212_rate_limit_wait_seconds_total{endpoint="equity/portfolio"} 1.5`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("Metrics output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+// TestEscapeLabel tests label value escaping
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escapeLabel() = %v", got)
+	}
+}
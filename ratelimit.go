@@ -0,0 +1,107 @@
+package trading212
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const maxRetryDelay = time.Minute
+
+// RateLimit represents the rate limit state reported by the last API response
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Used      int
+	Period    time.Duration
+	Reset     time.Time
+}
+
+// RateLimit returns the rate limit state from the most recent response
+func (c *Client) RateLimit() RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimit
+}
+
+// updateRateLimit stores the rate limit headers of a response, if present
+func (c *Client) updateRateLimit(header http.Header) {
+	limit, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	c.rateLimit = limit
+	c.mu.Unlock()
+}
+
+// parseRateLimit reads the x-ratelimit-* response headers
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	if header.Get("x-ratelimit-limit") == "" {
+		return RateLimit{}, false
+	}
+
+	limit := RateLimit{
+		Limit:     headerInt(header, "x-ratelimit-limit"),
+		Remaining: headerInt(header, "x-ratelimit-remaining"),
+		Used:      headerInt(header, "x-ratelimit-used"),
+		Period:    time.Duration(headerInt(header, "x-ratelimit-period")) * time.Second,
+	}
+	if reset := headerInt(header, "x-ratelimit-reset"); reset > 0 {
+		limit.Reset = time.Unix(int64(reset), 0)
+	}
+	return limit, true
+}
+
+// headerInt parses an integer header, returning zero when absent or invalid
+func headerInt(header http.Header, name string) int {
+	value, err := strconv.Atoi(header.Get(name))
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// isRetryable reports whether a failed attempt may be retried. Rate-limited
+// requests were never executed so are always safe to retry; transport and
+// server errors are only retried for idempotent methods.
+func isRetryable(method string, resp *http.Response, err error) bool {
+	idempotent := method == http.MethodGet || method == http.MethodDelete
+	if err != nil {
+		return idempotent
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return idempotent && resp.StatusCode >= 500
+}
+
+// retryDelay returns how long to wait before the next attempt, honouring the
+// Retry-After and x-ratelimit-reset headers before falling back to exponential backoff
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	delay := time.Duration(1<<(attempt-1)) * time.Second
+
+	if resp != nil {
+		if seconds := headerInt(resp.Header, "Retry-After"); seconds > 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else if limit, ok := parseRateLimit(resp.Header); ok && resp.StatusCode == http.StatusTooManyRequests && !limit.Reset.IsZero() {
+			delay = time.Until(limit.Reset)
+		}
+	}
+
+	return min(max(delay, 0), maxRetryDelay)
+}
+
+// rewindRequest prepares a request to be sent again
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+	}
+	return next, nil
+}
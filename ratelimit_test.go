@@ -0,0 +1,89 @@
+package trading212
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestParseRateLimit tests reading rate limit headers
+func TestParseRateLimit(t *testing.T) {
+	header := http.Header{}
+	if _, ok := parseRateLimit(header); ok {
+		t.Error("Expected no rate limit without headers")
+	}
+
+	header.Set("x-ratelimit-limit", "60")
+	header.Set("x-ratelimit-remaining", "59")
+	header.Set("x-ratelimit-used", "1")
+	header.Set("x-ratelimit-period", "60")
+	header.Set("x-ratelimit-reset", "1751900000")
+
+	limit, ok := parseRateLimit(header)
+	if !ok {
+		t.Fatal("Expected rate limit to be parsed")
+	}
+	if limit.Limit != 60 || limit.Remaining != 59 || limit.Used != 1 || limit.Period != time.Minute {
+		t.Errorf("parseRateLimit() = %+v", limit)
+	}
+	if !limit.Reset.Equal(time.Unix(1751900000, 0)) {
+		t.Errorf("Reset = %v", limit.Reset)
+	}
+}
+
+// TestClientRetriesRateLimited tests that rate-limited requests are retried
+func TestClientRetriesRateLimited(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("x-ratelimit-limit", "1")
+		w.Header().Set("x-ratelimit-remaining", "0")
+		w.Header().Set("x-ratelimit-reset", "1")
+		if calls == 1 {
+			writeErrorResponse(t, w, http.StatusTooManyRequests, "slow down")
+			return
+		}
+		writeJSONResponse(t, w, Order{ID: 7})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.SetMaxRetries(2)
+
+	order, err := client.EquityOrderPlaceMarket("AAPL", 1)
+	if err != nil {
+		t.Fatalf("EquityOrderPlaceMarket() error = %v", err)
+	}
+	if order.ID != 7 || calls != 2 {
+		t.Errorf("order ID = %d after %d calls, want 7 after 2", order.ID, calls)
+	}
+	if client.RateLimit().Limit != 1 {
+		t.Errorf("RateLimit() = %+v, want limit 1", client.RateLimit())
+	}
+}
+
+// TestIsRetryable tests which failures may be retried
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		status int
+		want   bool
+	}{
+		{name: "GET rate limited", method: "GET", status: 429, want: true},
+		{name: "POST rate limited", method: "POST", status: 429, want: true},
+		{name: "GET server error", method: "GET", status: 503, want: true},
+		{name: "POST server error", method: "POST", status: 503, want: false},
+		{name: "GET client error", method: "GET", status: 400, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if got := isRetryable(tt.method, resp, nil); got != tt.want {
+				t.Errorf("isRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/0xnu/trading212"
+)
+
+// LoadRecords reads an order journal written by a bot, as CSV when path ends
+// in .csv and as JSON Lines otherwise, naming its records after source and
+// taking missing ISINs from instruments, which may be nil
+func LoadRecords(path, source string, instruments []trading212.Instrument) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []Record
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		records, err = LoadRecordsCSV(file, source)
+	} else {
+		records, err = LoadRecordsJSON(file, source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return addISINs(records, instruments), nil
+}
+
+// LoadRecordsJSON reads one JSON object per line with the fields of Record:
+// id, ticker, isin, time in RFC 3339, quantity, negative for sells, and
+// price. Only ticker, time and quantity are required.
+func LoadRecordsJSON(r io.Reader, source string) ([]Record, error) {
+	var records []Record
+	decoder := json.NewDecoder(r)
+	for i := 1; ; i++ {
+		var record Record
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("order journal record %d: %v", i, err)
+		}
+		record.Source = source
+		if err := record.validate(); err != nil {
+			return nil, fmt.Errorf("order journal record %d: %v", i, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// LoadRecordsCSV reads records from a CSV file with the header
+// id,ticker,isin,time,quantity,price. Only ticker, time and quantity are
+// required columns, and times may be dates or RFC 3339.
+func LoadRecordsCSV(r io.Reader, source string) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read order journal: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("order journal is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"ticker", "time", "quantity"} {
+		if _, exists := columns[name]; !exists {
+			return nil, fmt.Errorf("order journal has no %s column", name)
+		}
+	}
+
+	records := make([]Record, 0, len(rows)-1)
+	for line, row := range rows[1:] {
+		field := func(name string) string {
+			i, exists := columns[name]
+			if !exists || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		record := Record{Source: source, ID: field("id"), Ticker: field("ticker"), ISIN: field("isin")}
+		if record.Time, err = trading212.ParseDate(field("time")); err != nil {
+			return nil, fmt.Errorf("order journal line %d: %v", line+2, err)
+		}
+		if record.Quantity, err = trading212.ParseDecimal(field("quantity")); err != nil {
+			return nil, fmt.Errorf("order journal line %d: invalid quantity %q", line+2, field("quantity"))
+		}
+		if price := field("price"); price != "" {
+			if record.Price, err = trading212.ParseDecimal(price); err != nil {
+				return nil, fmt.Errorf("order journal line %d: invalid price %q", line+2, price)
+			}
+		}
+		if err := record.validate(); err != nil {
+			return nil, fmt.Errorf("order journal line %d: %v", line+2, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// validate checks that a loaded record identifies a trade
+func (r Record) validate() error {
+	switch {
+	case r.Ticker == "":
+		return fmt.Errorf("no ticker")
+	case r.Time.IsZero():
+		return fmt.Errorf("no time")
+	case r.Quantity.IsZero():
+		return fmt.Errorf("no quantity")
+	}
+	return nil
+}
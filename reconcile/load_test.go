@@ -0,0 +1,77 @@
+package reconcile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xnu/trading212"
+)
+
+// TestLoadRecords tests reading an order journal as JSON Lines and as CSV,
+// with missing ISINs taken from the instruments
+func TestLoadRecords(t *testing.T) {
+	dir := t.TempDir()
+	instruments := []trading212.Instrument{{Ticker: "AAPL_US_EQ", ISIN: "US0378331005"}}
+	files := map[string]string{
+		"orders.jsonl": `{"id":"1","ticker":"AAPL_US_EQ","time":"2024-01-02T12:00:00Z","quantity":2,"price":"150"}` + "\n" +
+			`{"ticker":"MSFT_US_EQ","isin":"US5949181045","time":"2024-01-02T12:05:00Z","quantity":"-1.5"}` + "\n",
+		"orders.csv": "id,ticker,isin,time,quantity,price\n" +
+			"1,AAPL_US_EQ,,2024-01-02T12:00:00Z,2,150\n" +
+			",MSFT_US_EQ,US5949181045,2024-01-02T12:05:00Z,-1.5,\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := LoadRecords(path, "journal", instruments)
+		if err != nil {
+			t.Fatalf("LoadRecords(%s) error = %v", name, err)
+		}
+		want := []Record{
+			record("journal", "1", "AAPL_US_EQ", 0, "2", "150"),
+			record("journal", "", "MSFT_US_EQ", 5, "-1.5", "0"),
+		}
+		want[0].ISIN, want[1].ISIN = "US0378331005", "US5949181045"
+		if len(records) != len(want) {
+			t.Fatalf("LoadRecords(%s) = %+v", name, records)
+		}
+		for i := range want {
+			if records[i] != want[i] {
+				t.Errorf("LoadRecords(%s)[%d] = %+v, want %+v", name, i, records[i], want[i])
+			}
+		}
+	}
+}
+
+// TestLoadRecordsErrors tests malformed order journals
+func TestLoadRecordsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "bad.jsonl", content: `{"ticker":"AAPL_US_EQ","time":"2024-01-02T12:00:00Z","quantity":2}` + "\n{", want: "record 2"},
+		{name: "empty.jsonl", content: `{"ticker":"AAPL_US_EQ","quantity":2}`, want: "no time"},
+		{name: "empty.csv", content: "", want: "empty"},
+		{name: "columns.csv", content: "ticker,time\nAAPL_US_EQ,2024-01-02\n", want: "no quantity column"},
+		{name: "time.csv", content: "ticker,time,quantity\nAAPL_US_EQ,yesterday,2\n", want: "line 2"},
+		{name: "zero.csv", content: "ticker,time,quantity\nAAPL_US_EQ,2024-01-02,0\n", want: "no quantity"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRecords(path, "journal", nil); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadRecords(%s) error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := LoadRecords(filepath.Join(t.TempDir(), "missing.jsonl"), "journal", nil); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,306 @@
+// Package reconcile compares the orders recorded by different sources, such
+// as a bot's local journal, the order history endpoint and CSV exports, and
+// reports records that are missing from a source, duplicated within one, or
+// that disagree between sources.
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// Issue kinds
+const (
+	Missing   = "missing"
+	Duplicate = "duplicate"
+	Mismatch  = "mismatch"
+)
+
+// Record is a filled order from one source. Quantity is negative for sells.
+type Record struct {
+	Source   string             `json:"source"`
+	ID       string             `json:"id,omitempty"`
+	Ticker   string             `json:"ticker"`
+	ISIN     string             `json:"isin,omitempty"`
+	Time     time.Time          `json:"time"`
+	Quantity trading212.Decimal `json:"quantity"`
+	Price    trading212.Decimal `json:"price,omitzero"`
+}
+
+// Source is a named set of records
+type Source struct {
+	Name    string
+	Records []Record
+}
+
+// RecordsFromOrders returns the filled orders as records, taking each
+// instrument's ISIN from instruments, which may be nil
+func RecordsFromOrders(source string, orders []trading212.HistoricalOrder, instruments []trading212.Instrument) []Record {
+	trades := trading212.TradesFromOrders(orders, instruments, "")
+	return addISINs(RecordsFromTrades(source, trades), instruments)
+}
+
+// addISINs sets the ISIN of records without one from the instrument with
+// their ticker
+func addISINs(records []Record, instruments []trading212.Instrument) []Record {
+	isins := make(map[string]string, len(instruments))
+	for _, instrument := range instruments {
+		isins[instrument.Ticker] = instrument.ISIN
+	}
+	for i := range records {
+		if records[i].ISIN == "" {
+			records[i].ISIN = isins[records[i].Ticker]
+		}
+	}
+	return records
+}
+
+// RecordsFromExport returns the buys and sells of a CSV export as records
+func RecordsFromExport(source string, rows []trading212.ExportRow) []Record {
+	return RecordsFromTrades(source, trading212.TradesFromExport(rows))
+}
+
+// RecordsFromTrades returns trades as records
+func RecordsFromTrades(source string, trades []trading212.Trade) []Record {
+	records := make([]Record, 0, len(trades))
+	for _, trade := range trades {
+		records = append(records, Record{
+			Source:   source,
+			ID:       trade.ID,
+			Ticker:   trade.Ticker,
+			ISIN:     trade.ISIN,
+			Time:     trade.Time,
+			Quantity: trade.Quantity,
+			Price:    trade.Price,
+		})
+	}
+	return records
+}
+
+// Tolerance is how far two records may differ and still match. Price is a
+// fraction of the reference price.
+type Tolerance struct {
+	Time     time.Duration
+	Quantity trading212.Decimal
+	Price    float64
+}
+
+// DefaultTolerance allows a minute between timestamps, rounding of
+// fractional shares and a 0.1% difference in price
+var DefaultTolerance = Tolerance{
+	Time:     time.Minute,
+	Quantity: trading212.MustParseDecimal("0.000001"),
+	Price:    0.001,
+}
+
+// Issue is a discrepancy. Source is where the record is missing, duplicated
+// or disagrees with Against, the reference.
+type Issue struct {
+	Kind     string             `json:"kind"`
+	Source   string             `json:"source"`
+	Against  string             `json:"against,omitempty"`
+	ID       string             `json:"id,omitempty"`
+	Ticker   string             `json:"ticker"`
+	Time     time.Time          `json:"time"`
+	Quantity trading212.Decimal `json:"quantity"`
+	Detail   string             `json:"detail"`
+}
+
+// Summary counts how a source compared with the reference
+type Summary struct {
+	Source     string `json:"source"`
+	Records    int    `json:"records"`
+	Matched    int    `json:"matched"`
+	Missing    int    `json:"missing"`
+	Extra      int    `json:"extra"`
+	Duplicates int    `json:"duplicates"`
+	Mismatched int    `json:"mismatched"`
+}
+
+// Report is the result of a reconciliation
+type Report struct {
+	Reference string    `json:"reference"`
+	Sources   []Summary `json:"sources"`
+	Issues    []Issue   `json:"issues"`
+}
+
+// Reconcile compares every other source with the reference, such as the
+// order history endpoint. Records are matched by ID when both have the same
+// one, then by instrument, side and the nearest time within the tolerance. A
+// match whose time, quantity or price differ by more than the tolerance is a
+// mismatch. Records of the reference absent from a source are reported as
+// missing from that source, and records only in a source as missing from the
+// reference.
+func Reconcile(reference Source, others []Source, tolerance Tolerance) *Report {
+	report := &Report{Reference: reference.Name, Issues: []Issue{}}
+
+	summary := Summary{Source: reference.Name, Records: len(reference.Records)}
+	expected := report.unique(reference, tolerance, &summary)
+	report.Sources = append(report.Sources, summary)
+
+	for _, other := range others {
+		summary := Summary{Source: other.Name, Records: len(other.Records)}
+		found := report.unique(other, tolerance, &summary)
+		report.compare(Source{Name: reference.Name, Records: expected}, Source{Name: other.Name, Records: found}, tolerance, &summary)
+		report.Sources = append(report.Sources, summary)
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool { return report.Issues[i].Time.Before(report.Issues[j].Time) })
+	return report
+}
+
+// unique reports records that appear more than once in a source, with the
+// same ID or, without IDs, for the same instrument, time, quantity and price,
+// and returns the source's records without them, oldest first
+func (r *Report) unique(source Source, tolerance Tolerance, summary *Summary) []Record {
+	records := sorted(source.Records)
+	var kept []Record
+	seen := make(map[string]bool)
+	for _, record := range records {
+		duplicate := false
+		if record.ID != "" {
+			duplicate = seen[record.ID]
+			seen[record.ID] = true
+		} else {
+			for _, earlier := range kept {
+				if earlier.ID == "" && sameInstrument(earlier, record) && len(differences(earlier, record, tolerance)) == 0 {
+					duplicate = true
+					break
+				}
+			}
+		}
+		if duplicate {
+			summary.Duplicates++
+			r.add(Duplicate, source.Name, "", record, "recorded more than once")
+			continue
+		}
+		kept = append(kept, record)
+	}
+	return kept
+}
+
+// compare matches the unique records of other against the reference
+func (r *Report) compare(reference, other Source, tolerance Tolerance, summary *Summary) {
+	expected, found := reference.Records, other.Records
+	matched := make([]bool, len(found))
+	pairs := make([]int, len(expected))
+
+	ids := make(map[string]int)
+	for j, record := range found {
+		if _, exists := ids[record.ID]; record.ID != "" && !exists {
+			ids[record.ID] = j
+		}
+	}
+	for i, record := range expected {
+		pairs[i] = -1
+		if j, exists := ids[record.ID]; record.ID != "" && exists && !matched[j] {
+			pairs[i], matched[j] = j, true
+		}
+	}
+
+	for i, record := range expected {
+		if pairs[i] >= 0 {
+			continue
+		}
+		best := -1
+		var bestGap time.Duration
+		for j, candidate := range found {
+			if matched[j] || !sameInstrument(record, candidate) || record.Quantity.Sign() != candidate.Quantity.Sign() {
+				continue
+			}
+			gap := record.Time.Sub(candidate.Time)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > tolerance.Time {
+				continue
+			}
+			if best < 0 || gap < bestGap {
+				best, bestGap = j, gap
+			}
+		}
+		if best >= 0 {
+			pairs[i], matched[best] = best, true
+		}
+	}
+
+	for i, record := range expected {
+		if pairs[i] < 0 {
+			summary.Missing++
+			r.add(Missing, other.Name, reference.Name, record, fmt.Sprintf("in %s but not in %s", reference.Name, other.Name))
+			continue
+		}
+		if diffs := differences(record, found[pairs[i]], tolerance); len(diffs) > 0 {
+			summary.Mismatched++
+			r.add(Mismatch, other.Name, reference.Name, found[pairs[i]], strings.Join(diffs, "; "))
+			continue
+		}
+		summary.Matched++
+	}
+	for j, record := range found {
+		if !matched[j] {
+			summary.Extra++
+			r.add(Missing, reference.Name, other.Name, record, fmt.Sprintf("in %s but not in %s", other.Name, reference.Name))
+		}
+	}
+}
+
+// add records an issue about a record
+func (r *Report) add(kind, source, against string, record Record, detail string) {
+	r.Issues = append(r.Issues, Issue{
+		Kind:     kind,
+		Source:   source,
+		Against:  against,
+		ID:       record.ID,
+		Ticker:   record.Ticker,
+		Time:     record.Time,
+		Quantity: record.Quantity,
+		Detail:   detail,
+	})
+}
+
+// differences describes how a record found in a source differs from the
+// expected one by more than the tolerance
+func differences(expected, found Record, tolerance Tolerance) []string {
+	var diffs []string
+	gap := found.Time.Sub(expected.Time)
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap > tolerance.Time {
+		diffs = append(diffs, fmt.Sprintf("time %s, expected %s", found.Time.Format(time.RFC3339), expected.Time.Format(time.RFC3339)))
+	}
+	if found.Quantity.Sub(expected.Quantity).Abs().Cmp(tolerance.Quantity) > 0 {
+		diffs = append(diffs, fmt.Sprintf("quantity %s, expected %s", found.Quantity, expected.Quantity))
+	}
+	if !expected.Price.IsZero() && !found.Price.IsZero() {
+		if found.Price.Sub(expected.Price).Abs().Div(expected.Price.Abs()).Float64() > tolerance.Price {
+			diffs = append(diffs, fmt.Sprintf("price %s, expected %s", found.Price, expected.Price))
+		}
+	}
+	if !sameInstrument(expected, found) {
+		diffs = append(diffs, fmt.Sprintf("instrument %s, expected %s", found.Ticker, expected.Ticker))
+	}
+	return diffs
+}
+
+// sameInstrument compares records by ISIN when both have one, since exports
+// name instruments by symbol rather than by API ticker, and by ticker
+// otherwise
+func sameInstrument(a, b Record) bool {
+	if a.ISIN != "" && b.ISIN != "" {
+		return a.ISIN == b.ISIN
+	}
+	return strings.EqualFold(a.Ticker, b.Ticker)
+}
+
+// sorted returns a copy of the records, oldest first
+func sorted(records []Record) []Record {
+	result := append([]Record(nil), records...)
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Time.Before(result[j].Time) })
+	return result
+}
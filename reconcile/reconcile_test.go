@@ -0,0 +1,111 @@
+package reconcile
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// record returns a record of a trade at the given minute past noon on 2 January 2024
+func record(source, id, ticker string, minute int, quantity, price string) Record {
+	return Record{
+		Source:   source,
+		ID:       id,
+		Ticker:   ticker,
+		Time:     time.Date(2024, 1, 2, 12, minute, 0, 0, time.UTC),
+		Quantity: trading212.MustParseDecimal(quantity),
+		Price:    trading212.MustParseDecimal(price),
+	}
+}
+
+// TestReconcile tests matching by ID and by details, and each kind of issue
+func TestReconcile(t *testing.T) {
+	api := Source{Name: "api", Records: []Record{
+		record("api", "1", "AAPL_US_EQ", 0, "2", "150"),
+		record("api", "2", "MSFT_US_EQ", 5, "1.5", "400"),
+		record("api", "3", "TSLA_US_EQ", 10, "-3", "200"),
+		record("api", "4", "NVDA_US_EQ", 15, "1", "500"),
+	}}
+	journal := Source{Name: "journal", Records: []Record{
+		record("journal", "1", "AAPL_US_EQ", 0, "2", "150"),
+		record("journal", "1", "AAPL_US_EQ", 0, "2", "150"),
+		record("journal", "2", "MSFT_US_EQ", 5, "1.4", "400"),
+		record("journal", "3", "TSLA_US_EQ", 10, "-3", "200.1"),
+		record("journal", "9", "AMZN_US_EQ", 20, "1", "180"),
+	}}
+	export := Source{Name: "export", Records: []Record{
+		record("export", "EOF1", "AAPL_US_EQ", 0, "2", "150"),
+		record("export", "EOF2", "MSFT_US_EQ", 5, "1.5", "400"),
+		record("export", "EOF3", "TSLA_US_EQ", 10, "-3", "230"),
+	}}
+	// The export's timestamp is rounded but within the tolerance
+	export.Records[1].Time = export.Records[1].Time.Add(30 * time.Second)
+
+	report := Reconcile(api, []Source{journal, export}, DefaultTolerance)
+
+	want := []Summary{
+		{Source: "api", Records: 4},
+		{Source: "journal", Records: 5, Matched: 2, Missing: 1, Extra: 1, Duplicates: 1, Mismatched: 1},
+		{Source: "export", Records: 3, Matched: 2, Missing: 1, Mismatched: 1},
+	}
+	for i, summary := range report.Sources {
+		if summary != want[i] {
+			t.Errorf("Sources[%d] = %+v, want %+v", i, summary, want[i])
+		}
+	}
+
+	var issues []string
+	for _, issue := range report.Issues {
+		issues = append(issues, issue.Kind+" "+issue.Source+" "+issue.Ticker+": "+issue.Detail)
+	}
+	got := strings.Join(issues, "\n")
+	for _, expected := range []string{
+		"duplicate journal AAPL_US_EQ: recorded more than once",
+		"mismatch journal MSFT_US_EQ: quantity 1.4, expected 1.5",
+		"mismatch export TSLA_US_EQ: price 230, expected 200",
+		"missing journal NVDA_US_EQ: in api but not in journal",
+		"missing export NVDA_US_EQ: in api but not in export",
+		"missing api AMZN_US_EQ: in journal but not in api",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("issues missing %q, got:\n%s", expected, got)
+		}
+	}
+	if len(report.Issues) != 6 {
+		t.Errorf("got %d issues, want 6:\n%s", len(report.Issues), got)
+	}
+}
+
+// TestReconcileByISIN tests matching an export's symbols to API tickers
+func TestReconcileByISIN(t *testing.T) {
+	instruments := []trading212.Instrument{{Ticker: "AAPL_US_EQ", ISIN: "US0378331005"}}
+	orders := []trading212.HistoricalOrder{{
+		ID:             7,
+		Ticker:         "AAPL_US_EQ",
+		Status:         "FILLED",
+		FilledQuantity: 2,
+		FillPrice:      trading212.MustParseDecimal("150"),
+		DateExecuted:   time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC),
+	}}
+	rows := []trading212.ExportRow{{
+		Action: "Market buy",
+		Time:   time.Date(2024, 1, 2, 12, 0, 1, 0, time.UTC),
+		ISIN:   "US0378331005",
+		Ticker: "AAPL",
+		Shares: 2,
+		Price:  trading212.MustParseDecimal("150"),
+		Total:  trading212.MustParseDecimal("300"),
+		ID:     "EOF7",
+	}}
+
+	report := Reconcile(
+		Source{Name: "api", Records: RecordsFromOrders("api", orders, instruments)},
+		[]Source{{Name: "export", Records: RecordsFromExport("export", rows)}},
+		DefaultTolerance,
+	)
+	if len(report.Issues) != 0 || report.Sources[1].Matched != 1 {
+		t.Errorf("Reconcile() = %+v, want the export row matched", report)
+	}
+}
@@ -0,0 +1,192 @@
+// Package replay rebuilds the account as it stood at a past moment by
+// replaying its order, dividend and transaction history, and values the
+// holdings from historical prices.
+package replay
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/analytics"
+	"github.com/0xnu/trading212/corporate"
+	"github.com/0xnu/trading212/costbasis"
+	"github.com/0xnu/trading212/fx"
+)
+
+// History is everything the account has recorded. Instruments give the
+// currency of each instrument's prices, and actions restate trades for the
+// splits and renames that had happened by the moment replayed.
+type History struct {
+	Orders       []trading212.HistoricalOrder
+	Dividends    []trading212.Dividend
+	Transactions []trading212.Transaction
+	Instruments  []trading212.Instrument
+	Actions      corporate.Actions
+}
+
+// Options control a replay
+type Options struct {
+	// Currency is the account currency
+	Currency string
+	// Method matches sales with lots, by default FIFO
+	Method costbasis.Method
+	// Rates convert trades and prices in other currencies, and may be nil
+	// when everything is in the account currency
+	Rates fx.RateSource
+	// Prices by ticker, oldest first, such as from analytics.LoadPricesCSV.
+	// Holdings are valued at the last price on or before the moment.
+	Prices map[string][]analytics.Point
+}
+
+// Holding is an instrument held at the moment. Price, Value and Unrealised
+// are only set when the holding is priced; Price is in the instrument
+// currency as quoted.
+type Holding struct {
+	Ticker      string             `json:"ticker"`
+	Quantity    trading212.Decimal `json:"quantity"`
+	Cost        trading212.Decimal `json:"cost"`
+	AverageCost trading212.Decimal `json:"averageCost"`
+	Price       trading212.Decimal `json:"price,omitzero"`
+	PriceDate   time.Time          `json:"priceDate,omitzero"`
+	Value       trading212.Decimal `json:"value,omitzero"`
+	Unrealised  trading212.Decimal `json:"unrealised,omitzero"`
+	Priced      bool               `json:"priced"`
+}
+
+// Portfolio is the account at a moment in the account currency. Cash is
+// the deposits less withdrawals, plus transfers, dividends and sale proceeds,
+// less purchases and fees. Value is the total of the priced holdings.
+type Portfolio struct {
+	At        time.Time          `json:"at"`
+	Currency  string             `json:"currency"`
+	Holdings  []Holding          `json:"holdings"`
+	Cash      trading212.Decimal `json:"cash"`
+	Deposits  trading212.Decimal `json:"deposits"`
+	Dividends trading212.Decimal `json:"dividends"`
+	Fees      trading212.Decimal `json:"fees"`
+	Realised  trading212.Decimal `json:"realised"`
+	Cost      trading212.Decimal `json:"cost"`
+	Value     trading212.Decimal `json:"value"`
+	Total     trading212.Decimal `json:"total"`
+	Warnings  []string           `json:"warnings,omitempty"`
+}
+
+// At replays the history up to and including at
+func At(history History, at time.Time, options Options) (*Portfolio, error) {
+	if options.Method == "" {
+		options.Method = costbasis.FIFO
+	}
+	portfolio := &Portfolio{At: at, Currency: strings.ToUpper(options.Currency), Holdings: []Holding{}}
+
+	var actions corporate.Actions
+	for _, action := range history.Actions {
+		if !action.Date.After(at) {
+			actions = append(actions, action)
+		}
+	}
+
+	var trades []trading212.Trade
+	for _, trade := range trading212.TradesFromOrders(history.Orders, history.Instruments, portfolio.Currency) {
+		if !trade.Time.After(at) {
+			trades = append(trades, trade)
+		}
+	}
+	book := costbasis.NewBook(options.Method, portfolio.Currency, options.Rates)
+	book.SetActions(actions)
+	if err := book.AddAll(trades); err != nil {
+		return nil, err
+	}
+	for _, trade := range trades {
+		value, fees, err := fx.TradeValue(options.Rates, trade, portfolio.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert trade %s in %s: %v", trade.ID, trade.Ticker, err)
+		}
+		portfolio.Fees = portfolio.Fees.Add(fees)
+		if trade.IsBuy() {
+			portfolio.Cash = portfolio.Cash.Sub(value.Add(fees))
+		} else {
+			portfolio.Cash = portfolio.Cash.Add(value.Sub(fees))
+		}
+	}
+
+	for _, dividend := range history.Dividends {
+		if !dividend.PaidOn.After(at) {
+			portfolio.Dividends = portfolio.Dividends.Add(dividend.Amount)
+		}
+	}
+	portfolio.Cash = portfolio.Cash.Add(portfolio.Dividends)
+
+	for _, transaction := range history.Transactions {
+		if transaction.DateTime.After(at) {
+			continue
+		}
+		switch strings.ToUpper(transaction.Type) {
+		case "DEPOSIT":
+			portfolio.Deposits = portfolio.Deposits.Add(transaction.Amount.Abs())
+			portfolio.Cash = portfolio.Cash.Add(transaction.Amount.Abs())
+		case "WITHDRAW", "WITHDRAWAL":
+			portfolio.Deposits = portfolio.Deposits.Sub(transaction.Amount.Abs())
+			portfolio.Cash = portfolio.Cash.Sub(transaction.Amount.Abs())
+		case "FEE":
+			portfolio.Fees = portfolio.Fees.Add(transaction.Amount.Abs())
+			portfolio.Cash = portfolio.Cash.Sub(transaction.Amount.Abs())
+		default:
+			portfolio.Cash = portfolio.Cash.Add(transaction.Amount)
+		}
+	}
+
+	currencies := make(map[string]string, len(history.Instruments))
+	for _, instrument := range history.Instruments {
+		currencies[instrument.Ticker] = strings.ToUpper(instrument.CurrencyCode)
+	}
+
+	for _, pnl := range book.PnL(nil) {
+		portfolio.Realised = portfolio.Realised.Add(pnl.Realised)
+		if pnl.Quantity.IsZero() {
+			continue
+		}
+		holding := Holding{Ticker: pnl.Ticker, Quantity: pnl.Quantity, Cost: pnl.Cost, AverageCost: pnl.AverageCost}
+		if warning := portfolio.price(&holding, currencies[pnl.Ticker], options, at); warning != "" {
+			portfolio.Warnings = append(portfolio.Warnings, warning)
+		}
+		if holding.Priced {
+			portfolio.Value = portfolio.Value.Add(holding.Value)
+		}
+		portfolio.Cost = portfolio.Cost.Add(holding.Cost)
+		portfolio.Holdings = append(portfolio.Holdings, holding)
+	}
+	sort.Slice(portfolio.Holdings, func(i, j int) bool { return portfolio.Holdings[i].Ticker < portfolio.Holdings[j].Ticker })
+
+	portfolio.Total = portfolio.Value.Add(portfolio.Cash)
+	return portfolio, nil
+}
+
+// price values a holding at the last price on or before at, returning a
+// warning when it cannot be valued
+func (p *Portfolio) price(holding *Holding, currency string, options Options, at time.Time) string {
+	prices := options.Prices[holding.Ticker]
+	i := sort.Search(len(prices), func(i int) bool { return prices[i].Time.After(at) })
+	if i == 0 {
+		return "no price for " + holding.Ticker + " on or before " + at.Format("2006-01-02")
+	}
+	price := prices[i-1]
+
+	if currency == "" {
+		currency = p.Currency
+		p.Warnings = append(p.Warnings, fmt.Sprintf("no instrument currency for %s, assuming %s", holding.Ticker, p.Currency))
+	}
+	rate, err := fx.Rate(options.Rates, currency, p.Currency, price.Time)
+	if err != nil {
+		return fmt.Sprintf("cannot value %s: %v", holding.Ticker, err)
+	}
+
+	holding.Price = price.Value
+	holding.PriceDate = price.Time
+	holding.Value = price.Value.Mul(holding.Quantity).Mul(rate)
+	holding.Unrealised = holding.Value.Sub(holding.Cost)
+	holding.Priced = true
+	return ""
+}
@@ -0,0 +1,146 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/analytics"
+	"github.com/0xnu/trading212/corporate"
+	"github.com/0xnu/trading212/fx"
+)
+
+// date returns noon UTC on a day of 2024
+func date(month time.Month, day int) time.Time {
+	return time.Date(2024, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+// endOf returns the last moment of a day of 2024
+func endOf(month time.Month, day int) time.Time {
+	return time.Date(2024, month, day+1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)
+}
+
+// order returns a filled order
+func order(id int64, ticker string, day int, quantity float64, price, fee string) trading212.HistoricalOrder {
+	o := trading212.HistoricalOrder{
+		ID:             id,
+		Ticker:         ticker,
+		Status:         "FILLED",
+		FilledQuantity: quantity,
+		FillPrice:      trading212.MustParseDecimal(price),
+		DateExecuted:   date(1, day),
+	}
+	if fee != "" {
+		o.Taxes = []trading212.Tax{{Name: "STAMP_DUTY", Quantity: trading212.MustParseDecimal(fee)}}
+	}
+	return o
+}
+
+// history is a month of trading in one instrument with a split
+func history() History {
+	return History{
+		Orders: []trading212.HistoricalOrder{
+			order(1, "XYZ_EQ", 2, 10, "20", "1"),
+			order(2, "XYZ_EQ", 15, -5, "12", ""),
+		},
+		Dividends: []trading212.Dividend{{Ticker: "XYZ_EQ", Amount: trading212.MustParseDecimal("3"), PaidOn: date(1, 20)}},
+		Transactions: []trading212.Transaction{
+			{Type: "DEPOSIT", Amount: trading212.MustParseDecimal("1000"), DateTime: date(1, 1)},
+			{Type: "FEE", Amount: trading212.MustParseDecimal("-2"), DateTime: date(1, 25)},
+			{Type: "WITHDRAW", Amount: trading212.MustParseDecimal("-100"), DateTime: date(2, 1)},
+		},
+		Instruments: []trading212.Instrument{{Ticker: "XYZ_EQ", CurrencyCode: "GBP"}},
+		Actions: corporate.Actions{{
+			Type:   corporate.Split,
+			Date:   date(1, 10),
+			Ticker: "XYZ_EQ",
+			Ratio:  corporate.Ratio{New: trading212.NewDecimalFromInt(2), Old: trading212.NewDecimalFromInt(1)},
+		}},
+	}
+}
+
+// TestAt tests rebuilding holdings, cost basis and cash at moments before
+// and after a split
+func TestAt(t *testing.T) {
+	prices := map[string][]analytics.Point{
+		"XYZ_EQ": {
+			{Time: date(1, 3), Value: trading212.MustParseDecimal("21")},
+			{Time: date(1, 30), Value: trading212.MustParseDecimal("11")},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		cash      string
+		quantity  string
+		cost      string
+		value     string
+		realised  string
+		total     string
+		deposits  string
+		dividends string
+		fees      string
+	}{
+		{name: "before the split", at: endOf(1, 5), cash: "799", quantity: "10", cost: "201", value: "210", realised: "0", total: "1009", deposits: "1000", dividends: "0", fees: "1"},
+		{name: "after the sale", at: endOf(1, 31), cash: "860", quantity: "15", cost: "150.75", value: "165", realised: "9.75", total: "1025", deposits: "1000", dividends: "3", fees: "3"},
+		{name: "after the withdrawal", at: endOf(2, 1), cash: "760", quantity: "15", cost: "150.75", value: "165", realised: "9.75", total: "925", deposits: "900", dividends: "3", fees: "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			portfolio, err := At(history(), tt.at, Options{Currency: "gbp", Prices: prices})
+			if err != nil {
+				t.Fatalf("At() error = %v", err)
+			}
+			if len(portfolio.Holdings) != 1 {
+				t.Fatalf("Holdings = %+v, want one", portfolio.Holdings)
+			}
+			holding := portfolio.Holdings[0]
+			got := []string{portfolio.Cash.String(), holding.Quantity.String(), holding.Cost.String(), holding.Value.String(), portfolio.Realised.String(), portfolio.Total.String(), portfolio.Deposits.String(), portfolio.Dividends.String(), portfolio.Fees.String()}
+			want := []string{tt.cash, tt.quantity, tt.cost, tt.value, tt.realised, tt.total, tt.deposits, tt.dividends, tt.fees}
+			if strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("cash, quantity, cost, value, realised, total, deposits, dividends, fees = %v, want %v", got, want)
+			}
+			if !holding.Priced || portfolio.Currency != "GBP" {
+				t.Errorf("holding = %+v in %s, want priced in GBP", holding, portfolio.Currency)
+			}
+		})
+	}
+}
+
+// TestAtUnpriced tests holdings without a price and conversion of foreign
+// prices
+func TestAtUnpriced(t *testing.T) {
+	h := History{
+		Orders:       []trading212.HistoricalOrder{order(1, "AAPL_US_EQ", 2, 2, "100", "")},
+		Transactions: []trading212.Transaction{{Type: "DEPOSIT", Amount: trading212.MustParseDecimal("500"), DateTime: date(1, 1)}},
+		Instruments:  []trading212.Instrument{{Ticker: "AAPL_US_EQ", CurrencyCode: "USD"}},
+	}
+	rates := fx.StaticRates{{From: "USD", To: "GBP"}: trading212.MustParseDecimal("0.8")}
+
+	portfolio, err := At(h, endOf(1, 2), Options{Currency: "GBP", Rates: rates})
+	if err != nil {
+		t.Fatalf("At() error = %v", err)
+	}
+	if portfolio.Cash.String() != "340" || portfolio.Holdings[0].Cost.String() != "160" || portfolio.Holdings[0].Priced {
+		t.Errorf("At() = %+v", portfolio)
+	}
+	if len(portfolio.Warnings) != 1 || !strings.Contains(portfolio.Warnings[0], "no price for AAPL_US_EQ") {
+		t.Errorf("Warnings = %v", portfolio.Warnings)
+	}
+
+	prices := map[string][]analytics.Point{"AAPL_US_EQ": {{Time: date(1, 2), Value: trading212.MustParseDecimal("110")}}}
+	portfolio, err = At(h, endOf(1, 2), Options{Currency: "GBP", Rates: rates, Prices: prices})
+	if err != nil {
+		t.Fatalf("At() error = %v", err)
+	}
+	if portfolio.Value.String() != "176" || portfolio.Holdings[0].Unrealised.String() != "16" {
+		t.Errorf("At() = %+v", portfolio)
+	}
+
+	if _, err := At(h, endOf(1, 2), Options{Currency: "GBP"}); err == nil {
+		t.Error("Expected an error converting a USD trade without rates")
+	}
+}
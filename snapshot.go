@@ -0,0 +1,67 @@
+package trading212
+
+import (
+	"strings"
+	"time"
+)
+
+// AccountSnapshot is the state of the account at one moment, recorded
+// periodically because the API only reports the current state
+type AccountSnapshot struct {
+	Time      time.Time  `json:"time"`
+	Currency  string     `json:"currency"`
+	Cash      CashInfo   `json:"cash"`
+	Positions []Position `json:"positions"`
+	Pies      []Pie      `json:"pies,omitempty"`
+}
+
+// Value returns the value of the positions plus free cash in the account
+// currency
+func (s AccountSnapshot) Value() Decimal {
+	value := s.Cash.Free
+	for _, position := range s.Positions {
+		value = value.Add(position.Value)
+	}
+	return value
+}
+
+// Position returns the snapshot's position in ticker, and false when none was
+// held
+func (s AccountSnapshot) Position(ticker string) (Position, bool) {
+	for _, position := range s.Positions {
+		if position.Ticker == ticker {
+			return position, true
+		}
+	}
+	return Position{}, false
+}
+
+// Snapshot fetches the account currency, cash and open positions, and the
+// pies when withPies is set, as a snapshot taken now
+func (c *Client) Snapshot(withPies bool) (*AccountSnapshot, error) {
+	info, err := c.AccountInfo()
+	if err != nil {
+		return nil, err
+	}
+	cash, err := c.Cash()
+	if err != nil {
+		return nil, err
+	}
+	positions, err := c.Portfolio()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &AccountSnapshot{
+		Time:      time.Now().UTC(),
+		Currency:  strings.ToUpper(info.CurrencyCode),
+		Cash:      *cash,
+		Positions: positions,
+	}
+	if withPies {
+		if snapshot.Pies, err = c.Pies(); err != nil {
+			return nil, err
+		}
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,132 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// day is the length of a calendar day for retention ages
+const day = 24 * time.Hour
+
+// Policy decides which snapshots compaction keeps. Every snapshot younger
+// than KeepAll is kept, then the last of each day younger than Daily, then
+// the last of each ISO week. Snapshots older than MaxAge are dropped; zero
+// keeps them however old.
+type Policy struct {
+	KeepAll time.Duration
+	Daily   time.Duration
+	MaxAge  time.Duration
+}
+
+// DefaultPolicy keeps every snapshot for a week and one a day for a year
+var DefaultPolicy = Policy{KeepAll: 7 * day, Daily: 365 * day}
+
+// CompactResult reports what compaction did
+type CompactResult struct {
+	Path    string    `json:"path"`
+	Before  int       `json:"before"`
+	After   int       `json:"after"`
+	Removed int       `json:"removed"`
+	Oldest  time.Time `json:"oldest,omitzero"`
+	Newest  time.Time `json:"newest,omitzero"`
+}
+
+// Compact rewrites the store in time order without duplicate timestamps,
+// thinned according to policy as of now. The new file replaces the old one
+// atomically, so a failure leaves the store as it was.
+func (s *Store) Compact(policy Policy, now time.Time) (*CompactResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	kept := Retain(snapshots, policy, now)
+
+	result := &CompactResult{Path: s.path, Before: len(snapshots), After: len(kept), Removed: len(snapshots) - len(kept)}
+	if len(kept) > 0 {
+		result.Oldest, result.Newest = kept[0].Time, kept[len(kept)-1].Time
+	}
+	if len(snapshots) == 0 {
+		return result, nil
+	}
+	if err := s.write(kept); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Retain returns the snapshots that policy keeps as of now. Snapshots must be
+// oldest first, and of several with the same time only the last is kept.
+func Retain(snapshots []trading212.AccountSnapshot, policy Policy, now time.Time) []trading212.AccountSnapshot {
+	var kept []trading212.AccountSnapshot
+	for i, snapshot := range snapshots {
+		age := now.Sub(snapshot.Time)
+		if policy.MaxAge > 0 && age > policy.MaxAge {
+			continue
+		}
+		if i+1 < len(snapshots) {
+			next := snapshots[i+1].Time
+			switch {
+			case next.Equal(snapshot.Time):
+				continue
+			case age < policy.KeepAll:
+			case age < policy.Daily:
+				if sameDay(snapshot.Time, next) {
+					continue
+				}
+			default:
+				if sameWeek(snapshot.Time, next) {
+					continue
+				}
+			}
+		}
+		kept = append(kept, snapshot)
+	}
+	return kept
+}
+
+// write replaces the store's file with the snapshots
+func (s *Store) write(snapshots []trading212.AccountSnapshot) error {
+	temp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	encoder := json.NewEncoder(temp)
+	for _, snapshot := range snapshots {
+		if err := encoder.Encode(snapshot); err != nil {
+			temp.Close()
+			return fmt.Errorf("failed to encode snapshot: %v", err)
+		}
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), s.path)
+}
+
+// sameDay reports whether two times fall on the same UTC day
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// sameWeek reports whether two times fall in the same ISO week
+func sameWeek(a, b time.Time) bool {
+	ay, aw := a.UTC().ISOWeek()
+	by, bw := b.UTC().ISOWeek()
+	return ay == by && aw == bw
+}
@@ -0,0 +1,212 @@
+// Package snapshot keeps a local history of account snapshots in an
+// append-only JSON Lines file, since the API only reports the current state,
+// and answers questions about value, positions and cash over time.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/analytics"
+)
+
+// Store is a file of snapshots, one JSON object per line, oldest first
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns the store at path. The file is created by the first Append.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the file the store is kept in
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append adds a snapshot to the end of the store in a single write. A
+// truncated last line, left by an interrupted write, is removed first so the
+// new snapshot starts on a line of its own.
+func (s *Store) Append(snapshot trading212.AccountSnapshot) error {
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if err := dropPartialLine(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to repair %s: %v", s.path, err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// dropPartialLine truncates the file after its last newline, removing a line
+// that was not completely written
+func dropPartialLine(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	end := info.Size()
+	buf := make([]byte, 4096)
+	for offset := end; offset > 0; {
+		n := min(int64(len(buf)), offset)
+		offset -= n
+		if _, err := file.ReadAt(buf[:n], offset); err != nil {
+			return err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			if keep := offset + int64(i) + 1; keep < end {
+				return file.Truncate(keep)
+			}
+			return nil
+		}
+	}
+	if end == 0 {
+		return nil
+	}
+	return file.Truncate(0)
+}
+
+// Load returns the snapshots taken from from to to inclusive, oldest first.
+// A zero bound leaves that end of the range open. A missing store is empty,
+// and a truncated last line, left by an interrupted write, is ignored.
+func (s *Store) Load(from, to time.Time) ([]trading212.AccountSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshots, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []trading212.AccountSnapshot
+	for _, snapshot := range snapshots {
+		if (!from.IsZero() && snapshot.Time.Before(from)) || (!to.IsZero() && snapshot.Time.After(to)) {
+			continue
+		}
+		selected = append(selected, snapshot)
+	}
+	return selected, nil
+}
+
+// read decodes every snapshot in the file and sorts them by time
+func (s *Store) read() ([]trading212.AccountSnapshot, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	var snapshots []trading212.AccountSnapshot
+	for i, line := range lines {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var snapshot trading212.AccountSnapshot
+		if err := json.Unmarshal(line, &snapshot); err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, fmt.Errorf("%s line %d: %v", s.path, i+1, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].Time.Before(snapshots[j].Time) })
+	return snapshots, nil
+}
+
+// Values returns the account value at each snapshot in the range, for use
+// with analytics.Measure and the risk measures
+func (s *Store) Values(from, to time.Time) ([]analytics.Point, error) {
+	snapshots, err := s.Load(from, to)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]analytics.Point, len(snapshots))
+	for i, snapshot := range snapshots {
+		points[i] = analytics.Point{Time: snapshot.Time, Value: snapshot.Value()}
+	}
+	return points, nil
+}
+
+// PositionPoint is the holding in one instrument at a snapshot. Quantity and
+// Value are zero when it was not held.
+type PositionPoint struct {
+	Time         time.Time          `json:"time"`
+	Ticker       string             `json:"ticker"`
+	Quantity     float64            `json:"quantity"`
+	Value        trading212.Decimal `json:"value"`
+	AveragePrice trading212.Decimal `json:"averagePrice,omitzero"`
+	CurrentPrice trading212.Decimal `json:"currentPrice,omitzero"`
+	PPL          trading212.Decimal `json:"ppl,omitzero"`
+}
+
+// Positions returns the holding in ticker at each snapshot in the range
+func (s *Store) Positions(ticker string, from, to time.Time) ([]PositionPoint, error) {
+	snapshots, err := s.Load(from, to)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]PositionPoint, len(snapshots))
+	for i, snapshot := range snapshots {
+		position, _ := snapshot.Position(ticker)
+		points[i] = PositionPoint{
+			Time:         snapshot.Time,
+			Ticker:       ticker,
+			Quantity:     position.Quantity,
+			Value:        position.Value,
+			AveragePrice: position.AveragePrice,
+			CurrentPrice: position.CurrentPrice,
+			PPL:          position.PPL,
+		}
+	}
+	return points, nil
+}
+
+// CashPoint is the account cash at a snapshot
+type CashPoint struct {
+	Time time.Time `json:"time"`
+	trading212.CashInfo
+}
+
+// CashHistory returns the account cash at each snapshot in the range
+func (s *Store) CashHistory(from, to time.Time) ([]CashPoint, error) {
+	snapshots, err := s.Load(from, to)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]CashPoint, len(snapshots))
+	for i, snapshot := range snapshots {
+		points[i] = CashPoint{Time: snapshot.Time, CashInfo: snapshot.Cash}
+	}
+	return points, nil
+}
@@ -0,0 +1,196 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+)
+
+// at returns a UTC time on a day in 2024
+func at(month time.Month, day, hour int) time.Time {
+	return time.Date(2024, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+// account returns a snapshot holding value in AAPL_US_EQ, when non-zero, and
+// free cash
+func account(t time.Time, value, cash int64) trading212.AccountSnapshot {
+	snapshot := trading212.AccountSnapshot{
+		Time:     t,
+		Currency: "GBP",
+		Cash:     trading212.CashInfo{Free: trading212.NewDecimalFromInt(cash), Total: trading212.NewDecimalFromInt(cash + value)},
+	}
+	if value != 0 {
+		snapshot.Positions = []trading212.Position{{Ticker: "AAPL_US_EQ", Quantity: float64(value) / 100, Value: trading212.NewDecimalFromInt(value)}}
+	}
+	return snapshot
+}
+
+// newStore returns a store in a temporary directory holding the snapshots
+func newStore(t *testing.T, snapshots ...trading212.AccountSnapshot) *Store {
+	t.Helper()
+	store := Open(filepath.Join(t.TempDir(), "data", "snapshots.jsonl"))
+	for _, snapshot := range snapshots {
+		if err := store.Append(snapshot); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	return store
+}
+
+// TestStoreQueries tests value, position and cash history over a range
+func TestStoreQueries(t *testing.T) {
+	store := newStore(t,
+		account(at(1, 2, 12), 500, 100),
+		account(at(1, 1, 12), 0, 600),
+		account(at(1, 3, 12), 550, 100),
+	)
+
+	all, err := store.Load(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(all) != 3 || !all[0].Time.Equal(at(1, 1, 12)) {
+		t.Fatalf("Load() = %d snapshots starting %v, want 3 sorted", len(all), all[0].Time)
+	}
+
+	values, err := store.Values(at(1, 2, 0), time.Time{})
+	if err != nil {
+		t.Fatalf("Values() error = %v", err)
+	}
+	want := []string{"600", "650"}
+	if len(values) != len(want) {
+		t.Fatalf("Values() = %v, want %v", values, want)
+	}
+	for i, value := range values {
+		if value.Value.String() != want[i] {
+			t.Errorf("Values()[%d] = %s, want %s", i, value.Value, want[i])
+		}
+	}
+
+	positions, err := store.Positions("AAPL_US_EQ", time.Time{}, at(1, 2, 12))
+	if err != nil {
+		t.Fatalf("Positions() error = %v", err)
+	}
+	if len(positions) != 2 || positions[0].Quantity != 0 || positions[1].Quantity != 5 || positions[1].Value.String() != "500" {
+		t.Errorf("Positions() = %+v", positions)
+	}
+
+	cash, err := store.CashHistory(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("CashHistory() error = %v", err)
+	}
+	if len(cash) != 3 || cash[0].Free.String() != "600" || cash[2].Total.String() != "650" {
+		t.Errorf("CashHistory() = %+v", cash)
+	}
+}
+
+// TestStoreLoadDamaged tests that a truncated last line is ignored and other
+// bad lines are reported
+func TestStoreLoadDamaged(t *testing.T) {
+	store := newStore(t, account(at(1, 1, 12), 0, 100))
+
+	if snapshots, err := Open(filepath.Join(t.TempDir(), "missing.jsonl")).Load(time.Time{}, time.Time{}); err != nil || snapshots != nil {
+		t.Errorf("Load() of a missing store = %v, %v, want empty", snapshots, err)
+	}
+
+	file, err := os.OpenFile(store.Path(), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"time":"2024-01-02T12:00:00Z","curr`)
+	file.Close()
+
+	snapshots, err := store.Load(time.Time{}, time.Time{})
+	if err != nil || len(snapshots) != 1 {
+		t.Fatalf("Load() = %d snapshots, %v, want the complete snapshot only", len(snapshots), err)
+	}
+
+	// Appending replaces the partial line rather than continuing it
+	if err := store.Append(account(at(1, 3, 12), 0, 100)); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	snapshots, err = store.Load(time.Time{}, time.Time{})
+	if err != nil || len(snapshots) != 2 || !snapshots[1].Time.Equal(at(1, 3, 12)) {
+		t.Fatalf("Load() after Append() = %d snapshots, %v, want both complete snapshots", len(snapshots), err)
+	}
+
+	// A partial first line leaves nothing to keep
+	os.WriteFile(store.Path(), []byte(`{"time":`), 0600)
+	if err := store.Append(account(at(1, 4, 12), 0, 100)); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if snapshots, err = store.Load(time.Time{}, time.Time{}); err != nil || len(snapshots) != 1 {
+		t.Errorf("Load() = %d snapshots, %v, want the appended snapshot only", len(snapshots), err)
+	}
+
+	// Damage before the last line is still an error
+	os.WriteFile(store.Path(), []byte("garbage\n{}\n"), 0600)
+	if _, err := store.Load(time.Time{}, time.Time{}); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Load() error = %v, want an error on line 1", err)
+	}
+}
+
+// TestRetain tests thinning snapshots by age
+func TestRetain(t *testing.T) {
+	now := at(3, 1, 0)
+	policy := Policy{KeepAll: 2 * day, Daily: 30 * day, MaxAge: 55 * day}
+
+	snapshots := []trading212.AccountSnapshot{
+		account(at(1, 1, 12), 0, 1),  // older than MaxAge
+		account(at(1, 8, 9), 0, 2),   // Monday, same week as the next
+		account(at(1, 10, 9), 0, 3),  // last of its week
+		account(at(2, 10, 9), 0, 4),  // same day as the next
+		account(at(2, 10, 18), 0, 5), // last of its day
+		account(at(2, 11, 9), 0, 6),  // only one that day
+		account(at(2, 28, 9), 0, 7),  // recent: all kept
+		account(at(2, 28, 9), 0, 8),  // duplicate time: the later one is kept
+		account(at(2, 28, 10), 0, 9),
+	}
+	kept := Retain(snapshots, policy, now)
+
+	var got []string
+	for _, snapshot := range kept {
+		got = append(got, snapshot.Cash.Free.String())
+	}
+	if want := "3 5 6 8 9"; strings.Join(got, " ") != want {
+		t.Errorf("Retain() kept %v, want %s", got, want)
+	}
+}
+
+// TestStoreCompact tests that compaction rewrites the store in order and
+// that appends continue afterwards
+func TestStoreCompact(t *testing.T) {
+	store := newStore(t,
+		account(at(2, 10, 18), 0, 2),
+		account(at(2, 10, 9), 0, 1),
+		account(at(2, 11, 9), 0, 3),
+	)
+
+	result, err := store.Compact(Policy{Daily: 365 * day}, at(3, 1, 0))
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if result.Before != 3 || result.After != 2 || result.Removed != 1 || !result.Oldest.Equal(at(2, 10, 18)) {
+		t.Errorf("Compact() = %+v", result)
+	}
+
+	if err := store.Append(account(at(2, 12, 9), 0, 4)); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	data, err := os.ReadFile(store.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Errorf("store has %d lines after compaction and append, want 3", lines)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(store.Path()))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to remain, found %d entries", len(entries))
+	}
+}
@@ -0,0 +1,61 @@
+package trading212
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestClientSnapshot tests fetching the account state as a snapshot
+func TestClientSnapshot(t *testing.T) {
+	positions := []Position{
+		{Ticker: "AAPL_US_EQ", Quantity: 2, Value: MustParseDecimal("300.50")},
+		{Ticker: "VUSA_EQ", Quantity: 10, Value: MustParseDecimal("650")},
+	}
+	var piesRequested bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/equity/account/info":
+			writeJSONResponse(t, w, AccountInfo{CurrencyCode: "gbp", ID: 1, Type: "LIVE"})
+		case "/api/v0/equity/account/cash":
+			writeJSONResponse(t, w, CashInfo{Free: NewDecimalFromInt(100), Total: MustParseDecimal("1050.50")})
+		case "/api/v0/equity/portfolio":
+			writeJSONResponse(t, w, positions)
+		case "/api/v0/equity/pies":
+			piesRequested = true
+			writeJSONResponse(t, w, []Pie{{ID: 7}})
+		default:
+			writeErrorResponse(t, w, http.StatusNotFound, "not found")
+		}
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL)
+
+	snapshot, err := client.Snapshot(false)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if piesRequested || snapshot.Pies != nil {
+		t.Error("Expected pies to be skipped")
+	}
+	if snapshot.Currency != "GBP" || snapshot.Time.IsZero() || len(snapshot.Positions) != 2 {
+		t.Errorf("Snapshot() = %+v", snapshot)
+	}
+	if got := snapshot.Value(); got != MustParseDecimal("1050.50") {
+		t.Errorf("Value() = %s, want 1050.50", got)
+	}
+	if position, held := snapshot.Position("VUSA_EQ"); !held || position.Quantity != 10 {
+		t.Errorf("Position(VUSA_EQ) = %+v, %v", position, held)
+	}
+	if _, held := snapshot.Position("MSFT_US_EQ"); held {
+		t.Error("Expected MSFT_US_EQ not to be held")
+	}
+
+	snapshot, err = client.Snapshot(true)
+	if err != nil {
+		t.Fatalf("Snapshot(true) error = %v", err)
+	}
+	if len(snapshot.Pies) != 1 || snapshot.Pies[0].ID != 7 {
+		t.Errorf("Pies = %+v, want pie 7", snapshot.Pies)
+	}
+}
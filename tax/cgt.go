@@ -0,0 +1,325 @@
+package tax
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/corporate"
+	"github.com/0xnu/trading212/fx"
+)
+
+// ReportCurrency is the currency UK tax reports are calculated in
+const ReportCurrency = "GBP"
+
+// bedAndBreakfastDays is the period after a disposal in which acquisitions of
+// the same shares are matched with it
+const bedAndBreakfastDays = 30
+
+// Share matching rules, applied in this order
+const (
+	RuleSameDay         = "same-day"
+	RuleBedAndBreakfast = "bed-and-breakfast"
+	RuleSection104      = "section-104"
+)
+
+// Match is the part of a disposal identified with acquisitions under one rule
+type Match struct {
+	Rule     string             `json:"rule"`
+	Quantity trading212.Decimal `json:"quantity"`
+	Cost     trading212.Decimal `json:"cost"`
+
+	// AcquiredOn is the acquisition date for same-day and bed-and-breakfast
+	// matches
+	AcquiredOn time.Time `json:"acquiredOn,omitzero"`
+}
+
+// Disposal is all sales of one share on one day, which HMRC treats as a single
+// disposal. Amounts are in pounds sterling converted at the rate on the trade
+// date; Cost includes purchase fees and Gain is net of sale fees.
+type Disposal struct {
+	Ticker   string             `json:"ticker"`
+	Date     time.Time          `json:"date"`
+	Quantity trading212.Decimal `json:"quantity"`
+	Proceeds trading212.Decimal `json:"proceeds"`
+	Fees     trading212.Decimal `json:"fees"`
+	Cost     trading212.Decimal `json:"cost"`
+	Gain     trading212.Decimal `json:"gain"`
+	Matches  []Match            `json:"matches"`
+}
+
+// Pool is a Section 104 holding: the shares not matched under the same-day or
+// bed-and-breakfast rules and their pooled allowable cost
+type Pool struct {
+	Ticker   string             `json:"ticker"`
+	Quantity trading212.Decimal `json:"quantity"`
+	Cost     trading212.Decimal `json:"cost"`
+}
+
+// YearReport summarises the disposals in one tax year. Gains and Losses are
+// the totals of disposals at a gain and at a loss, and Pools are the Section
+// 104 holdings at the end of the year.
+type YearReport struct {
+	TaxYear   TaxYear            `json:"taxYear"`
+	Disposals []Disposal         `json:"disposals"`
+	Proceeds  trading212.Decimal `json:"proceeds"`
+	Costs     trading212.Decimal `json:"costs"`
+	Gains     trading212.Decimal `json:"gains"`
+	Losses    trading212.Decimal `json:"losses"`
+	NetGain   trading212.Decimal `json:"netGain"`
+	Pools     []Pool             `json:"pools"`
+}
+
+// Report is a capital gains calculation over every tax year with trades
+type Report struct {
+	Currency string       `json:"currency"`
+	Years    []YearReport `json:"years"`
+}
+
+// Year returns the report for a tax year, and false if there were no trades
+// in it
+func (r *Report) Year(year TaxYear) (YearReport, bool) {
+	for _, report := range r.Years {
+		if report.TaxYear == year {
+			return report, true
+		}
+	}
+	return YearReport{}, false
+}
+
+// tradingDay is one share's acquisitions and disposals on one day. The
+// remaining fields start equal to the totals and are reduced as the matching
+// rules identify shares.
+type tradingDay struct {
+	date time.Time
+
+	bought, boughtCost             trading212.Decimal
+	sold, proceeds, fees           trading212.Decimal
+	unmatchedBought, unmatchedCost trading212.Decimal
+	unmatchedSold                  trading212.Decimal
+
+	matches []Match
+}
+
+// poolState is a share's Section 104 pool after a day's trades
+type poolState struct {
+	date time.Time
+	pool Pool
+}
+
+// CapitalGains computes disposals under the HMRC share matching rules: sales
+// are matched first with purchases on the same day, then with purchases in
+// the following 30 days, earliest first, and finally with the Section 104
+// pool at its average cost. Trades in other currencies are converted to
+// pounds at the rate on the trade date using source, which may be nil when
+// every trade is in pounds or pence. Trades are first restated for actions,
+// which may be nil, so that shares bought before a split or rename are pooled
+// with those sold after it.
+func CapitalGains(trades []trading212.Trade, source fx.RateSource, actions corporate.Actions) (*Report, error) {
+	trades = actions.AdjustTrades(trades)
+	days := make(map[string][]*tradingDay)
+	for _, trade := range trades {
+		if trade.Quantity.IsZero() {
+			continue
+		}
+		value, fees, err := fx.TradeValue(source, trade, ReportCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert trade %s in %s: %v", trade.ID, trade.Ticker, err)
+		}
+
+		d := findDay(days, trade.Ticker, day(trade.Time))
+		if trade.IsBuy() {
+			d.bought = d.bought.Add(trade.Shares())
+			d.boughtCost = d.boughtCost.Add(value).Add(fees)
+		} else {
+			d.sold = d.sold.Add(trade.Shares())
+			d.proceeds = d.proceeds.Add(value)
+			d.fees = d.fees.Add(fees)
+		}
+	}
+
+	var disposals []Disposal
+	pools := make(map[string][]poolState)
+	for ticker, tickerDays := range days {
+		sort.Slice(tickerDays, func(i, j int) bool { return tickerDays[i].date.Before(tickerDays[j].date) })
+		matchSameDay(tickerDays)
+		matchBedAndBreakfast(tickerDays)
+		states, err := matchSection104(ticker, tickerDays)
+		if err != nil {
+			return nil, err
+		}
+		pools[ticker] = states
+
+		for _, d := range tickerDays {
+			if d.sold.IsZero() {
+				continue
+			}
+			disposal := Disposal{Ticker: ticker, Date: d.date, Quantity: d.sold, Proceeds: d.proceeds, Fees: d.fees, Matches: d.matches}
+			for _, match := range d.matches {
+				disposal.Cost = disposal.Cost.Add(match.Cost)
+			}
+			disposal.Gain = disposal.Proceeds.Sub(disposal.Fees).Sub(disposal.Cost)
+			disposals = append(disposals, disposal)
+		}
+	}
+	sort.Slice(disposals, func(i, j int) bool {
+		if !disposals[i].Date.Equal(disposals[j].Date) {
+			return disposals[i].Date.Before(disposals[j].Date)
+		}
+		return disposals[i].Ticker < disposals[j].Ticker
+	})
+
+	return buildReport(trades, disposals, pools), nil
+}
+
+// findDay returns the trading day for a share, creating it when needed
+func findDay(days map[string][]*tradingDay, ticker string, date time.Time) *tradingDay {
+	for _, d := range days[ticker] {
+		if d.date.Equal(date) {
+			return d
+		}
+	}
+	d := &tradingDay{date: date}
+	days[ticker] = append(days[ticker], d)
+	return d
+}
+
+// matchSameDay matches each day's sales with that day's purchases
+func matchSameDay(days []*tradingDay) {
+	for _, d := range days {
+		d.unmatchedBought, d.unmatchedCost, d.unmatchedSold = d.bought, d.boughtCost, d.sold
+		quantity := minDecimal(d.bought, d.sold)
+		if quantity.IsZero() {
+			continue
+		}
+		cost := d.take(quantity)
+		d.unmatchedSold = d.unmatchedSold.Sub(quantity)
+		d.matches = append(d.matches, Match{Rule: RuleSameDay, Quantity: quantity, Cost: cost, AcquiredOn: d.date})
+	}
+}
+
+// matchBedAndBreakfast matches remaining sales with purchases in the 30 days
+// after them, taking earlier sales and earlier purchases first
+func matchBedAndBreakfast(days []*tradingDay) {
+	for i, d := range days {
+		limit := d.date.AddDate(0, 0, bedAndBreakfastDays)
+		for _, later := range days[i+1:] {
+			if d.unmatchedSold.IsZero() || later.date.After(limit) {
+				break
+			}
+			quantity := minDecimal(d.unmatchedSold, later.unmatchedBought)
+			if quantity.IsZero() {
+				continue
+			}
+			cost := later.take(quantity)
+			d.unmatchedSold = d.unmatchedSold.Sub(quantity)
+			d.matches = append(d.matches, Match{Rule: RuleBedAndBreakfast, Quantity: quantity, Cost: cost, AcquiredOn: later.date})
+		}
+	}
+}
+
+// matchSection104 adds unmatched purchases to the pool and matches unmatched
+// sales with it at average cost, returning the pool after each day
+func matchSection104(ticker string, days []*tradingDay) ([]poolState, error) {
+	pool := Pool{Ticker: ticker}
+	states := make([]poolState, 0, len(days))
+	for _, d := range days {
+		pool.Quantity = pool.Quantity.Add(d.unmatchedBought)
+		pool.Cost = pool.Cost.Add(d.unmatchedCost)
+
+		if quantity := d.unmatchedSold; !quantity.IsZero() {
+			if quantity.Cmp(pool.Quantity) > 0 {
+				return nil, fmt.Errorf("disposal of %s %s on %s exceeds the %s shares held", quantity, ticker, d.date.Format("2006-01-02"), pool.Quantity)
+			}
+			cost := pool.Cost.MulDiv(quantity, pool.Quantity)
+			pool.Quantity = pool.Quantity.Sub(quantity)
+			pool.Cost = pool.Cost.Sub(cost)
+			d.unmatchedSold = trading212.Decimal{}
+			d.matches = append(d.matches, Match{Rule: RuleSection104, Quantity: quantity, Cost: cost})
+		}
+		states = append(states, poolState{date: d.date, pool: pool})
+	}
+	return states, nil
+}
+
+// take removes quantity shares from the day's unmatched purchases and returns
+// their share of the cost
+func (d *tradingDay) take(quantity trading212.Decimal) trading212.Decimal {
+	cost := d.unmatchedCost.MulDiv(quantity, d.unmatchedBought)
+	d.unmatchedBought = d.unmatchedBought.Sub(quantity)
+	d.unmatchedCost = d.unmatchedCost.Sub(cost)
+	return cost
+}
+
+// buildReport groups disposals and pool states into tax years from the first
+// trade to the last
+func buildReport(trades []trading212.Trade, disposals []Disposal, pools map[string][]poolState) *Report {
+	report := &Report{Currency: ReportCurrency}
+	if len(trades) == 0 {
+		return report
+	}
+
+	first, last := trades[0].Time, trades[0].Time
+	for _, trade := range trades {
+		if trade.Time.Before(first) {
+			first = trade.Time
+		}
+		if trade.Time.After(last) {
+			last = trade.Time
+		}
+	}
+
+	tickers := make([]string, 0, len(pools))
+	for ticker := range pools {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	for year := TaxYearOf(first); year <= TaxYearOf(last); year++ {
+		yearReport := YearReport{TaxYear: year, Disposals: []Disposal{}, Pools: []Pool{}}
+		for _, disposal := range disposals {
+			if !year.Contains(disposal.Date) {
+				continue
+			}
+			yearReport.Disposals = append(yearReport.Disposals, disposal)
+			yearReport.Proceeds = yearReport.Proceeds.Add(disposal.Proceeds)
+			yearReport.Costs = yearReport.Costs.Add(disposal.Cost).Add(disposal.Fees)
+			if disposal.Gain.Sign() >= 0 {
+				yearReport.Gains = yearReport.Gains.Add(disposal.Gain)
+			} else {
+				yearReport.Losses = yearReport.Losses.Sub(disposal.Gain)
+			}
+		}
+		yearReport.NetGain = yearReport.Gains.Sub(yearReport.Losses)
+
+		for _, ticker := range tickers {
+			if pool, held := poolAt(pools[ticker], year.End()); held {
+				yearReport.Pools = append(yearReport.Pools, pool)
+			}
+		}
+		report.Years = append(report.Years, yearReport)
+	}
+	return report
+}
+
+// poolAt returns the last pool state before the given time, and false when no
+// shares were held
+func poolAt(states []poolState, before time.Time) (Pool, bool) {
+	var pool Pool
+	for _, state := range states {
+		if !state.date.Before(before) {
+			break
+		}
+		pool = state.pool
+	}
+	return pool, !pool.Quantity.IsZero()
+}
+
+// minDecimal returns the smaller of a and b
+func minDecimal(a, b trading212.Decimal) trading212.Decimal {
+	if a.Cmp(b) < 0 {
+		return a
+	}
+	return b
+}
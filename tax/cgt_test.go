@@ -0,0 +1,219 @@
+package tax
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/corporate"
+	"github.com/0xnu/trading212/fx"
+)
+
+// gbpTrade returns a trade in pounds with the given gross value and fees
+func gbpTrade(ticker, date string, quantity int64, value, fees string) trading212.Trade {
+	at, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		panic(err)
+	}
+	return trading212.Trade{
+		ID:       ticker + date,
+		Ticker:   ticker,
+		Time:     at.Add(12 * time.Hour),
+		Quantity: trading212.NewDecimalFromInt(quantity),
+		Value:    trading212.MustParseDecimal(value),
+		Fees:     trading212.MustParseDecimal(fees),
+		Currency: "GBP",
+	}
+}
+
+// TestCapitalGainsMatchingRules tests same-day, bed-and-breakfast and Section
+// 104 matching of one disposal, and pools carried across tax years
+func TestCapitalGainsMatchingRules(t *testing.T) {
+	trades := []trading212.Trade{
+		gbpTrade("VOD", "2023-05-01", 1000, "3990", "10"),
+		gbpTrade("VOD", "2023-09-01", 500, "2500", "0"),
+		gbpTrade("VOD", "2024-01-10", -700, "4200", "10"),
+		gbpTrade("VOD", "2024-01-10", 100, "620", "0"),
+		gbpTrade("VOD", "2024-01-25", 200, "1100", "0"),
+		gbpTrade("VOD", "2024-05-01", -1100, "4000", "0"),
+	}
+
+	report, err := CapitalGains(trades, nil, nil)
+	if err != nil {
+		t.Fatalf("CapitalGains() error = %v", err)
+	}
+	if len(report.Years) != 2 {
+		t.Fatalf("report has %d years, want 2", len(report.Years))
+	}
+
+	year, _ := report.Year(2023)
+	if len(year.Disposals) != 1 {
+		t.Fatalf("2023/24 has %d disposals, want 1", len(year.Disposals))
+	}
+	disposal := year.Disposals[0]
+
+	wantMatches := []struct {
+		rule     string
+		quantity string
+		cost     string
+	}{
+		{rule: RuleSameDay, quantity: "100", cost: "620"},
+		{rule: RuleBedAndBreakfast, quantity: "200", cost: "1100"},
+		{rule: RuleSection104, quantity: "400", cost: "1733.33333333"},
+	}
+	if len(disposal.Matches) != len(wantMatches) {
+		t.Fatalf("Matches = %+v", disposal.Matches)
+	}
+	for i, want := range wantMatches {
+		match := disposal.Matches[i]
+		if match.Rule != want.rule || match.Quantity.String() != want.quantity || match.Cost.String() != want.cost {
+			t.Errorf("match %d = %s %s %s, want %s %s %s", i, match.Rule, match.Quantity, match.Cost, want.rule, want.quantity, want.cost)
+		}
+	}
+	if disposal.Gain.String() != "736.66666667" || year.Gains != disposal.Gain || !year.Losses.IsZero() {
+		t.Errorf("2023/24 gain = %s, gains %s, losses %s", disposal.Gain, year.Gains, year.Losses)
+	}
+	if len(year.Pools) != 1 || year.Pools[0].Quantity.String() != "1100" || year.Pools[0].Cost.String() != "4766.66666667" {
+		t.Errorf("2023/24 pools = %+v", year.Pools)
+	}
+
+	next, _ := report.Year(2024)
+	if next.NetGain.String() != "-766.66666667" || next.Losses.String() != "766.66666667" || len(next.Pools) != 0 {
+		t.Errorf("2024/25 = net %s, losses %s, pools %+v", next.NetGain, next.Losses, next.Pools)
+	}
+}
+
+// TestCapitalGainsBedAndBreakfastWindow tests that purchases more than 30 days
+// after a sale go to the pool
+func TestCapitalGainsBedAndBreakfastWindow(t *testing.T) {
+	trades := []trading212.Trade{
+		gbpTrade("BP", "2024-06-01", 10, "100", "0"),
+		gbpTrade("BP", "2024-06-10", -10, "150", "0"),
+		gbpTrade("BP", "2024-07-10", 10, "80", "0"),
+		gbpTrade("BP", "2024-07-11", 10, "90", "0"),
+	}
+
+	report, err := CapitalGains(trades, nil, nil)
+	if err != nil {
+		t.Fatalf("CapitalGains() error = %v", err)
+	}
+	year, _ := report.Year(2024)
+	match := year.Disposals[0].Matches[0]
+	if len(year.Disposals[0].Matches) != 1 || match.Rule != RuleBedAndBreakfast || match.Cost.String() != "80" {
+		t.Errorf("Matches = %+v", year.Disposals[0].Matches)
+	}
+	if len(year.Pools) != 1 || year.Pools[0].Cost.String() != "190" {
+		t.Errorf("Pools = %+v", year.Pools)
+	}
+}
+
+// TestCapitalGainsFX tests conversion of foreign trades at the trade date rate
+func TestCapitalGainsFX(t *testing.T) {
+	rates := fx.NewHistoricalRates()
+	rates.Add("GBP", "USD", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), trading212.MustParseDecimal("1.25"))
+	rates.Add("GBP", "USD", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), trading212.MustParseDecimal("1.6"))
+
+	trades := []trading212.Trade{
+		{Ticker: "AAPL", Time: time.Date(2024, 2, 1, 15, 0, 0, 0, time.UTC), Quantity: trading212.NewDecimalFromInt(10), Price: trading212.NewDecimalFromInt(100), PriceCurrency: "USD", Currency: "GBP"},
+		{Ticker: "AAPL", Time: time.Date(2024, 7, 1, 15, 0, 0, 0, time.UTC), Quantity: trading212.NewDecimalFromInt(-10), Price: trading212.NewDecimalFromInt(120), PriceCurrency: "USD", Fees: trading212.MustParseDecimal("1.5"), Currency: "GBP"},
+	}
+
+	report, err := CapitalGains(trades, rates, nil)
+	if err != nil {
+		t.Fatalf("CapitalGains() error = %v", err)
+	}
+	// Cost 1000 USD at 0.8 is £800, proceeds 1200 USD at 0.625 are £750.
+	year, _ := report.Year(2024)
+	if disposal := year.Disposals[0]; disposal.Proceeds.String() != "750" || disposal.Cost.String() != "800" || disposal.Gain.String() != "-51.5" {
+		t.Errorf("disposal = %+v", disposal)
+	}
+
+	if _, err := CapitalGains(trades, nil, nil); err == nil {
+		t.Error("Expected error without USD rates")
+	}
+}
+
+// TestCapitalGainsLargeQuantity tests that the pooled cost of a disposal does
+// not overflow when cost × quantity is beyond the range of a Decimal
+func TestCapitalGainsLargeQuantity(t *testing.T) {
+	trades := []trading212.Trade{
+		gbpTrade("LLOY", "2024-06-01", 2000000, "200000", "0"),
+		gbpTrade("LLOY", "2024-08-01", 500000, "60000", "0"),
+		gbpTrade("LLOY", "2024-09-20", -1000000, "150000", "0"),
+	}
+
+	report, err := CapitalGains(trades, nil, nil)
+	if err != nil {
+		t.Fatalf("CapitalGains() error = %v", err)
+	}
+	year, _ := report.Year(2024)
+	if len(year.Disposals) != 1 || year.Disposals[0].Cost.String() != "104000" || year.Disposals[0].Gain.String() != "46000" {
+		t.Errorf("disposals = %+v", year.Disposals)
+	}
+	if len(year.Pools) != 1 || year.Pools[0].Quantity.String() != "1500000" || year.Pools[0].Cost.String() != "156000" {
+		t.Errorf("pools = %+v", year.Pools)
+	}
+}
+
+// TestCapitalGainsOversold tests that selling more than is held is an error
+func TestCapitalGainsOversold(t *testing.T) {
+	trades := []trading212.Trade{
+		gbpTrade("BP", "2024-06-01", 5, "50", "0"),
+		gbpTrade("BP", "2024-06-02", -6, "60", "0"),
+	}
+	if _, err := CapitalGains(trades, nil, nil); err == nil {
+		t.Error("Expected error for selling more shares than held")
+	}
+}
+
+// TestCapitalGainsActions tests that a split between a purchase and a sale
+// is applied before matching
+func TestCapitalGainsActions(t *testing.T) {
+	trades := []trading212.Trade{
+		gbpTrade("VOD", "2024-01-10", 100, "1000", "0"),
+		gbpTrade("VOD", "2024-03-01", -500, "600", "0"),
+	}
+	split := corporate.Action{Type: corporate.Split, Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Ticker: "VOD", Ratio: corporate.Ratio{New: trading212.NewDecimalFromInt(10), Old: trading212.NewDecimalFromInt(1)}}
+
+	if _, err := CapitalGains(trades, nil, nil); err == nil {
+		t.Error("Expected error for selling more pre-split shares than held")
+	}
+
+	report, err := CapitalGains(trades, nil, corporate.Actions{split})
+	if err != nil {
+		t.Fatalf("CapitalGains() error = %v", err)
+	}
+	year, _ := report.Year(2023)
+	if len(year.Disposals) != 1 || year.Disposals[0].Cost.String() != "500" || year.Disposals[0].Gain.String() != "100" {
+		t.Errorf("disposals = %+v", year.Disposals)
+	}
+	if len(year.Pools) != 1 || year.Pools[0].Quantity.String() != "500" || year.Pools[0].Cost.String() != "500" {
+		t.Errorf("pools = %+v", year.Pools)
+	}
+}
+
+// TestTaxYear tests tax year boundaries and formatting
+func TestTaxYear(t *testing.T) {
+	tests := []struct {
+		at   time.Time
+		want string
+	}{
+		{at: time.Date(2024, 4, 5, 12, 0, 0, 0, time.UTC), want: "2023/24"},
+		{at: time.Date(2024, 4, 6, 12, 0, 0, 0, time.UTC), want: "2024/25"},
+		{at: time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC), want: "1999/00"},
+	}
+	for _, tt := range tests {
+		if got := TaxYearOf(tt.at).String(); got != tt.want {
+			t.Errorf("TaxYearOf(%v) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"2024/25", "2024-25", "2024"} {
+		if year, err := ParseTaxYear(input); err != nil || year != 2024 {
+			t.Errorf("ParseTaxYear(%q) = %v, %v", input, year, err)
+		}
+	}
+	if _, err := ParseTaxYear("last year"); err == nil {
+		t.Error("Expected error for an invalid tax year")
+	}
+}
@@ -0,0 +1,285 @@
+package tax
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/fx"
+)
+
+// DefaultWithholdingRates are the withholding rates a UK resident normally
+// pays on dividends paid in each currency, after treaty relief
+var DefaultWithholdingRates = map[string]float64{
+	"GBP": 0,
+	"GBX": 0,
+	"USD": 0.15,
+	"CAD": 0.15,
+	"JPY": 0.15315,
+	"EUR": 0.30,
+	"CHF": 0.35,
+	"DKK": 0.27,
+	"NOK": 0.25,
+	"SEK": 0.30,
+}
+
+// withholdingTolerance is how far above the expected rate withholding may be
+// before it is highlighted, allowing for rounding by the payer
+const withholdingTolerance = 0.01
+
+// DividendPayment is one dividend. Gross and withholding are normally in the
+// instrument currency and net in the account currency. Withholding is zero
+// when the source does not report it, in which case it is the difference
+// between gross and net.
+type DividendPayment struct {
+	Ticker      string           `json:"ticker"`
+	Name        string           `json:"name,omitempty"`
+	PaidOn      time.Time        `json:"paidOn"`
+	Quantity    float64          `json:"quantity"`
+	Gross       trading212.Money `json:"gross"`
+	Withholding trading212.Money `json:"withholding"`
+	Net         trading212.Money `json:"net"`
+}
+
+// DividendLine totals one instrument's dividends in one currency and tax year.
+// Gross and Withholding are in the payment currency; the Account amounts are
+// converted to the account currency at the rate on each payment date.
+type DividendLine struct {
+	TaxYear            TaxYear          `json:"taxYear"`
+	Ticker             string           `json:"ticker"`
+	Name               string           `json:"name,omitempty"`
+	Currency           string           `json:"currency"`
+	Payments           int              `json:"payments"`
+	Gross              trading212.Money `json:"gross"`
+	Withholding        trading212.Money `json:"withholding"`
+	AccountGross       trading212.Money `json:"accountGross"`
+	AccountWithholding trading212.Money `json:"accountWithholding"`
+	AccountNet         trading212.Money `json:"accountNet"`
+	WithholdingRate    float64          `json:"withholdingRate"`
+	HighWithholding    bool             `json:"highWithholding"`
+	Warning            string           `json:"warning,omitempty"`
+}
+
+// DividendYear totals a tax year's dividends in the account currency
+type DividendYear struct {
+	TaxYear     TaxYear            `json:"taxYear"`
+	Gross       trading212.Decimal `json:"gross"`
+	Withholding trading212.Decimal `json:"withholding"`
+	Net         trading212.Decimal `json:"net"`
+}
+
+// DividendReport is dividend income grouped by tax year, instrument and currency
+type DividendReport struct {
+	Currency string         `json:"currency"`
+	Lines    []DividendLine `json:"lines"`
+	Years    []DividendYear `json:"years"`
+}
+
+// DividendsFromHistory converts paid dividends from the API. The gross amount
+// is the gross amount per share times the quantity, in the instrument's
+// currency, and the net amount is in the account currency.
+func DividendsFromHistory(dividends []trading212.Dividend, instruments []trading212.Instrument, accountCurrency string) []DividendPayment {
+	metadata := make(map[string]trading212.Instrument, len(instruments))
+	for _, instrument := range instruments {
+		metadata[instrument.Ticker] = instrument
+	}
+
+	payments := make([]DividendPayment, 0, len(dividends))
+	for _, dividend := range dividends {
+		instrument := metadata[dividend.Ticker]
+		currency := instrument.CurrencyCode
+		if currency == "" {
+			currency = accountCurrency
+		}
+		payments = append(payments, DividendPayment{
+			Ticker:   dividend.Ticker,
+			Name:     instrument.Name,
+			PaidOn:   dividend.PaidOn,
+			Quantity: dividend.Quantity,
+			Gross:    trading212.NewMoney(dividend.GrossAmountPerShare.Mul(trading212.NewDecimalFromFloat(dividend.Quantity)), currency),
+			Net:      trading212.NewMoney(dividend.Amount, accountCurrency),
+		})
+	}
+	return payments
+}
+
+// DividendsFromExport converts the dividend rows of an account history export,
+// which report the gross amount per share and the tax withheld
+func DividendsFromExport(rows []trading212.ExportRow) []DividendPayment {
+	var payments []DividendPayment
+	for _, row := range rows {
+		if !row.IsDividend() {
+			continue
+		}
+		withholdingCurrency := row.WithholdingTaxCurrency
+		if withholdingCurrency == "" {
+			withholdingCurrency = row.PriceCurrency
+		}
+		payments = append(payments, DividendPayment{
+			Ticker:      row.Ticker,
+			Name:        row.Name,
+			PaidOn:      row.Time,
+			Quantity:    row.Shares,
+			Gross:       trading212.NewMoney(row.Price.Mul(trading212.NewDecimalFromFloat(row.Shares)), row.PriceCurrency),
+			Withholding: trading212.NewMoney(row.WithholdingTax.Abs(), withholdingCurrency),
+			Net:         trading212.NewMoney(row.Total, row.TotalCurrency),
+		})
+	}
+	return payments
+}
+
+// DividendIncome groups dividends by tax year, instrument and currency and
+// converts them to the account currency using source. Lines whose effective
+// withholding rate is above the expected rate for their currency are
+// highlighted; expected may be nil to use DefaultWithholdingRates.
+func DividendIncome(payments []DividendPayment, accountCurrency string, source fx.RateSource, expected map[string]float64) (*DividendReport, error) {
+	if expected == nil {
+		expected = DefaultWithholdingRates
+	}
+	accountCurrency = strings.ToUpper(accountCurrency)
+
+	type key struct {
+		year     TaxYear
+		ticker   string
+		currency string
+	}
+	lines := make(map[key]*DividendLine)
+	years := make(map[TaxYear]*DividendYear)
+
+	for _, payment := range payments {
+		amounts, err := convertDividend(payment, accountCurrency, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s dividend of %s: %v", payment.Ticker, payment.PaidOn.Format("2006-01-02"), err)
+		}
+
+		year := TaxYearOf(payment.PaidOn)
+		k := key{year: year, ticker: payment.Ticker, currency: amounts.currency}
+		line, exists := lines[k]
+		if !exists {
+			line = &DividendLine{
+				TaxYear:            year,
+				Ticker:             payment.Ticker,
+				Name:               payment.Name,
+				Currency:           amounts.currency,
+				Gross:              trading212.NewMoney(trading212.Decimal{}, amounts.currency),
+				Withholding:        trading212.NewMoney(trading212.Decimal{}, amounts.currency),
+				AccountGross:       trading212.NewMoney(trading212.Decimal{}, accountCurrency),
+				AccountWithholding: trading212.NewMoney(trading212.Decimal{}, accountCurrency),
+				AccountNet:         trading212.NewMoney(trading212.Decimal{}, accountCurrency),
+			}
+			lines[k] = line
+		}
+		line.Payments++
+		line.Gross.Amount = line.Gross.Amount.Add(amounts.gross)
+		line.Withholding.Amount = line.Withholding.Amount.Add(amounts.withholding)
+		line.AccountGross.Amount = line.AccountGross.Amount.Add(amounts.accountGross)
+		line.AccountWithholding.Amount = line.AccountWithholding.Amount.Add(amounts.accountWithholding)
+		line.AccountNet.Amount = line.AccountNet.Amount.Add(amounts.accountNet)
+
+		total, exists := years[year]
+		if !exists {
+			total = &DividendYear{TaxYear: year}
+			years[year] = total
+		}
+		total.Gross = total.Gross.Add(amounts.accountGross)
+		total.Withholding = total.Withholding.Add(amounts.accountWithholding)
+		total.Net = total.Net.Add(amounts.accountNet)
+	}
+
+	report := &DividendReport{Currency: accountCurrency, Lines: []DividendLine{}, Years: []DividendYear{}}
+	for _, line := range lines {
+		checkWithholding(line, expected)
+		report.Lines = append(report.Lines, *line)
+	}
+	sort.Slice(report.Lines, func(i, j int) bool {
+		a, b := report.Lines[i], report.Lines[j]
+		if a.TaxYear != b.TaxYear {
+			return a.TaxYear < b.TaxYear
+		}
+		if a.Ticker != b.Ticker {
+			return a.Ticker < b.Ticker
+		}
+		return a.Currency < b.Currency
+	})
+
+	for _, year := range years {
+		report.Years = append(report.Years, *year)
+	}
+	sort.Slice(report.Years, func(i, j int) bool { return report.Years[i].TaxYear < report.Years[j].TaxYear })
+	return report, nil
+}
+
+// dividendAmounts is a payment in its own currency and the account currency
+type dividendAmounts struct {
+	currency                                     string
+	gross, withholding                           trading212.Decimal
+	accountGross, accountWithholding, accountNet trading212.Decimal
+}
+
+// convertDividend converts a payment to the account currency, deriving the
+// withholding from gross and net when it is not reported
+func convertDividend(payment DividendPayment, accountCurrency string, source fx.RateSource) (dividendAmounts, error) {
+	amounts := dividendAmounts{currency: payment.Gross.Currency}
+	if amounts.currency == "" {
+		amounts.currency = accountCurrency
+	}
+
+	net, err := fx.Convert(source, payment.Net, accountCurrency, payment.PaidOn)
+	if err != nil {
+		return dividendAmounts{}, err
+	}
+	amounts.accountNet = net.Amount
+
+	toAccount, err := fx.Rate(source, amounts.currency, accountCurrency, payment.PaidOn)
+	if err != nil {
+		return dividendAmounts{}, err
+	}
+
+	if !payment.Withholding.IsZero() {
+		withholding, err := fx.Convert(source, payment.Withholding, amounts.currency, payment.PaidOn)
+		if err != nil {
+			return dividendAmounts{}, err
+		}
+		amounts.withholding = withholding.Amount
+		amounts.accountWithholding = withholding.Amount.Mul(toAccount)
+	}
+
+	switch {
+	case payment.Gross.IsZero():
+		amounts.accountGross = amounts.accountNet.Add(amounts.accountWithholding)
+		amounts.gross = amounts.accountGross.Div(toAccount)
+	case payment.Withholding.IsZero():
+		amounts.gross = payment.Gross.Amount
+		amounts.accountGross = amounts.gross.Mul(toAccount)
+		if difference := amounts.accountGross.Sub(amounts.accountNet); difference.Sign() > 0 {
+			amounts.accountWithholding = difference
+			amounts.withholding = difference.Div(toAccount)
+		}
+	default:
+		amounts.gross = payment.Gross.Amount
+		amounts.accountGross = amounts.gross.Mul(toAccount)
+	}
+	return amounts, nil
+}
+
+// checkWithholding sets the line's effective withholding rate and highlights
+// it when it is above the rate expected for the currency
+func checkWithholding(line *DividendLine, expected map[string]float64) {
+	if line.AccountGross.Amount.Sign() <= 0 {
+		return
+	}
+	line.WithholdingRate = line.AccountWithholding.Amount.Div(line.AccountGross.Amount).Round(4, trading212.RoundHalfEven).Float64()
+
+	want, known := expected[line.Currency]
+	if !known || line.WithholdingRate <= want+withholdingTolerance {
+		return
+	}
+
+	line.HighWithholding = true
+	line.Warning = fmt.Sprintf("withholding of %.1f%% is above the expected %.1f%%", line.WithholdingRate*100, want*100)
+	if line.Currency == "USD" {
+		line.Warning += "; check that a W-8BEN form is on file"
+	}
+}
@@ -0,0 +1,86 @@
+package tax
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xnu/trading212"
+	"github.com/0xnu/trading212/fx"
+)
+
+// TestDividendIncome tests grouping, conversion and withholding checks
+func TestDividendIncome(t *testing.T) {
+	rates := fx.StaticRates{{From: "GBP", To: "USD"}: trading212.MustParseDecimal("1.25")}
+	payments := []DividendPayment{
+		{
+			Ticker: "AAPL", PaidOn: time.Date(2024, 5, 16, 12, 0, 0, 0, time.UTC),
+			Gross:       trading212.NewMoney(trading212.NewDecimalFromInt(10), "USD"),
+			Withholding: trading212.NewMoney(trading212.MustParseDecimal("1.5"), "USD"),
+			Net:         trading212.NewMoney(trading212.MustParseDecimal("6.8"), "GBP"),
+		},
+		{
+			Ticker: "AAPL", PaidOn: time.Date(2024, 8, 15, 12, 0, 0, 0, time.UTC),
+			Gross: trading212.NewMoney(trading212.NewDecimalFromInt(10), "USD"),
+			Net:   trading212.NewMoney(trading212.MustParseDecimal("6.8"), "GBP"),
+		},
+		{
+			Ticker: "KO", PaidOn: time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC),
+			Gross:       trading212.NewMoney(trading212.NewDecimalFromInt(20), "USD"),
+			Withholding: trading212.NewMoney(trading212.NewDecimalFromInt(6), "USD"),
+			Net:         trading212.NewMoney(trading212.MustParseDecimal("11.2"), "GBP"),
+		},
+		{
+			Ticker: "VOD", PaidOn: time.Date(2024, 2, 2, 12, 0, 0, 0, time.UTC),
+			Gross: trading212.NewMoney(trading212.NewDecimalFromInt(500), "GBX"),
+			Net:   trading212.NewMoney(trading212.NewDecimalFromInt(5), "GBP"),
+		},
+	}
+
+	report, err := DividendIncome(payments, "gbp", rates, nil)
+	if err != nil {
+		t.Fatalf("DividendIncome() error = %v", err)
+	}
+	if len(report.Lines) != 3 || len(report.Years) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	vod, aapl, ko := report.Lines[0], report.Lines[1], report.Lines[2]
+	if vod.TaxYear != 2023 || vod.AccountGross.String() != "5.00 GBP" || vod.WithholdingRate != 0 || vod.HighWithholding {
+		t.Errorf("VOD = %+v", vod)
+	}
+	if aapl.Payments != 2 || aapl.Gross.String() != "20.00 USD" || aapl.Withholding.String() != "3.00 USD" || aapl.AccountNet.String() != "13.60 GBP" {
+		t.Errorf("AAPL = %+v", aapl)
+	}
+	if aapl.WithholdingRate != 0.15 || aapl.HighWithholding {
+		t.Errorf("AAPL withholding = %v %v", aapl.WithholdingRate, aapl.HighWithholding)
+	}
+	if ko.WithholdingRate != 0.3 || !ko.HighWithholding || !strings.Contains(ko.Warning, "W-8BEN") {
+		t.Errorf("KO = %+v", ko)
+	}
+
+	if year := report.Years[1]; year.TaxYear != 2024 || year.Gross.String() != "32" || year.Withholding.String() != "7.2" || year.Net.String() != "24.8" {
+		t.Errorf("2024/25 = %+v", year)
+	}
+
+	if _, err := DividendIncome(payments, "GBP", nil, nil); err == nil {
+		t.Error("Expected error without USD rates")
+	}
+}
+
+// TestDividendsFromExport tests reading gross and withholding from export rows
+func TestDividendsFromExport(t *testing.T) {
+	rows := []trading212.ExportRow{
+		{Action: "Market buy", Ticker: "AAPL"},
+		{Action: "Dividend (Ordinary)", Ticker: "AAPL", Time: time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC), Shares: 40, Price: trading212.MustParseDecimal("0.25"),
+			PriceCurrency: "USD", WithholdingTax: trading212.MustParseDecimal("1.5"), WithholdingTaxCurrency: "USD", Total: trading212.MustParseDecimal("6.8"), TotalCurrency: "GBP"},
+	}
+
+	payments := DividendsFromExport(rows)
+	if len(payments) != 1 {
+		t.Fatalf("DividendsFromExport() returned %d payments, want 1", len(payments))
+	}
+	if p := payments[0]; p.Gross.String() != "10.00 USD" || p.Withholding.String() != "1.50 USD" || p.Net.String() != "6.80 GBP" {
+		t.Errorf("payment = %+v", p)
+	}
+}
@@ -0,0 +1,90 @@
+// Package tax computes UK capital gains and dividend income reports from
+// account history.
+package tax
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// london is the time zone that decides which day, and so which tax year, a
+// trade falls in
+var london = loadLocation("Europe/London")
+
+// loadLocation returns the named time zone, or UTC when the system has no
+// time zone database
+func loadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// TaxYear is a UK tax year running from 6 April to 5 April, identified by the
+// calendar year in which it starts
+type TaxYear int
+
+// TaxYearOf returns the tax year containing t
+func TaxYearOf(t time.Time) TaxYear {
+	t = t.In(london)
+	year := t.Year()
+	if t.Month() < time.April || (t.Month() == time.April && t.Day() < 6) {
+		year--
+	}
+	return TaxYear(year)
+}
+
+// ParseTaxYear parses a tax year written as "2024/25", "2024-25" or "2024"
+func ParseTaxYear(s string) (TaxYear, error) {
+	first, _, _ := strings.Cut(strings.ReplaceAll(strings.TrimSpace(s), "-", "/"), "/")
+	year, err := strconv.Atoi(first)
+	if err != nil || year < 1900 || year > 9999 {
+		return 0, fmt.Errorf("invalid tax year %q", s)
+	}
+	return TaxYear(year), nil
+}
+
+// Start returns the first moment of the tax year, 6 April in London
+func (y TaxYear) Start() time.Time {
+	return time.Date(int(y), time.April, 6, 0, 0, 0, 0, london)
+}
+
+// End returns the first moment after the tax year
+func (y TaxYear) End() time.Time {
+	return (y + 1).Start()
+}
+
+// Contains reports whether t falls within the tax year
+func (y TaxYear) Contains(t time.Time) bool {
+	return TaxYearOf(t) == y
+}
+
+// String formats the tax year as "2024/25"
+func (y TaxYear) String() string {
+	return fmt.Sprintf("%d/%02d", int(y), (int(y)+1)%100)
+}
+
+// MarshalText encodes the tax year as "2024/25"
+func (y TaxYear) MarshalText() ([]byte, error) {
+	return []byte(y.String()), nil
+}
+
+// UnmarshalText decodes a tax year written as "2024/25"
+func (y *TaxYear) UnmarshalText(text []byte) error {
+	parsed, err := ParseTaxYear(string(text))
+	if err != nil {
+		return err
+	}
+	*y = parsed
+	return nil
+}
+
+// day returns midnight in London on the day of t, which is the unit HMRC
+// matching rules work in
+func day(t time.Time) time.Time {
+	t = t.In(london)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, london)
+}
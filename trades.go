@@ -0,0 +1,122 @@
+package trading212
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Trade is a filled buy or sell normalised from order history or an account
+// history export, for use by cost basis, tax and performance calculations
+type Trade struct {
+	ID       string    `json:"id"`
+	Ticker   string    `json:"ticker"`
+	ISIN     string    `json:"isin,omitempty"`
+	Time     time.Time `json:"time"`
+	Quantity Decimal   `json:"quantity"`
+	Price    Decimal   `json:"price"`
+
+	// PriceCurrency is the currency of Price, GBX for pence
+	PriceCurrency string `json:"priceCurrency"`
+
+	// Value is the gross value of the trade before fees in Currency, or zero
+	// when only the price is known
+	Value Decimal `json:"value,omitzero"`
+
+	// Fees are taxes and charges paid on the trade in Currency
+	Fees Decimal `json:"fees,omitzero"`
+
+	// Currency is the account currency in which Value and Fees are paid
+	Currency string `json:"currency"`
+}
+
+// IsBuy reports whether the trade adds shares
+func (t Trade) IsBuy() bool {
+	return t.Quantity.Sign() > 0
+}
+
+// Shares returns the number of shares traded, which is always positive
+func (t Trade) Shares() Decimal {
+	return t.Quantity.Abs()
+}
+
+// TradesFromOrders returns the filled orders as trades, oldest first. Sells
+// have a negative quantity. The instruments provide the price currency, and
+// fees are the order's taxes in the account currency.
+func TradesFromOrders(orders []HistoricalOrder, instruments []Instrument, accountCurrency string) []Trade {
+	currencies := make(map[string]string, len(instruments))
+	for _, instrument := range instruments {
+		currencies[instrument.Ticker] = instrument.CurrencyCode
+	}
+
+	var trades []Trade
+	for _, order := range orders {
+		if order.FilledQuantity == 0 || order.FillPrice.IsZero() || (order.Status != "" && order.Status != "FILLED") {
+			continue
+		}
+
+		var fees Decimal
+		for _, tax := range order.Taxes {
+			fees = fees.Add(tax.Quantity.Abs())
+		}
+
+		executed := order.DateExecuted
+		if executed.IsZero() {
+			executed = order.DateModified
+		}
+
+		trades = append(trades, Trade{
+			ID:            strconv.FormatInt(order.ID, 10),
+			Ticker:        order.Ticker,
+			Time:          executed,
+			Quantity:      NewDecimalFromFloat(order.FilledQuantity),
+			Price:         order.FillPrice,
+			PriceCurrency: strings.ToUpper(currencies[order.Ticker]),
+			Fees:          fees,
+			Currency:      strings.ToUpper(accountCurrency),
+		})
+	}
+	SortTrades(trades)
+	return trades
+}
+
+// TradesFromExport returns the buys and sells in an account history export as
+// trades, oldest first. The export's total includes fees, which are separated
+// out so that Value is the gross value.
+func TradesFromExport(rows []ExportRow) []Trade {
+	var trades []Trade
+	for _, row := range rows {
+		if !row.IsBuy() && !row.IsSell() {
+			continue
+		}
+
+		quantity := NewDecimalFromFloat(row.Shares)
+		value := row.Total.Sub(row.Fees())
+		if row.IsSell() {
+			quantity = quantity.Neg()
+			value = row.Total.Add(row.Fees())
+		}
+
+		trades = append(trades, Trade{
+			ID:            row.ID,
+			Ticker:        row.Ticker,
+			ISIN:          row.ISIN,
+			Time:          row.Time,
+			Quantity:      quantity,
+			Price:         row.Price,
+			PriceCurrency: strings.ToUpper(row.PriceCurrency),
+			Value:         value,
+			Fees:          row.Fees(),
+			Currency:      strings.ToUpper(row.TotalCurrency),
+		})
+	}
+	SortTrades(trades)
+	return trades
+}
+
+// SortTrades orders trades by time, keeping the original order of trades at
+// the same time
+func SortTrades(trades []Trade) {
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].Time.Before(trades[j].Time) })
+}
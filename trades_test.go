@@ -0,0 +1,48 @@
+package trading212
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTradesFromOrders tests that only fills become trades, oldest first
+func TestTradesFromOrders(t *testing.T) {
+	orders := []HistoricalOrder{
+		{ID: 2, Ticker: "AAPL_US_EQ", Status: "FILLED", FilledQuantity: -1.5, FillPrice: MustParseDecimal("190"), DateExecuted: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			Taxes: []Tax{{Name: "CURRENCY_CONVERSION_FEE", Quantity: MustParseDecimal("-0.43")}}},
+		{ID: 1, Ticker: "AAPL_US_EQ", Status: "FILLED", FilledQuantity: 2, FillPrice: MustParseDecimal("180"), DateExecuted: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 3, Ticker: "AAPL_US_EQ", Status: "CANCELLED", OrderedQuantity: 1},
+	}
+	instruments := []Instrument{{Ticker: "AAPL_US_EQ", CurrencyCode: "USD"}}
+
+	trades := TradesFromOrders(orders, instruments, "gbp")
+	if len(trades) != 2 {
+		t.Fatalf("TradesFromOrders() returned %d trades, want 2", len(trades))
+	}
+	if trades[0].ID != "1" || !trades[0].IsBuy() || trades[0].PriceCurrency != "USD" || trades[0].Currency != "GBP" {
+		t.Errorf("first trade = %+v", trades[0])
+	}
+	if sell := trades[1]; sell.IsBuy() || sell.Shares().String() != "1.5" || sell.Fees.String() != "0.43" || !sell.Value.IsZero() {
+		t.Errorf("sell = %+v", sell)
+	}
+}
+
+// TestTradesFromExport tests that fees are separated from export totals
+func TestTradesFromExport(t *testing.T) {
+	rows := []ExportRow{
+		{Action: "Deposit", Total: NewDecimalFromInt(1000), TotalCurrency: "GBP"},
+		{Action: "Market sell", Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Ticker: "BP", Shares: 10, Price: NewDecimalFromInt(480), PriceCurrency: "GBX", Total: MustParseDecimal("47.5"), TotalCurrency: "GBP", ConversionFee: MustParseDecimal("0.5")},
+		{Action: "Limit buy", Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Ticker: "BP", Shares: 20, Price: NewDecimalFromInt(450), PriceCurrency: "GBX", Total: MustParseDecimal("90.5"), TotalCurrency: "GBP", StampDuty: MustParseDecimal("0.5")},
+	}
+
+	trades := TradesFromExport(rows)
+	if len(trades) != 2 {
+		t.Fatalf("TradesFromExport() returned %d trades, want 2", len(trades))
+	}
+	if buy := trades[0]; !buy.IsBuy() || buy.Value.String() != "90" || buy.Fees.String() != "0.5" {
+		t.Errorf("buy = %+v", buy)
+	}
+	if sell := trades[1]; sell.Quantity.String() != "-10" || sell.Value.String() != "48" {
+		t.Errorf("sell = %+v", sell)
+	}
+}